@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -10,27 +11,44 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
+	"phResume/internal/api"
 	"phResume/internal/auth"
 	"phResume/internal/config"
 	"phResume/internal/database"
+	"phResume/internal/redisclient"
 )
 
 func main() {
 	var (
-		username = flag.String("username", "", "初始管理员用户名（必填）")
-		dbHost   = flag.String("db-host", "", "数据库 Host（可选，默认读 DATABASE_HOST）")
-		dbPort   = flag.Int("db-port", 0, "数据库 Port（可选，默认读 DATABASE_PORT）")
-		dbName   = flag.String("db-name", "", "数据库名（可选，默认读 POSTGRES_DB）")
-		dbUser   = flag.String("db-user", "", "数据库用户（可选，默认读 POSTGRES_USER）")
-		dbPass   = flag.String("db-password", "", "数据库密码（可选，默认读 POSTGRES_PASSWORD）")
-		sslMode  = flag.String("db-sslmode", "", "数据库 SSLMODE（可选，默认读 DATABASE_SSLMODE）")
+		username  = flag.String("username", "", "初始管理员用户名（必填）")
+		dbHost    = flag.String("db-host", "", "数据库 Host（可选，默认读 DATABASE_HOST）")
+		dbPort    = flag.Int("db-port", 0, "数据库 Port（可选，默认读 DATABASE_PORT）")
+		dbName    = flag.String("db-name", "", "数据库名（可选，默认读 POSTGRES_DB）")
+		dbUser    = flag.String("db-user", "", "数据库用户（可选，默认读 POSTGRES_USER）")
+		dbPass    = flag.String("db-password", "", "数据库密码（可选，默认读 POSTGRES_PASSWORD）")
+		sslMode   = flag.String("db-sslmode", "", "数据库 SSLMODE（可选，默认读 DATABASE_SSLMODE）")
+		promote   = flag.Bool("promote", false, "将已存在的用户授予管理员权限，而不是创建新账号")
+		unlock    = flag.Bool("unlock", false, "立即解除该用户名的登录锁定并清零失败计数（读 REDIS_HOST/REDIS_PORT）")
+		invite    = flag.Bool("create-invite", false, "生成一次性注册邀请码（API_REGISTRATION_MODE=invite，读 REDIS_HOST/REDIS_PORT），无需 --username")
+		inviteTTL = flag.Duration("invite-ttl", 7*24*time.Hour, "邀请码有效期（配合 --create-invite）")
 	)
 	flag.Parse()
 
-	u := strings.TrimSpace(*username)
+	if *invite {
+		code, err := createInvite(*inviteTTL)
+		if err != nil {
+			log.Fatalf("create invite code: %v", err)
+		}
+		fmt.Printf("已生成一次性邀请码（%s 内有效，注册成功后失效）：\n%s\n", inviteTTL.String(), code)
+		return
+	}
+
+	u := database.NormalizeUsername(*username)
 	if u == "" {
 		log.Fatal("missing required flag: --username")
 	}
@@ -49,11 +67,33 @@ func main() {
 		log.Fatalf("auto migrate: %v", err)
 	}
 
+	if *unlock {
+		if err := unlockLogin(u); err != nil {
+			log.Fatalf("unlock user: %v", err)
+		}
+		// 同时清理数据库兜底的锁定状态（API_LOGIN_LOCK_DB_FALLBACK）。
+		if err := api.ClearUsernameDBLock(context.Background(), db, u); err != nil {
+			log.Fatalf("clear db login lock: %v", err)
+		}
+		fmt.Printf("已解除用户 %s 的登录锁定。\n", u)
+		return
+	}
+
 	var existing database.User
-	switch err := db.Where("username = ?", u).First(&existing).Error; {
+	switch err := database.WhereUsername(db, u).Order("id").First(&existing).Error; {
 	case err == nil:
-		log.Fatalf("user %q already exists", u)
+		if !*promote {
+			log.Fatalf("user %q already exists (use --promote to grant admin)", u)
+		}
+		if err := db.Model(&existing).Update("is_admin", true).Error; err != nil {
+			log.Fatalf("promote user: %v", err)
+		}
+		fmt.Printf("已授予用户 %s 管理员权限（重新登录或刷新令牌后生效）。\n", u)
+		return
 	case errors.Is(err, gorm.ErrRecordNotFound):
+		if *promote {
+			log.Fatalf("user %q not found", u)
+		}
 	default:
 		log.Fatalf("query user: %v", err)
 	}
@@ -72,6 +112,7 @@ func main() {
 		Username:           u,
 		PasswordHash:       hashed,
 		MustChangePassword: true,
+		IsAdmin:            true,
 	}
 	if err := db.Create(&user).Error; err != nil {
 		log.Fatalf("create user: %v", err)
@@ -83,6 +124,58 @@ func main() {
 	fmt.Printf("提示：请立即登录并修改密码（该密码仅显示一次）。\n")
 }
 
+// unlockLogin 直接删除 Redis 中该用户名的锁定键与失败计数，不经过 API 进程。
+func unlockLogin(username string) error {
+	client, err := newRedisClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return api.ClearUsernameLoginLock(context.Background(), client, username)
+}
+
+// createInvite 在 Redis 中写入一次性邀请码，API 注册成功时消费。
+func createInvite(ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", errors.New("invite ttl must be positive")
+	}
+	client, err := newRedisClient()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	return api.CreateInviteCode(context.Background(), client, ttl)
+}
+
+// newRedisClient 按 REDIS_MODE 与 REDIS_HOST / REDIS_PORT（或哨兵/集群的 REDIS_ADDRS、REDIS_SENTINEL_MASTER）连接 API 使用的 Redis。
+func newRedisClient() (redis.UniversalClient, error) {
+	cfg := config.RedisConfig{
+		Host:           strings.TrimSpace(os.Getenv("REDIS_HOST")),
+		Port:           6379,
+		Mode:           strings.ToLower(strings.TrimSpace(os.Getenv("REDIS_MODE"))),
+		SentinelMaster: strings.TrimSpace(os.Getenv("REDIS_SENTINEL_MASTER")),
+	}
+	if cfg.Host == "" {
+		cfg.Host = "localhost"
+	}
+	if env := strings.TrimSpace(os.Getenv("REDIS_PORT")); env != "" {
+		p, err := strconv.Atoi(env)
+		if err != nil {
+			return nil, fmt.Errorf("parse REDIS_PORT: %w", err)
+		}
+		cfg.Port = p
+	}
+	for _, addr := range strings.Split(os.Getenv("REDIS_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.Addrs = append(cfg.Addrs, addr)
+		}
+	}
+	if cfg.Mode != "" && cfg.Mode != redisclient.ModeStandalone && len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("REDIS_ADDRS is required when REDIS_MODE=%s", cfg.Mode)
+	}
+	return redisclient.New(cfg), nil
+}
+
 func loadDatabaseConfig(host string, port int, name, user, password, sslmode string) (config.DatabaseConfig, error) {
 	if strings.TrimSpace(host) == "" {
 		host = os.Getenv("DATABASE_HOST")
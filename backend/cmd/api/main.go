@@ -7,19 +7,31 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/redis/go-redis/v9"
 
 	"phResume/internal/api"
 	"phResume/internal/api/middleware"
 	"phResume/internal/auth"
+	"phResume/internal/buildinfo"
 	"phResume/internal/config"
 	"phResume/internal/database"
 	"phResume/internal/metrics"
+	"phResume/internal/notify"
+	"phResume/internal/redisclient"
+	resumepkg "phResume/internal/resume"
 	"phResume/internal/storage"
+	"phResume/internal/tracecontext"
+)
+
+// 构建信息，由 -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..." 注入。
+var (
+	version   string
+	commit    string
+	buildTime string
 )
 
 func main() {
@@ -34,11 +46,26 @@ func main() {
 	slogLogger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(slogLogger)
 
+	build := buildinfo.New(version, commit, buildTime)
+	slogLogger.Info("api build", slog.String("version", build.Version), slog.String("commit", build.Commit))
+
+	// 未配置 OTEL_EXPORTER_OTLP_ENDPOINT 时 span 只用于生成 traceparent，不会导出。
+	shutdownTracing := tracecontext.Setup(cfg.Tracing.OTLPEndpoint, "phresume-api", slogLogger)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("flush trace spans: %v", err)
+		}
+	}()
+
 	authService, err := auth.NewAuthService(
+		cfg.JWT.Algorithm,
 		cfg.JWT.PrivateKeyPEM,
 		cfg.JWT.PublicKeyPEM,
 		cfg.JWT.AccessTokenTTL,
 		cfg.JWT.RefreshTokenTTL,
+		cfg.JWT.SessionLifetime,
 	)
 	if err != nil {
 		log.Fatalf("init auth service: %v", err)
@@ -50,10 +77,14 @@ func main() {
 	}
 	log.Printf("database connection ready")
 
-	if err := db.AutoMigrate(&database.User{}, &database.Resume{}, &database.Template{}, &database.Asset{}); err != nil {
-		log.Fatalf("auto migrate: %v", err)
+	if cfg.Database.AutoMigrate {
+		if err := database.Migrate(db); err != nil {
+			log.Fatalf("migrate database: %v", err)
+		}
+		log.Printf("database migrated")
+	} else {
+		log.Printf("database auto migrate disabled; run cmd/migrate before deploying schema changes")
 	}
-	log.Printf("database migrated")
 
 	storageClient, err := storage.NewClient(cfg.MinIO)
 	if err != nil {
@@ -63,8 +94,7 @@ func main() {
 	address := fmt.Sprintf(":%d", cfg.API.Port)
 	log.Printf("api listening on %s", address)
 
-	redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	redisClient := redisclient.New(cfg.Redis)
 	defer func() {
 		if err := redisClient.Close(); err != nil {
 			log.Printf("close redis client: %v", err)
@@ -73,8 +103,9 @@ func main() {
 	if err := redisClient.Ping(context.Background()).Err(); err != nil {
 		log.Fatalf("ping redis: %v", err)
 	}
+	notify.SetNamespace(cfg.Redis.NotifyNamespace)
 
-	asynqClient := asynq.NewClient(asynq.RedisClientOpt{Addr: redisAddr})
+	asynqClient := asynq.NewClient(redisclient.AsynqOpt(cfg.Redis))
 	defer func() {
 		if err := asynqClient.Close(); err != nil {
 			log.Printf("close asynq client: %v", err)
@@ -82,41 +113,123 @@ func main() {
 	}()
 
 	clamdAddr := fmt.Sprintf("tcp://%s:%s", cfg.ClamAV.Host, cfg.ClamAV.Port)
+	virusScanner := api.NewClamdScanner(clamdAddr)
+	// clamd 不可用时 API 仍可启动（仅上传受影响），这里提前告警，避免等到用户上传才暴露故障。
+	pingCtx, cancelPing := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := api.PingVirusScanner(pingCtx, virusScanner); err != nil {
+		slogLogger.Warn("clamav is unreachable, uploads will fail until it recovers",
+			slog.String("addr", clamdAddr),
+			slog.Any("error", err),
+		)
+	} else {
+		log.Printf("clamav reachable at %s", clamdAddr)
+	}
+	cancelPing()
+
+	loginChallenge, err := auth.NewChallengeVerifier(cfg.API.LoginChallengeProvider, cfg.API.LoginChallengeToken)
+	if err != nil {
+		log.Fatalf("init login challenge verifier: %v", err)
+	}
+
+	gridLimits := resumepkg.GridLimits{
+		MaxColumns: cfg.API.GridMaxColumns,
+		MaxRows:    cfg.API.GridMaxRows,
+	}
+	// 起始简历对所有新用户可见：配置的文件非法时拒绝启动，而不是静默回退。
+	defaultResumeContent, err := resumepkg.LoadDefaultContent(cfg.API.DefaultResumeContent, resumepkg.ContentLimits{
+		MaxBytes: cfg.API.TemplateMaxBytes,
+		MaxItems: cfg.API.TemplateMaxItems,
+		Grid:     gridLimits,
+	})
+	if err != nil {
+		log.Fatalf("load default resume content: %v", err)
+	}
 
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(metrics.GinMiddleware())
 	router.Use(middleware.CorrelationIDMiddleware())
-	router.Use(middleware.SlogLoggerMiddleware(slogLogger))
+	router.Use(middleware.LocaleMiddleware(cfg.API.DefaultLocale))
+	router.Use(middleware.SlogLoggerMiddleware(slogLogger, cfg.API.SlowRequestThreshold))
+	router.Use(middleware.GzipMiddleware(cfg.API.GzipMinBytes))
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	router.GET("/ready", api.ReadinessHandler(2*time.Second,
+		api.ReadinessCheck{Name: "database", Check: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		api.ReadinessCheck{Name: "redis", Check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}},
+		api.ReadinessCheck{Name: "clamav", Optional: !cfg.ClamAV.ReadyRequired, Check: func(ctx context.Context) error {
+			return api.PingVirusScanner(ctx, virusScanner)
+		}},
+	))
+	router.GET("/version", gin.WrapF(buildinfo.Handler(build)))
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
-
-	api.RegisterRoutes(
-		router,
-		db,
-		asynqClient,
-		authService,
-		redisClient,
-		slogLogger,
-		storageClient,
-		cfg.InternalAPISecret,
-		clamdAddr,
-		cfg.API.MaxResumes,
-		cfg.API.MaxTemplates,
-		cfg.API.MaxAssetsPerUser,
-		cfg.API.MaxUploadsPerDay,
-		cfg.API.AllowedOrigins,
-		cfg.API.LoginRateLimitPerHour,
-		cfg.API.LoginLockThreshold,
-		cfg.API.LoginLockTTL,
-		cfg.API.PdfRateLimitPerHour,
-		cfg.API.PdfDownloadTokenTTL,
-		cfg.API.UploadMaxBytes,
-		cfg.API.UploadMIMEWhitelist,
-		cfg.API.CookieDomain,
-	)
+	api.RegisterFallbackHandlers(router)
+
+	api.RegisterRoutes(router, api.RouteDeps{
+		DB:           db,
+		Asynq:        asynqClient,
+		AuthService:  authService,
+		Redis:        redisClient,
+		Logger:       slogLogger,
+		Storage:      storageClient,
+		VirusScanner: virusScanner,
+	}, api.RouteOptions{
+		InternalAPISecret:          cfg.InternalAPISecret,
+		AllowedOrigins:             cfg.API.AllowedOrigins,
+		MaxJSONBodyBytes:           cfg.API.MaxJSONBodyBytes,
+		PasswordGateDBCheck:        cfg.API.PasswordGateDBCheck,
+		PrintStrictImageMIME:       cfg.API.PrintStrictImageMIME,
+		PreviewURLTTL:              cfg.MinIO.PresignPreviewTTL,
+		GridLimits:                 gridLimits,
+		MaxResumes:                 cfg.API.MaxResumes,
+		PdfRateLimitPerHour:        cfg.API.PdfRateLimitPerHour,
+		PdfDownloadTokenTTL:        cfg.API.PdfDownloadTokenTTL,
+		RejectDuplicateResumeTitle: cfg.API.RejectDupResumeTitle,
+		DefaultResumeContent:       defaultResumeContent,
+		MaxTemplates:               cfg.API.MaxTemplates,
+		TemplateMaxBytes:           cfg.API.TemplateMaxBytes,
+		TemplateMaxItems:           cfg.API.TemplateMaxItems,
+		TemplatePublishReview:      cfg.API.TemplatePublishReview,
+		Auth: api.AuthHandlerOptions{
+			LoginRateLimitPerHour:   cfg.API.LoginRateLimitPerHour,
+			LoginUsernameLock:       cfg.API.LoginUsernameLock,
+			LoginLockThreshold:      cfg.API.LoginLockThreshold,
+			LoginLockTTL:            cfg.API.LoginLockTTL,
+			LoginIPLockThreshold:    cfg.API.LoginIPLockThreshold,
+			LoginIPLockTTL:          cfg.API.LoginIPLockTTL,
+			LoginLockDBFallback:     cfg.API.LoginLockDBFallback,
+			Challenge:               loginChallenge,
+			ChallengeThreshold:      cfg.API.LoginChallengeAfter,
+			CookieDomain:            cfg.API.CookieDomain,
+			CookieSameSite:          cfg.API.CookieSameSite,
+			CookieSecure:            cfg.API.CookieSecure,
+			AccessTokenCookie:       cfg.API.AccessTokenCookie,
+			RegistrationMode:        cfg.API.RegistrationMode,
+			RegistrationInviteCodes: cfg.API.RegistrationInviteCodes,
+			RefreshBinding:          cfg.API.RefreshBinding,
+		},
+		Asset: api.AssetHandlerOptions{
+			MaxAssetsPerUser: cfg.API.MaxAssetsPerUser,
+			MaxUploadsPerDay: cfg.API.MaxUploadsPerDay,
+			MaxBytes:         cfg.API.UploadMaxBytes,
+			MIMEWhitelist:    cfg.API.UploadMIMEWhitelist,
+			ListURLTTL:       cfg.MinIO.PresignAssetListTTL,
+			ViewURLTTL:       cfg.MinIO.PresignAssetViewTTL,
+			ScanTimeout:      cfg.ClamAV.ScanTimeout,
+			StorageQuota:     cfg.API.StorageQuotaBytes,
+			ExtensionCheck:   cfg.API.UploadExtensionCheck,
+			OpaqueKeys:       cfg.API.AssetOpaqueKeys,
+		},
+	})
 
 	if err := router.Run(address); err != nil {
 		log.Fatalf("failed to start api server: %v", err)
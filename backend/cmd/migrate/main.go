@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"log/slog"
+	"os"
+
+	"phResume/internal/config"
+	"phResume/internal/database"
+)
+
+// migrate 在发布新版本前单独执行数据库迁移，读取与 API/Worker 相同的环境变量；
+// 生产环境关闭 DATABASE_AUTO_MIGRATE，避免多个服务启动时并发迁移。
+func main() {
+	cfg := config.MustLoad()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	db, err := database.InitDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("init database: %v", err)
+	}
+	if err := database.Migrate(db); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+	log.Printf("database migrated")
+}
@@ -2,40 +2,71 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/redis/go-redis/v9"
 
+	"phResume/internal/buildinfo"
 	"phResume/internal/config"
 	"phResume/internal/database"
 	"phResume/internal/metrics"
+	"phResume/internal/notify"
+	"phResume/internal/redisclient"
 	"phResume/internal/storage"
 	"phResume/internal/tasks"
+	"phResume/internal/tracecontext"
 	"phResume/internal/worker"
 )
 
+// 构建信息，由 -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..." 注入。
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
 func main() {
 	cfg := config.MustLoad()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
+	build := buildinfo.New(version, commit, buildTime)
+	logger.Info("worker build", slog.String("version", build.Version), slog.String("commit", build.Commit))
+
+	shutdownTracing := tracecontext.Setup(cfg.Tracing.OTLPEndpoint, "phresume-worker", logger)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("flush trace spans failed", slog.Any("error", err))
+		}
+	}()
+
+	if err := worker.CheckBrowserBin(cfg.Worker.BrowserBin); err != nil {
+		log.Fatalf("check browser binary: %v", err)
+	}
+
 	db, err := database.InitDatabase(cfg.Database)
 	if err != nil {
 		log.Fatalf("init database: %v", err)
 	}
 	log.Println("database connection ready for worker")
 
-	if err := db.AutoMigrate(&database.User{}, &database.Resume{}, &database.Template{}, &database.Asset{}); err != nil {
-		log.Fatalf("auto migrate: %v", err)
+	if cfg.Database.AutoMigrate {
+		if err := database.Migrate(db); err != nil {
+			log.Fatalf("migrate database: %v", err)
+		}
+		log.Println("worker database migrated")
 	}
-	log.Println("worker database migrated")
 
 	storageClient, err := storage.NewClient(cfg.MinIO)
 	if err != nil {
@@ -43,8 +74,7 @@ func main() {
 	}
 	log.Printf("storage client ready, bucket=%s", cfg.MinIO.Bucket)
 
-	redisAddr := fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port)
-	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	redisClient := redisclient.New(cfg.Redis)
 	defer func() {
 		if err := redisClient.Close(); err != nil {
 			logger.Error("close redis client failed", slog.Any("error", err))
@@ -54,49 +84,131 @@ func main() {
 	if err := redisClient.Ping(context.Background()).Err(); err != nil {
 		log.Fatalf("ping redis: %v", err)
 	}
+	notify.SetNamespace(cfg.Redis.NotifyNamespace)
 
-	redisOpt := asynq.RedisClientOpt{Addr: redisAddr}
+	redisOpt := redisclient.AsynqOpt(cfg.Redis)
 	server := asynq.NewServer(redisOpt, asynq.Config{
 		Concurrency: cfg.Worker.Concurrency,
+		// 退出时等待在途渲染完成，超时未完成的任务交还队列，避免部署时中途截断 PDF 导出。
+		ShutdownTimeout: cfg.Worker.ShutdownTimeout,
+		// 用户并发渲染超限只是延后执行，不应消耗重试次数或计入失败统计。
+		IsFailure: func(err error) bool {
+			return !errors.Is(err, worker.ErrUserRenderLimit)
+		},
 	})
 
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsMux.Handle("/version", buildinfo.Handler(build))
+	metricsServer := &http.Server{
+		Addr:              cfg.Worker.MetricsAddr,
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
 	go func() {
-		metricsMux := http.NewServeMux()
-		metricsMux.Handle("/metrics", promhttp.Handler())
-
 		logger.Info("worker metrics server started", slog.String("addr", cfg.Worker.MetricsAddr))
-		if err := http.ListenAndServe(cfg.Worker.MetricsAddr, metricsMux); err != nil {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("could not start worker metrics server: %v", err)
 		}
 	}()
 
 	internalSecret := cfg.InternalAPISecret
 
-	pdfHandler := worker.NewPDFTaskHandler(
-		db,
-		storageClient,
-		redisClient,
-		logger,
-		internalSecret,
-		cfg.Worker.InternalAPIBaseURL,
-		cfg.Worker.FrontendBaseURL,
-	)
-	templatePreviewHandler := worker.NewTemplatePreviewHandler(
-		db,
-		storageClient,
-		logger,
-		internalSecret,
-		cfg.Worker.InternalAPIBaseURL,
-		cfg.Worker.FrontendBaseURL,
-	)
+	// PDF 与预览任务共享同一个渲染名额池，独立于 asynq 并发数限制同时运行的浏览器数量。
+	renderSemaphore := worker.NewRenderSemaphore(cfg.Worker.MaxConcurrentRenders)
+
+	renderOpts := worker.RenderOptions{
+		InternalSecret:              internalSecret,
+		InternalAPIBaseURL:          cfg.Worker.InternalAPIBaseURL,
+		FrontendBaseURL:             cfg.Worker.FrontendBaseURL,
+		BrowserLaunchAttempts:       cfg.Worker.BrowserLaunchAttempts,
+		BrowserLaunchBackoff:        cfg.Worker.BrowserLaunchBackoff,
+		BrowserBin:                  cfg.Worker.BrowserBin,
+		BrowserFlags:                cfg.Worker.BrowserFlags,
+		RenderSemaphore:             renderSemaphore,
+		PreviewFormat:               cfg.Worker.PreviewFormat,
+		PreviewQuality:              cfg.Worker.PreviewQuality,
+		MaxConcurrentRendersPerUser: cfg.Worker.MaxRendersPerUser,
+		RenderMode:                  cfg.Worker.RenderMode,
+		HTMLToPDFMaxBytes:           cfg.Worker.HTMLToPDFMaxBytes,
+	}
+
+	// 内部 HTML 转 PDF 接口默认关闭；开启后独立监听，不与指标端口混用。
+	var htmlToPDFServer *http.Server
+	if cfg.Worker.HTMLToPDFAddr != "" {
+		htmlToPDFServer = worker.NewHTMLToPDFServer(cfg.Worker.HTMLToPDFAddr, worker.NewHTMLToPDFHandler(logger, renderOpts))
+		go func() {
+			logger.Info("worker html to pdf server started", slog.String("addr", cfg.Worker.HTMLToPDFAddr))
+			if err := htmlToPDFServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("could not start worker html to pdf server: %v", err)
+			}
+		}()
+	}
+
+	pdfHandler := worker.NewPDFTaskHandler(db, storageClient, redisClient, logger, renderOpts)
+	pdfBundleHandler := worker.NewPDFBundleHandler(pdfHandler, cfg.Worker.PDFBundleLinkTTL)
+	templatePreviewHandler := worker.NewTemplatePreviewHandler(db, storageClient, logger, renderOpts)
+	resumePreviewHandler := worker.NewResumePreviewHandler(db, storageClient, logger, renderOpts)
+	accountCleanupHandler := worker.NewAccountCleanupHandler(storageClient, logger)
+	maintenanceHandler := worker.NewMaintenanceHandler(db, storageClient, logger, cfg.Worker.ResumeEventRetention)
 
 	mux := asynq.NewServeMux()
 	mux.Use(metrics.AsynqMetricsMiddleware())
 	mux.Handle(tasks.TypePDFGenerate, pdfHandler)
+	mux.Handle(tasks.TypePDFBundle, pdfBundleHandler)
 	mux.Handle(tasks.TypeTemplatePreview, templatePreviewHandler)
+	mux.Handle(tasks.TypeResumePreview, resumePreviewHandler)
+	mux.Handle(tasks.TypeAccountCleanup, accountCleanupHandler)
+	mux.Handle(tasks.TypeMaintenance, maintenanceHandler)
+
+	// 多个 Worker 副本各自运行调度器，Unique 保证同一间隔内只入队一次维护任务。
+	maintenanceOpts := []asynq.Option{asynq.Unique(cfg.Worker.MaintenanceInterval), asynq.MaxRetry(1)}
+	scheduler := asynq.NewScheduler(redisOpt, nil)
+	if _, err := scheduler.Register("@every "+cfg.Worker.MaintenanceInterval.String(), tasks.NewMaintenanceTask(), maintenanceOpts...); err != nil {
+		log.Fatalf("register maintenance task: %v", err)
+	}
+	// 启动时先跑一轮，让升级前未记录过期时间的缩略图链接尽快续签。
+	enqueueClient := asynq.NewClient(redisOpt)
+	if _, err := enqueueClient.Enqueue(tasks.NewMaintenanceTask(), maintenanceOpts...); err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		logger.Warn("enqueue startup maintenance task failed", slog.Any("error", err))
+	}
+	if err := enqueueClient.Close(); err != nil {
+		logger.Warn("close asynq client failed", slog.Any("error", err))
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	logger.Info("worker service started", slog.String("redis_addr", redisAddr))
-	if err := server.Run(mux); err != nil {
-		logger.Error("worker server stopped", slog.Any("error", err))
+	logger.Info("worker service started", slog.String("redis", redisclient.Describe(cfg.Redis)))
+	if err := server.Start(mux); err != nil {
+		logger.Error("worker server start failed", slog.Any("error", err))
+		return
+	}
+	if err := scheduler.Start(); err != nil {
+		logger.Error("maintenance scheduler start failed", slog.Any("error", err))
+		server.Shutdown()
+		return
+	}
+
+	<-signalCtx.Done()
+	logger.Info("shutdown signal received, draining in-flight tasks", slog.Duration("timeout", cfg.Worker.ShutdownTimeout))
+
+	scheduler.Shutdown()
+	// Shutdown 先停止拉取新任务，再等待在途任务（及其浏览器进程）结束，超时未完成的任务交还队列重试。
+	server.Shutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("shutdown metrics server failed", slog.Any("error", err))
+	}
+	if htmlToPDFServer != nil {
+		if err := htmlToPDFServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("shutdown html to pdf server failed", slog.Any("error", err))
+		}
 	}
+	logger.Info("worker stopped")
 }
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+// POST /v1/admin/users/:id/unlock
+// 管理员立即解除账号的登录锁定并清零失败计数，无需等待 API_LOGIN_LOCK_TTL 过期。
+// 仅处理用户名维度的锁定；IP 封禁针对来源而非账号，不在此解除。
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		BadRequest(c, "invalid user id")
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := h.loggerFromContext(c)
+
+	var user database.User
+	if err := h.db.WithContext(ctx).Select("id", "username", "locked_until").First(&user, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			NotFound(c, "user not found")
+			return
+		}
+		logger.Error("unlock user: query failed", slog.Any("error", err))
+		Internal(c, "internal error")
+		return
+	}
+
+	// 锁定键以登录时的小写用户名为准。
+	wasLocked := h.loginAttempts.usernameLockRemaining(ctx, strings.ToLower(user.Username)) > 0 ||
+		h.loginAttempts.dbLockRemaining(user) > 0
+	if err := h.loginAttempts.unlockUsername(ctx, user.Username); err != nil {
+		logger.Error("unlock user: clear login lock failed", slog.Any("error", err))
+		Internal(c, "failed to unlock user")
+		return
+	}
+	if err := h.loginAttempts.clearDBLock(ctx, user.ID); err != nil {
+		logger.Error("unlock user: clear db lock failed", slog.Any("error", err))
+		Internal(c, "failed to unlock user")
+		return
+	}
+
+	adminID, _ := userIDFromContext(c)
+	logger.Info("audit: user login lock cleared",
+		slog.Uint64("user_id", uint64(user.ID)),
+		slog.Uint64("admin_id", uint64(adminID)),
+		slog.Bool("was_locked", wasLocked),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         user.ID,
+		"username":   user.Username,
+		"was_locked": wasLocked,
+	})
+}
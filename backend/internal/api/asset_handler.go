@@ -2,6 +2,8 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,30 +13,48 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dutchcoders/go-clamd"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"phResume/internal/api/middleware"
 	"phResume/internal/database"
+	"phResume/internal/errcode"
 	"phResume/internal/storage"
 )
 
 type assetStore interface {
 	CountByUser(ctx context.Context, userID uint) (int64, error)
-	ListByUser(ctx context.Context, userID uint, limit int) ([]database.Asset, error)
-	Create(ctx context.Context, asset database.Asset) error
+	ListByUser(ctx context.Context, userID uint, limit int, after *assetCursor) ([]database.Asset, error)
+	// Create 写入资产记录；quota > 0 时用量超出配额返回 errStorageQuotaExceeded 且不写入。
+	Create(ctx context.Context, asset database.Asset, quota int64) error
 	FindByUserAndKey(ctx context.Context, userID uint, objectKey string) (database.Asset, error)
 	DeleteByID(ctx context.Context, id uint) error
+	FindByUserAndKeys(ctx context.Context, userID uint, objectKeys []string) ([]database.Asset, error)
+	DeleteByIDs(ctx context.Context, ids []uint) error
+	StorageBytesByUser(ctx context.Context, userID uint) (int64, error)
+	UsageByUser(ctx context.Context, userID uint) (assetUsage, error)
+	EnsureAssetKeyDir(ctx context.Context, userID uint) (string, error)
+}
+
+// assetUsage 为用户的资产用量聚合值，读取自 users 表上增量维护的计数列。
+type assetUsage struct {
+	AssetCount   int64
+	StorageBytes int64
 }
 
 type assetStorage interface {
 	UploadFile(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (*minio.UploadInfo, error)
 	GeneratePresignedURL(ctx context.Context, objectKey string, expiry time.Duration) (string, error)
 	DeleteObject(ctx context.Context, objectKey string) error
+	DeleteObjects(ctx context.Context, objectKeys []string) map[string]error
+}
+
+type assetURLPresigner interface {
+	GeneratePresignedURL(ctx context.Context, objectKey string, expiry time.Duration) (string, error)
 }
 
 type assetCounter interface {
@@ -62,11 +82,16 @@ func (s *gormAssetStore) CountByUser(ctx context.Context, userID uint) (int64, e
 	return count, nil
 }
 
-func (s *gormAssetStore) ListByUser(ctx context.Context, userID uint, limit int) ([]database.Asset, error) {
+// ListByUser 按 created_at、id 倒序分页列出资产；after 为上一页游标，nil 表示第一页。
+func (s *gormAssetStore) ListByUser(ctx context.Context, userID uint, limit int, after *assetCursor) ([]database.Asset, error) {
 	var assets []database.Asset
-	if err := s.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	if err := query.
 		Order("created_at desc").
+		Order("id desc").
 		Limit(limit).
 		Find(&assets).Error; err != nil {
 		return nil, err
@@ -74,8 +99,85 @@ func (s *gormAssetStore) ListByUser(ctx context.Context, userID uint, limit int)
 	return assets, nil
 }
 
-func (s *gormAssetStore) Create(ctx context.Context, asset database.Asset) error {
-	return s.db.WithContext(ctx).Create(&asset).Error
+// assetCursor 为 ListAssets 的翻页游标：上一页最后一条资产的 created_at 与 id。
+type assetCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+var errInvalidAssetCursor = errors.New("invalid asset cursor")
+
+// encodeAssetCursor 将资产位置编码为不透明游标（base64url("<unix_nano>.<id>")）。
+func encodeAssetCursor(a database.Asset) string {
+	raw := strconv.FormatInt(a.CreatedAt.UnixNano(), 10) + "." + strconv.FormatUint(uint64(a.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAssetCursor(value string) (assetCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return assetCursor{}, errInvalidAssetCursor
+	}
+	nanosStr, idStr, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return assetCursor{}, errInvalidAssetCursor
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return assetCursor{}, errInvalidAssetCursor
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return assetCursor{}, errInvalidAssetCursor
+	}
+	return assetCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: uint(id)}, nil
+}
+
+// errStorageQuotaExceeded 表示写入资产后用户存储用量会超出配额。
+var errStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// Create 写入资产记录并在同一事务中按配额条件累加用户的存储用量，超额时整体回滚。
+func (s *gormAssetStore) Create(ctx context.Context, asset database.Asset, quota int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reserved, err := database.ReserveStorageBytes(tx, asset.UserID, asset.Size, quota)
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			return errStorageQuotaExceeded
+		}
+		if err := tx.Create(&asset).Error; err != nil {
+			return err
+		}
+		return database.AdjustAssetCount(tx, asset.UserID, 1)
+	})
+}
+
+// StorageBytesByUser 返回用户当前的存储用量（增量维护的 users.storage_bytes，无需扫描资产表）。
+func (s *gormAssetStore) StorageBytesByUser(ctx context.Context, userID uint) (int64, error) {
+	var user database.User
+	if err := s.db.WithContext(ctx).Select("storage_bytes").Where("id = ?", userID).Limit(1).Find(&user).Error; err != nil {
+		return 0, err
+	}
+	return user.StorageBytes, nil
+}
+
+// UsageByUser 一次读取用户的资产数与存储用量聚合值；用户不存在时返回零值。
+func (s *gormAssetStore) UsageByUser(ctx context.Context, userID uint) (assetUsage, error) {
+	var user database.User
+	if err := s.db.WithContext(ctx).Select("asset_count", "storage_bytes").Where("id = ?", userID).Limit(1).Find(&user).Error; err != nil {
+		return assetUsage{}, err
+	}
+	return assetUsage{AssetCount: user.AssetCount, StorageBytes: user.StorageBytes}, nil
+}
+
+// EnsureAssetKeyDir 返回用户的不透明资产目录，首次调用时随机分配并写入 users.asset_key_dir。
+func (s *gormAssetStore) EnsureAssetKeyDir(ctx context.Context, userID uint) (string, error) {
+	candidate, err := newAssetKeyDir()
+	if err != nil {
+		return "", err
+	}
+	return database.EnsureAssetKeyDir(s.db.WithContext(ctx), userID, candidate)
 }
 
 func (s *gormAssetStore) FindByUserAndKey(ctx context.Context, userID uint, objectKey string) (database.Asset, error) {
@@ -87,7 +189,47 @@ func (s *gormAssetStore) FindByUserAndKey(ctx context.Context, userID uint, obje
 }
 
 func (s *gormAssetStore) DeleteByID(ctx context.Context, id uint) error {
-	return s.db.WithContext(ctx).Delete(&database.Asset{}, id).Error
+	return s.DeleteByIDs(ctx, []uint{id})
+}
+
+func (s *gormAssetStore) FindByUserAndKeys(ctx context.Context, userID uint, objectKeys []string) ([]database.Asset, error) {
+	var assets []database.Asset
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND object_key IN ?", userID, objectKeys).
+		Find(&assets).Error
+	return assets, err
+}
+
+func (s *gormAssetStore) DeleteByIDs(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	// 删除记录与扣减用量放在同一事务中，按 RETURNING 得到的实际删除行汇总大小：
+	// 并发删除同一资产时只有真正删除该行的请求扣减用量。
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var assets []database.Asset
+		if err := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "user_id"}, {Name: "size"}}}).
+			Where("id IN ?", ids).
+			Delete(&assets).Error; err != nil {
+			return err
+		}
+		freed := make(map[uint]assetUsage, 1)
+		for _, a := range assets {
+			usage := freed[a.UserID]
+			usage.AssetCount++
+			usage.StorageBytes += a.Size
+			freed[a.UserID] = usage
+		}
+		for userID, usage := range freed {
+			if err := database.AdjustAssetCount(tx, userID, -usage.AssetCount); err != nil {
+				return err
+			}
+			if err := database.AdjustStorageBytes(tx, userID, -usage.StorageBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // AssetHandler 负责处理资产上传与访问。
@@ -95,29 +237,79 @@ type AssetHandler struct {
 	store            assetStore
 	Storage          assetStorage
 	Logger           *slog.Logger
-	ClamdAddr        string
+	Scanner          VirusScanner
 	MaxBytes         int
 	MIMEWhitelist    []string
 	RedisClient      assetCounter
 	maxAssetsPerUser int
 	maxUploadsPerDay int
+	listURLTTL       time.Duration
+	viewURLTTL       time.Duration
+	scanTimeout      time.Duration
+	// storageQuota 为每用户存储字节配额（资产 + 生成的 PDF/缩略图），0 表示不限制。
+	storageQuota int64
+	// extensionCheck 为文件名扩展名与嗅探类型不一致时的处理方式：strict 拒绝，其余仅告警。
+	extensionCheck string
+	// opaqueKeys 开启后新上传的资产放在用户的随机目录下，object key 不再包含用户 ID。
+	opaqueKeys bool
+	// presigner 为带 Redis 缓存的预签名器；为 nil 时直接使用 Storage 签名。
+	presigner assetURLPresigner
+}
+
+// AssetHandlerOptions 为 AssetHandler 的配置项；数量与体积上限为 0 表示不限制。
+type AssetHandlerOptions struct {
+	MaxAssetsPerUser int
+	MaxUploadsPerDay int
+	// MaxBytes 为单次上传大小上限，MIMEWhitelist 为允许的嗅探 MIME 类型。
+	MaxBytes      int
+	MIMEWhitelist []string
+	// ListURLTTL / ViewURLTTL 为列表与单个查看时签发的预签名链接有效期。
+	ListURLTTL time.Duration
+	ViewURLTTL time.Duration
+	// ScanTimeout 为单次病毒扫描的超时时间。
+	ScanTimeout time.Duration
+	// StorageQuota 为每用户存储字节配额（资产 + 生成的 PDF/缩略图）。
+	StorageQuota int64
+	// ExtensionCheck 为扩展名与嗅探类型不一致时的处理方式（strict 拒绝 / lenient 仅告警）。
+	ExtensionCheck string
+	// OpaqueKeys 开启后新上传对象写入不透明目录（users.asset_key_dir），不暴露用户 ID。
+	OpaqueKeys bool
 }
 
 // NewAssetHandler 返回 AssetHandler 实例。
-func NewAssetHandler(db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, clamdAddr string, redisClient *redis.Client, maxAssetsPerUser int, maxUploadsPerDay int, maxBytes int, mimeWhitelist []string) *AssetHandler {
+func NewAssetHandler(db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, scanner VirusScanner, redisClient redis.UniversalClient, opts AssetHandlerOptions) *AssetHandler {
+	var cacheStore storage.PresignCacheStore
+	if redisClient != nil {
+		cacheStore = redisClient
+	}
 	return &AssetHandler{
 		store:            newGormAssetStore(db),
 		Storage:          storageClient,
 		Logger:           logger,
-		ClamdAddr:        clamdAddr,
-		MaxBytes:         maxBytes,
-		MIMEWhitelist:    mimeWhitelist,
+		Scanner:          scanner,
+		MaxBytes:         opts.MaxBytes,
+		MIMEWhitelist:    opts.MIMEWhitelist,
 		RedisClient:      redisClient,
-		maxAssetsPerUser: maxAssetsPerUser,
-		maxUploadsPerDay: maxUploadsPerDay,
+		maxAssetsPerUser: opts.MaxAssetsPerUser,
+		maxUploadsPerDay: opts.MaxUploadsPerDay,
+		listURLTTL:       opts.ListURLTTL,
+		viewURLTTL:       opts.ViewURLTTL,
+		scanTimeout:      opts.ScanTimeout,
+		storageQuota:     opts.StorageQuota,
+		extensionCheck:   opts.ExtensionCheck,
+		opaqueKeys:       opts.OpaqueKeys,
+		presigner:        storage.NewPresignCache(storageClient, cacheStore),
 	}
 }
 
+// urlPresigner 返回带缓存的预签名器；未配置时直接使用对象存储客户端。
+func (h *AssetHandler) urlPresigner() assetURLPresigner {
+	if h.presigner != nil {
+		return h.presigner
+	}
+	return h.Storage
+}
+
 // UploadAsset 处理受保护的图片上传，并在上传前扫描病毒。
 func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
@@ -138,7 +330,7 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		return
 	}
 	if h.maxAssetsPerUser > 0 && existingCount >= int64(h.maxAssetsPerUser) {
-		Forbidden(c, "asset limit reached")
+		Forbidden(c, "asset limit reached", errcode.LimitReached)
 		return
 	}
 
@@ -149,7 +341,7 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		count = 0
 	}
 	if h.maxUploadsPerDay > 0 && count > int64(h.maxUploadsPerDay) {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		TooManyRequests(c, "rate limit exceeded")
 		return
 	}
 
@@ -160,36 +352,42 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	}
 
 	if file.Size > int64(h.MaxBytes) {
-		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+		Error(c, http.StatusRequestEntityTooLarge, "payload too large")
 		return
 	}
 
-	clamdClient := clamd.NewClamd(h.ClamdAddr)
+	if h.storageQuota > 0 {
+		used, err := h.store.StorageBytesByUser(ctx, userID)
+		if err != nil {
+			logger.Error("load storage usage failed", slog.Any("error", err))
+			Internal(c, "failed to check storage quota")
+			return
+		}
+		if used+file.Size > h.storageQuota {
+			h.respondStorageQuotaExceeded(c, file.Size, used)
+			return
+		}
+	}
 
-	fileReader, err := file.Open()
-	if err != nil {
+	clean, err := h.scanUpload(ctx, file)
+	switch {
+	case errors.Is(err, errVirusScanTimeout):
+		logger.Error("scan file timed out", slog.Duration("timeout", h.scanTimeout))
+		Error(c, http.StatusServiceUnavailable, "virus scan timed out")
+		return
+	case errors.Is(err, errUploadOpen):
 		Internal(c, "failed to open file")
 		return
-	}
-
-	abortChan := make(chan bool)
-	scanChan, err := clamdClient.ScanStream(fileReader, abortChan)
-	fileReader.Close()
-	if err != nil {
-		h.Logger.Error("scan file", slog.String("error", err.Error()))
+	case err != nil:
+		logger.Error("scan file", slog.String("error", err.Error()))
 		Internal(c, "failed to scan file")
 		return
-	}
-	defer close(abortChan)
-
-	for result := range scanChan {
-		if result.Status != clamd.RES_OK {
-			BadRequest(c, "malicious file detected")
-			return
-		}
+	case !clean:
+		BadRequest(c, "malicious file detected")
+		return
 	}
 
-	fileReader, err = file.Open()
+	fileReader, err := file.Open()
 	if err != nil {
 		Internal(c, "failed to reopen file")
 		return
@@ -209,10 +407,22 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		}
 	}
 	if !allowed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported media type"})
+		BadRequest(c, "unsupported media type", errcode.UnsupportedMediaType)
 		return
 	}
 
+	// 声明扩展名与实际内容不符多见于改名或伪装文件；存储扩展名始终以嗅探结果为准。
+	if declared, ok := uploadExtensionMatches(file.Filename, sniffed); !ok {
+		if h.extensionCheck == UploadExtensionStrict {
+			BadRequest(c, "file extension does not match content", errcode.UnsupportedMediaType)
+			return
+		}
+		logger.Warn("upload extension mismatch, normalized",
+			slog.String("declared_ext", declared),
+			slog.String("sniffed", sniffed),
+		)
+	}
+
 	fileReader, err = file.Open()
 	if err != nil {
 		Internal(c, "failed to reopen file")
@@ -220,14 +430,15 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	}
 	defer fileReader.Close()
 
-	ext := ".png"
-	switch sniffed {
-	case "image/jpeg":
-		ext = ".jpg"
-	case "image/webp":
-		ext = ".webp"
+	dir := strconv.FormatUint(uint64(userID), 10)
+	if h.opaqueKeys {
+		if dir, err = h.store.EnsureAssetKeyDir(ctx, userID); err != nil {
+			logger.Error("ensure asset key dir failed", slog.Any("error", err))
+			Internal(c, "failed to upload file")
+			return
+		}
 	}
-	objectKey := fmt.Sprintf("user-assets/%d/%s%s", userID, uuid.NewString(), ext)
+	objectKey := userAssetRoot + dir + "/" + uuid.NewString() + uploadObjectExtension(sniffed)
 	contentType := sniffed
 
 	if _, err := h.Storage.UploadFile(ctx, objectKey, fileReader, file.Size, contentType); err != nil {
@@ -242,10 +453,16 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 		ContentType: contentType,
 		Size:        file.Size,
 	}
-	if err := h.store.Create(ctx, asset); err != nil {
+	if err := h.store.Create(ctx, asset, h.storageQuota); err != nil {
 		if delErr := h.Storage.DeleteObject(ctx, objectKey); delErr != nil {
 			logger.Error("rollback delete object failed", slog.String("object_key", objectKey), slog.Any("error", delErr))
 		}
+		// 预检之后并发上传占满了配额：以写入时的条件更新为准。
+		if errors.Is(err, errStorageQuotaExceeded) {
+			used, _ := h.store.StorageBytesByUser(ctx, userID)
+			h.respondStorageQuotaExceeded(c, file.Size, used)
+			return
+		}
 		logger.Error("create asset record failed", slog.String("object_key", objectKey), slog.Any("error", err))
 		Internal(c, "failed to upload file")
 		return
@@ -254,7 +471,18 @@ func (h *AssetHandler) UploadAsset(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"objectKey": objectKey})
 }
 
-// ListAssets 列出用户上传的资产。
+// respondStorageQuotaExceeded 返回 403 及本次上传大小、当前用量与配额。
+func (h *AssetHandler) respondStorageQuotaExceeded(c *gin.Context, size, used int64) {
+	body := errorBody(c, http.StatusForbidden, "storage quota exceeded", []int{errcode.LimitReached})
+	body["storage"] = gin.H{
+		"current": size,
+		"used":    used,
+		"limit":   h.storageQuota,
+	}
+	c.JSON(http.StatusForbidden, body)
+}
+
+// ListAssets 按 created_at、id 倒序分页列出用户上传的资产；nextCursor 非空时可用 ?cursor= 继续翻页。
 func (h *AssetHandler) ListAssets(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -273,18 +501,42 @@ func (h *AssetHandler) ListAssets(c *gin.Context) {
 		limit = 200
 	}
 
+	var after *assetCursor
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		cursor, err := decodeAssetCursor(raw)
+		if err != nil {
+			BadRequest(c, "invalid cursor")
+			return
+		}
+		after = &cursor
+	}
+
 	logger := middleware.LoggerFromContext(c).With(slog.Uint64("user_id", uint64(userID)))
-	assets, err := h.store.ListByUser(ctx, userID, limit)
+	// 多取一条用于判断是否还有下一页。
+	assets, err := h.store.ListByUser(ctx, userID, limit+1, after)
 	if err != nil {
 		logger.Error("list assets failed", slog.Any("error", err))
 		Internal(c, "failed to list assets")
 		return
 	}
+	var nextCursor *string
+	if len(assets) > limit {
+		assets = assets[:limit]
+		cursor := encodeAssetCursor(assets[limit-1])
+		nextCursor = &cursor
+	}
+
+	assetCount, err := h.store.CountByUser(ctx, userID)
+	if err != nil {
+		logger.Error("count assets failed", slog.Any("error", err))
+		Internal(c, "failed to list assets")
+		return
+	}
 
-	assetCount := int64(len(assets))
+	// 预签名次数以单页 limit 为上限。
 	items := make([]gin.H, 0, len(assets))
 	for _, a := range assets {
-		url, err := h.Storage.GeneratePresignedURL(ctx, a.ObjectKey, 10*time.Minute)
+		url, err := h.urlPresigner().GeneratePresignedURL(ctx, a.ObjectKey, h.listURLTTL)
 		if err != nil {
 			logger.Error("generate asset url failed", slog.String("object_key", a.ObjectKey), slog.Any("error", err))
 			continue
@@ -297,25 +549,68 @@ func (h *AssetHandler) ListAssets(c *gin.Context) {
 		})
 	}
 
-	todayKey := fmt.Sprintf("rate:upload:day:%d:%s", userID, time.Now().UTC().Format("20060102"))
-	todayUploads := int64(0)
-	if value, err := h.RedisClient.Get(ctx, todayKey).Result(); err == nil {
-		if parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
-			todayUploads = parsed
-		}
+	storageUsed, err := h.store.StorageBytesByUser(ctx, userID)
+	if err != nil {
+		logger.Error("load storage usage failed", slog.Any("error", err))
+		Internal(c, "failed to list assets")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"items": items,
+		"items":      items,
+		"nextCursor": nextCursor,
 		"stats": gin.H{
 			"assetCount":       assetCount,
 			"maxAssets":        h.maxAssetsPerUser,
-			"todayUploads":     todayUploads,
+			"todayUploads":     h.todayUploads(ctx, userID),
 			"maxUploadsPerDay": h.maxUploadsPerDay,
+			"storageUsed":      storageUsed,
+			"storageQuota":     h.storageQuota,
 		},
 	})
 }
 
+// GetUsage 返回用户的资产用量与各项上限，供前端展示用量条而无需拉取资产列表。
+// 资产数与字节数读取 users 表上增量维护的聚合列，当日上传数读取 Redis 频控计数，均不扫描资产表。
+func (h *AssetHandler) GetUsage(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	usage, err := h.store.UsageByUser(ctx, userID)
+	if err != nil {
+		middleware.LoggerFromContext(c).Error("load asset usage failed", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		Internal(c, "failed to load asset usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"assetCount":       usage.AssetCount,
+		"maxAssets":        h.maxAssetsPerUser,
+		"storageUsed":      usage.StorageBytes,
+		"storageQuota":     h.storageQuota,
+		"todayUploads":     h.todayUploads(ctx, userID),
+		"maxUploadsPerDay": h.maxUploadsPerDay,
+	})
+}
+
+// todayUploads 读取用户当日（UTC）的上传计数；计数不存在或读取失败时按 0 处理。
+func (h *AssetHandler) todayUploads(ctx context.Context, userID uint) int64 {
+	todayKey := fmt.Sprintf("rate:upload:day:%d:%s", userID, time.Now().UTC().Format("20060102"))
+	value, err := h.RedisClient.Get(ctx, todayKey).Result()
+	if err != nil {
+		return 0
+	}
+	parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
 // GetAssetURL 返回资产的临时预签名 URL。
 func (h *AssetHandler) GetAssetURL(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
@@ -332,7 +627,7 @@ func (h *AssetHandler) GetAssetURL(c *gin.Context) {
 		return
 	}
 	objectKey = strings.TrimSpace(objectKey)
-	if !isValidUserAssetObjectKey(userID, objectKey) {
+	if !isValidAssetObjectKey(objectKey) {
 		Forbidden(c, "access denied")
 		return
 	}
@@ -340,7 +635,7 @@ func (h *AssetHandler) GetAssetURL(c *gin.Context) {
 		Forbidden(c, "access denied")
 		return
 	}
-	signedURL, err := h.Storage.GeneratePresignedURL(ctx, objectKey, 15*time.Minute)
+	signedURL, err := h.urlPresigner().GeneratePresignedURL(ctx, objectKey, h.viewURLTTL)
 	if err != nil {
 		h.Logger.Error("generate presigned url", slog.String("error", err.Error()))
 		Internal(c, "failed to generate url")
@@ -364,7 +659,7 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 		BadRequest(c, "missing key")
 		return
 	}
-	if !isValidUserAssetObjectKey(userID, objectKey) {
+	if !isValidAssetObjectKey(objectKey) {
 		Forbidden(c, "access denied")
 		return
 	}
@@ -389,3 +684,125 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "asset deleted"})
 }
+
+// maxBatchDeleteKeys 为单次批量删除允许的 key 数量上限。
+const maxBatchDeleteKeys = 100
+
+// 批量删除中单个 key 的处理结果。
+const (
+	batchDeleteDeleted    = "deleted"
+	batchDeleteInvalidKey = "invalid_key"
+	batchDeleteNotFound   = "not_found"
+	batchDeleteFailed     = "failed"
+)
+
+type batchDeleteAssetsRequest struct {
+	Keys []string `json:"keys" binding:"required"`
+}
+
+type batchDeleteResult struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+}
+
+// BatchDeleteAssets 批量删除当前用户的资产：逐个校验 key 的归属，存储对象通过批量接口删除，
+// 只有对象删除成功的 key 才会删除数据库记录；部分失败按 key 逐条返回，不会中断整批操作。
+func (h *AssetHandler) BatchDeleteAssets(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	var req batchDeleteAssetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	keys := make([]string, 0, len(req.Keys))
+	seen := make(map[string]struct{}, len(req.Keys))
+	for _, key := range req.Keys {
+		key = strings.TrimSpace(key)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		BadRequest(c, "missing keys")
+		return
+	}
+	if len(keys) > maxBatchDeleteKeys {
+		BadRequest(c, fmt.Sprintf("too many keys (max %d)", maxBatchDeleteKeys))
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := middleware.LoggerFromContext(c).With(slog.Uint64("user_id", uint64(userID)))
+
+	statuses := make(map[string]string, len(keys))
+	candidates := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !isValidAssetObjectKey(key) {
+			statuses[key] = batchDeleteInvalidKey
+			continue
+		}
+		candidates = append(candidates, key)
+	}
+
+	if len(candidates) > 0 {
+		assets, err := h.store.FindByUserAndKeys(ctx, userID, candidates)
+		if err != nil {
+			logger.Error("find assets for batch delete failed", slog.Any("error", err))
+			Internal(c, "failed to delete assets")
+			return
+		}
+		owned := make(map[string]uint, len(assets))
+		ownedKeys := make([]string, 0, len(assets))
+		for _, asset := range assets {
+			owned[asset.ObjectKey] = asset.ID
+			ownedKeys = append(ownedKeys, asset.ObjectKey)
+		}
+		for _, key := range candidates {
+			if _, ok := owned[key]; !ok {
+				statuses[key] = batchDeleteNotFound
+			}
+		}
+
+		failed := h.Storage.DeleteObjects(ctx, ownedKeys)
+		removedIDs := make([]uint, 0, len(ownedKeys))
+		removedKeys := make([]string, 0, len(ownedKeys))
+		for _, key := range ownedKeys {
+			if err, ok := failed[key]; ok {
+				logger.Error("delete object failed", slog.String("object_key", key), slog.Any("error", err))
+				statuses[key] = batchDeleteFailed
+				continue
+			}
+			removedIDs = append(removedIDs, owned[key])
+			removedKeys = append(removedKeys, key)
+		}
+
+		if err := h.store.DeleteByIDs(ctx, removedIDs); err != nil {
+			logger.Error("delete asset records failed", slog.Int("count", len(removedIDs)), slog.Any("error", err))
+			for _, key := range removedKeys {
+				statuses[key] = batchDeleteFailed
+			}
+		} else {
+			for _, key := range removedKeys {
+				statuses[key] = batchDeleteDeleted
+			}
+		}
+	}
+
+	results := make([]batchDeleteResult, 0, len(keys))
+	deleted := 0
+	for _, key := range keys {
+		if statuses[key] == batchDeleteDeleted {
+			deleted++
+		}
+		results = append(results, batchDeleteResult{Key: key, Status: statuses[key]})
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted, "results": results})
+}
@@ -3,11 +3,16 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,12 +23,16 @@ import (
 	"gorm.io/gorm"
 
 	"phResume/internal/database"
+	"phResume/internal/errcode"
 )
 
 type fakeStorage struct {
 	uploaded map[string][]byte
 
-	deleted []string
+	deleted         []string
+	deletedPrefixes []string
+	deleteErr       error
+	deleteFailures  map[string]error // DeleteObjects 中按 key 注入的失败
 
 	presign map[string]string
 }
@@ -50,17 +59,35 @@ func (s *fakeStorage) GeneratePresignedURL(_ context.Context, objectKey string,
 
 func (s *fakeStorage) DeleteObject(_ context.Context, objectKey string) error {
 	s.deleted = append(s.deleted, objectKey)
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
 	delete(s.uploaded, objectKey)
 	return nil
 }
 
+func (s *fakeStorage) DeleteObjects(_ context.Context, objectKeys []string) map[string]error {
+	failed := make(map[string]error)
+	for _, key := range objectKeys {
+		s.deleted = append(s.deleted, key)
+		if err, ok := s.deleteFailures[key]; ok {
+			failed[key] = err
+			continue
+		}
+		delete(s.uploaded, key)
+	}
+	return failed
+}
+
 func newTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	// 每个测试独立的内存库，避免 cache=shared 下数据互相污染。
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
-	if err := db.AutoMigrate(&database.Asset{}); err != nil {
+	if err := db.AutoMigrate(database.Models()...); err != nil {
 		t.Fatalf("migrate: %v", err)
 	}
 	return db
@@ -100,7 +127,7 @@ func TestUploadAsset_LimitsByCount(t *testing.T) {
 		store:            newGormAssetStore(db),
 		Storage:          storage,
 		Logger:           nil,
-		ClamdAddr:        "",
+		Scanner:          nil,
 		MaxBytes:         5 * 1024 * 1024,
 		MIMEWhitelist:    []string{"image/png"},
 		RedisClient:      redisClient,
@@ -110,7 +137,7 @@ func TestUploadAsset_LimitsByCount(t *testing.T) {
 
 	for i := 0; i < 4; i++ {
 		objectKey := "user-assets/1/existing-" + strconv.Itoa(i) + ".png"
-		if err := h.store.Create(ctx, database.Asset{UserID: 1, ObjectKey: objectKey}); err != nil {
+		if err := h.store.Create(ctx, database.Asset{UserID: 1, ObjectKey: objectKey}, 0); err != nil {
 			t.Fatalf("seed asset: %v", err)
 		}
 	}
@@ -130,3 +157,483 @@ func TestUploadAsset_LimitsByCount(t *testing.T) {
 		t.Fatalf("expected 403 got %d body=%s", w.Code, w.Body.String())
 	}
 }
+
+func TestUploadAsset_ScanTimeoutReturns503(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scanner := &fakeScanner{hangScan: true, aborted: make(chan struct{})}
+	h := &AssetHandler{
+		store:            newGormAssetStore(newTestDB(t)),
+		Storage:          newFakeStorage(),
+		Logger:           nil,
+		Scanner:          scanner,
+		MaxBytes:         5 * 1024 * 1024,
+		MIMEWhitelist:    []string{"image/png"},
+		RedisClient:      newRedisCounter(t),
+		maxAssetsPerUser: 4,
+		maxUploadsPerDay: 4,
+		scanTimeout:      20 * time.Millisecond,
+	}
+
+	body, contentType := newMultipartUpload(t, "a.png", []byte("\x89PNG\r\n\x1a\n"))
+	req := httptest.NewRequest(http.MethodPost, "/v1/assets/upload", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set("userID", uint(1))
+
+	h.UploadAsset(c)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d body=%s", w.Code, w.Body.String())
+	}
+	select {
+	case <-scanner.aborted:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the hung scan to be aborted")
+	}
+}
+
+func TestBatchDeleteAssets_ReportsPerKeyResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	storage := newFakeStorage()
+	storage.deleteFailures = map[string]error{"user-assets/1/broken.png": errors.New("minio unavailable")}
+	h := &AssetHandler{store: newGormAssetStore(newTestDB(t)), Storage: storage}
+
+	for _, key := range []string{"user-assets/1/a.png", "user-assets/1/b.woff2", "user-assets/1/broken.png"} {
+		if err := h.store.Create(ctx, database.Asset{UserID: 1, ObjectKey: key}, 0); err != nil {
+			t.Fatalf("seed asset: %v", err)
+		}
+	}
+	if err := h.store.Create(ctx, database.Asset{UserID: 2, ObjectKey: "user-assets/2/other.png"}, 0); err != nil {
+		t.Fatalf("seed foreign asset: %v", err)
+	}
+
+	body := `{"keys":["user-assets/1/a.png","user-assets/1/b.woff2","user-assets/1/a.png","user-assets/1/broken.png","user-assets/1/missing.png","user-assets/2/other.png","user-assets/1/a.exe"]}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/v1/assets/batch", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", uint(1))
+	h.BatchDeleteAssets(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Deleted int                 `json:"deleted"`
+		Results []batchDeleteResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := map[string]string{
+		"user-assets/1/a.png":       batchDeleteDeleted,
+		"user-assets/1/b.woff2":     batchDeleteDeleted,
+		"user-assets/1/broken.png":  batchDeleteFailed,
+		"user-assets/1/missing.png": batchDeleteNotFound,
+		// 归属按 assets 表判断：他人的 key 与不存在的 key 一样返回 not_found。
+		"user-assets/2/other.png": batchDeleteNotFound,
+		"user-assets/1/a.exe":     batchDeleteInvalidKey,
+	}
+	if resp.Deleted != 2 || len(resp.Results) != len(want) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	for _, result := range resp.Results {
+		if want[result.Key] != result.Status {
+			t.Fatalf("key %s: expected %s got %s", result.Key, want[result.Key], result.Status)
+		}
+	}
+
+	// 对象删除失败的记录保留，便于重试；他人资产不受影响。
+	if count, _ := h.store.CountByUser(ctx, 1); count != 1 {
+		t.Fatalf("expected only the failed asset to remain, got %d", count)
+	}
+	if count, _ := h.store.CountByUser(ctx, 2); count != 1 {
+		t.Fatalf("expected foreign asset untouched, got %d", count)
+	}
+}
+
+func TestListAssets_PaginatesWithCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	h := &AssetHandler{
+		store:            newGormAssetStore(newTestDB(t)),
+		Storage:          newFakeStorage(),
+		RedisClient:      newRedisCounter(t),
+		maxAssetsPerUser: 10,
+		maxUploadsPerDay: 10,
+	}
+
+	// 前三条 created_at 相同，用于验证同一时间戳下按 id 稳定翻页。
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	createdAt := []time.Time{base, base, base, base.Add(-time.Minute), base.Add(-2 * time.Minute)}
+	for i, ts := range createdAt {
+		asset := database.Asset{UserID: 1, ObjectKey: "user-assets/1/a" + strconv.Itoa(i) + ".png"}
+		asset.CreatedAt = ts
+		if err := h.store.Create(ctx, asset, 0); err != nil {
+			t.Fatalf("seed asset: %v", err)
+		}
+	}
+	if err := h.store.Create(ctx, database.Asset{UserID: 2, ObjectKey: "user-assets/2/other.png"}, 0); err != nil {
+		t.Fatalf("seed foreign asset: %v", err)
+	}
+
+	type page struct {
+		Items []struct {
+			ObjectKey string `json:"objectKey"`
+		} `json:"items"`
+		NextCursor *string `json:"nextCursor"`
+		Stats      struct {
+			AssetCount int64 `json:"assetCount"`
+		} `json:"stats"`
+	}
+	fetch := func(query string) (int, page) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/assets?"+query, nil)
+		c.Set("userID", uint(1))
+		h.ListAssets(c)
+		var p page
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+		}
+		return w.Code, p
+	}
+
+	var keys []string
+	query := "limit=2"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination did not terminate")
+		}
+		code, p := fetch(query)
+		if code != http.StatusOK {
+			t.Fatalf("expected 200 got %d", code)
+		}
+		if p.Stats.AssetCount != 5 {
+			t.Fatalf("expected total asset count 5 got %d", p.Stats.AssetCount)
+		}
+		for _, item := range p.Items {
+			keys = append(keys, item.ObjectKey)
+		}
+		if p.NextCursor == nil {
+			break
+		}
+		query = "limit=2&cursor=" + *p.NextCursor
+	}
+
+	want := []string{"user-assets/1/a2.png", "user-assets/1/a1.png", "user-assets/1/a0.png", "user-assets/1/a3.png", "user-assets/1/a4.png"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected order %v want %v", keys, want)
+	}
+
+	if code, _ := fetch("cursor=not-a-cursor"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid cursor got %d", code)
+	}
+}
+
+func TestUploadAsset_EnforcesStorageQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	db := newTestDB(t)
+	if err := db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	h := &AssetHandler{
+		store:            newGormAssetStore(db),
+		Storage:          newFakeStorage(),
+		Scanner:          &fakeScanner{},
+		MaxBytes:         5 * 1024 * 1024,
+		MIMEWhitelist:    []string{"image/png"},
+		RedisClient:      newRedisCounter(t),
+		maxAssetsPerUser: 10,
+		maxUploadsPerDay: 10,
+		storageQuota:     1000,
+	}
+
+	// 用量随记录创建/删除增量维护。
+	seed := []database.Asset{
+		{UserID: 1, ObjectKey: "user-assets/1/a.png", Size: 600},
+		{UserID: 1, ObjectKey: "user-assets/1/b.png", Size: 390},
+	}
+	for _, a := range seed {
+		if err := h.store.Create(ctx, a, 0); err != nil {
+			t.Fatalf("seed asset: %v", err)
+		}
+	}
+	if used, err := h.store.StorageBytesByUser(ctx, 1); err != nil || used != 990 {
+		t.Fatalf("expected 990 bytes used, got %d (err=%v)", used, err)
+	}
+
+	upload := func() *httptest.ResponseRecorder {
+		body, contentType := newMultipartUpload(t, "c.png", []byte("\x89PNG\r\n\x1a\n0123456789abcdef"))
+		req := httptest.NewRequest(http.MethodPost, "/v1/assets/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", uint(1))
+		h.UploadAsset(c)
+		return w
+	}
+
+	w := upload()
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Error   errcode.Detail `json:"error"`
+		Storage struct {
+			Current int64 `json:"current"`
+			Used    int64 `json:"used"`
+			Limit   int64 `json:"limit"`
+		} `json:"storage"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error.Code != errcode.LimitReached || resp.Storage.Current != 24 || resp.Storage.Used != 990 || resp.Storage.Limit != 1000 {
+		t.Fatalf("unexpected quota response %+v", resp)
+	}
+
+	var stored []database.Asset
+	if err := db.Where("user_id = ?", 1).Order("id").Find(&stored).Error; err != nil {
+		t.Fatalf("load assets: %v", err)
+	}
+	if err := h.store.DeleteByID(ctx, stored[0].ID); err != nil {
+		t.Fatalf("delete asset: %v", err)
+	}
+	if used, _ := h.store.StorageBytesByUser(ctx, 1); used != 390 {
+		t.Fatalf("expected 390 bytes after delete, got %d", used)
+	}
+	if w := upload(); w.Code != http.StatusCreated {
+		t.Fatalf("expected upload within quota to succeed, got %d body=%s", w.Code, w.Body.String())
+	}
+	if used, _ := h.store.StorageBytesByUser(ctx, 1); used != 414 {
+		t.Fatalf("expected 414 bytes after upload, got %d", used)
+	}
+
+	// 写入时按配额条件累加：预检之后被并发上传占满时拒绝写入，用量不变。
+	if err := h.store.Create(ctx, database.Asset{UserID: 1, ObjectKey: "user-assets/1/d.png", Size: 600}, h.storageQuota); !errors.Is(err, errStorageQuotaExceeded) {
+		t.Fatalf("expected errStorageQuotaExceeded, got %v", err)
+	}
+	if used, _ := h.store.StorageBytesByUser(ctx, 1); used != 414 {
+		t.Fatalf("rejected create must not change usage, got %d", used)
+	}
+	if count, _ := h.store.CountByUser(ctx, 1); count != 2 {
+		t.Fatalf("rejected create must not insert a record, got %d assets", count)
+	}
+
+	// 重复删除同一资产（并发批量删除）只扣减一次。
+	if err := h.store.DeleteByIDs(ctx, []uint{stored[1].ID}); err != nil {
+		t.Fatalf("delete asset: %v", err)
+	}
+	if err := h.store.DeleteByIDs(ctx, []uint{stored[1].ID}); err != nil {
+		t.Fatalf("repeat delete asset: %v", err)
+	}
+	if used, _ := h.store.StorageBytesByUser(ctx, 1); used != 24 {
+		t.Fatalf("expected 24 bytes after deleting b.png once, got %d", used)
+	}
+}
+
+func TestGetUsage_ReadsMaintainedAggregates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	db := newTestDB(t)
+	if err := db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	counter := newMemoryLoginStore()
+	h := &AssetHandler{
+		store:            newGormAssetStore(db),
+		RedisClient:      counter,
+		maxAssetsPerUser: 10,
+		maxUploadsPerDay: 5,
+		storageQuota:     1000,
+	}
+
+	for i, size := range []int64{100, 200, 300} {
+		asset := database.Asset{UserID: 1, ObjectKey: "user-assets/1/u" + strconv.Itoa(i) + ".png", Size: size}
+		if err := h.store.Create(ctx, asset, 0); err != nil {
+			t.Fatalf("seed asset: %v", err)
+		}
+	}
+	var first database.Asset
+	if err := db.Where("user_id = ?", 1).Order("id").First(&first).Error; err != nil {
+		t.Fatalf("load asset: %v", err)
+	}
+	if err := h.store.DeleteByID(ctx, first.ID); err != nil {
+		t.Fatalf("delete asset: %v", err)
+	}
+	todayKey := "rate:upload:day:1:" + time.Now().UTC().Format("20060102")
+	counter.Incr(ctx, todayKey)
+	counter.Incr(ctx, todayKey)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/assets/usage", nil)
+	c.Set("userID", uint(1))
+	h.GetUsage(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		AssetCount       int64 `json:"assetCount"`
+		MaxAssets        int   `json:"maxAssets"`
+		StorageUsed      int64 `json:"storageUsed"`
+		StorageQuota     int64 `json:"storageQuota"`
+		TodayUploads     int64 `json:"todayUploads"`
+		MaxUploadsPerDay int   `json:"maxUploadsPerDay"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.AssetCount != 2 || resp.StorageUsed != 500 || resp.TodayUploads != 2 ||
+		resp.MaxAssets != 10 || resp.MaxUploadsPerDay != 5 || resp.StorageQuota != 1000 {
+		t.Fatalf("unexpected usage %+v", resp)
+	}
+}
+
+func TestUploadAsset_ExtensionConsistency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	png := []byte("\x89PNG\r\n\x1a\n0123456789abcdef")
+
+	cases := []struct {
+		name     string
+		mode     string
+		filename string
+		wantCode int
+	}{
+		{name: "strict match", mode: UploadExtensionStrict, filename: "a.PNG", wantCode: http.StatusCreated},
+		{name: "strict no extension", mode: UploadExtensionStrict, filename: "a", wantCode: http.StatusCreated},
+		{name: "strict mismatch", mode: UploadExtensionStrict, filename: "a.jpg", wantCode: http.StatusBadRequest},
+		{name: "lenient mismatch", mode: UploadExtensionLenient, filename: "a.jpg", wantCode: http.StatusCreated},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if err := db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+				t.Fatalf("seed user: %v", err)
+			}
+			storage := newFakeStorage()
+			h := &AssetHandler{
+				store:            newGormAssetStore(db),
+				Storage:          storage,
+				Scanner:          &fakeScanner{},
+				MaxBytes:         5 * 1024 * 1024,
+				MIMEWhitelist:    []string{"image/png", "image/jpeg"},
+				RedisClient:      newRedisCounter(t),
+				maxAssetsPerUser: 10,
+				maxUploadsPerDay: 10,
+				extensionCheck:   tc.mode,
+			}
+
+			body, contentType := newMultipartUpload(t, tc.filename, png)
+			req := httptest.NewRequest(http.MethodPost, "/v1/assets/upload", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Set("userID", uint(1))
+			h.UploadAsset(c)
+
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected %d got %d body=%s", tc.wantCode, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				if len(storage.uploaded) != 0 {
+					t.Fatalf("rejected upload must not be stored")
+				}
+				return
+			}
+			// 存储扩展名始终以嗅探类型为准。
+			for key := range storage.uploaded {
+				if !strings.HasSuffix(key, ".png") {
+					t.Fatalf("expected .png object key, got %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadExtensionMatches(t *testing.T) {
+	cases := []struct {
+		filename string
+		sniffed  string
+		want     bool
+	}{
+		{"photo.jpeg", "image/jpeg", true},
+		{"photo.JPG", "image/jpeg", true},
+		{"photo.png", "image/jpeg", false},
+		{"font.woff2", "font/ttf", false},
+		{"font.ttf", "font/ttf", true},
+		{"payload.png.html", "image/png", false},
+		{"noext", "image/png", true},
+		{"a.bin", "application/x-custom", true},
+	}
+	for _, tc := range cases {
+		if _, got := uploadExtensionMatches(tc.filename, tc.sniffed); got != tc.want {
+			t.Errorf("uploadExtensionMatches(%q, %q) = %v, want %v", tc.filename, tc.sniffed, got, tc.want)
+		}
+	}
+}
+
+func TestUploadAsset_OpaqueKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	if err := db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	storage := newFakeStorage()
+	h := &AssetHandler{
+		store:            newGormAssetStore(db),
+		Storage:          storage,
+		Scanner:          &fakeScanner{},
+		MaxBytes:         5 * 1024 * 1024,
+		MIMEWhitelist:    []string{"image/png"},
+		RedisClient:      newRedisCounter(t),
+		maxAssetsPerUser: 10,
+		maxUploadsPerDay: 10,
+		opaqueKeys:       true,
+	}
+
+	upload := func() string {
+		body, contentType := newMultipartUpload(t, "a.png", []byte("\x89PNG\r\n\x1a\n0123456789abcdef"))
+		req := httptest.NewRequest(http.MethodPost, "/v1/assets/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Set("userID", uint(1))
+		h.UploadAsset(c)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 got %d body=%s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			ObjectKey string `json:"objectKey"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		return resp.ObjectKey
+	}
+
+	first, second := upload(), upload()
+	dir, err := database.LoadAssetKeyDir(db, 1)
+	if err != nil || len(dir) != 32 {
+		t.Fatalf("expected an assigned asset dir, got %q (err=%v)", dir, err)
+	}
+	for _, key := range []string{first, second} {
+		if !strings.HasPrefix(key, "user-assets/"+dir+"/") {
+			t.Fatalf("expected key %q under the opaque dir %q", key, dir)
+		}
+		if !isValidUserImageObjectKey(assetOwner{UserID: 1, Dir: dir}, key) {
+			t.Fatalf("expected key %q to be printable by its owner", key)
+		}
+	}
+}
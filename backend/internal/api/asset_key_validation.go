@@ -1,17 +1,70 @@
 package api
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"unicode/utf8"
 )
 
-func isValidUserAssetObjectKey(userID uint, key string) bool {
+const userAssetRoot = "user-assets/"
+
+var (
+	imageAssetExtensions = []string{".png", ".jpg", ".jpeg", ".webp"}
+	fontAssetExtensions  = []string{".ttf", ".woff2"}
+)
+
+// assetOwner 描述一个用户可引用的资产目录：历史的 user-assets/<user_id>/，
+// 以及开启 API_ASSET_OPAQUE_KEYS 后分配的随机目录 user-assets/<dir>/（users.asset_key_dir）。
+type assetOwner struct {
+	UserID uint
+	Dir    string
+}
+
+// owns 报告 key 是否位于该用户的资产目录下。
+func (o assetOwner) owns(key string) bool {
+	if strings.HasPrefix(key, fmt.Sprintf("%s%d/", userAssetRoot, o.UserID)) {
+		return true
+	}
+	return o.Dir != "" && strings.HasPrefix(key, userAssetRoot+o.Dir+"/")
+}
+
+// newAssetKeyDir 生成不透明的资产目录名（32 位十六进制），不含用户 ID，无法由 ID 推算。
+func newAssetKeyDir() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// isValidAssetObjectKey 只校验 key 形态（资产目录、无路径穿越、图片或字体扩展名）；
+// 归属由调用方按 assets 表确认，不再从 key 中解析用户 ID。
+func isValidAssetObjectKey(key string) bool {
+	return isValidAssetKeyShape(key, imageAssetExtensions) || isValidAssetKeyShape(key, fontAssetExtensions)
+}
+
+// isValidUserImageObjectKey 校验 key 为该用户的图片资产（打印时内联的图片只允许图片类型）。
+func isValidUserImageObjectKey(owner assetOwner, key string) bool {
+	return owner.owns(key) && isValidAssetKeyShape(key, imageAssetExtensions)
+}
+
+// isValidUserFontObjectKey 校验 key 为该用户的字体资产（ttf/woff2）。
+func isValidUserFontObjectKey(owner assetOwner, key string) bool {
+	return owner.owns(key) && isValidAssetKeyShape(key, fontAssetExtensions)
+}
+
+func isValidAssetKeyShape(key string, extensions []string) bool {
 	if key == "" || !utf8.ValidString(key) {
 		return false
 	}
-	expected := fmt.Sprintf("user-assets/%d/", userID)
-	if !strings.HasPrefix(key, expected) {
+	rest, ok := strings.CutPrefix(key, userAssetRoot)
+	if !ok {
+		return false
+	}
+	dir, name, ok := strings.Cut(rest, "/")
+	if !ok || !isAssetKeyDir(dir) || name == "" {
 		return false
 	}
 	if strings.Contains(key, "..") || strings.Contains(key, "\\") || strings.Contains(key, "//") {
@@ -21,8 +74,23 @@ func isValidUserAssetObjectKey(userID uint, key string) bool {
 		return false
 	}
 	lower := strings.ToLower(strings.TrimSpace(key))
-	if !(strings.HasSuffix(lower, ".png") || strings.HasSuffix(lower, ".jpg") || strings.HasSuffix(lower, ".jpeg") || strings.HasSuffix(lower, ".webp")) {
+	for _, ext := range extensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssetKeyDir 接受历史的数字用户 ID 与随机分配的十六进制目录。
+func isAssetKeyDir(dir string) bool {
+	if dir == "" || len(dir) > 64 {
 		return false
 	}
+	for _, r := range dir {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
 	return true
 }
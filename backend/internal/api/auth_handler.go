@@ -4,24 +4,40 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
 	stdhttp "net/http"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
 	"phResume/internal/api/middleware"
 	"phResume/internal/auth"
 	"phResume/internal/database"
+	"phResume/internal/errcode"
+	"phResume/internal/metrics"
+	"phResume/internal/storage"
+	"phResume/internal/tasks"
 )
 
 const refreshTokenCookieName = "refresh_token"
 const refreshTokenBlacklistKeyPrefix = "auth:refresh:blacklist:"
 
+// accountCleanupDelay 为注销后兜底清理任务的延迟，覆盖注销时仍在途的渲染任务写入对象的窗口。
+const accountCleanupDelay = 10 * time.Minute
+
+// accountObjectStorage 抽象注销账号时需要的对象存储能力。
+type accountObjectStorage interface {
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
 // AuthHandler 处理注册、登录、刷新与退出。
 type AuthHandler struct {
 	db                    *gorm.DB
@@ -29,54 +45,203 @@ type AuthHandler struct {
 	redis                 redis.UniversalClient
 	logger                *slog.Logger
 	loginRateLimitPerHour int
-	loginLockThreshold    int
-	loginLockTTL          time.Duration
+	loginAttempts         *loginAttemptLimiter
 	cookieDomain          string
+	// accessTokenCookie 为 true 时登录/刷新额外以 HttpOnly Cookie 下发 access token，供不愿在 JS 中保存令牌的客户端使用。
+	accessTokenCookie bool
+	// cookieSameSite / cookieSecure 为认证 Cookie 的属性；cookieSecure 为 "auto" 时按请求是否 HTTPS 推断。
+	cookieSameSite stdhttp.SameSite
+	cookieSecure   string
+	storage        accountObjectStorage
+	asynqClient    taskEnqueuer
+
+	challenge          auth.ChallengeVerifier
+	challengeThreshold int
+
+	registration registrationGate
+
+	refreshBinding refreshBinder
+}
+
+// AuthHandlerOptions 为 AuthHandler 的配置项；新增配置在此加字段，零值即关闭对应能力。
+type AuthHandlerOptions struct {
+	// LoginRateLimitPerHour 为单 IP 每小时登录请求上限。
+	LoginRateLimitPerHour int
+	// LoginUsernameLock 开启后用户名连续失败 LoginLockThreshold 次锁定 LoginLockTTL；IP 维度阈值与时长独立配置。
+	LoginUsernameLock    bool
+	LoginLockThreshold   int
+	LoginLockTTL         time.Duration
+	LoginIPLockThreshold int
+	LoginIPLockTTL       time.Duration
+	// LoginLockDBFallback 开启后同时把用户名锁定写入 users.locked_until，Redis 被清空时仍生效。
+	LoginLockDBFallback bool
+	// Challenge 为人机验证器，连续失败达到 ChallengeThreshold 次后要求携带验证令牌；为空时不校验。
+	Challenge          auth.ChallengeVerifier
+	ChallengeThreshold int
+	// CookieDomain / CookieSameSite / CookieSecure 为认证 Cookie 的属性；CookieSecure 为 "auto" 时按请求是否 HTTPS 推断。
+	CookieDomain   string
+	CookieSameSite string
+	CookieSecure   string
+	// AccessTokenCookie 开启后登录/刷新额外以 HttpOnly Cookie 下发 access token。
+	AccessTokenCookie bool
+	// RegistrationMode 为注册策略（open / invite / disabled），invite 时接受 RegistrationInviteCodes 中的静态邀请码。
+	RegistrationMode        string
+	RegistrationInviteCodes []string
+	// RefreshBinding 为刷新令牌与设备指纹的绑定策略（off / warn / enforce）。
+	RefreshBinding string
 }
 
 // NewAuthHandler 构造认证处理器。
-func NewAuthHandler(db *gorm.DB, authService *auth.AuthService, redisClient redis.UniversalClient, logger *slog.Logger, loginRateLimitPerHour int, loginLockThreshold int, loginLockTTL time.Duration, cookieDomain string) *AuthHandler {
-	return &AuthHandler{
+func NewAuthHandler(db *gorm.DB, authService *auth.AuthService, redisClient redis.UniversalClient, logger *slog.Logger, storageClient *storage.Client, asynqClient *asynq.Client, opts AuthHandlerOptions) *AuthHandler {
+	challenge := opts.Challenge
+	if challenge == nil {
+		challenge = auth.NoopChallengeVerifier{}
+	}
+	h := &AuthHandler{
 		db:                    db,
 		authService:           authService,
 		redis:                 redisClient,
 		logger:                logger,
-		loginRateLimitPerHour: loginRateLimitPerHour,
-		loginLockThreshold:    loginLockThreshold,
-		loginLockTTL:          loginLockTTL,
-		cookieDomain:          cookieDomain,
+		loginRateLimitPerHour: opts.LoginRateLimitPerHour,
+		loginAttempts: &loginAttemptLimiter{
+			store:               redisClient,
+			usernameLockEnabled: opts.LoginUsernameLock,
+			usernameThreshold:   opts.LoginLockThreshold,
+			usernameTTL:         opts.LoginLockTTL,
+			ipThreshold:         opts.LoginIPLockThreshold,
+			ipTTL:               opts.LoginIPLockTTL,
+		},
+		cookieDomain:       opts.CookieDomain,
+		accessTokenCookie:  opts.AccessTokenCookie,
+		cookieSameSite:     parseCookieSameSite(opts.CookieSameSite),
+		cookieSecure:       opts.CookieSecure,
+		challenge:          challenge,
+		challengeThreshold: opts.ChallengeThreshold,
+		registration: registrationGate{
+			mode:        opts.RegistrationMode,
+			staticCodes: opts.RegistrationInviteCodes,
+			store:       redisClient,
+		},
+		refreshBinding: refreshBinder{mode: opts.RefreshBinding, store: redisClient},
+	}
+	if opts.LoginLockDBFallback {
+		h.loginAttempts.db = db
+	}
+	// 避免把 nil 指针装进接口，使 deleteAccountObjects/enqueueAccountCleanup 的 nil 判断失效。
+	if storageClient != nil {
+		h.storage = storageClient
+	}
+	if asynqClient != nil {
+		h.asynqClient = asynqClient
+	}
+	return h
+}
+
+var errUsernameTaken = errors.New("username already taken")
+
+// minUsernameLength 与注册/改名请求的 binding 下限一致，用于校验去除首尾空白后的用户名。
+const minUsernameLength = 3
+
+// ensureUsernameAvailable 校验用户名（大小写不敏感）未被其他账号占用（excludeUserID 为 0 表示不排除任何账号）。
+// 注册与修改用户名共用该校验，保证规则一致。
+func ensureUsernameAvailable(db *gorm.DB, username string, excludeUserID uint) error {
+	query := database.WhereUsername(db, username)
+	if excludeUserID != 0 {
+		query = query.Where("id <> ?", excludeUserID)
+	}
+	var existing database.User
+	err := query.First(&existing).Error
+	switch {
+	case err == nil:
+		return errUsernameTaken
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// isUsernameUniqueViolation 判断写入是否撞上未删除账号的用户名唯一索引（并发注册/改名时预检查无法避免）。
+// PostgreSQL 报错中带索引名 idx_users_username_active；SQLite（测试）只报告列名。
+func isUsernameUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "idx_users_username_active") || strings.Contains(msg, "UNIQUE constraint failed: users.username")
+}
+
+// newMustChangePasswordLookup 返回按用户 ID 实时查询 must_change_password 的闸门复核函数；用户不存在时返回 middleware.ErrUserNotFound。
+func newMustChangePasswordLookup(db *gorm.DB) middleware.MustChangePasswordLookup {
+	return func(ctx context.Context, userID uint) (bool, error) {
+		var user database.User
+		if err := db.WithContext(ctx).Select("id", "must_change_password").First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, middleware.ErrUserNotFound
+			}
+			return false, err
+		}
+		return user.MustChangePassword, nil
 	}
 }
 
 type registerRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=64"`
 	Password string `json:"password" binding:"required,min=8,max=72"`
+	// InviteCode 仅在 API_REGISTRATION_MODE=invite 时必填。
+	InviteCode string `json:"invite_code" binding:"max=128"`
 }
 
-// Register 创建新用户账号。
+// Register 创建新用户账号；注册策略为 disabled 时返回 403，invite 时需携带有效邀请码。
 func (h *AuthHandler) Register(c *gin.Context) {
+	if h.registration.mode == RegistrationDisabled {
+		Forbidden(c, "registration is disabled", errcode.RegistrationDisabled)
+		return
+	}
+
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
+		return
+	}
+
+	username := database.NormalizeUsername(req.Username)
+	if utf8.RuneCountInString(username) < minUsernameLength {
+		BadRequest(c, "username must be at least 3 characters")
 		return
 	}
 
 	ctx := c.Request.Context()
 	logger := h.loggerFromContext(c).With(
-		slog.String("username", req.Username),
+		slog.String("username", username),
 	)
 
-	var existing database.User
-	if err := h.db.WithContext(ctx).Where("username = ?", req.Username).First(&existing).Error; err == nil {
-		logger.Info("register conflict: user already exists")
-		Conflict(c, "username already taken")
-		return
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := ensureUsernameAvailable(h.db.WithContext(ctx), username, 0); err != nil {
+		if errors.Is(err, errUsernameTaken) {
+			logger.Info("register conflict: user already exists")
+			Conflict(c, "username already taken")
+			return
+		}
 		logger.Error("register lookup failed", slog.Any("error", err))
 		Internal(c, "internal error")
 		return
 	}
 
+	// 用户名校验通过后再核验邀请码，避免一次性邀请码被用户名冲突等失败请求白白消耗。
+	if err := h.registration.check(ctx, req.InviteCode); err != nil {
+		switch {
+		case errors.Is(err, errRegistrationDisabled):
+			Forbidden(c, "registration is disabled", errcode.RegistrationDisabled)
+		case errors.Is(err, errInviteCodeInvalid):
+			logger.Info("register rejected: invalid invite code")
+			Forbidden(c, "invalid invite code", errcode.InviteCodeInvalid)
+		default:
+			logger.Error("register invite code check failed", slog.Any("error", err))
+			Internal(c, "internal error")
+		}
+		return
+	}
+
 	hashed, err := h.authService.HashPassword(req.Password)
 	if err != nil {
 		logger.Error("hash password failed", slog.Any("error", err))
@@ -85,11 +250,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	user := database.User{
-		Username:     req.Username,
+		Username:     username,
 		PasswordHash: hashed,
 	}
 
 	if err := h.db.WithContext(ctx).Create(&user).Error; err != nil {
+		if isUsernameUniqueViolation(err) {
+			logger.Info("register conflict: username taken concurrently")
+			Conflict(c, "username already taken")
+			return
+		}
 		logger.Error("create user failed", slog.Any("error", err))
 		Internal(c, "internal error")
 		return
@@ -100,8 +270,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 }
 
 type loginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username       string `json:"username" binding:"required"`
+	Password       string `json:"password" binding:"required"`
+	ChallengeToken string `json:"challenge_token"`
 }
 
 type tokenResponse struct {
@@ -116,7 +287,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	ip := c.ClientIP()
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
 		return
 	}
 
@@ -126,54 +297,109 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	)
 
 	// 速率限制：每 IP+用户名 每小时 10 次
-	rateKey := "rate:login:" + ip + ":" + strings.ToLower(req.Username) + ":" + time.Now().UTC().Format("2006010215")
+	rateKey := "rate:login:" + ip + ":" + database.NormalizeUsername(req.Username) + ":" + time.Now().UTC().Format("2006010215")
 	count, err := incrWithTTL(ctx, h.redis, rateKey, time.Hour)
 	if err != nil {
 		count = 0
 	}
 	if count > int64(h.loginRateLimitPerHour) {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		metrics.AuthLogin(metrics.LoginRateLimited)
+		TooManyRequests(c, "rate limit exceeded")
 		return
 	}
 
-	// 锁定检查
-	lockKey := "lock:login:" + strings.ToLower(req.Username)
-	if ttl, _ := h.redis.TTL(ctx, lockKey).Result(); ttl > 0 {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "account temporarily locked"})
+	// 锁定检查：来源 IP 封禁优先于账号锁定
+	username := database.NormalizeUsername(req.Username)
+	switch h.loginAttempts.blocked(ctx, username, ip) {
+	case loginBlockedByIP:
+		logger.Info("login rejected: ip temporarily blocked", slog.String("ip", ip))
+		metrics.AuthLogin(metrics.LoginLocked)
+		TooManyRequests(c, "too many failed login attempts", errcode.LoginIPBlocked)
+		return
+	case loginBlockedByUsername:
+		// 告知客户端自动解锁时间，避免用户在锁定期内反复重试。
+		if remaining := h.loginAttempts.usernameLockRemaining(ctx, username); remaining > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		}
+		metrics.AuthLogin(metrics.LoginLocked)
+		TooManyRequests(c, "account temporarily locked", errcode.AccountLocked)
 		return
 	}
 
+	// 软阈值：同一 IP+用户名 失败次数达到阈值后，需携带通过校验的人机验证 token
+	if h.challengeThreshold > 0 && h.loginAttempts.pairFailures(ctx, username, ip) >= int64(h.challengeThreshold) {
+		passed, err := h.challenge.Verify(ctx, req.ChallengeToken, ip)
+		if err != nil {
+			logger.Error("login challenge verification failed", slog.Any("error", err))
+			metrics.AuthLogin(metrics.LoginError)
+			Internal(c, "failed to verify challenge")
+			return
+		}
+		if !passed {
+			metrics.AuthLogin(metrics.LoginChallengeFailed)
+			if strings.TrimSpace(req.ChallengeToken) == "" {
+				Forbidden(c, "challenge required", errcode.ChallengeRequired)
+				return
+			}
+			logger.Info("login rejected: invalid challenge token")
+			h.loginAttempts.recordFailure(ctx, username, ip)
+			Forbidden(c, "challenge verification failed", errcode.ChallengeFailed)
+			return
+		}
+	}
+
 	var user database.User
-	if err := h.db.WithContext(ctx).Where("username = ?", req.Username).First(&user).Error; err != nil {
+	// 大小写不敏感查找；存在因冲突未能规范化的历史记录时取较早注册的账号。
+	if err := database.WhereUsername(h.db.WithContext(ctx), username).Order("id").First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			logger.Info("login failed: user not found")
-			_ = h.incrementLoginFail(ctx, strings.ToLower(req.Username))
+			h.loginAttempts.recordFailure(ctx, username, ip)
+			metrics.AuthLogin(metrics.LoginInvalidCredentials)
 			Unauthorized(c)
 			return
 		}
 		logger.Error("login query failed", slog.Any("error", err))
+		metrics.AuthLogin(metrics.LoginError)
 		Internal(c, "internal error")
 		return
 	}
 
+	// 数据库兜底：Redis 锁定状态丢失（被清空或不可用）时仍按持久化的 locked_until 拒绝。
+	if remaining := h.loginAttempts.dbLockRemaining(user); remaining > 0 {
+		logger.Info("login rejected: account locked (db)", slog.Uint64("user_id", uint64(user.ID)))
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		metrics.AuthLogin(metrics.LoginLocked)
+		TooManyRequests(c, "account temporarily locked", errcode.AccountLocked)
+		return
+	}
+
 	if !h.authService.CheckPasswordHash(req.Password, user.PasswordHash) {
 		logger.Info("login failed: password mismatch", slog.Uint64("user_id", uint64(user.ID)))
-		_ = h.incrementLoginFail(ctx, strings.ToLower(req.Username))
+		h.loginAttempts.recordFailure(ctx, username, ip)
+		if err := h.loginAttempts.recordDBFailure(ctx, user.ID); err != nil {
+			logger.Warn("login: record db failure failed", slog.Any("error", err))
+		}
+		metrics.AuthLogin(metrics.LoginInvalidCredentials)
 		Unauthorized(c)
 		return
 	}
 
 	// 登录成功：清理失败计数
-	_ = h.redis.Del(ctx, "lock:login:fail:"+strings.ToLower(req.Username)).Err()
+	h.loginAttempts.recordSuccess(ctx, username, ip)
+	if err := h.loginAttempts.clearDBLock(ctx, user.ID); err != nil {
+		logger.Warn("login: clear db lock failed", slog.Any("error", err))
+	}
 
 	mustChangePassword := user.MustChangePassword
-	tokenPair, err := h.authService.GenerateTokenPair(user.ID, mustChangePassword)
+	tokenPair, err := h.authService.GenerateTokenPair(user.ID, mustChangePassword, auth.RoleFor(user.IsAdmin))
 	if err != nil {
 		logger.Error("generate token pair failed", slog.Any("error", err))
+		metrics.AuthLogin(metrics.LoginError)
 		Internal(c, "internal error")
 		return
 	}
 
+	metrics.AuthLogin(metrics.LoginSuccess)
 	h.replyWithTokenPair(c, tokenPair, mustChangePassword)
 }
 
@@ -185,6 +411,7 @@ type refreshRequest struct {
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	refreshToken := h.extractRefreshToken(c)
 	if refreshToken == "" {
+		metrics.AuthRefresh(metrics.RefreshInvalid)
 		Unauthorized(c)
 		return
 	}
@@ -195,17 +422,20 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	claims, err := h.authService.ValidateToken(refreshToken)
 	if err != nil {
 		logger.Info("refresh token invalid", slog.Any("error", err))
+		metrics.AuthRefresh(metrics.RefreshInvalid)
 		Unauthorized(c)
 		return
 	}
 	if claims.TokenType != "refresh" {
 		logger.Info("refresh token wrong type", slog.String("token_type", claims.TokenType))
+		metrics.AuthRefresh(metrics.RefreshInvalid)
 		Unauthorized(c)
 		return
 	}
 
 	if claims.ID == "" {
 		logger.Info("refresh token missing jti")
+		metrics.AuthRefresh(metrics.RefreshInvalid)
 		Unauthorized(c)
 		return
 	}
@@ -213,25 +443,59 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	key := refreshTokenBlacklistKeyPrefix + claims.ID
 	if err := h.redis.Get(ctx, key).Err(); err == nil {
 		logger.Info("refresh token revoked", slog.String("jti", claims.ID))
+		metrics.AuthRefresh(metrics.RefreshRevoked)
 		Unauthorized(c)
 		return
 	} else if !errors.Is(err, redis.Nil) {
 		logger.Error("refresh token blacklist lookup failed", slog.Any("error", err))
+		metrics.AuthRefresh(metrics.RefreshError)
 		Internal(c, "internal error")
 		return
 	}
 
+	// 设备绑定：指纹与签发时不一致说明刷新 Cookie 可能被盗用；enforce 模式下吊销该令牌并拒绝。
+	mismatch, err := h.refreshBinding.mismatch(ctx, claims.ID, requestDeviceFingerprint(c))
+	if err != nil {
+		logger.Warn("refresh binding lookup failed", slog.Any("error", err))
+	}
+	if mismatch {
+		logger.Warn("security: refresh token presented from a different device",
+			slog.Uint64("user_id", uint64(claims.UserID)),
+			slog.String("jti", claims.ID),
+			slog.String("ip", c.ClientIP()),
+			slog.String("mode", h.refreshBinding.mode),
+		)
+		if h.refreshBinding.mode == RefreshBindingEnforce {
+			if err := h.revokeRefreshToken(ctx, key, claims.ExpiresAt); err != nil {
+				logger.Error("refresh revoke mismatched token failed", slog.Any("error", err))
+			}
+			metrics.AuthRefresh(metrics.RefreshDeviceMismatch)
+			Unauthorized(c)
+			return
+		}
+	}
+
+	// 会话绝对有效期：轮换不会延长登录时间，超过后必须重新登录。
+	if h.authService.SessionExpired(claims, time.Now()) {
+		logger.Info("refresh rejected: session lifetime exceeded", slog.Uint64("user_id", uint64(claims.UserID)))
+		metrics.AuthRefresh(metrics.RefreshSessionExpired)
+		Unauthorized(c)
+		return
+	}
+
 	var user database.User
 	if err := h.db.WithContext(ctx).First(&user, claims.UserID).Error; err != nil {
 		logger.Info("refresh user not found", slog.Any("error", err))
+		metrics.AuthRefresh(metrics.RefreshInvalid)
 		Unauthorized(c)
 		return
 	}
 
 	mustChangePassword := user.MustChangePassword
-	tokenPair, err := h.authService.GenerateTokenPair(claims.UserID, mustChangePassword)
+	tokenPair, err := h.authService.RotateTokenPair(claims.UserID, mustChangePassword, auth.RoleFor(user.IsAdmin), auth.SessionAuthTime(claims))
 	if err != nil {
 		logger.Error("refresh generate token pair failed", slog.Any("error", err))
+		metrics.AuthRefresh(metrics.RefreshError)
 		Internal(c, "internal error")
 		return
 	}
@@ -239,10 +503,12 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	// 旋转旧刷新令牌，防止重复使用。
 	if err := h.revokeRefreshToken(ctx, key, claims.ExpiresAt); err != nil {
 		logger.Error("refresh revoke old token failed", slog.Any("error", err))
+		metrics.AuthRefresh(metrics.RefreshError)
 		Internal(c, "internal error")
 		return
 	}
 
+	metrics.AuthRefresh(metrics.RefreshSuccess)
 	h.replyWithTokenPair(c, tokenPair, mustChangePassword)
 }
 
@@ -256,7 +522,7 @@ type changePasswordRequest struct {
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	var req changePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
 		return
 	}
 	if req.NewPassword != req.ConfirmPassword {
@@ -318,7 +584,15 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		}
 	}
 
-	tokenPair, err := h.authService.GenerateTokenPair(user.ID, false)
+	// 改密后解除账号锁定：被锁定的用户仍可能持有有效会话并在此改密，否则需等锁定过期。
+	if err := h.loginAttempts.unlockUsername(ctx, user.Username); err != nil {
+		logger.Warn("change password: clear login lock failed", slog.Any("error", err))
+	}
+	if err := h.loginAttempts.clearDBLock(ctx, user.ID); err != nil {
+		logger.Warn("change password: clear db lock failed", slog.Any("error", err))
+	}
+
+	tokenPair, err := h.authService.GenerateTokenPair(user.ID, false, auth.RoleFor(user.IsAdmin))
 	if err != nil {
 		logger.Error("change password: generate token pair failed", slog.Any("error", err))
 		Internal(c, "internal error")
@@ -328,8 +602,125 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	h.replyWithTokenPair(c, tokenPair, false)
 }
 
+type meResponse struct {
+	ID                 uint      `json:"id"`
+	Username           string    `json:"username"`
+	MustChangePassword bool      `json:"must_change_password"`
+	IsAdmin            bool      `json:"is_admin"`
+	CreatedAt          time.Time `json:"created_at"`
+	ActiveResumeID     *uint     `json:"active_resume_id"`
+}
+
+// Me 返回当前登录用户的基本信息。
+func (h *AuthHandler) Me(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	var user database.User
+	if err := h.db.WithContext(c.Request.Context()).
+		Select("id", "username", "must_change_password", "is_admin", "created_at", "active_resume_id").
+		First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			Unauthorized(c)
+			return
+		}
+		h.loggerFromContext(c).Error("me: query user failed", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+		Internal(c, "internal error")
+		return
+	}
+
+	c.JSON(http.StatusOK, meResponse{
+		ID:                 user.ID,
+		Username:           user.Username,
+		MustChangePassword: user.MustChangePassword,
+		IsAdmin:            user.IsAdmin,
+		CreatedAt:          user.CreatedAt,
+		ActiveResumeID:     user.ActiveResumeID,
+	})
+}
+
+type changeUsernameRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,min=8,max=72"`
+	NewUsername     string `json:"new_username" binding:"required,min=3,max=64"`
+}
+
+// ChangeUsername 校验当前密码后修改用户名，规则与注册一致。
+func (h *AuthHandler) ChangeUsername(c *gin.Context) {
+	var req changeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := h.loggerFromContext(c).With(slog.Uint64("user_id", uint64(userID)))
+
+	var user database.User
+	if err := h.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		logger.Info("change username: user not found", slog.Any("error", err))
+		Unauthorized(c)
+		return
+	}
+
+	if !h.authService.CheckPasswordHash(req.CurrentPassword, user.PasswordHash) {
+		logger.Info("change username: current password mismatch")
+		Unauthorized(c)
+		return
+	}
+
+	oldUsername := user.Username
+	newUsername := database.NormalizeUsername(req.NewUsername)
+	if utf8.RuneCountInString(newUsername) < minUsernameLength {
+		BadRequest(c, "username must be at least 3 characters")
+		return
+	}
+	if newUsername == oldUsername {
+		BadRequest(c, "new username must be different from current username")
+		return
+	}
+
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureUsernameAvailable(tx, newUsername, user.ID); err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("username", newUsername).Error
+	})
+	if err != nil {
+		if errors.Is(err, errUsernameTaken) || isUsernameUniqueViolation(err) {
+			logger.Info("change username conflict", slog.String("new_username", newUsername))
+			Conflict(c, "username already taken")
+			return
+		}
+		logger.Error("change username: update failed", slog.Any("error", err))
+		Internal(c, "internal error")
+		return
+	}
+
+	logger.Info("audit: username changed",
+		slog.String("old_username", oldUsername),
+		slog.String("new_username", newUsername),
+		slog.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusOK, gin.H{"username": newUsername})
+}
+
 func (h *AuthHandler) replyWithTokenPair(c *gin.Context, tokenPair auth.TokenPair, mustChangePassword bool) {
+	if err := h.refreshBinding.bind(c.Request.Context(), tokenPair, requestDeviceFingerprint(c)); err != nil {
+		h.loggerFromContext(c).Warn("record refresh binding failed", slog.Any("error", err))
+	}
 	h.setRefreshCookie(c, tokenPair.RefreshToken)
+	if h.accessTokenCookie {
+		h.setAccessCookie(c, tokenPair.AccessToken)
+	}
 	c.JSON(http.StatusOK, tokenResponse{
 		AccessToken:        tokenPair.AccessToken,
 		TokenType:          "Bearer",
@@ -373,20 +764,157 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	// 清除 Cookie。
-	stdhttp.SetCookie(c.Writer, &stdhttp.Cookie{
-		Name:     refreshTokenCookieName,
-		Value:    "",
-		MaxAge:   -1,
-		Path:     "/",
-		Secure:   h.isHTTPSRequest(c),
-		HttpOnly: true,
-		SameSite: stdhttp.SameSiteLaxMode,
-		Domain:   h.getCookieDomain(),
-	})
+	h.clearRefreshCookie(c)
+	h.clearAccessCookie(c)
 	c.Status(http.StatusOK)
 }
 
+type deleteAccountRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// DeleteAccount 校验当前密码后注销账号：删除用户及其简历、模板、资产记录，吊销刷新令牌，并清理对象存储。
+// 对象删除为尽力而为且幂等；为覆盖部分失败与在途任务，额外入队一次延迟的兜底清理任务。
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := h.loggerFromContext(c).With(slog.Uint64("user_id", uint64(userID)))
+
+	var user database.User
+	if err := h.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		logger.Info("delete account: user not found", slog.Any("error", err))
+		Unauthorized(c)
+		return
+	}
+
+	if !h.authService.CheckPasswordHash(req.Password, user.PasswordHash) {
+		logger.Info("delete account: password mismatch")
+		Unauthorized(c)
+		return
+	}
+
+	cleanup := tasks.AccountCleanupPayload{
+		UserID:        user.ID,
+		AssetKeyDir:   user.AssetKeyDir,
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 缩略图按简历/模板 ID 存放，需在删除记录前记下（含已软删除的记录）。
+		if err := tx.Unscoped().Model(&database.Resume{}).Where("user_id = ?", user.ID).Pluck("id", &cleanup.ResumeIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&database.Template{}).Where("user_id = ?", user.ID).Pluck("id", &cleanup.TemplateIDs).Error; err != nil {
+			return err
+		}
+		// 简历与模板在 Postgres 中由外键 OnDelete:CASCADE 级联删除；此处显式删除以覆盖未启用外键约束的库。
+		// 资产表与简历事件表没有外键，必须显式删除。
+		for _, model := range []any{&database.Asset{}, &database.ResumeTag{}, &database.ResumeEvent{}, &database.Resume{}, &database.Template{}} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&database.User{}, user.ID).Error
+	})
+	if err != nil {
+		logger.Error("delete account: delete records failed", slog.Any("error", err))
+		Internal(c, "failed to delete account")
+		return
+	}
+
+	// 用户记录已删除，Refresh 无法再为该账号签发令牌；这里吊销该用户已签发的 access token 与当前刷新令牌并清除 Cookie。
+	if h.redis != nil {
+		if err := revokeUserTokens(ctx, h.redis, user.ID, h.authService.AccessTokenTTL()); err != nil {
+			logger.Warn("delete account: revoke access tokens failed", slog.Any("error", err))
+		}
+	}
+	if refreshToken, err := c.Cookie(refreshTokenCookieName); err == nil && refreshToken != "" {
+		if claims, err := h.authService.ValidateToken(refreshToken); err == nil && claims.TokenType == "refresh" && claims.ID != "" {
+			if err := h.revokeRefreshToken(ctx, refreshTokenBlacklistKeyPrefix+claims.ID, claims.ExpiresAt); err != nil {
+				logger.Warn("delete account: revoke refresh failed", slog.Any("error", err))
+			}
+		}
+	}
+	h.clearRefreshCookie(c)
+	h.clearAccessCookie(c)
+
+	h.markAccountPDFTasksCancelled(ctx, logger, cleanup.ResumeIDs)
+	objectsDeleted := h.deleteAccountObjects(ctx, logger, cleanup)
+	cleanupScheduled := h.enqueueAccountCleanup(logger, cleanup)
+
+	logger.Info("audit: account deleted",
+		slog.String("username", user.Username),
+		slog.Int("resume_count", len(cleanup.ResumeIDs)),
+		slog.Int("template_count", len(cleanup.TemplateIDs)),
+		slog.Bool("objects_deleted", objectsDeleted),
+		slog.Bool("cleanup_scheduled", cleanupScheduled),
+		slog.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusAccepted, gin.H{
+		"deleted":           true,
+		"cleanup_scheduled": cleanupScheduled,
+	})
+}
+
+// markAccountPDFTasksCancelled 为账号下的简历写入取消标记，阻止排队中的 PDF 任务继续渲染。
+func (h *AuthHandler) markAccountPDFTasksCancelled(ctx context.Context, logger *slog.Logger, resumeIDs []uint) {
+	if h.redis == nil {
+		return
+	}
+	for _, id := range resumeIDs {
+		if err := h.redis.Set(ctx, tasks.PDFCancelKey(id), 1, tasks.PDFCancelTTL).Err(); err != nil {
+			logger.Warn("delete account: mark pdf tasks cancelled failed", slog.Uint64("resume_id", uint64(id)), slog.Any("error", err))
+		}
+	}
+}
+
+// deleteAccountObjects 尽力删除账号的全部对象前缀，返回是否全部成功。
+func (h *AuthHandler) deleteAccountObjects(ctx context.Context, logger *slog.Logger, cleanup tasks.AccountCleanupPayload) bool {
+	if h.storage == nil {
+		return false
+	}
+	ok := true
+	for _, prefix := range cleanup.ObjectPrefixes() {
+		if err := h.storage.DeletePrefix(ctx, prefix); err != nil {
+			logger.Warn("delete account: delete prefix failed", slog.String("prefix", prefix), slog.Any("error", err))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// enqueueAccountCleanup 入队延迟执行的兜底清理任务，失败仅记录日志。
+func (h *AuthHandler) enqueueAccountCleanup(logger *slog.Logger, cleanup tasks.AccountCleanupPayload) bool {
+	if h.asynqClient == nil {
+		return false
+	}
+	task, err := tasks.NewAccountCleanupTask(cleanup)
+	if err != nil {
+		logger.Error("delete account: build cleanup task failed", slog.Any("error", err))
+		return false
+	}
+	if _, err := h.asynqClient.Enqueue(task, asynq.ProcessIn(accountCleanupDelay), asynq.MaxRetry(10)); err != nil {
+		logger.Error("delete account: enqueue cleanup task failed", slog.Any("error", err))
+		return false
+	}
+	return true
+}
+
+// requestDeviceFingerprint 按请求的 User-Agent 与 X-Client-ID 计算设备指纹。
+func requestDeviceFingerprint(c *gin.Context) string {
+	return deviceFingerprint(c.Request.UserAgent(), c.GetHeader(clientIDHeader))
+}
+
 func (h *AuthHandler) extractRefreshToken(c *gin.Context) string {
 	if token, err := c.Cookie(refreshTokenCookieName); err == nil && token != "" {
 		return token
@@ -404,18 +932,72 @@ func (h *AuthHandler) setRefreshCookie(c *gin.Context, refreshToken string) {
 	if maxAge <= 0 {
 		maxAge = int(time.Hour.Seconds())
 	}
-	cookie := &stdhttp.Cookie{
-		Name:     refreshTokenCookieName,
-		Value:    refreshToken,
+	h.writeAuthCookie(c, refreshTokenCookieName, refreshToken, maxAge, time.Now().Add(h.authService.RefreshTokenTTL()))
+}
+
+func (h *AuthHandler) clearRefreshCookie(c *gin.Context) {
+	h.writeAuthCookie(c, refreshTokenCookieName, "", -1, time.Time{})
+}
+
+// setAccessCookie 以 HttpOnly Cookie 下发 access token，有效期与令牌 TTL 一致；
+// AuthMiddleware 在缺少 Authorization 头时从该 Cookie 读取令牌。
+func (h *AuthHandler) setAccessCookie(c *gin.Context, accessToken string) {
+	maxAge := int(h.authService.AccessTokenTTL().Seconds())
+	if maxAge <= 0 {
+		maxAge = int(time.Minute.Seconds())
+	}
+	h.writeAuthCookie(c, middleware.AccessTokenCookieName, accessToken, maxAge, time.Now().Add(h.authService.AccessTokenTTL()))
+}
+
+// clearAccessCookie 无条件清除 access token Cookie，关闭该选项后旧 Cookie 也能在退出时被清理。
+func (h *AuthHandler) clearAccessCookie(c *gin.Context) {
+	h.writeAuthCookie(c, middleware.AccessTokenCookieName, "", -1, time.Time{})
+}
+
+// writeAuthCookie 统一写入认证 Cookie，保证设置与清除使用相同的 Domain/SameSite/Secure，
+// 否则浏览器会把清除视为另一个 Cookie。
+func (h *AuthHandler) writeAuthCookie(c *gin.Context, name, value string, maxAge int, expires time.Time) {
+	stdhttp.SetCookie(c.Writer, &stdhttp.Cookie{
+		Name:     name,
+		Value:    value,
 		MaxAge:   maxAge,
 		Path:     "/",
-		Secure:   h.isHTTPSRequest(c),
+		Secure:   h.cookieSecureFor(c),
 		HttpOnly: true,
-		SameSite: stdhttp.SameSiteLaxMode,
+		SameSite: h.sameSite(),
 		Domain:   h.getCookieDomain(),
-		Expires:  time.Now().Add(h.authService.RefreshTokenTTL()),
+		Expires:  expires,
+	})
+}
+
+func (h *AuthHandler) sameSite() stdhttp.SameSite {
+	if h.cookieSameSite == 0 {
+		return stdhttp.SameSiteLaxMode
+	}
+	return h.cookieSameSite
+}
+
+func (h *AuthHandler) cookieSecureFor(c *gin.Context) bool {
+	switch h.cookieSecure {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return h.isHTTPSRequest(c)
+	}
+}
+
+// parseCookieSameSite 将配置值映射为 http.SameSite；未知值按 Lax 处理（配置层已校验）。
+func parseCookieSameSite(value string) stdhttp.SameSite {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "strict":
+		return stdhttp.SameSiteStrictMode
+	case "none":
+		return stdhttp.SameSiteNoneMode
+	default:
+		return stdhttp.SameSiteLaxMode
 	}
-	stdhttp.SetCookie(c.Writer, cookie)
 }
 
 func (h *AuthHandler) revokeRefreshToken(ctx context.Context, key string, expiresAt *jwt.NumericDate) error {
@@ -451,17 +1033,3 @@ func (h *AuthHandler) isHTTPSRequest(c *gin.Context) bool {
 	return strings.EqualFold(c.Request.Header.Get("X-Forwarded-Proto"), "https")
 }
 func (h *AuthHandler) getCookieDomain() string { return strings.TrimSpace(h.cookieDomain) }
-func (h *AuthHandler) incrementLoginFail(ctx context.Context, username string) error {
-	failKey := "lock:login:fail:" + username
-	count, err := h.redis.Incr(ctx, failKey).Result()
-	if err != nil {
-		return err
-	}
-	if count == 1 {
-		_ = h.redis.Expire(ctx, failKey, h.loginLockTTL).Err()
-	}
-	if count >= int64(h.loginLockThreshold) {
-		_ = h.redis.Set(ctx, "lock:login:"+username, "1", h.loginLockTTL).Err()
-	}
-	return nil
-}
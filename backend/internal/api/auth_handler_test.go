@@ -0,0 +1,780 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/auth"
+	"phResume/internal/database"
+	"phResume/internal/errcode"
+	"phResume/internal/metrics"
+	"phResume/internal/tasks"
+)
+
+func newTestAuthService(t *testing.T) *auth.AuthService {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	svc, err := auth.NewAuthService(
+		auth.AlgorithmRS256,
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}),
+		time.Minute,
+		time.Hour,
+		24*time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("new auth service: %v", err)
+	}
+	return svc
+}
+
+func TestMustChangePasswordLookup_ReadsCurrentFlag(t *testing.T) {
+	db := newTestDB(t)
+	user := database.User{Username: "alice", MustChangePassword: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	lookup := newMustChangePasswordLookup(db)
+	ctx := context.Background()
+
+	mustChange, err := lookup(ctx, user.ID)
+	if err != nil || !mustChange {
+		t.Fatalf("expected must change, got %v err=%v", mustChange, err)
+	}
+
+	if err := db.Model(&user).Update("must_change_password", false).Error; err != nil {
+		t.Fatalf("clear flag: %v", err)
+	}
+	mustChange, err = lookup(ctx, user.ID)
+	if err != nil || mustChange {
+		t.Fatalf("expected flag cleared, got %v err=%v", mustChange, err)
+	}
+
+	if _, err := lookup(ctx, user.ID+100); !errors.Is(err, middleware.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound for unknown user, got %v", err)
+	}
+}
+
+// memoryRevokedUserStore 为 revokedUserStore 的内存实现。
+type memoryRevokedUserStore struct {
+	ttls map[string]time.Duration
+}
+
+func (s *memoryRevokedUserStore) Set(_ context.Context, key string, _ interface{}, expiration time.Duration) *redis.StatusCmd {
+	s.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (s *memoryRevokedUserStore) Exists(_ context.Context, keys ...string) *redis.IntCmd {
+	var n int64
+	for _, key := range keys {
+		if _, ok := s.ttls[key]; ok {
+			n++
+		}
+	}
+	return redis.NewIntResult(n, nil)
+}
+
+func TestRevokeUserTokens_MarksOnlyThatUser(t *testing.T) {
+	store := &memoryRevokedUserStore{ttls: map[string]time.Duration{}}
+	lookup := newRevokedUserLookup(store)
+	ctx := context.Background()
+
+	if revoked, err := lookup(ctx, 7); err != nil || revoked {
+		t.Fatalf("expected not revoked before delete, got %v err=%v", revoked, err)
+	}
+	if err := revokeUserTokens(ctx, store, 7, 15*time.Minute); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if revoked, err := lookup(ctx, 7); err != nil || !revoked {
+		t.Fatalf("expected revoked after delete, got %v err=%v", revoked, err)
+	}
+	if revoked, _ := lookup(ctx, 8); revoked {
+		t.Fatal("other users must stay valid")
+	}
+	if ttl := store.ttls[revokedUserKey(7)]; ttl != 15*time.Minute {
+		t.Fatalf("marker ttl must follow the access token ttl, got %v", ttl)
+	}
+}
+
+// newChallengeAuthHandler 构造启用人机验证（stub 校验器，阈值 2）的登录处理器，失败计数使用内存实现。
+func newChallengeAuthHandler(t *testing.T) *AuthHandler {
+	t.Helper()
+	db := newTestDB(t)
+	authService := newTestAuthService(t)
+	hashed, err := authService.HashPassword("correct-password")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	if err := db.Create(&database.User{Username: "alice", PasswordHash: hashed}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	h := NewAuthHandler(db, authService, newRedisCounter(t), slog.Default(), nil, nil, AuthHandlerOptions{
+		LoginRateLimitPerHour: 100,
+		LoginUsernameLock:     true,
+		LoginLockThreshold:    10,
+		LoginLockTTL:          30 * time.Minute,
+		LoginIPLockThreshold:  100,
+		LoginIPLockTTL:        30 * time.Minute,
+		Challenge:             auth.StubChallengeVerifier{Token: "human"},
+		ChallengeThreshold:    2,
+		CookieSameSite:        "lax",
+		CookieSecure:          "auto",
+		RegistrationMode:      RegistrationOpen,
+		RefreshBinding:        RefreshBindingOff,
+	})
+	h.loginAttempts.store = newMemoryLoginStore()
+	return h
+}
+
+func loginRequestWith(h *AuthHandler, password, challengeToken string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	body, _ := json.Marshal(map[string]string{
+		"username":        "alice",
+		"password":        password,
+		"challenge_token": challengeToken,
+	})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/login", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.RemoteAddr = "10.0.0.1:12345"
+	h.Login(c)
+	return w
+}
+
+func errorCodeOf(t *testing.T, w *httptest.ResponseRecorder) int {
+	t.Helper()
+	var body struct {
+		Error errcode.Detail `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	return body.Error.Code
+}
+
+func TestLogin_RequiresChallengeAfterSoftThreshold(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+
+	for i := 0; i < 2; i++ {
+		if w := loginRequestWith(h, "wrong-password", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401 got %d", i+1, w.Code)
+		}
+	}
+
+	w := loginRequestWith(h, "correct-password", "")
+	if w.Code != http.StatusForbidden || errorCodeOf(t, w) != errcode.ChallengeRequired {
+		t.Fatalf("expected challenge required, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = loginRequestWith(h, "correct-password", "bot")
+	if w.Code != http.StatusForbidden || errorCodeOf(t, w) != errcode.ChallengeFailed {
+		t.Fatalf("expected challenge failed, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLogin_ChallengePassedResetsCounter(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+
+	for i := 0; i < 2; i++ {
+		loginRequestWith(h, "wrong-password", "")
+	}
+
+	if w := loginRequestWith(h, "correct-password", "human"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with valid challenge, got %d %s", w.Code, w.Body.String())
+	}
+	// 登录成功后清零 IP+用户名 计数，不再要求人机验证。
+	if w := loginRequestWith(h, "correct-password", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after reset, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func deleteAccountCall(h *AuthHandler, userID uint, password string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	body, _ := json.Marshal(map[string]string{"password": password})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/v1/auth/account", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", userID)
+	h.DeleteAccount(c)
+	return w
+}
+
+func TestDeleteAccount_RemovesDataAndSchedulesCleanup(t *testing.T) {
+	db := newTestDB(t)
+	authService := newTestAuthService(t)
+	hashed, err := authService.HashPassword("correct-password")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	user := database.User{Username: "alice", PasswordHash: hashed}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	resume := database.Resume{UserID: user.ID, Title: "r", Content: datatypes.JSON(`{}`)}
+	template := database.Template{UserID: user.ID, Title: "t", Content: datatypes.JSON(`{}`)}
+	asset := database.Asset{UserID: user.ID, ObjectKey: "user-assets/1/a.png"}
+	for _, record := range []any{&resume, &template, &asset} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seed record: %v", err)
+		}
+	}
+
+	storage := newFakeStorage()
+	enqueuer := &fakeEnqueuer{}
+	h := &AuthHandler{db: db, authService: authService, logger: slog.Default(), storage: storage, asynqClient: enqueuer}
+
+	if w := deleteAccountCall(h, user.ID, "wrong-password"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong password, got %d", w.Code)
+	}
+
+	w := deleteAccountCall(h, user.ID, "correct-password")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d %s", w.Code, w.Body.String())
+	}
+
+	for _, model := range []any{&database.User{}, &database.Resume{}, &database.Template{}, &database.Asset{}} {
+		var count int64
+		if err := db.Unscoped().Model(model).Count(&count).Error; err != nil {
+			t.Fatalf("count %T: %v", model, err)
+		}
+		if count != 0 {
+			t.Fatalf("expected %T records to be deleted, got %d", model, count)
+		}
+	}
+
+	assertContains(t, storage.deletedPrefixes, "user-assets/1/")
+	assertContains(t, storage.deletedPrefixes, "generated-resumes/1/")
+	assertContains(t, storage.deletedPrefixes, fmt.Sprintf("thumbnails/resume/%d/", resume.ID))
+	assertContains(t, storage.deletedPrefixes, fmt.Sprintf("thumbnails/template/%d/", template.ID))
+
+	if len(enqueuer.tasks) != 1 || enqueuer.tasks[0].Type() != tasks.TypeAccountCleanup {
+		t.Fatalf("expected one account cleanup task, got %v", enqueuer.tasks)
+	}
+}
+
+func TestLogin_SetsAccessTokenCookieWhenEnabled(t *testing.T) {
+	findCookie := func(w *httptest.ResponseRecorder, name string) *http.Cookie {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == name {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	h := newChallengeAuthHandler(t)
+	w := loginRequestWith(h, "correct-password", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	if findCookie(w, middleware.AccessTokenCookieName) != nil {
+		t.Fatalf("access token cookie must not be set when disabled")
+	}
+
+	h.accessTokenCookie = true
+	w = loginRequestWith(h, "correct-password", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	var resp tokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	cookie := findCookie(w, middleware.AccessTokenCookieName)
+	if cookie == nil || !cookie.HttpOnly || cookie.Value != resp.AccessToken {
+		t.Fatalf("expected HttpOnly access token cookie matching the response, got %+v", cookie)
+	}
+	if cookie.MaxAge <= 0 || cookie.MaxAge > int(h.authService.AccessTokenTTL().Seconds()) {
+		t.Fatalf("access cookie max-age %d should follow access token ttl", cookie.MaxAge)
+	}
+}
+
+func TestAuthCookies_UseConfiguredSameSiteAndSecure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name         string
+		sameSite     string
+		secure       string
+		https        bool
+		wantSameSite http.SameSite
+		wantSecure   bool
+	}{
+		{name: "default lax inferred from plain http", sameSite: "lax", secure: "auto", wantSameSite: http.SameSiteLaxMode, wantSecure: false},
+		{name: "auto follows forwarded proto", sameSite: "strict", secure: "auto", https: true, wantSameSite: http.SameSiteStrictMode, wantSecure: true},
+		{name: "cross-site forced secure", sameSite: "none", secure: "true", wantSameSite: http.SameSiteNoneMode, wantSecure: true},
+		{name: "secure forced off behind https", sameSite: "lax", secure: "false", https: true, wantSameSite: http.SameSiteLaxMode, wantSecure: false},
+	}
+	for _, tc := range cases {
+		h := &AuthHandler{
+			authService:    newTestAuthService(t),
+			cookieSameSite: parseCookieSameSite(tc.sameSite),
+			cookieSecure:   tc.secure,
+		}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/login", nil)
+		if tc.https {
+			c.Request.Header.Set("X-Forwarded-Proto", "https")
+		}
+		h.setRefreshCookie(c, "token")
+		h.clearRefreshCookie(c)
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 2 {
+			t.Fatalf("%s: expected set and clear cookies, got %d", tc.name, len(cookies))
+		}
+		for _, cookie := range cookies {
+			if cookie.SameSite != tc.wantSameSite || cookie.Secure != tc.wantSecure || !cookie.HttpOnly {
+				t.Fatalf("%s: unexpected cookie attributes %+v", tc.name, cookie)
+			}
+		}
+	}
+}
+
+func TestUnlockUser_ClearsAccountLock(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	h.challengeThreshold = 0
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		h.loginAttempts.recordFailure(ctx, "alice", "10.0.0.7")
+	}
+	w := loginRequestWith(h, "correct-password", "")
+	if w.Code != http.StatusTooManyRequests || errorCodeOf(t, w) != errcode.AccountLocked {
+		t.Fatalf("expected locked account, got %d body=%s", w.Code, w.Body.String())
+	}
+	if retry := w.Header().Get("Retry-After"); retry == "" {
+		t.Fatalf("expected Retry-After header on locked account")
+	}
+
+	var user database.User
+	if err := h.db.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	unlock := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/users/"+id+"/unlock", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		h.UnlockUser(c)
+		return w
+	}
+
+	w = unlock(strconv.FormatUint(uint64(user.ID), 10))
+	var resp struct {
+		WasLocked bool `json:"was_locked"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK || !resp.WasLocked {
+		t.Fatalf("unlock: code=%d body=%s", w.Code, w.Body.String())
+	}
+	if w := loginRequestWith(h, "correct-password", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected login after unlock, got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := unlock("999"); w.Code != http.StatusNotFound {
+		t.Fatalf("unlock missing user: expected 404 got %d", w.Code)
+	}
+}
+
+func TestChangePassword_ClearsAccountLock(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		h.loginAttempts.recordFailure(ctx, "alice", "10.0.0.7")
+	}
+	if h.loginAttempts.blocked(ctx, "alice", "10.0.0.8") != loginBlockedByUsername {
+		t.Fatalf("expected account to be locked")
+	}
+
+	var user database.User
+	if err := h.db.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	body := `{"current_password":"correct-password","new_password":"another-password","confirm_password":"another-password"}`
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/change-password", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", user.ID)
+	h.ChangePassword(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("change password: expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	if reason := h.loginAttempts.blocked(ctx, "alice", "10.0.0.8"); reason != loginNotBlocked {
+		t.Fatalf("expected lock to be cleared after password change, got %v", reason)
+	}
+}
+
+func TestLogin_DBLockSurvivesRedisFlush(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	h.challengeThreshold = 0
+	h.loginAttempts.db = h.db
+
+	for i := 0; i < 10; i++ {
+		if w := loginRequestWith(h, "wrong-password", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401 got %d", i+1, w.Code)
+		}
+	}
+	var user database.User
+	if err := h.db.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if user.LockedUntil == nil || !user.LockedUntil.After(time.Now()) || user.FailedLoginCount != 0 {
+		t.Fatalf("expected persisted lock with reset counter, got locked_until=%v count=%d", user.LockedUntil, user.FailedLoginCount)
+	}
+
+	// 模拟 Redis 被清空：锁定仍由数据库兜底。
+	h.loginAttempts.store = newMemoryLoginStore()
+	w := loginRequestWith(h, "correct-password", "")
+	if w.Code != http.StatusTooManyRequests || errorCodeOf(t, w) != errcode.AccountLocked || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected db lock to reject login, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// 锁定过期后可正常登录，成功登录清零持久化状态。
+	past := time.Now().Add(-time.Minute)
+	if err := h.db.Model(&user).Updates(map[string]any{"locked_until": past, "failed_login_count": 3}).Error; err != nil {
+		t.Fatalf("expire lock: %v", err)
+	}
+	if w := loginRequestWith(h, "correct-password", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected login after lock expiry, got %d body=%s", w.Code, w.Body.String())
+	}
+	var reloaded database.User
+	if err := h.db.First(&reloaded, user.ID).Error; err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	if reloaded.LockedUntil != nil || reloaded.FailedLoginCount != 0 {
+		t.Fatalf("expected cleared db lock after success, got locked_until=%v count=%d", reloaded.LockedUntil, reloaded.FailedLoginCount)
+	}
+}
+
+func TestClearUsernameDBLock_AdminUnlockAllowsLogin(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	h.challengeThreshold = 0
+	h.loginAttempts.db = h.db
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		if w := loginRequestWith(h, "wrong-password", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401 got %d", i+1, w.Code)
+		}
+	}
+
+	// 只清 Redis 不足以解锁：数据库兜底仍会拒绝。
+	if err := ClearUsernameLoginLock(ctx, h.loginAttempts.store, "alice"); err != nil {
+		t.Fatalf("clear redis lock: %v", err)
+	}
+	if w := loginRequestWith(h, "correct-password", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected db lock to remain, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	// cmd/admin --unlock 的路径：用户名按规范形式匹配。
+	if err := ClearUsernameDBLock(ctx, h.db, "  Alice "); err != nil {
+		t.Fatalf("clear db lock: %v", err)
+	}
+	var user database.User
+	if err := h.db.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if user.LockedUntil != nil || user.FailedLoginCount != 0 {
+		t.Fatalf("expected cleared db lock, got locked_until=%v count=%d", user.LockedUntil, user.FailedLoginCount)
+	}
+	if w := loginRequestWith(h, "correct-password", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected login after unlock, got %d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func changeUsernameCall(h *AuthHandler, userID uint, currentPassword, newUsername string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	body, _ := json.Marshal(map[string]string{"current_password": currentPassword, "new_username": newUsername})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/auth/username", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.RemoteAddr = "10.0.0.7:4242"
+	c.Set("userID", userID)
+	h.ChangeUsername(c)
+	return w
+}
+
+func TestChangeUsername(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	// 未经 LoggerMiddleware 时 handler 使用 slog.Default()，临时替换以捕获审计日志。
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var alice database.User
+	if err := h.db.Where("username = ?", "alice").First(&alice).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if err := h.db.Create(&database.User{Username: "bob", PasswordHash: alice.PasswordHash}).Error; err != nil {
+		t.Fatalf("seed bob: %v", err)
+	}
+	usernameOf := func() string {
+		var user database.User
+		if err := h.db.First(&user, alice.ID).Error; err != nil {
+			t.Fatalf("reload user: %v", err)
+		}
+		return user.Username
+	}
+
+	// 密码闸门：当前密码错误时不改名。
+	if w := changeUsernameCall(h, alice.ID, "wrong-password", "carol"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: expected 401 got %d %s", w.Code, w.Body.String())
+	}
+	if got := usernameOf(); got != "alice" {
+		t.Fatalf("username must not change on wrong password, got %q", got)
+	}
+
+	// 冲突：与其他账号大小写不敏感重名。
+	if w := changeUsernameCall(h, alice.ID, "correct-password", " BOB "); w.Code != http.StatusConflict || errorCodeOf(t, w) != errcode.Conflict {
+		t.Fatalf("taken username: expected 409 got %d %s", w.Code, w.Body.String())
+	}
+	if w := changeUsernameCall(h, alice.ID, "correct-password", "ALICE"); w.Code != http.StatusBadRequest {
+		t.Fatalf("same username: expected 400 got %d %s", w.Code, w.Body.String())
+	}
+
+	// 成功：规范化后写入并记录审计日志。
+	logs.Reset()
+	w := changeUsernameCall(h, alice.ID, "correct-password", "  Carol ")
+	if w.Code != http.StatusOK || decodeJSONBody(t, w)["username"] != "carol" {
+		t.Fatalf("rename: expected 200 with carol, got %d %s", w.Code, w.Body.String())
+	}
+	if got := usernameOf(); got != "carol" {
+		t.Fatalf("expected persisted username carol, got %q", got)
+	}
+	var audit map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		var entry map[string]any
+		if json.Unmarshal(line, &entry) == nil && entry["msg"] == "audit: username changed" {
+			audit = entry
+		}
+	}
+	if audit == nil || audit["old_username"] != "alice" || audit["new_username"] != "carol" ||
+		audit["client_ip"] != "10.0.0.7" || audit["user_id"] != float64(alice.ID) {
+		t.Fatalf("expected audit log entry, got %s", logs.String())
+	}
+}
+
+func TestChangeUsername_ConcurrentRenameMapsToConflict(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	var alice database.User
+	if err := h.db.Where("username = ?", "alice").First(&alice).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+
+	// 模拟预检查通过后、UPDATE 之前另一请求抢先占用了同名（唯一索引兜底）。
+	raced := false
+	if err := h.db.Callback().Update().Before("gorm:update").Register("test:race_username", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "users" {
+			return
+		}
+		raced = true
+		if err := tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO users (username, password_hash, created_at, updated_at) VALUES (?, ?, ?, ?)",
+			"dave", alice.PasswordHash, time.Now(), time.Now(),
+		).Error; err != nil {
+			t.Errorf("insert racing user: %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	w := changeUsernameCall(h, alice.ID, "correct-password", "dave")
+	if !raced || w.Code != http.StatusConflict || errorCodeOf(t, w) != errcode.Conflict {
+		t.Fatalf("expected 409 for unique violation, got %d %s (raced=%v)", w.Code, w.Body.String(), raced)
+	}
+}
+
+func registerCall(h *AuthHandler, username, password string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/register", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.Register(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestRegister_ReusesUsernameAfterDeletion(t *testing.T) {
+	db := newTestDB(t)
+	h := &AuthHandler{db: db, authService: newTestAuthService(t), logger: slog.Default(), storage: newFakeStorage(), asynqClient: &fakeEnqueuer{}}
+
+	if w := registerCall(h, "alice", "correct-password"); w.Code != http.StatusCreated {
+		t.Fatalf("register: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+	if w := registerCall(h, "alice", "correct-password"); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate register: expected 409 got %d", w.Code)
+	}
+
+	// 注销账号为硬删除，用户名立即可再注册。
+	var user database.User
+	if err := db.Where("username = ?", "alice").First(&user).Error; err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if w := deleteAccountCall(h, user.ID, "correct-password"); w.Code != http.StatusAccepted {
+		t.Fatalf("delete account: expected 202 got %d %s", w.Code, w.Body.String())
+	}
+	if w := registerCall(h, "alice", "correct-password"); w.Code != http.StatusCreated {
+		t.Fatalf("re-register after account deletion: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+
+	// 软删除的记录同样不占用用户名。
+	if err := db.Where("username = ?", "alice").Delete(&database.User{}).Error; err != nil {
+		t.Fatalf("soft delete user: %v", err)
+	}
+	if w := registerCall(h, "alice", "correct-password"); w.Code != http.StatusCreated {
+		t.Fatalf("re-register after soft delete: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+
+	var total int64
+	db.Unscoped().Model(&database.User{}).Where("username = ?", "alice").Count(&total)
+	if total != 2 {
+		t.Fatalf("expected the soft-deleted and the active record, got %d", total)
+	}
+}
+
+func TestUsernames_AreCaseInsensitive(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+
+	if w := registerCall(h, "ALICE", "correct-password"); w.Code != http.StatusConflict {
+		t.Fatalf("register with different case: expected 409 got %d", w.Code)
+	}
+	if w := registerCall(h, " Bob ", "correct-password"); w.Code != http.StatusCreated {
+		t.Fatalf("register: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+	var bob database.User
+	if err := h.db.Where("username = ?", "bob").First(&bob).Error; err != nil {
+		t.Fatalf("expected username stored in canonical form: %v", err)
+	}
+
+	// 规范化之前写入的大小写混合记录同样参与查重与登录。
+	hashed, _ := h.authService.HashPassword("correct-password")
+	if err := h.db.Create(&database.User{Username: "Carol", PasswordHash: hashed}).Error; err != nil {
+		t.Fatalf("seed legacy user: %v", err)
+	}
+	if w := registerCall(h, "carol", "correct-password"); w.Code != http.StatusConflict {
+		t.Fatalf("register over legacy mixed-case user: expected 409 got %d", w.Code)
+	}
+
+	login := func(username string) int {
+		body, _ := json.Marshal(map[string]string{"username": username, "password": "correct-password"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/login", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.RemoteAddr = "10.0.0.1:12345"
+		h.Login(c)
+		return w.Code
+	}
+	for _, username := range []string{"Alice", "BOB", "carol"} {
+		if code := login(username); code != http.StatusOK {
+			t.Fatalf("login as %q: expected 200 got %d", username, code)
+		}
+	}
+}
+
+// authCounterValue 从默认注册表读取认证计数器在指定标签下的当前值；计数器为进程级，测试比较前后差值。
+func authCounterValue(t *testing.T, name, label, value string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() == label && lp.GetValue() == value {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestAuthMetrics_RecordOutcomes(t *testing.T) {
+	h := newChallengeAuthHandler(t)
+	h.loginAttempts.ipThreshold = 3
+
+	before := map[string]float64{}
+	for _, outcome := range []string{metrics.LoginInvalidCredentials, metrics.LoginChallengeFailed, metrics.LoginSuccess, metrics.LoginLocked} {
+		before[outcome] = authCounterValue(t, "phresume_auth_logins_total", "outcome", outcome)
+	}
+	lockoutsBefore := authCounterValue(t, "phresume_auth_lockouts_total", "scope", metrics.LockoutIP)
+	refreshBefore := authCounterValue(t, "phresume_auth_refresh_total", "outcome", metrics.RefreshInvalid)
+
+	loginRequestWith(h, "wrong-password", "")
+	loginRequestWith(h, "wrong-password", "")
+	loginRequestWith(h, "correct-password", "")
+	loginRequestWith(h, "correct-password", "human")
+	// 第三次失败达到 IP 阈值，触发一次封禁；之后的请求按 locked 计。
+	loginRequestWith(h, "wrong-password", "human")
+	if w := loginRequestWith(h, "correct-password", "human"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected ip block, got %d %s", w.Code, w.Body.String())
+	}
+
+	want := map[string]float64{
+		metrics.LoginInvalidCredentials: 3,
+		metrics.LoginChallengeFailed:    1,
+		metrics.LoginSuccess:            1,
+		metrics.LoginLocked:             1,
+	}
+	for outcome, delta := range want {
+		if got := authCounterValue(t, "phresume_auth_logins_total", "outcome", outcome) - before[outcome]; got != delta {
+			t.Errorf("logins_total{outcome=%q} delta = %v, want %v", outcome, got, delta)
+		}
+	}
+	if got := authCounterValue(t, "phresume_auth_lockouts_total", "scope", metrics.LockoutIP) - lockoutsBefore; got != 1 {
+		t.Errorf("lockouts_total{scope=ip} delta = %v, want 1", got)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", strings.NewReader(`{"refresh_token":"not-a-jwt"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.Refresh(c)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for invalid refresh token, got %d", w.Code)
+	}
+	if got := authCounterValue(t, "phresume_auth_refresh_total", "outcome", metrics.RefreshInvalid) - refreshBefore; got != 1 {
+		t.Errorf("refresh_total{outcome=invalid} delta = %v, want 1", got)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/database"
+)
+
+// resumeETag 由简历 ID 与 UpdatedAt（纳秒）生成弱 ETag；内容、标题或缩略图变更都会刷新 UpdatedAt。
+// 保存草稿不刷新 UpdatedAt，存在草稿时追加草稿保存时间，使响应中的 has_draft 能随之更新。
+func resumeETag(resume database.Resume) string {
+	if resume.DraftSavedAt != nil {
+		return fmt.Sprintf(`W/"r%d-%d-d%d"`, resume.ID, resume.UpdatedAt.UnixNano(), resume.DraftSavedAt.UnixNano())
+	}
+	return fmt.Sprintf(`W/"r%d-%d"`, resume.ID, resume.UpdatedAt.UnixNano())
+}
+
+// withETagSuffix 在 ETag 的结尾引号前追加 -suffix；suffix 为空时原样返回。
+func withETagSuffix(etag, suffix string) string {
+	if suffix == "" || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + suffix + `"`
+}
+
+// notModified 写入 ETag 与 Cache-Control: no-cache（要求客户端每次重新校验），
+// 若 If-None-Match 命中则直接响应 304 并返回 true。
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("Cache-Control", "no-cache")
+	c.Header("ETag", etag)
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches 按弱比较规则（忽略 W/ 前缀）判断 If-None-Match 列表是否包含 etag。
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/notify"
+)
+
+// sseHeartbeatInterval 为 SSE 注释行心跳间隔，需短于常见代理的空闲超时（通常 60s）。
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseRetryMillis 为建议客户端断线重连的等待时间（毫秒）。
+const sseRetryMillis = 5000
+
+// EventsHandler 以 Server-Sent Events 推送用户通知，供屏蔽 WebSocket 的网络环境使用；
+// 与 /v1/ws 订阅同一 user_notify 频道，payload 原样转发。
+type EventsHandler struct {
+	redisClient redis.UniversalClient
+}
+
+// NewEventsHandler 构造 SSE 通知处理器。
+func NewEventsHandler(redisClient redis.UniversalClient) *EventsHandler {
+	return &EventsHandler{redisClient: redisClient}
+}
+
+// Stream 保持 text/event-stream 长连接，直到客户端断开或 Redis 订阅中断；鉴权由路由上的中间件完成。
+func (h *EventsHandler) Stream(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	log := middleware.LoggerFromContext(c).With(slog.Uint64("user_id", uint64(userID)))
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// 关闭 nginx 等反向代理的响应缓冲，保证事件即时送达。
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// 先写出重连间隔并 flush，让客户端与中间代理立即收到响应头。
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	// 浏览器 EventSource 重连时自动携带 Last-Event-ID；手动建连的客户端可用查询参数传入。
+	lastEventID := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if lastEventID == "" {
+		lastEventID = strings.TrimSpace(c.Query("last_event_id"))
+	}
+
+	err := forwardUserNotifications(c.Request.Context(), h.redisClient, userID, lastEventID, sseHeartbeatInterval, log,
+		func(event notify.Event) error {
+			if err := writeSSEEvent(c.Writer, event); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		},
+		func() error {
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		},
+	)
+	if err != nil {
+		log.Info("event stream closed", slog.Any("error", err))
+		return
+	}
+	log.Info("event stream closed")
+}
+
+// writeSSEEvent 以默认 message 事件写出通知：带 id 时写 id 行供 EventSource 记录 Last-Event-ID；
+// 多行 payload 拆成多条 data 行，由客户端按换行拼回。
+func writeSSEEvent(w io.Writer, event notify.Event) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: ")
+		b.WriteString(event.ID)
+		b.WriteString("\n")
+	}
+	payload := event.Payload
+	for _, line := range strings.Split(strings.ReplaceAll(payload, "\r\n", "\n"), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/notify"
+)
+
+func TestWriteSSEEvent(t *testing.T) {
+	var b strings.Builder
+	if err := writeSSEEvent(&b, notify.Event{ID: "1700000000000-0", Payload: `{"id":"1700000000000-0","status":"completed"}`}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := b.String(); got != "id: 1700000000000-0\ndata: {\"id\":\"1700000000000-0\",\"status\":\"completed\"}\n\n" {
+		t.Fatalf("unexpected frame %q", got)
+	}
+
+	b.Reset()
+	if err := writeSSEEvent(&b, notify.Event{Payload: "a\r\nb\nc"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := b.String(); got != "data: a\ndata: b\ndata: c\n\n" {
+		t.Fatalf("multi-line payload must be split into data lines, got %q", got)
+	}
+}
+
+func TestEventsStream_RequiresUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/events", nil)
+
+	NewEventsHandler(nil).Stream(c)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatal("stream must not start before authentication")
+	}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessCheck 描述一个就绪探针依赖项：Check 返回 error 表示该依赖不可用。
+// Optional 为 true 的依赖失败时只把整体状态标记为 degraded，仍返回 200。
+type ReadinessCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+// ReadinessHandler 依次执行依赖检查并返回各项状态；任一必需依赖失败时返回 503，便于运维在用户请求失败前发现故障。
+// 单项检查受 timeout 约束，避免某个依赖挂起拖住探针。
+func ReadinessHandler(timeout time.Duration, checks ...ReadinessCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := http.StatusOK
+		degraded := false
+		results := make(map[string]string, len(checks))
+		for _, check := range checks {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			err := check.Check(ctx)
+			cancel()
+			if err != nil {
+				if check.Optional {
+					degraded = true
+				} else {
+					status = http.StatusServiceUnavailable
+				}
+				results[check.Name] = err.Error()
+				continue
+			}
+			results[check.Name] = "ok"
+		}
+
+		overall := "ready"
+		switch {
+		case status != http.StatusOK:
+			overall = "unavailable"
+		case degraded:
+			overall = "degraded"
+		}
+		c.JSON(status, gin.H{"status": overall, "checks": results})
+	}
+}
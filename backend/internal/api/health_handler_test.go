@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dutchcoders/go-clamd"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeScanner struct {
+	pingErr  error
+	block    chan struct{}
+	hangScan bool // 模拟挂起的 clamd：不返回结果，直到 abort 被关闭
+	aborted  chan struct{}
+}
+
+func (s *fakeScanner) Ping() error {
+	if s.block != nil {
+		<-s.block
+	}
+	return s.pingErr
+}
+
+func (s *fakeScanner) ScanStream(_ io.Reader, abort chan bool) (chan *clamd.ScanResult, error) {
+	if s.hangScan {
+		ch := make(chan *clamd.ScanResult)
+		go func() {
+			for range abort {
+			}
+			close(ch)
+			if s.aborted != nil {
+				close(s.aborted)
+			}
+		}()
+		return ch, nil
+	}
+	ch := make(chan *clamd.ScanResult, 1)
+	ch <- &clamd.ScanResult{Status: clamd.RES_OK}
+	close(ch)
+	return ch, nil
+}
+
+func TestReadinessHandler_ReportsFailingDependency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scanner := &fakeScanner{pingErr: errors.New("connection refused")}
+	r := gin.New()
+	r.GET("/ready", ReadinessHandler(time.Second,
+		ReadinessCheck{Name: "database", Check: func(context.Context) error { return nil }},
+		ReadinessCheck{Name: "clamav", Check: func(ctx context.Context) error { return PingVirusScanner(ctx, scanner) }},
+	))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d", w.Code)
+	}
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Status != "unavailable" || body.Checks["database"] != "ok" || body.Checks["clamav"] != "connection refused" {
+		t.Fatalf("unexpected readiness body %+v", body)
+	}
+
+	scanner.pingErr = nil
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 once clamd recovers, got %d", w.Code)
+	}
+}
+
+func TestReadinessHandler_OptionalDependencyDegrades(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ready", ReadinessHandler(time.Second,
+		ReadinessCheck{Name: "database", Check: func(context.Context) error { return nil }},
+		ReadinessCheck{Name: "clamav", Optional: true, Check: func(context.Context) error { return errors.New("connection refused") }},
+	))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("optional dependency outage must not fail readiness, got %d", w.Code)
+	}
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Status != "degraded" || body.Checks["clamav"] != "connection refused" {
+		t.Fatalf("unexpected readiness body %+v", body)
+	}
+}
+
+func TestPingVirusScanner_RespectsDeadline(t *testing.T) {
+	scanner := &fakeScanner{block: make(chan struct{})}
+	defer close(scanner.block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := PingVirusScanner(ctx, scanner); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if err := PingVirusScanner(context.Background(), nil); !errors.Is(err, errVirusScannerUnavailable) {
+		t.Fatalf("expected unavailable error for nil scanner, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedRichTextTags 为富文本允许保留的标签；其它标签只去掉标签本身，保留其文本内容。
+var allowedRichTextTags = map[string]struct{}{
+	"p": {}, "div": {}, "span": {}, "br": {},
+	"b": {}, "strong": {}, "i": {}, "em": {}, "u": {}, "s": {}, "strike": {},
+	"sub": {}, "sup": {}, "mark": {}, "code": {}, "pre": {}, "blockquote": {},
+	"ul": {}, "ol": {}, "li": {}, "a": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+}
+
+// droppedRichTextTags 连同内容整体丢弃的标签（脚本、样式表、内嵌文档等）。
+var droppedRichTextTags = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "frame": {}, "frameset": {},
+	"object": {}, "embed": {}, "applet": {}, "noscript": {}, "noembed": {},
+	"noframes": {}, "template": {}, "textarea": {}, "title": {}, "xmp": {},
+	"svg": {}, "math": {},
+}
+
+// allowedRichTextAttrs 为所有允许标签通用的属性；href 仅对 <a> 生效并单独校验协议。
+var allowedRichTextAttrs = map[string]struct{}{
+	"class": {},
+	"dir":   {},
+	"value": {},
+}
+
+// allowedLinkSchemes 为 <a href> 允许的协议，拒绝 javascript:/data:/vbscript: 以及相对地址。
+var allowedLinkSchemes = map[string]struct{}{
+	"http":   {},
+	"https":  {},
+	"mailto": {},
+}
+
+// allowedStyleProperties 为行内 style 允许的属性，覆盖 Lexical 工具栏产生的格式。
+var allowedStyleProperties = map[string]struct{}{
+	"color": {}, "background-color": {},
+	"font-family": {}, "font-size": {}, "font-style": {}, "font-weight": {},
+	"text-align": {}, "text-decoration": {}, "text-indent": {}, "text-transform": {},
+	"line-height": {}, "letter-spacing": {}, "white-space": {}, "vertical-align": {},
+	"margin": {}, "margin-top": {}, "margin-bottom": {}, "margin-left": {}, "margin-right": {},
+	"padding": {}, "padding-top": {}, "padding-bottom": {}, "padding-left": {}, "padding-right": {},
+	"padding-inline-start": {}, "list-style-type": {},
+}
+
+// unsafeStyleFragments 命中任一片段的 style 声明会被丢弃（外部资源、IE expression、脚本协议、转义绕过等）。
+var unsafeStyleFragments = []string{"url(", "expression", "javascript:", "vbscript:", "@import", "\\", "/*", "<", ">", "behavior", "-moz-binding"}
+
+// sanitizeRichTextHTML 以白名单方式清洗富文本 HTML：移除脚本/样式/内嵌文档及其内容、事件属性与危险链接，
+// 保留段落、列表、强调、链接等常见格式；输出重新转义，不会原样透传任何输入片段。
+func sanitizeRichTextHTML(input string) string {
+	if input == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(input))
+	var skipTag string
+	skipDepth := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF 或畸形输入：返回已清洗部分。
+			return b.String()
+		}
+		tok := z.Token()
+
+		if skipDepth > 0 {
+			switch {
+			case tt == html.StartTagToken && tok.Data == skipTag:
+				skipDepth++
+			case tt == html.EndTagToken && tok.Data == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(tok.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if _, drop := droppedRichTextTags[tok.Data]; drop {
+				if tt == html.StartTagToken {
+					skipTag = tok.Data
+					skipDepth = 1
+				}
+				continue
+			}
+			if _, ok := allowedRichTextTags[tok.Data]; !ok {
+				continue
+			}
+			b.WriteByte('<')
+			b.WriteString(tok.Data)
+			writeRichTextAttrs(&b, tok)
+			b.WriteByte('>')
+		case html.EndTagToken:
+			if _, ok := allowedRichTextTags[tok.Data]; !ok || tok.Data == "br" {
+				continue
+			}
+			b.WriteString("</")
+			b.WriteString(tok.Data)
+			b.WriteByte('>')
+		default:
+			// 注释、DOCTYPE 等一律丢弃。
+		}
+	}
+}
+
+func writeRichTextAttrs(b *strings.Builder, tok html.Token) {
+	for _, attr := range tok.Attr {
+		if attr.Namespace != "" {
+			continue
+		}
+		key := strings.ToLower(attr.Key)
+		value := attr.Val
+		switch {
+		case key == "href":
+			if tok.Data != "a" || !isSafeLinkHref(value) {
+				continue
+			}
+			value = strings.TrimSpace(value)
+		case key == "style":
+			value = sanitizeInlineStyle(value)
+			if value == "" {
+				continue
+			}
+		default:
+			if _, ok := allowedRichTextAttrs[key]; !ok {
+				continue
+			}
+		}
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(value))
+		b.WriteByte('"')
+	}
+}
+
+func isSafeLinkHref(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	_, ok := allowedLinkSchemes[strings.ToLower(u.Scheme)]
+	return ok
+}
+
+// sanitizeInlineStyle 逐条过滤 style 声明，只保留白名单属性且值不含危险片段的部分。
+func sanitizeInlineStyle(style string) string {
+	kept := make([]string, 0, 4)
+	for _, decl := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if _, allowed := allowedStyleProperties[name]; !allowed || value == "" {
+			continue
+		}
+		lower := strings.ToLower(value)
+		unsafe := false
+		for _, frag := range unsafeStyleFragments {
+			if strings.Contains(lower, frag) {
+				unsafe = true
+				break
+			}
+		}
+		if unsafe {
+			continue
+		}
+		kept = append(kept, name+": "+value)
+	}
+	return strings.Join(kept, "; ")
+}
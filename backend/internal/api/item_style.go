@@ -0,0 +1,226 @@
+package api
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// itemStyleRule 校验并修正单个样式值；返回 false 表示该属性应被丢弃。
+type itemStyleRule func(value any) (any, bool)
+
+// maxItemStyleValueLength 限制单个样式值的长度，避免超长字符串拖慢打印页渲染。
+const maxItemStyleValueLength = 128
+
+// itemStyleRules 为 item.style 允许的属性（与前端 React style 一致使用 camelCase）。
+// 未列出的属性一律丢弃，尤其是 position/zIndex/top/left/width/height/display/overflow 等会破坏网格布局或逃逸页面边界的属性。
+var itemStyleRules = map[string]itemStyleRule{
+	// 排版
+	"color":          cssTextValue,
+	"fontFamily":     cssTextValue,
+	"fontSize":       cssLengthValue(4, 128),
+	"fontWeight":     cssTextValue,
+	"fontStyle":      cssTextValue,
+	"lineHeight":     cssNumberValue(0.5, 4),
+	"letterSpacing":  cssLengthValue(-4, 32),
+	"textAlign":      cssTextValue,
+	"textDecoration": cssTextValue,
+	"textTransform":  cssTextValue,
+	"whiteSpace":     cssTextValue,
+	"verticalAlign":  cssTextValue,
+	"wordBreak":      cssTextValue,
+	"overflowWrap":   cssTextValue,
+
+	// 背景
+	"backgroundColor":   cssTextValue,
+	"backgroundOpacity": cssNumberValue(0, 1),
+	"opacity":           cssNumberValue(0, 1),
+
+	// 边框与内边距
+	"border":                  cssTextValue,
+	"borderTop":               cssTextValue,
+	"borderRight":             cssTextValue,
+	"borderBottom":            cssTextValue,
+	"borderLeft":              cssTextValue,
+	"borderColor":             cssTextValue,
+	"borderStyle":             cssTextValue,
+	"borderWidth":             cssLengthValue(0, 32),
+	"borderRadius":            cssLengthValue(0, 256),
+	"borderTopLeftRadius":     cssLengthValue(0, 256),
+	"borderTopRightRadius":    cssLengthValue(0, 256),
+	"borderBottomLeftRadius":  cssLengthValue(0, 256),
+	"borderBottomRightRadius": cssLengthValue(0, 256),
+	"padding":                 cssTextValue,
+	"paddingTop":              cssLengthValue(0, 256),
+	"paddingRight":            cssLengthValue(0, 256),
+	"paddingBottom":           cssLengthValue(0, 256),
+	"paddingLeft":             cssLengthValue(0, 256),
+
+	// 图片
+	"objectFit":       cssTextValue,
+	"objectPosition":  cssTextValue,
+	"transform":       cssScaleTransform(0.5, 2),
+	"transformOrigin": cssTextValue,
+}
+
+// unsafeItemStyleFragments 在富文本 style 的危险片段之外，额外拒绝可能注入新声明或规则块的字符。
+var unsafeItemStyleFragments = append([]string{";", "{", "}", "!important"}, unsafeStyleFragments...)
+
+// cssTextValue 接受数字或不含危险片段的短字符串。
+func cssTextValue(value any) (any, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		return v, true
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" || len(s) > maxItemStyleValueLength || strings.ContainsFunc(s, isCSSControlRune) {
+			return nil, false
+		}
+		lower := strings.ToLower(s)
+		for _, frag := range unsafeItemStyleFragments {
+			if strings.Contains(lower, frag) {
+				return nil, false
+			}
+		}
+		return s, true
+	default:
+		return nil, false
+	}
+}
+
+func isCSSControlRune(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// cssNumberValue 接受无单位数字（或数字字符串），并钳制到 [lo, hi]。
+func cssNumberValue(lo, hi float64) itemStyleRule {
+	return func(value any) (any, bool) {
+		n, ok := styleNumber(value)
+		if !ok {
+			return nil, false
+		}
+		return math.Min(hi, math.Max(lo, n)), true
+	}
+}
+
+var cssLengthPattern = regexp.MustCompile(`^(-?\d+(?:\.\d+)?)(px|pt|em|rem)?$`)
+
+// cssLengthUnitPx 为各单位换算为 px 的系数（em/rem 按 16px 估算），用于以统一的 px 上下限钳制。
+var cssLengthUnitPx = map[string]float64{
+	"":    1,
+	"px":  1,
+	"pt":  4.0 / 3.0,
+	"em":  16,
+	"rem": 16,
+}
+
+// cssLengthValue 接受数字（按 px）或 px/pt/em/rem 长度，并以 px 计的 [loPx, hiPx] 钳制，保留原单位。
+func cssLengthValue(loPx, hiPx float64) itemStyleRule {
+	return func(value any) (any, bool) {
+		if n, ok := value.(float64); ok {
+			if math.IsNaN(n) || math.IsInf(n, 0) {
+				return nil, false
+			}
+			return math.Min(hiPx, math.Max(loPx, n)), true
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		m := cssLengthPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+		if m == nil {
+			return nil, false
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return nil, false
+		}
+		unit := m[2]
+		factor := cssLengthUnitPx[unit]
+		n = math.Min(hiPx/factor, math.Max(loPx/factor, n))
+		if unit == "" {
+			unit = "px"
+		}
+		return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64) + unit, true
+	}
+}
+
+var cssScalePattern = regexp.MustCompile(`^scale\(\s*(\d+(?:\.\d+)?)\s*\)$`)
+
+// cssScaleTransform 只接受编辑器产生的 scale(n) 形式，并钳制到 [lo, hi]；rotate/translate 等可能把内容移出单元格的变换一律丢弃。
+func cssScaleTransform(lo, hi float64) itemStyleRule {
+	return func(value any) (any, bool) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		m := cssScalePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+		if m == nil {
+			return nil, false
+		}
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return nil, false
+		}
+		n = math.Min(hi, math.Max(lo, n))
+		return "scale(" + strconv.FormatFloat(n, 'f', -1, 64) + ")", true
+	}
+}
+
+func styleNumber(value any) (float64, bool) {
+	var n float64
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		n = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
+
+// sanitizeItemStyle 按 itemStyleRules 过滤并钳制 item["style"]，返回被丢弃的属性名（已排序）。
+// style 缺失或不是对象时替换为空对象。
+func sanitizeItemStyle(item map[string]any) []string {
+	raw, ok := item["style"].(map[string]any)
+	if !ok {
+		if item["style"] != nil {
+			item["style"] = map[string]any{}
+			return []string{"style"}
+		}
+		item["style"] = map[string]any{}
+		return nil
+	}
+
+	var dropped []string
+	clean := make(map[string]any, len(raw))
+	for key, value := range raw {
+		rule, allowed := itemStyleRules[key]
+		if !allowed {
+			dropped = append(dropped, key)
+			continue
+		}
+		v, ok := rule(value)
+		if !ok {
+			dropped = append(dropped, key)
+			continue
+		}
+		clean[key] = v
+	}
+	item["style"] = clean
+	sort.Strings(dropped)
+	return dropped
+}
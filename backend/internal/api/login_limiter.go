@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	"phResume/internal/metrics"
+)
+
+// loginAttemptStore 为登录失败计数/锁定所需的 Redis 子集，便于测试替换。
+type loginAttemptStore interface {
+	redisRateCounter
+	TTL(ctx context.Context, key string) *redis.DurationCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// loginBlockReason 描述登录被拒绝的锁定类型。
+type loginBlockReason int
+
+const (
+	loginNotBlocked loginBlockReason = iota
+	loginBlockedByIP
+	loginBlockedByUsername
+)
+
+// loginAttemptLimiter 按 IP 与用户名两个维度统计登录失败：
+// - IP 维度：同一来源在窗口内失败过多则临时封禁该 IP，抵御跨用户名的撞库喷洒；
+// - 用户名维度（可关闭）：失败过多锁定账号。攻击者可借此恶意锁定他人账号，故允许仅依赖 IP 维度。
+type loginAttemptLimiter struct {
+	store               loginAttemptStore
+	usernameLockEnabled bool
+	usernameThreshold   int
+	usernameTTL         time.Duration
+	ipThreshold         int
+	ipTTL               time.Duration
+	// db 非 nil 时账号锁定同时写入 users.failed_login_count / locked_until：
+	// Redis 仍是快速路径，数据库作为持久兜底，Redis 被清空或不可用时锁定依然生效。
+	db *gorm.DB
+}
+
+func loginUsernameFailKey(username string) string { return "lock:login:fail:" + username }
+func loginUsernameLockKey(username string) string { return "lock:login:" + username }
+func loginIPFailKey(ip string) string             { return "lock:login:ip:fail:" + ip }
+func loginIPLockKey(ip string) string             { return "lock:login:ip:" + ip }
+func loginPairFailKey(username, ip string) string {
+	return "login:challenge:fail:" + ip + ":" + username
+}
+
+// blocked 检查 IP 封禁与账号锁定；Redis 异常时放行，与频控的容错策略一致。
+func (l *loginAttemptLimiter) blocked(ctx context.Context, username, ip string) loginBlockReason {
+	if ip != "" {
+		if ttl, _ := l.store.TTL(ctx, loginIPLockKey(ip)).Result(); ttl > 0 {
+			return loginBlockedByIP
+		}
+	}
+	if l.usernameLockEnabled {
+		if ttl, _ := l.store.TTL(ctx, loginUsernameLockKey(username)).Result(); ttl > 0 {
+			return loginBlockedByUsername
+		}
+	}
+	return loginNotBlocked
+}
+
+// pairFailures 返回该 IP+用户名 组合在窗口内的失败次数，用于判断是否需要人机验证；读取失败按 0 处理。
+func (l *loginAttemptLimiter) pairFailures(ctx context.Context, username, ip string) int64 {
+	count, err := l.store.Get(ctx, loginPairFailKey(username, ip)).Int64()
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// recordFailure 累加失败计数，达到阈值时写入对应锁定键。
+func (l *loginAttemptLimiter) recordFailure(ctx context.Context, username, ip string) {
+	_, _ = incrWithTTL(ctx, l.store, loginPairFailKey(username, ip), l.usernameTTL)
+	if ip != "" && l.ipThreshold > 0 {
+		if count, err := incrWithTTL(ctx, l.store, loginIPFailKey(ip), l.ipTTL); err == nil && count >= int64(l.ipThreshold) {
+			if l.store.Set(ctx, loginIPLockKey(ip), "1", l.ipTTL).Err() == nil {
+				metrics.AuthLockout(metrics.LockoutIP)
+			}
+		}
+	}
+	if l.usernameLockEnabled && l.usernameThreshold > 0 {
+		if count, err := incrWithTTL(ctx, l.store, loginUsernameFailKey(username), l.usernameTTL); err == nil && count >= int64(l.usernameThreshold) {
+			if l.store.Set(ctx, loginUsernameLockKey(username), "1", l.usernameTTL).Err() == nil {
+				metrics.AuthLockout(metrics.LockoutUsername)
+			}
+		}
+	}
+}
+
+// usernameLockRemaining 返回账号锁定的剩余时间，未锁定或读取失败时返回 0。
+func (l *loginAttemptLimiter) usernameLockRemaining(ctx context.Context, username string) time.Duration {
+	ttl, err := l.store.TTL(ctx, loginUsernameLockKey(username)).Result()
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// unlockUsername 立即解除账号锁定并清零用户名维度的失败计数。
+// 被锁定的用户无法通过登录成功来清理计数，需由改密或管理员解锁触发。
+func (l *loginAttemptLimiter) unlockUsername(ctx context.Context, username string) error {
+	return ClearUsernameLoginLock(ctx, l.store, username)
+}
+
+// ClearUsernameLoginLock 删除用户名的锁定键与失败计数，供 API 与 cmd/admin 共用；username 按登录时的小写形式处理。
+func ClearUsernameLoginLock(ctx context.Context, store interface {
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}, username string) error {
+	username = strings.ToLower(strings.TrimSpace(username))
+	return delEach(ctx, store, loginUsernameLockKey(username), loginUsernameFailKey(username))
+}
+
+// recordSuccess 清理该用户名及 IP+用户名 组合的失败计数。
+// IP 维度计数不在成功时清零，避免攻击者穿插登录自有账号来重置计数。
+func (l *loginAttemptLimiter) recordSuccess(ctx context.Context, username, ip string) {
+	_ = delEach(ctx, l.store, loginUsernameFailKey(username), loginPairFailKey(username, ip))
+}
+
+// dbLockRemaining 返回数据库记录的账号锁定剩余时间；未开启兜底、未锁定或已过期时返回 0。
+func (l *loginAttemptLimiter) dbLockRemaining(user database.User) time.Duration {
+	if l.db == nil || !l.usernameLockEnabled || user.LockedUntil == nil {
+		return 0
+	}
+	if remaining := time.Until(*user.LockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// recordDBFailure 原子累加连续失败次数，达到阈值时写入 locked_until 并清零计数。
+// 两个 CASE 读取的都是更新前的 failed_login_count（PostgreSQL 与 SQLite 语义一致）。
+func (l *loginAttemptLimiter) recordDBFailure(ctx context.Context, userID uint) error {
+	if l.db == nil || !l.usernameLockEnabled || l.usernameThreshold <= 0 {
+		return nil
+	}
+	threshold := l.usernameThreshold
+	lockedUntil := time.Now().Add(l.usernameTTL)
+	return l.db.WithContext(ctx).Model(&database.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"failed_login_count": gorm.Expr("CASE WHEN failed_login_count + 1 >= ? THEN 0 ELSE failed_login_count + 1 END", threshold),
+			"locked_until":       gorm.Expr("CASE WHEN failed_login_count + 1 >= ? THEN ? ELSE locked_until END", threshold, lockedUntil),
+		}).Error
+}
+
+// clearDBLock 清零数据库中的失败计数与锁定时间，登录成功、改密与管理员解锁时调用。
+func (l *loginAttemptLimiter) clearDBLock(ctx context.Context, userID uint) error {
+	if l.db == nil {
+		return nil
+	}
+	return resetDBLock(l.db.WithContext(ctx).Model(&database.User{}).Where("id = ?", userID))
+}
+
+// ClearUsernameDBLock 清零该用户名账号在数据库中的失败计数与锁定时间，供 cmd/admin 在不经过 API 进程时解锁。
+func ClearUsernameDBLock(ctx context.Context, db *gorm.DB, username string) error {
+	return resetDBLock(database.WhereUsername(db.WithContext(ctx).Model(&database.User{}), username))
+}
+
+func resetDBLock(query *gorm.DB) error {
+	return query.Where("failed_login_count <> 0 OR locked_until IS NOT NULL").
+		Updates(map[string]any{"failed_login_count": 0, "locked_until": nil}).Error
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryLoginStore 为 loginAttemptStore 的内存实现（不模拟过期，仅记录 TTL）。
+type memoryLoginStore struct {
+	counters map[string]int64
+	ttls     map[string]time.Duration
+}
+
+func newMemoryLoginStore() *memoryLoginStore {
+	return &memoryLoginStore{counters: map[string]int64{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *memoryLoginStore) Incr(_ context.Context, key string) *redis.IntCmd {
+	s.counters[key]++
+	return redis.NewIntResult(s.counters[key], nil)
+}
+
+func (s *memoryLoginStore) Expire(_ context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	s.ttls[key] = expiration
+	return redis.NewBoolResult(true, nil)
+}
+
+func (s *memoryLoginStore) TTL(_ context.Context, key string) *redis.DurationCmd {
+	if ttl, ok := s.ttls[key]; ok {
+		return redis.NewDurationResult(ttl, nil)
+	}
+	return redis.NewDurationResult(-2*time.Second, nil)
+}
+
+func (s *memoryLoginStore) Get(_ context.Context, key string) *redis.StringCmd {
+	count, ok := s.counters[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(strconv.FormatInt(count, 10), nil)
+}
+
+func (s *memoryLoginStore) Set(_ context.Context, key string, _ interface{}, expiration time.Duration) *redis.StatusCmd {
+	s.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (s *memoryLoginStore) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	for _, key := range keys {
+		delete(s.counters, key)
+		delete(s.ttls, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func newTestLoginLimiter(store loginAttemptStore, usernameLock bool) *loginAttemptLimiter {
+	return &loginAttemptLimiter{
+		store:               store,
+		usernameLockEnabled: usernameLock,
+		usernameThreshold:   5,
+		usernameTTL:         30 * time.Minute,
+		ipThreshold:         3,
+		ipTTL:               time.Hour,
+	}
+}
+
+func TestLoginAttemptLimiter_BlocksIPAcrossUsernames(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryLoginStore()
+	limiter := newTestLoginLimiter(store, true)
+
+	// 同一 IP 对不同用户名喷洒：单个用户名未达阈值，但 IP 维度应被封禁。
+	for _, username := range []string{"alice", "bob", "carol"} {
+		if reason := limiter.blocked(ctx, username, "10.0.0.1"); reason != loginNotBlocked {
+			t.Fatalf("unexpected block before threshold: %v", reason)
+		}
+		limiter.recordFailure(ctx, username, "10.0.0.1")
+	}
+
+	if reason := limiter.blocked(ctx, "dave", "10.0.0.1"); reason != loginBlockedByIP {
+		t.Fatalf("expected ip block, got %v", reason)
+	}
+	if reason := limiter.blocked(ctx, "dave", "10.0.0.2"); reason != loginNotBlocked {
+		t.Fatalf("other ip should not be blocked, got %v", reason)
+	}
+	if ttl := store.ttls[loginIPLockKey("10.0.0.1")]; ttl != time.Hour {
+		t.Fatalf("ip lock ttl = %v, want 1h", ttl)
+	}
+
+	// 登录成功不应清除 IP 维度计数。
+	limiter.recordSuccess(ctx, "alice", "10.0.0.1")
+	if reason := limiter.blocked(ctx, "alice", "10.0.0.1"); reason != loginBlockedByIP {
+		t.Fatalf("ip block should survive a successful login, got %v", reason)
+	}
+}
+
+func TestLoginAttemptLimiter_UsernameLockOptional(t *testing.T) {
+	ctx := context.Background()
+
+	enabled := newTestLoginLimiter(newMemoryLoginStore(), true)
+	enabled.ipThreshold = 100
+	for i := 0; i < 5; i++ {
+		enabled.recordFailure(ctx, "victim", "10.0.0.1")
+	}
+	if reason := enabled.blocked(ctx, "victim", "10.0.0.9"); reason != loginBlockedByUsername {
+		t.Fatalf("expected username lock, got %v", reason)
+	}
+
+	store := newMemoryLoginStore()
+	disabled := newTestLoginLimiter(store, false)
+	disabled.ipThreshold = 100
+	for i := 0; i < 5; i++ {
+		disabled.recordFailure(ctx, "victim", "10.0.0.1")
+	}
+	if reason := disabled.blocked(ctx, "victim", "10.0.0.9"); reason != loginNotBlocked {
+		t.Fatalf("username lock disabled, got %v", reason)
+	}
+	if _, ok := store.counters[loginUsernameFailKey("victim")]; ok {
+		t.Fatalf("username failures should not be counted when lock is disabled")
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
+	"phResume/internal/openapi"
+	resumepkg "phResume/internal/resume"
+)
+
+// ListErrorCodes 返回错误码登记表（code -> 名称/说明），供前端与错误码保持同步。
+func ListErrorCodes(c *gin.Context) {
+	entries := errcode.Catalog()
+	codes := make(map[string]errcode.Entry, len(entries))
+	for _, entry := range entries {
+		codes[strconv.Itoa(entry.Code)] = entry
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{"codes": codes})
+}
+
+// ResumeContentSchema 返回简历/模板 content 的 JSON Schema，供第三方客户端在提交前自行校验。
+func ResumeContentSchema(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/schema+json", resumepkg.ContentSchema())
+}
+
+// OpenAPISpec 返回 /v1 接口的 OpenAPI 3 文档，供客户端生成与接口调试。
+func OpenAPISpec(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Spec())
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"phResume/internal/i18n"
+	resumepkg "phResume/internal/resume"
+)
+
+func TestResumeContentSchema_ValidatesDefaultContent(t *testing.T) {
+	router := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/resume-schema", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/schema+json") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+
+	for _, locale := range []string{i18n.EN, i18n.ZhCN} {
+		var content any
+		if err := json.Unmarshal(resumepkg.StarterContent(locale), &content); err != nil {
+			t.Fatalf("decode %s starter content: %v", locale, err)
+		}
+		if errs := validateJSONSchema(schema, schema, content, "$"); len(errs) > 0 {
+			t.Fatalf("%s starter content does not match schema: %v", locale, errs)
+		}
+	}
+
+	// 反例：确保校验器确实在工作，而不是对任何输入都放行。
+	invalid := []string{
+		`{"items":[]}`,
+		`{"layout_settings":{"columns":0,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":0},"items":[]}`,
+		`{"layout_settings":{"columns":24,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":0},"items":[{"id":"a","type":"shape","content":"","layout":{"x":0,"y":0,"w":1,"h":1}}]}`,
+		`{"layout_settings":{"columns":24,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":0},"items":[{"id":"a","type":"image","content":"../etc/passwd","layout":{"x":0,"y":0,"w":1,"h":1}}]}`,
+		`{"layout_settings":{"columns":24,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":0},"items":[{"id":"a","type":"qrcode","content":"javascript:alert(1)","layout":{"x":0,"y":0,"w":1,"h":1}}]}`,
+		`{"layout_settings":{"columns":24,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":0},"items":[{"id":"a","type":"text","content":"x","layout":{"x":0,"y":0,"w":0,"h":1}}]}`,
+	}
+	for _, raw := range invalid {
+		var doc any
+		if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+			t.Fatalf("decode %s: %v", raw, err)
+		}
+		if errs := validateJSONSchema(schema, schema, doc, "$"); len(errs) == 0 {
+			t.Fatalf("expected schema violation for %s", raw)
+		}
+	}
+}
+
+// validateJSONSchema 为测试用的最小 JSON Schema 校验器，仅覆盖 content.schema.json 用到的关键字。
+func validateJSONSchema(root, schema map[string]any, value any, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		target := root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			target, _ = target[part].(map[string]any)
+		}
+		return validateJSONSchema(root, target, value, path)
+	}
+
+	var errs []string
+	fail := func(format string, args ...any) {
+		errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if typ, ok := schema["type"]; ok && !matchesSchemaType(typ, value) {
+		fail("type %v mismatch for %T", typ, value)
+		return errs
+	}
+	if c, ok := schema["const"]; ok && c != value {
+		fail("expected const %v", c)
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if e == value {
+				found = true
+			}
+		}
+		if !found {
+			fail("value %v not in enum", value)
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && v < minimum {
+			fail("%v < minimum %v", v, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && v > maximum {
+			fail("%v > maximum %v", v, maximum)
+		}
+	case string:
+		n := float64(utf8.RuneCountInString(v))
+		if minLen, ok := schema["minLength"].(float64); ok && n < minLen {
+			fail("shorter than %v", minLen)
+		}
+		if maxLen, ok := schema["maxLength"].(float64); ok && n > maxLen {
+			fail("longer than %v", maxLen)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(v) {
+			fail("does not match %q", pattern)
+		}
+	case []any:
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(v)) > maxItems {
+			fail("more than %v items", maxItems)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, elem := range v {
+				errs = append(errs, validateJSONSchema(root, items, elem, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, key := range required {
+				if _, present := v[key.(string)]; !present {
+					fail("missing required %q", key)
+				}
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for key, elem := range v {
+			if sub, ok := props[key].(map[string]any); ok {
+				errs = append(errs, validateJSONSchema(root, sub, elem, path+"."+key)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unexpected property %q", key)
+				}
+			case map[string]any:
+				errs = append(errs, validateJSONSchema(root, extra, elem, path+"."+key)...)
+			}
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			subSchema := sub.(map[string]any)
+			if cond, ok := subSchema["if"].(map[string]any); ok {
+				if len(validateJSONSchema(root, cond, value, path)) == 0 {
+					if then, ok := subSchema["then"].(map[string]any); ok {
+						errs = append(errs, validateJSONSchema(root, then, value, path)...)
+					}
+				}
+				continue
+			}
+			errs = append(errs, validateJSONSchema(root, subSchema, value, path)...)
+		}
+	}
+	return errs
+}
+
+func matchesSchemaType(typ any, value any) bool {
+	if list, ok := typ.([]any); ok {
+		for _, t := range list {
+			if matchesSchemaType(t, value) {
+				return true
+			}
+		}
+		return false
+	}
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return false
+}
@@ -1,35 +1,34 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"phResume/internal/auth"
+	"phResume/internal/errcode"
 )
 
-// AuthMiddleware 校验访问令牌并将 userID 注入上下文。
+// AccessTokenCookieName 为开启 API_ACCESS_TOKEN_COOKIE 后下发 access token 的 HttpOnly Cookie 名。
+const AccessTokenCookieName = "access_token"
+
 func abortUnauthorized(c *gin.Context) {
-	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	abortWithError(c, http.StatusUnauthorized, errcode.Unauthorized, "unauthorized")
 }
 
-func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		header := c.GetHeader("Authorization")
-		if header == "" {
-			abortUnauthorized(c)
-			return
-		}
+// RevokedUserLookup 查询用户的令牌是否已整体吊销（如账号已注销）。
+type RevokedUserLookup func(ctx context.Context, userID uint) (bool, error)
 
-		parts := strings.Fields(header)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			abortUnauthorized(c)
-			return
-		}
-
-		rawToken := parts[1]
-		if strings.TrimSpace(rawToken) == "" {
+// AuthMiddleware 校验访问令牌并将 userID 注入上下文。
+// 令牌优先取 Authorization: Bearer 头；仅当请求不带该头时才回退读取 access token Cookie，
+// 带了格式错误或无效的头不会再尝试 Cookie。
+// revoked 非 nil 时拒绝已吊销用户的令牌；查询失败时放行，避免 Redis 故障导致全部接口 401。
+func AuthMiddleware(authService *auth.AuthService, revoked RevokedUserLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawToken, ok := accessTokenFromRequest(c)
+		if !ok {
 			abortUnauthorized(c)
 			return
 		}
@@ -39,9 +38,50 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 			abortUnauthorized(c)
 			return
 		}
+		if revoked != nil {
+			if isRevoked, err := revoked(c.Request.Context(), claims.UserID); err == nil && isRevoked {
+				abortUnauthorized(c)
+				return
+			}
+		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("mustChangePassword", claims.MustChangePassword)
+		c.Set("role", claims.Role)
+		c.Next()
+	}
+}
+
+func accessTokenFromRequest(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		token, err := c.Cookie(AccessTokenCookieName)
+		if err != nil || strings.TrimSpace(token) == "" {
+			return "", false
+		}
+		return token, true
+	}
+
+	parts := strings.Fields(header)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", false
+	}
+	if strings.TrimSpace(parts[1]) == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// AccessTokenQueryMiddleware 供 EventSource 等无法设置请求头的客户端使用：
+// 请求未带 Authorization 头且查询参数 param 非空时，将其作为 Bearer token 交给后续的 AuthMiddleware。
+// 带了 Authorization 头时以头为准；查询参数会出现在代理访问日志中，仅应挂在确有需要的路由上。
+func AccessTokenQueryMiddleware(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := strings.TrimSpace(c.Query(param)); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/auth"
+)
+
+func TestAuthMiddleware_AcceptsHeaderOrCookie(t *testing.T) {
+	authService := newTestAuthService(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/resume", AuthMiddleware(authService, nil), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("userID")})
+	})
+
+	pair, err := authService.GenerateTokenPair(7, false, auth.RoleUser)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+
+	do := func(header, cookie string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/resume", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: AccessTokenCookieName, Value: cookie})
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	cases := []struct {
+		name   string
+		header string
+		cookie string
+		want   int
+	}{
+		{name: "bearer header", header: "Bearer " + pair.AccessToken, want: http.StatusOK},
+		{name: "cookie only", cookie: pair.AccessToken, want: http.StatusOK},
+		{name: "header wins over cookie", header: "Bearer " + pair.AccessToken, cookie: "garbage", want: http.StatusOK},
+		{name: "invalid header does not fall back", header: "Bearer garbage", cookie: pair.AccessToken, want: http.StatusUnauthorized},
+		{name: "refresh token in cookie", cookie: pair.RefreshToken, want: http.StatusUnauthorized},
+		{name: "nothing", want: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if got := do(tc.header, tc.cookie); got != tc.want {
+			t.Fatalf("%s: expected %d got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAuthMiddleware_RejectsRevokedUser(t *testing.T) {
+	authService := newTestAuthService(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	revoked := func(_ context.Context, userID uint) (bool, error) {
+		if userID == 3 {
+			return false, errors.New("redis down")
+		}
+		return userID == 2, nil
+	}
+	router.GET("/v1/resume", AuthMiddleware(authService, revoked), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{name: "active user", userID: 1, want: http.StatusOK},
+		{name: "deleted account", userID: 2, want: http.StatusUnauthorized},
+		{name: "lookup failure fails open", userID: 3, want: http.StatusOK},
+	}
+	for _, tc := range cases {
+		pair, err := authService.GenerateTokenPair(tc.userID, false, auth.RoleUser)
+		if err != nil {
+			t.Fatalf("generate token pair: %v", err)
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/resume", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		router.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s: expected %d got %d", tc.name, tc.want, w.Code)
+		}
+	}
+}
+
+func TestAccessTokenQueryMiddleware(t *testing.T) {
+	authService := newTestAuthService(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/events", AccessTokenQueryMiddleware("access_token"), AuthMiddleware(authService, nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	pair, err := authService.GenerateTokenPair(7, false, auth.RoleUser)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+
+	do := func(query, header string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/events"+query, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if got := do("?access_token="+pair.AccessToken, ""); got != http.StatusOK {
+		t.Fatalf("query token: expected 200 got %d", got)
+	}
+	if got := do("?access_token=garbage", "Bearer "+pair.AccessToken); got != http.StatusOK {
+		t.Fatalf("header should win over query: got %d", got)
+	}
+	if got := do("?access_token="+pair.RefreshToken, ""); got != http.StatusUnauthorized {
+		t.Fatalf("refresh token in query: expected 401 got %d", got)
+	}
+	if got := do("", ""); got != http.StatusUnauthorized {
+		t.Fatalf("no token: expected 401 got %d", got)
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
+)
+
+// BodyLimitMiddleware 限制请求体大小：声明的 Content-Length 超限直接返回 413，
+// 其余请求以 http.MaxBytesReader 包装，读取超限时由绑定逻辑映射为 413。
+// exemptPaths 为按路由模板（c.FullPath()）豁免的路径，例如自带上限的 multipart 上传接口。
+func BodyLimitMiddleware(maxBytes int64, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]struct{}, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if _, ok := exempt[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			abortWithError(c, http.StatusRequestEntityTooLarge, errcode.PayloadTooLarge, "request body too large")
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newBodyLimitRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(maxBytes, "/upload"))
+	read := func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.Status(http.StatusRequestEntityTooLarge)
+				return
+			}
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+	router.POST("/json", read)
+	router.POST("/upload", read)
+	return router
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	router := newBodyLimitRouter(16)
+	cases := []struct {
+		name          string
+		path          string
+		body          string
+		unknownLength bool
+		want          int
+	}{
+		{name: "within limit", path: "/json", body: `{"a":1}`, want: http.StatusOK},
+		{name: "declared length too large", path: "/json", body: strings.Repeat("x", 32), want: http.StatusRequestEntityTooLarge},
+		{name: "streamed body too large", path: "/json", body: strings.Repeat("x", 32), unknownLength: true, want: http.StatusRequestEntityTooLarge},
+		{name: "exempt path", path: "/upload", body: strings.Repeat("x", 32), want: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			if tc.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if got := w.Code; got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,24 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"phResume/internal/tracecontext"
 )
 
-const correlationIDKey = "correlationID"
+const (
+	correlationIDKey = "correlationID"
+	traceParentKey   = "traceParent"
+)
 
-// CorrelationIDMiddleware 确保每个请求都带有 Correlation ID。
+// CorrelationIDMiddleware 确保每个请求都带有 Correlation ID 与 W3C traceparent。
+// 上游带合法 traceparent 时以其为父开启本请求的 server span，否则新建 trace；
+// span 的 traceparent 写入上下文，供日志与入队任务继续传播，请求结束时导出 span（未配置导出器时为 noop）。
 func CorrelationIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.GetHeader("X-Correlation-ID")
@@ -18,7 +29,25 @@ func CorrelationIDMiddleware() gin.HandlerFunc {
 		c.Set(correlationIDKey, id)
 		c.Header("X-Correlation-ID", id)
 
+		ctx := tracecontext.ContextWithRemote(c.Request.Context(), c.GetHeader(tracecontext.Header))
+		ctx, span := tracecontext.StartSpan(ctx, c.Request.Method, tracecontext.SpanKindServer)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(traceParentKey, span.TraceParent())
+
 		c.Next()
+
+		route := c.FullPath()
+		if route != "" {
+			span.SetName(c.Request.Method + " " + route)
+			span.SetAttribute("http.route", route)
+		}
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("http.response.status_code", strconv.Itoa(c.Writer.Status()))
+		span.SetAttribute("correlation_id", id)
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			span.RecordError(fmt.Errorf("http status %d", c.Writer.Status()))
+		}
+		span.End()
 	}
 }
 
@@ -31,3 +60,23 @@ func GetCorrelationID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetTraceParent 返回当前请求的 traceparent 头值；未经过 CorrelationIDMiddleware 时返回空串。
+func GetTraceParent(c *gin.Context) string {
+	if value, ok := c.Get(traceParentKey); ok {
+		if tp, ok := value.(tracecontext.TraceParent); ok {
+			return tp.String()
+		}
+	}
+	return ""
+}
+
+// GetTraceID 返回当前请求的 trace id，用于日志关联。
+func GetTraceID(c *gin.Context) string {
+	if value, ok := c.Get(traceParentKey); ok {
+		if tp, ok := value.(tracecontext.TraceParent); ok {
+			return tp.TraceID
+		}
+	}
+	return ""
+}
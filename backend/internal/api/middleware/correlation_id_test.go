@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/tracecontext"
+)
+
+func TestCorrelationIDMiddleware_ContinuesTraceParent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CorrelationIDMiddleware())
+	var traceParent, traceID string
+	r.GET("/ping", func(c *gin.Context) {
+		traceParent = GetTraceParent(c)
+		traceID = GetTraceID(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(tracecontext.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	parsed, ok := tracecontext.Parse(traceParent)
+	if !ok || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || parsed.TraceID != traceID || parsed.ParentID == "00f067aa0ba902b7" {
+		t.Fatalf("expected continued trace, got traceparent=%q trace_id=%q", traceParent, traceID)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(tracecontext.Header, "not-a-traceparent")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if _, ok := tracecontext.Parse(traceParent); !ok || traceID == "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected a new root trace for invalid header, got %q", traceParent)
+	}
+}
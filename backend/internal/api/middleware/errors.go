@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
+	"phResume/internal/i18n"
+)
+
+// abortWithError 以统一错误结构 {"error":{"code":N,"message":"..."}} 中断请求，message 按请求语言渲染。
+func abortWithError(c *gin.Context, status, code int, msg string) {
+	message := i18n.Translate(GetLocale(c), code, msg)
+	c.AbortWithStatusJSON(status, gin.H{"error": errcode.Detail{Code: code, Message: message}})
+}
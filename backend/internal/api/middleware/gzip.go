@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressibleTypes 为允许压缩的响应类型；图片、PDF、字体等本身已压缩的类型以及 SSE 流不在其中。
+var compressibleTypes = map[string]struct{}{
+	"application/json":         {},
+	"application/problem+json": {},
+	"application/schema+json":  {},
+	"application/javascript":   {},
+	"application/xml":          {},
+	"image/svg+xml":            {},
+	"text/plain":               {},
+	"text/html":                {},
+	"text/css":                 {},
+	"text/csv":                 {},
+	"text/xml":                 {},
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+// GzipMiddleware 按 Accept-Encoding 对文本/JSON 响应做 gzip 压缩。
+// 响应体不足 minBytes 时原样输出；WebSocket 升级、Range 请求与 HEAD 请求直接放行，
+// 已设置 Content-Encoding 或 Content-Range 的响应（如 /metrics、分段下载）也不会被二次处理。
+func GzipMiddleware(minBytes int) gin.HandlerFunc {
+	if minBytes < 0 {
+		minBytes = 0
+	}
+	return func(c *gin.Context) {
+		req := c.Request
+		if req.Method == http.MethodHead ||
+			req.Header.Get("Range") != "" ||
+			strings.EqualFold(req.Header.Get("Upgrade"), "websocket") ||
+			!acceptsGzip(req.Header.Get("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		gw := &gzipResponseWriter{ResponseWriter: original, minBytes: minBytes, status: http.StatusOK}
+		original.Header().Add("Vary", "Accept-Encoding")
+		c.Writer = gw
+		defer func() {
+			gw.finish()
+			c.Writer = original
+		}()
+		c.Next()
+	}
+}
+
+// acceptsGzip 解析 Accept-Encoding，q=0 视为显式拒绝。
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter 先缓冲响应体直到达到 minBytes 再决定是否压缩，保证小响应不付出压缩开销。
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minBytes int
+	status   int
+	buf      bytes.Buffer
+	decided  bool
+	gz       *gzip.Writer
+	size     int
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.decided {
+		w.status = code
+	}
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.minBytes {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status / Size / Written 面向 handler 与内层中间件：Size 为压缩前字节数。
+func (w *gzipResponseWriter) Status() int {
+	if w.decided {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *gzipResponseWriter) Size() int {
+	if !w.decided && w.size == 0 {
+		return -1
+	}
+	return w.size
+}
+
+func (w *gzipResponseWriter) Written() bool {
+	return w.decided || w.buf.Len() > 0
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 根据状态码与响应头决定是否压缩，并写出缓冲内容。
+func (w *gzipResponseWriter) decide() error {
+	w.decided = true
+	if w.shouldCompress() {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(w.ResponseWriter)
+		w.gz = gz
+		w.ResponseWriter.WriteHeader(w.status)
+		if w.buf.Len() > 0 {
+			if _, err := gz.Write(w.buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		w.buf.Reset()
+		return nil
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	w.buf.Reset()
+	return nil
+}
+
+func (w *gzipResponseWriter) shouldCompress() bool {
+	if w.buf.Len() < w.minBytes || w.buf.Len() == 0 {
+		return false
+	}
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	_, ok := compressibleTypes[mediaType]
+	return ok
+}
+
+// finish 在 handler 返回后输出尚未决定的缓冲内容并归还 gzip.Writer。
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		gzipWriterPool.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newGzipRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GzipMiddleware(64))
+	large := strings.Repeat("a", 256)
+	r.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"data": large})
+	})
+	r.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	r.GET("/pdf", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/pdf", []byte(large))
+	})
+	r.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func doGzipRequest(r *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGzipMiddleware_CompressesLargeJSON(t *testing.T) {
+	r := newGzipRouter()
+	w := doGzipRequest(r, "/large", map[string]string{"Accept-Encoding": "br, gzip"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status to be preserved, got %d", w.Code)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoding, headers=%v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	if !strings.Contains(string(body), strings.Repeat("a", 256)) {
+		t.Fatalf("unexpected decompressed body %q", body)
+	}
+}
+
+func TestGzipMiddleware_SkipsIneligibleResponses(t *testing.T) {
+	r := newGzipRouter()
+	cases := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "below threshold", path: "/small", headers: map[string]string{"Accept-Encoding": "gzip"}, status: http.StatusOK},
+		{name: "already compressed type", path: "/pdf", headers: map[string]string{"Accept-Encoding": "gzip"}, status: http.StatusOK},
+		{name: "no accept-encoding", path: "/large", status: http.StatusCreated},
+		{name: "gzip refused", path: "/large", headers: map[string]string{"Accept-Encoding": "gzip;q=0"}, status: http.StatusCreated},
+		{name: "range request", path: "/large", headers: map[string]string{"Accept-Encoding": "gzip", "Range": "bytes=0-10"}, status: http.StatusCreated},
+		{name: "websocket upgrade", path: "/large", headers: map[string]string{"Accept-Encoding": "gzip", "Upgrade": "websocket"}, status: http.StatusCreated},
+		{name: "no content", path: "/empty", headers: map[string]string{"Accept-Encoding": "gzip"}, status: http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doGzipRequest(r, tc.path, tc.headers)
+			if w.Code != tc.status {
+				t.Fatalf("expected %d got %d", tc.status, w.Code)
+			}
+			if enc := w.Header().Get("Content-Encoding"); enc != "" {
+				t.Fatalf("expected identity encoding, got %q", enc)
+			}
+		})
+	}
+}
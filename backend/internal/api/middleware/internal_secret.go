@@ -5,20 +5,21 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"phResume/internal/auth"
+	"phResume/internal/errcode"
 )
 
 func InternalSecretMiddleware(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if strings.TrimSpace(secret) == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal api secret is not configured"})
-			c.Abort()
+			abortWithError(c, http.StatusInternalServerError, errcode.SystemError, "internal api secret is not configured")
 			return
 		}
 		// 内部调用必须通过 Header 传递密钥，避免 query 泄露到浏览器/日志。
 		token := strings.TrimSpace(c.GetHeader("X-Internal-Secret"))
-		if token == "" || token != secret {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-			c.Abort()
+		if !auth.SecretEqual(token, strings.TrimSpace(secret)) {
+			abortWithError(c, http.StatusUnauthorized, errcode.Unauthorized, "unauthorized")
 			return
 		}
 		c.Next()
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInternalSecretMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/internal", InternalSecretMiddleware("s3cret"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "correct secret", header: "s3cret", want: http.StatusNoContent},
+		{name: "wrong secret", header: "s3creT", want: http.StatusUnauthorized},
+		{name: "prefix only", header: "s3c", want: http.StatusUnauthorized},
+		{name: "longer secret", header: "s3cret-extra", want: http.StatusUnauthorized},
+		{name: "missing header", header: "", want: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/internal", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Internal-Secret", tc.header)
+			}
+			r.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected %d got %d", tc.want, w.Code)
+			}
+		})
+	}
+}
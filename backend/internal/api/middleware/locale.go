@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/i18n"
+)
+
+const localeKey = "locale"
+
+// LocaleMiddleware 按 Accept-Language 协商响应语言并写入上下文，未匹配时使用 fallback。
+func LocaleMiddleware(fallback string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"), fallback)
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// GetLocale 从上下文中取出协商后的语言，未经过 LocaleMiddleware 时返回 i18n.DefaultLocale。
+func GetLocale(c *gin.Context) string {
+	if value, ok := c.Get(localeKey); ok {
+		if locale, ok := value.(string); ok && locale != "" {
+			return locale
+		}
+	}
+	return i18n.DefaultLocale
+}
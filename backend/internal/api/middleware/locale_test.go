@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
+	"phResume/internal/i18n"
+)
+
+func TestLocaleMiddleware_LocalizesAbortMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(LocaleMiddleware(i18n.EN), BodyLimitMiddleware(4))
+	router.POST("/json", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		acceptLanguage string
+		wantLocale     string
+		wantMessage    string
+	}{
+		{acceptLanguage: "", wantLocale: i18n.EN, wantMessage: "request body too large"},
+		{acceptLanguage: "zh-CN,zh;q=0.9", wantLocale: i18n.ZhCN, wantMessage: "请求内容过大"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/json", strings.NewReader("too large body"))
+		req.Header.Set("Accept-Language", tc.acceptLanguage)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected 413 got %d", w.Code)
+		}
+		if got := w.Header().Get("Content-Language"); got != tc.wantLocale {
+			t.Fatalf("Content-Language = %q, want %q", got, tc.wantLocale)
+		}
+		var body struct {
+			Error errcode.Detail `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.Error.Code != errcode.PayloadTooLarge || body.Error.Message != tc.wantMessage {
+			t.Fatalf("unexpected error body: %+v", body.Error)
+		}
+	}
+}
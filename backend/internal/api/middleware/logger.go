@@ -10,7 +10,8 @@ import (
 const slogLoggerKey = "slogLogger"
 
 // SlogLoggerMiddleware 将 slog 集成到 Gin，并注入 Correlation ID。
-func SlogLoggerMiddleware(logger *slog.Logger) gin.HandlerFunc {
+// 请求耗时超过 slowThreshold 时以 Warn 级别记录，便于直接从日志定位慢接口；slowThreshold 为 0 时关闭。
+func SlogLoggerMiddleware(logger *slog.Logger, slowThreshold time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		correlationID := GetCorrelationID(c)
 		path := c.FullPath()
@@ -23,18 +24,61 @@ func SlogLoggerMiddleware(logger *slog.Logger) gin.HandlerFunc {
 			slog.String("method", c.Request.Method),
 			slog.String("path", path),
 		)
+		if traceID := GetTraceID(c); traceID != "" {
+			requestLogger = requestLogger.With(slog.String("trace_id", traceID))
+		}
 		c.Set(slogLoggerKey, requestLogger)
 
+		// 在 Gzip 等内层中间件之外统计，记录的是实际写出的（压缩后）字节数。
+		sw := &sizeTrackingWriter{ResponseWriter: c.Writer}
+		c.Writer = sw
+
 		start := time.Now()
 		c.Next()
+		latency := time.Since(start)
 
-		requestLogger.Info("request completed",
-			slog.Int("status", c.Writer.Status()),
-			slog.Duration("latency", time.Since(start)),
-		)
+		attrs := []any{
+			slog.Int("status", sw.Status()),
+			slog.Duration("latency", latency),
+			slog.Int64("bytes", sw.bytes),
+		}
+		// userID 由 AuthMiddleware 注入；匿名请求不记录该字段。
+		if userID, ok := c.Get("userID"); ok {
+			if id, ok := userID.(uint); ok {
+				attrs = append(attrs, slog.Uint64("user_id", uint64(id)))
+			}
+		}
+		// 慢请求沿用同一条消息，仅提升级别并附带阈值，按消息检索日志的方式不受影响。
+		level := slog.LevelInfo
+		if slowThreshold > 0 && latency >= slowThreshold {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.Duration("slow_threshold", slowThreshold))
+		}
+		requestLogger.Log(c.Request.Context(), level, "request completed", attrs...)
+		c.Writer = sw.ResponseWriter
 	}
 }
 
+// sizeTrackingWriter 统计写出的响应体字节数。
+// 通过嵌入 gin.ResponseWriter 透传 Flush/Hijack/CloseNotify，SSE 与 WebSocket 升级不受影响；
+// 连接被 Hijack 后的帧不经过 Write，不计入字节数。
+type sizeTrackingWriter struct {
+	gin.ResponseWriter
+	bytes int64
+}
+
+func (w *sizeTrackingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *sizeTrackingWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.bytes += int64(n)
+	return n, err
+}
+
 // LoggerFromContext 返回上下文中的 slog.Logger。
 func LoggerFromContext(c *gin.Context) *slog.Logger {
 	if value, ok := c.Get(slogLoggerKey); ok {
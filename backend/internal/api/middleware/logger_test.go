@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSlogLoggerMiddleware_LogsBytesAndUserID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := gin.New()
+	r.Use(SlogLoggerMiddleware(logger, 0), GzipMiddleware(64))
+	r.GET("/anon", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	r.GET("/me", func(c *gin.Context) {
+		c.Set("userID", uint(42))
+		c.String(http.StatusOK, strings.Repeat("a", 1024))
+	})
+
+	lastEntry := func() map[string]any {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+			t.Fatalf("decode log line: %v", err)
+		}
+		return entry
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/anon", nil))
+	entry := lastEntry()
+	if entry["bytes"] != float64(5) {
+		t.Fatalf("expected 5 bytes logged, got %v", entry["bytes"])
+	}
+	if _, ok := entry["user_id"]; ok {
+		t.Fatalf("anonymous request must not log user_id: %v", entry)
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	entry = lastEntry()
+	if entry["user_id"] != float64(42) {
+		t.Fatalf("expected user_id 42, got %v", entry["user_id"])
+	}
+	// 记录的是压缩后实际写出的字节数。
+	if entry["bytes"] != float64(w.Body.Len()) || w.Body.Len() >= 1024 {
+		t.Fatalf("expected compressed size %d logged, got %v", w.Body.Len(), entry["bytes"])
+	}
+}
+
+// hijackRecorder 为支持 Hijack 的 ResponseRecorder，用于验证包装后的 writer 仍能升级连接。
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	server, client := net.Pipe()
+	_ = client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestSlogLoggerMiddleware_ProxiesFlushAndHijack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+
+	r := gin.New()
+	r.Use(SlogLoggerMiddleware(logger, 0))
+	r.GET("/stream", func(c *gin.Context) {
+		c.String(http.StatusOK, "data: 1\n\n")
+		c.Writer.Flush()
+	})
+	r.GET("/ws", func(c *gin.Context) {
+		conn, _, err := c.Writer.Hijack()
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		_ = conn.Close()
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if !w.Flushed {
+		t.Fatalf("expected Flush to reach the underlying writer")
+	}
+
+	hw := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(hw, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if !hw.hijacked {
+		t.Fatalf("expected Hijack to reach the underlying writer, status=%d", hw.Code)
+	}
+}
+
+func TestSlogLoggerMiddleware_WarnsOnSlowRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := gin.New()
+	r.Use(SlogLoggerMiddleware(logger, 20*time.Millisecond))
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	levelOf := func(path string) string {
+		buf.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		var entry map[string]any
+		if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry); err != nil {
+			t.Fatalf("decode log line %q: %v", buf.String(), err)
+		}
+		if entry["path"] != path || entry["msg"] != "request completed" {
+			t.Fatalf("unexpected log entry %v", entry)
+		}
+		return entry["level"].(string)
+	}
+
+	if got := levelOf("/fast"); got != "INFO" {
+		t.Fatalf("fast request: expected INFO got %s", got)
+	}
+	if got := levelOf("/slow"); got != "WARN" {
+		t.Fatalf("slow request: expected WARN got %s", got)
+	}
+}
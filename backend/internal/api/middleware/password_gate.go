@@ -1,24 +1,54 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
 )
 
 const passwordChangeRequiredMessage = "password change required"
 
+// ErrUserNotFound 由 MustChangePasswordLookup 在用户已不存在（如账号已注销）时返回，闸门据此返回 401 而不是 500。
+var ErrUserNotFound = errors.New("user not found")
+
+// MustChangePasswordLookup 查询用户当前是否仍需改密，供闸门实时复核使用。
+type MustChangePasswordLookup func(ctx context.Context, userID uint) (bool, error)
+
 // RequirePasswordChangeCompletedMiddleware 阻止未完成改密的账号访问业务接口。
-// 仅依赖 access token 内的 must_change_password 声明，避免每次请求都查库。
-func RequirePasswordChangeCompletedMiddleware() gin.HandlerFunc {
+// lookup 为 nil 时仅依赖 access token 内的 must_change_password 声明，避免每次请求都查库，
+// 状态变更最多滞后一个 access token TTL；传入 lookup 时每次请求以数据库实时状态为准。
+func RequirePasswordChangeCompletedMiddleware(lookup MustChangePasswordLookup) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		value, ok := c.Get("mustChangePassword")
-		if ok {
-			if mustChange, ok := value.(bool); ok && mustChange {
-				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": passwordChangeRequiredMessage})
-				return
+		mustChange := false
+		if value, ok := c.Get("mustChangePassword"); ok {
+			mustChange, _ = value.(bool)
+		}
+
+		if lookup != nil {
+			if value, ok := c.Get("userID"); ok {
+				if userID, ok := value.(uint); ok {
+					current, err := lookup(c.Request.Context(), userID)
+					if errors.Is(err, ErrUserNotFound) {
+						abortUnauthorized(c)
+						return
+					}
+					if err != nil {
+						abortWithError(c, http.StatusInternalServerError, errcode.SystemError, "failed to verify password status")
+						return
+					}
+					mustChange = current
+				}
 			}
 		}
+
+		if mustChange {
+			abortWithError(c, http.StatusForbidden, errcode.PasswordChangeRequired, passwordChangeRequiredMessage)
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/auth"
+)
+
+func newTestAuthService(t *testing.T) *auth.AuthService {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	svc, err := auth.NewAuthService(
+		auth.AlgorithmRS256,
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}),
+		time.Minute,
+		time.Hour,
+		0,
+	)
+	if err != nil {
+		t.Fatalf("new auth service: %v", err)
+	}
+	return svc
+}
+
+// newGatedRouter 模拟 routes.go 的挂载方式：业务路由经过改密闸门，改密接口本身只校验登录。
+func newGatedRouter(authService *auth.AuthService, lookup MustChangePasswordLookup) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/v1/auth/change-password", AuthMiddleware(authService, nil), ok)
+	router.GET("/v1/resume", AuthMiddleware(authService, nil), RequirePasswordChangeCompletedMiddleware(lookup), ok)
+	return router
+}
+
+func doWithToken(router *gin.Engine, method, path, token string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestPasswordGate_BlocksMustChangeUser(t *testing.T) {
+	authService := newTestAuthService(t)
+	router := newGatedRouter(authService, nil)
+
+	pair, err := authService.GenerateTokenPair(1, true, auth.RoleUser)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+
+	if code := doWithToken(router, http.MethodGet, "/v1/resume", pair.AccessToken); code != http.StatusForbidden {
+		t.Fatalf("business route: expected 403 got %d", code)
+	}
+	if code := doWithToken(router, http.MethodPost, "/v1/auth/change-password", pair.AccessToken); code != http.StatusOK {
+		t.Fatalf("change-password route: expected 200 got %d", code)
+	}
+}
+
+func TestPasswordGate_AllowsRegularUser(t *testing.T) {
+	authService := newTestAuthService(t)
+	router := newGatedRouter(authService, nil)
+
+	pair, err := authService.GenerateTokenPair(1, false, auth.RoleUser)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+
+	if code := doWithToken(router, http.MethodGet, "/v1/resume", pair.AccessToken); code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", code)
+	}
+}
+
+func TestPasswordGate_DBCheckOverridesTokenClaim(t *testing.T) {
+	authService := newTestAuthService(t)
+	dbState := map[uint]bool{1: false, 2: true}
+	router := newGatedRouter(authService, func(_ context.Context, userID uint) (bool, error) {
+		if userID == 3 {
+			return false, errors.New("db down")
+		}
+		if userID == 4 {
+			return false, ErrUserNotFound
+		}
+		return dbState[userID], nil
+	})
+
+	cases := []struct {
+		name       string
+		userID     uint
+		tokenClaim bool
+		status     int
+	}{
+		{name: "password already changed with stale token", userID: 1, tokenClaim: true, status: http.StatusOK},
+		{name: "flagged after token issued", userID: 2, tokenClaim: false, status: http.StatusForbidden},
+		{name: "lookup failure", userID: 3, tokenClaim: false, status: http.StatusInternalServerError},
+		{name: "user deleted", userID: 4, tokenClaim: false, status: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pair, err := authService.GenerateTokenPair(tc.userID, tc.tokenClaim, auth.RoleUser)
+			if err != nil {
+				t.Fatalf("generate token pair: %v", err)
+			}
+			if code := doWithToken(router, http.MethodGet, "/v1/resume", pair.AccessToken); code != tc.status {
+				t.Fatalf("expected %d got %d", tc.status, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/auth"
+	"phResume/internal/errcode"
+)
+
+// RequireAdmin 仅放行 access token 角色声明为 admin 的请求，需挂在 AuthMiddleware 之后。
+// 角色随令牌签发，撤销管理员权限后最多滞后一个 access token TTL 生效。
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if role != auth.RoleAdmin {
+			abortWithError(c, http.StatusForbidden, errcode.Forbidden, "access denied")
+			return
+		}
+		c.Next()
+	}
+}
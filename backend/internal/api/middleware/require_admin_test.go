@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/auth"
+)
+
+func TestRequireAdmin_AllowsOnlyAdminRole(t *testing.T) {
+	authService := newTestAuthService(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/admin/ping", AuthMiddleware(authService, nil), RequireAdmin(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "admin", role: auth.RoleAdmin, want: http.StatusOK},
+		{name: "user", role: auth.RoleUser, want: http.StatusForbidden},
+		{name: "legacy token without role", role: "", want: http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		pair, err := authService.GenerateTokenPair(1, false, tc.role)
+		if err != nil {
+			t.Fatalf("%s: generate token pair: %v", tc.name, err)
+		}
+		if got := doWithToken(router, http.MethodGet, "/v1/admin/ping", pair.AccessToken); got != tc.want {
+			t.Fatalf("%s: expected %d got %d", tc.name, tc.want, got)
+		}
+	}
+
+	// 刷新令牌不携带角色，也不能冒充访问令牌。
+	pair, err := authService.GenerateTokenPair(1, false, auth.RoleAdmin)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+	if got := doWithToken(router, http.MethodGet, "/v1/admin/ping", pair.RefreshToken); got != http.StatusUnauthorized {
+		t.Fatalf("refresh token: expected 401 got %d", got)
+	}
+	if got := doWithToken(router, http.MethodGet, "/v1/admin/ping", ""); got != http.StatusUnauthorized {
+		t.Fatalf("missing token: expected 401 got %d", got)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/tasks"
+)
+
+// pdfInflightStore 记录正在进行的渲染任务，用于合并重复请求；id 为简历 ID（单份 PDF / 缩略图）或用户 ID（批量打包）。
+type pdfInflightStore interface {
+	// Claim 尝试为 want 占用生成名额（want 已带预先生成的 task id）；已有相同内容的任务在途时返回该任务且 claimed=false。
+	Claim(ctx context.Context, id uint, want tasks.PDFInflight) (existing tasks.PDFInflight, claimed bool, err error)
+	// Release 在入队失败或被拒绝时释放名额，仅当标记仍属于 taskID 时删除。
+	Release(ctx context.Context, id uint, taskID string) error
+}
+
+type redisPDFInflightStore struct {
+	client redis.UniversalClient
+	ttl    time.Duration
+	key    func(id uint) string
+}
+
+func newRedisPDFInflightStore(client redis.UniversalClient) *redisPDFInflightStore {
+	return &redisPDFInflightStore{client: client, ttl: tasks.PDFInflightTTL, key: tasks.PDFInflightKey}
+}
+
+// newRedisPDFBundleInflightStore 按用户记录在途的打包任务；打包标记不带内容 hash，已有标记时总是返回 claimed=false。
+func newRedisPDFBundleInflightStore(client redis.UniversalClient) *redisPDFInflightStore {
+	return &redisPDFInflightStore{client: client, ttl: tasks.PDFBundleInflightTTL, key: tasks.PDFBundleInflightKey}
+}
+
+// newRedisResumePreviewInflightStore 按简历记录已入队的缩略图任务，标记在 ResumePreviewInflightTTL 后自动过期。
+func newRedisResumePreviewInflightStore(client redis.UniversalClient) *redisPDFInflightStore {
+	return &redisPDFInflightStore{client: client, ttl: tasks.ResumePreviewInflightTTL, key: tasks.ResumePreviewInflightKey}
+}
+
+func (s *redisPDFInflightStore) Claim(ctx context.Context, id uint, want tasks.PDFInflight) (tasks.PDFInflight, bool, error) {
+	key := s.key(id)
+	raw, err := json.Marshal(want)
+	if err != nil {
+		return tasks.PDFInflight{}, false, err
+	}
+
+	ok, err := s.client.SetNX(ctx, key, raw, s.ttl).Result()
+	if err != nil {
+		return tasks.PDFInflight{}, false, err
+	}
+	if ok {
+		return tasks.PDFInflight{}, true, nil
+	}
+
+	current, err := s.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// 标记恰好过期，重新占用。
+			return tasks.PDFInflight{}, true, s.client.Set(ctx, key, raw, s.ttl).Err()
+		}
+		return tasks.PDFInflight{}, false, err
+	}
+
+	var existing tasks.PDFInflight
+	if err := json.Unmarshal(current, &existing); err == nil && existing.ContentHash == want.ContentHash {
+		return existing, false, nil
+	}
+
+	// 在途任务对应的是旧内容（Worker 会判定为 superseded），由新请求接管名额。
+	return tasks.PDFInflight{}, true, s.client.Set(ctx, key, raw, s.ttl).Err()
+}
+
+func (s *redisPDFInflightStore) Release(ctx context.Context, id uint, taskID string) error {
+	return tasks.ReleaseInflight(ctx, s.client, s.key(id), taskID)
+}
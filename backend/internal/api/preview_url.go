@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/storage"
+)
+
+// previewURLSigner 在读取简历/模板时按 preview_object_key 签发缩略图链接（经 Redis 缓存复用），
+// 行上不再保存会过期的预签名 URL。
+type previewURLSigner struct {
+	presigner assetURLPresigner
+	ttl       time.Duration
+}
+
+func newPreviewURLSigner(storageClient *storage.Client, redisClient redis.UniversalClient, ttl time.Duration) *previewURLSigner {
+	if storageClient == nil {
+		return nil
+	}
+	var cacheStore storage.PresignCacheStore
+	if redisClient != nil {
+		cacheStore = redisClient
+	}
+	return &previewURLSigner{presigner: storage.NewPresignCache(storageClient, cacheStore), ttl: ttl}
+}
+
+// url 返回缩略图链接：有 object key 时实时签发；没有 key（客户端自定义链接）或未配置签名器时返回行上保存的值。
+// 签名失败只记录日志并回落到保存的值，不影响列表/详情接口本身。
+func (s *previewURLSigner) url(c *gin.Context, objectKey, stored string) string {
+	objectKey = strings.TrimSpace(objectKey)
+	if s == nil || objectKey == "" {
+		return stored
+	}
+	signed, err := s.presigner.GeneratePresignedURL(c.Request.Context(), objectKey, s.ttl)
+	if err != nil {
+		middleware.LoggerFromContext(c).Error("generate preview url failed", slog.String("object_key", objectKey), slog.Any("error", err))
+		return stored
+	}
+	return signed
+}
+
+// etagWindow 返回 now 所在的签名窗口标识（窗口长度为 ttl/10），无 object key 或未配置签名器时返回空串。
+// 响应中的缩略图链接在读取时签发，ETag 只覆盖行数据时客户端会凭 304 一直沿用过期链接；
+// 将窗口并入 ETag 后，客户端持有的链接最多比当前窗口早 ttl/10，而 PresignCache 保证返回的链接至少还剩 ttl/5。
+func (s *previewURLSigner) etagWindow(objectKey string, now time.Time) string {
+	if s == nil || strings.TrimSpace(objectKey) == "" {
+		return ""
+	}
+	window := s.ttl / 10
+	if window < time.Second {
+		window = time.Second
+	}
+	return fmt.Sprintf("p%d", now.UnixNano()/int64(window))
+}
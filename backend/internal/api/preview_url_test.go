@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+func TestPreviewURLs_SignedOnReadFromObjectKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	if err := db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	resumes := []database.Resume{
+		{UserID: 1, Title: "rendered", PreviewImageURL: "https://stale-presign", PreviewObjectKey: "thumbnails/resume/1/a.jpg"},
+		{UserID: 1, Title: "custom", PreviewImageURL: "https://example.com/custom.png"},
+	}
+	if err := db.Create(&resumes).Error; err != nil {
+		t.Fatalf("seed resumes: %v", err)
+	}
+	template := database.Template{UserID: 1, Title: "tpl", PreviewObjectKey: "thumbnails/template/1/t.jpg"}
+	if err := db.Create(&template).Error; err != nil {
+		t.Fatalf("seed template: %v", err)
+	}
+
+	storage := newFakeStorage()
+	storage.presign["thumbnails/resume/1/a.jpg"] = "https://signed/resume"
+	storage.presign["thumbnails/template/1/t.jpg"] = "https://signed/template"
+	signer := &previewURLSigner{presigner: storage, ttl: time.Hour}
+
+	get := func(path string, handle gin.HandlerFunc, params ...gin.Param) []byte {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, path, nil)
+		c.Params = params
+		c.Set("userID", uint(1))
+		handle(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200 got %d: %s", path, w.Code, w.Body.String())
+		}
+		return w.Body.Bytes()
+	}
+
+	rh := &ResumeHandler{db: db, previewURLs: signer}
+	var list []resumeListItem
+	_ = json.Unmarshal(get("/v1/resume", rh.ListResumes), &list)
+	urls := map[string]string{}
+	for _, item := range list {
+		urls[item.Title] = item.PreviewImageURL
+	}
+	if urls["rendered"] != "https://signed/resume" || urls["custom"] != "https://example.com/custom.png" {
+		t.Fatalf("expected key-based preview to be signed and custom url kept, got %v", urls)
+	}
+
+	var detail resumeResponse
+	_ = json.Unmarshal(get("/v1/resume/1", rh.GetResume, gin.Param{Key: "id", Value: "1"}), &detail)
+	if detail.PreviewImageURL != "https://signed/resume" {
+		t.Fatalf("expected signed preview url in detail, got %q", detail.PreviewImageURL)
+	}
+
+	th := &TemplateHandler{db: db, previewURLs: signer}
+	var templates []templateListItem
+	_ = json.Unmarshal(get("/v1/templates", th.ListTemplates), &templates)
+	if len(templates) != 1 || templates[0].PreviewImageURL != "https://signed/template" {
+		t.Fatalf("expected signed template preview url, got %+v", templates)
+	}
+}
+
+func TestResumeETag_RotatesWithPreviewSignWindow(t *testing.T) {
+	signer := &previewURLSigner{presigner: newFakeStorage(), ttl: time.Hour}
+	h := &ResumeHandler{previewURLs: signer}
+	now := time.Now()
+	withKey := database.Resume{Model: gorm.Model{ID: 7, UpdatedAt: now}, PreviewObjectKey: "thumbnails/resume/7/a.jpg"}
+	withoutKey := database.Resume{Model: gorm.Model{ID: 8, UpdatedAt: now}}
+
+	if got := h.resumeETag(withoutKey); got != resumeETag(withoutKey) {
+		t.Fatalf("expected plain etag without preview key, got %s", got)
+	}
+	etag := h.resumeETag(withKey)
+	if etag == resumeETag(withKey) || !strings.HasPrefix(etag, `W/"`) || !strings.HasSuffix(etag, `"`) {
+		t.Fatalf("expected weak etag with sign window, got %s", etag)
+	}
+
+	start := now.Truncate(signer.ttl / 10)
+	window := signer.etagWindow(withKey.PreviewObjectKey, start)
+	if window != signer.etagWindow(withKey.PreviewObjectKey, start.Add(signer.ttl/10-time.Nanosecond)) {
+		t.Fatal("expected sign window to be stable within ttl/10")
+	}
+	if window == signer.etagWindow(withKey.PreviewObjectKey, start.Add(signer.ttl/10)) {
+		t.Fatal("expected sign window to rotate after ttl/10")
+	}
+	if (*previewURLSigner)(nil).etagWindow(withKey.PreviewObjectKey, now) != "" {
+		t.Fatal("expected no sign window without signer")
+	}
+}
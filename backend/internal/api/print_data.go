@@ -2,16 +2,20 @@ package api
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
 
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+
 	"phResume/internal/errcode"
-	"phResume/internal/storage"
+	"phResume/internal/i18n"
+	resumepkg "phResume/internal/resume"
 )
 
 type PrintWarning struct {
@@ -34,6 +38,22 @@ type RemovedImageItem struct {
 	Reason string
 }
 
+// printOwnerHeader 为 Worker 调用内部打印接口时声明期望所有者的请求头。
+const printOwnerHeader = "X-Print-Owner-ID"
+
+// printOwnerFromRequest 解析内部打印请求声明的所有者 ID；缺失或非法时返回 false。
+func printOwnerFromRequest(c *gin.Context) (uint, bool) {
+	raw := strings.TrimSpace(c.GetHeader(printOwnerHeader))
+	if raw == "" {
+		return 0, false
+	}
+	ownerID, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || ownerID == 0 {
+		return 0, false
+	}
+	return uint(ownerID), true
+}
+
 func LogRemovedImageItems(log *slog.Logger, removed []RemovedImageItem) {
 	for _, r := range removed {
 		log.Warn("print image item removed",
@@ -65,11 +85,34 @@ func itemString(item map[string]any, field string) string {
 	return ""
 }
 
-// BuildPrintData 将内容 JSON 构造成打印数据：内联图片、过滤无效/缺失图片，并返回被移除的图片项列表。
+// printObjectGetter 为 BuildPrintData 读取图片对象所需的最小存储能力。
+type printObjectGetter interface {
+	GetObject(ctx context.Context, objectKey string) (*minio.Object, error)
+}
+
+// PrintDataOptions 控制 BuildPrintData 的可选行为。
+type PrintDataOptions struct {
+	// StrictImageMIME 开启后校验图片扩展名与存储 content-type 是否一致，不一致时以嗅探结果为准。
+	StrictImageMIME bool
+	// Logger 用于记录 MIME 不一致告警；为空时使用 slog.Default()。
+	Logger *slog.Logger
+	// Grid 为网格约束；越界的 item 会被收敛到网格内，避免单个坏元素把整页 PDF 挤乱。零值不做处理。
+	Grid resumepkg.GridLimits
+	// AssetDir 为 owner 的不透明资产目录（users.asset_key_dir）；为空时只接受 user-assets/<owner_id>/ 下的 key。
+	AssetDir string
+}
+
+// BuildPrintData 将内容 JSON 构造成打印数据：清洗富文本 HTML 与 item 样式、内联图片、过滤无效/缺失图片，并返回被移除的图片项列表。
+// 各类型 item 的处理由 printItemHandlers 注册表分派，未登记的类型记录 warn 后剔除。
 // 约定：
 // - 对象不存在(NoSuchKey) => 移除该 image item，并记录 warning(4004)
 // - Bucket 不存在(NoSuchBucket) => 视为系统错误，直接返回 error
-func BuildPrintData(ctx context.Context, storageClient *storage.Client, ownerID uint, rawJSON []byte) (PrintData, []RemovedImageItem, error) {
+func BuildPrintData(ctx context.Context, storageClient printObjectGetter, ownerID uint, rawJSON []byte, opts PrintDataOptions) (PrintData, []RemovedImageItem, error) {
+	log := opts.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+
 	var data PrintData
 	if err := json.Unmarshal(rawJSON, &data); err != nil {
 		return PrintData{}, nil, &inlineImageError{
@@ -78,6 +121,11 @@ func BuildPrintData(ctx context.Context, storageClient *storage.Client, ownerID
 		}
 	}
 
+	owner := assetOwner{UserID: ownerID, Dir: opts.AssetDir}
+	filterCustomFonts(owner, data.LayoutSettings, log)
+	columns := clampGridColumns(data.LayoutSettings, opts.Grid, log)
+
+	pc := &printItemContext{ctx: ctx, storage: storageClient, owner: owner, opts: opts, log: log}
 	filtered := make([]map[string]any, 0, len(data.Items))
 	removed := make([]RemovedImageItem, 0)
 
@@ -85,107 +133,36 @@ func BuildPrintData(ctx context.Context, storageClient *storage.Client, ownerID
 		itemType := strings.TrimSpace(itemString(item, "type"))
 		itemID := strings.TrimSpace(itemString(item, "id"))
 
-		if itemType != "image" {
-			normalizeContentField(item)
-			filtered = append(filtered, item)
-			continue
-		}
+		clampItemLayout(item, itemID, columns, opts.Grid.MaxRows, log)
 
-		rawContent, ok := item["content"]
-		if rawContent == nil || !ok {
-			removed = append(removed, RemovedImageItem{
-				ItemID: itemID,
-				Reason: "image content 为空",
-			})
-			continue
+		if dropped := sanitizeItemStyle(item); len(dropped) > 0 {
+			log.Warn("print item style properties dropped",
+				slog.String("item_id", itemID),
+				slog.Any("properties", dropped),
+			)
 		}
 
-		contentStr, ok := rawContent.(string)
-		if !ok {
-			removed = append(removed, RemovedImageItem{
-				ItemID: itemID,
-				Reason: "image content 类型非法",
-			})
-			continue
+		if itemType == "" {
+			// 与前端 normalizeResumeContent 一致：缺省类型按 text 处理。
+			itemType = "text"
 		}
-
-		objectKey := strings.TrimSpace(contentStr)
-		if objectKey == "" {
-			removed = append(removed, RemovedImageItem{
-				ItemID: itemID,
-				Reason: "image content 为空字符串",
-			})
-			continue
-		}
-
-		// key 格式不合法：直接移除该 item，计入 4004。
-		if !isValidUserAssetObjectKey(ownerID, objectKey) {
-			removed = append(removed, RemovedImageItem{
-				ItemID: itemID,
-				Key:    objectKey,
-				Reason: "image object key 格式不合法",
-			})
+		handler, ok := printItemHandlers[itemType]
+		if !ok {
+			log.Warn("print item with unknown type dropped",
+				slog.String("item_id", itemID),
+				slog.String("item_type", itemType),
+			)
 			continue
 		}
 
-		obj, err := storageClient.GetObject(ctx, objectKey)
+		removedItem, err := handler(pc, item, itemID)
 		if err != nil {
-			if storage.IsNoSuchBucket(err) {
-				return PrintData{}, removed, fmt.Errorf("minio bucket does not exist: %w", err)
-			}
-			if storage.IsNoSuchKey(err) {
-				removed = append(removed, RemovedImageItem{
-					ItemID: itemID,
-					Key:    objectKey,
-					Reason: "image object 不存在",
-				})
-				continue
-			}
-			return PrintData{}, removed, fmt.Errorf("failed to fetch image: %w", err)
+			return PrintData{}, removed, err
 		}
-
-		stat, statErr := obj.Stat()
-		if statErr != nil {
-			_ = obj.Close()
-			if storage.IsNoSuchBucket(statErr) {
-				return PrintData{}, removed, fmt.Errorf("minio bucket does not exist: %w", statErr)
-			}
-			if storage.IsNoSuchKey(statErr) {
-				removed = append(removed, RemovedImageItem{
-					ItemID: itemID,
-					Key:    objectKey,
-					Reason: "image object 不存在",
-				})
-				continue
-			}
-			return PrintData{}, removed, fmt.Errorf("failed to stat image: %w", statErr)
-		}
-
-		contentType := "image/png"
-		if strings.TrimSpace(stat.ContentType) != "" {
-			contentType = stat.ContentType
-		}
-
-		imageBytes, readErr := io.ReadAll(obj)
-		_ = obj.Close()
-		if readErr != nil {
-			if storage.IsNoSuchBucket(readErr) {
-				return PrintData{}, removed, fmt.Errorf("minio bucket does not exist: %w", readErr)
-			}
-			if storage.IsNoSuchKey(readErr) {
-				removed = append(removed, RemovedImageItem{
-					ItemID: itemID,
-					Key:    objectKey,
-					Reason: "image object 不存在",
-				})
-				continue
-			}
-			return PrintData{}, removed, fmt.Errorf("failed to read image: %w", readErr)
+		if removedItem != nil {
+			removed = append(removed, *removedItem)
+			continue
 		}
-
-		base64Image := base64.StdEncoding.EncodeToString(imageBytes)
-		dataURI := fmt.Sprintf("data:%s;base64,%s", contentType, base64Image)
-		item["content"] = dataURI
 		filtered = append(filtered, item)
 	}
 
@@ -208,10 +185,145 @@ func BuildPrintData(ctx context.Context, storageClient *storage.Client, ownerID
 
 		data.Warnings = append(data.Warnings, PrintWarning{
 			Code:        errcode.ResourceMissing,
-			Message:     "部分图片资源缺失/无效，已自动跳过并继续生成",
+			Message:     i18n.Message(i18n.ZhCN, errcode.ResourceMissing),
 			MissingKeys: keys,
 		})
 	}
 
 	return data, removed, nil
 }
+
+// clampGridColumns 将 layout_settings.columns 收敛到 grid.MaxColumns 以内并返回生效列数；未设置列数时按上限计算但不改写，
+// 未配置上限时原样返回（0 表示不限制）。
+func clampGridColumns(settings map[string]any, grid resumepkg.GridLimits, log *slog.Logger) int {
+	raw, _ := layoutNumber(settings, "columns")
+	columns := grid.Columns(raw)
+	if raw != 0 && columns != raw {
+		log.Warn("print layout columns clamped", slog.Int("columns", raw), slog.Int("clamped", columns))
+		if settings != nil {
+			settings["columns"] = columns
+		}
+	}
+	return columns
+}
+
+// clampItemLayout 将 item.layout 收敛到网格内：位置不为负，宽高至少为 1，且不越过列数与 maxRows（<=0 表示不限制）。
+func clampItemLayout(item map[string]any, itemID string, columns, maxRows int, log *slog.Logger) {
+	layout, ok := item["layout"].(map[string]any)
+	if !ok || (columns <= 0 && maxRows <= 0) {
+		return
+	}
+	x, _ := layoutNumber(layout, "x")
+	y, _ := layoutNumber(layout, "y")
+	w, _ := layoutNumber(layout, "w")
+	h, _ := layoutNumber(layout, "h")
+	cx, cy, cw, ch := x, y, w, h
+	if columns > 0 {
+		cx = min(max(cx, 0), columns-1)
+		cw = min(max(cw, 1), columns-cx)
+	}
+	if maxRows > 0 {
+		cy = min(max(cy, 0), maxRows-1)
+		ch = min(max(ch, 1), maxRows-cy)
+	}
+	if cx == x && cy == y && cw == w && ch == h {
+		return
+	}
+	log.Warn("print item layout clamped",
+		slog.String("item_id", itemID),
+		slog.Any("layout", []int{x, y, w, h}),
+		slog.Any("clamped", []int{cx, cy, cw, ch}),
+	)
+	layout["x"], layout["y"], layout["w"], layout["h"] = cx, cy, cw, ch
+}
+
+// layoutNumber 读取 JSON 解码后的数值字段（float64），缺失或类型不符时返回 false。
+func layoutNumber(values map[string]any, field string) (int, bool) {
+	v, ok := values[field].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// filterCustomFonts 只保留 layout_settings.custom_fonts 中字体名合法、且 key 属于 owner 的 ttf/woff2 资产。
+// Worker 会为这些 key 签发预签名 URL 并注入 @font-face，因此越权或非法的 key 必须在此剔除。
+func filterCustomFonts(owner assetOwner, settings map[string]any, log *slog.Logger) {
+	raw, ok := settings["custom_fonts"]
+	if !ok {
+		return
+	}
+	entries, _ := raw.([]any)
+	fonts := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		font, _ := entry.(map[string]any)
+		family, familyOK := resumepkg.NormalizeFontFamily(itemString(font, "family"))
+		objectKey := strings.TrimSpace(itemString(font, "object_key"))
+		if !familyOK || !isValidUserFontObjectKey(owner, objectKey) {
+			log.Warn("print custom font removed", slog.String("family", itemString(font, "family")), slog.String("object_key", objectKey))
+			continue
+		}
+		if len(fonts) >= resumepkg.MaxCustomFonts {
+			log.Warn("print custom fonts truncated", slog.Int("max", resumepkg.MaxCustomFonts))
+			break
+		}
+		fonts = append(fonts, map[string]any{"family": family, "object_key": objectKey})
+	}
+	if len(fonts) == 0 {
+		delete(settings, "custom_fonts")
+		return
+	}
+	settings["custom_fonts"] = fonts
+}
+
+// imageMIMEFromExtension 按对象 key 的扩展名推导期望的图片 MIME，未知扩展名返回空串。
+func imageMIMEFromExtension(objectKey string) string {
+	switch strings.ToLower(path.Ext(objectKey)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".webp":
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
+// resolveImageContentType 决定 data URI 使用的 MIME。
+// 非严格模式沿用存储的 content-type（缺失时默认 png）；严格模式下扩展名、存储类型与嗅探结果
+// 任一不一致即视为 mismatch，优先使用嗅探出的图片类型，其次使用扩展名推导的类型。
+func resolveImageContentType(objectKey, storedType string, data []byte, strict bool) (contentType string, mismatch bool) {
+	stored := strings.ToLower(strings.TrimSpace(storedType))
+	if i := strings.IndexByte(stored, ';'); i >= 0 {
+		stored = strings.TrimSpace(stored[:i])
+	}
+	if !strict {
+		if stored == "" {
+			return "image/png", false
+		}
+		return strings.TrimSpace(storedType), false
+	}
+
+	expected := imageMIMEFromExtension(objectKey)
+	sniffed := http.DetectContentType(data)
+	if !strings.HasPrefix(sniffed, "image/") {
+		sniffed = ""
+	}
+
+	if expected != "" && (stored == "" || stored == expected) && (sniffed == "" || sniffed == expected) {
+		return expected, false
+	}
+
+	switch {
+	case sniffed != "":
+		contentType = sniffed
+	case expected != "":
+		contentType = expected
+	case stored != "":
+		contentType = stored
+	default:
+		contentType = "image/png"
+	}
+	return contentType, true
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image/png"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+)
+
+var (
+	pngBytes  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpegBytes = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	webpBytes = []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")
+)
+
+func TestResolveImageContentType(t *testing.T) {
+	cases := []struct {
+		name         string
+		key          string
+		stored       string
+		data         []byte
+		strict       bool
+		wantType     string
+		wantMismatch bool
+	}{
+		{name: "lenient keeps stored type", key: "user-assets/1/a.png", stored: "image/webp", data: pngBytes, strict: false, wantType: "image/webp"},
+		{name: "lenient defaults to png", key: "user-assets/1/a.png", stored: "", data: pngBytes, strict: false, wantType: "image/png"},
+		{name: "strict match", key: "user-assets/1/a.png", stored: "image/png", data: pngBytes, strict: true, wantType: "image/png"},
+		{name: "strict match ignores params and case", key: "user-assets/1/a.JPG", stored: "Image/JPEG; charset=binary", data: jpegBytes, strict: true, wantType: "image/jpeg"},
+		{name: "strict missing stored type", key: "user-assets/1/a.webp", stored: "", data: webpBytes, strict: true, wantType: "image/webp"},
+		{name: "strict stored mismatch uses sniffed", key: "user-assets/1/a.png", stored: "image/webp", data: pngBytes, strict: true, wantType: "image/png", wantMismatch: true},
+		{name: "strict extension mismatch uses sniffed", key: "user-assets/1/a.png", stored: "image/png", data: jpegBytes, strict: true, wantType: "image/jpeg", wantMismatch: true},
+		{name: "strict unsniffable falls back to extension", key: "user-assets/1/a.webp", stored: "image/png", data: []byte("not an image"), strict: true, wantType: "image/webp", wantMismatch: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, mismatch := resolveImageContentType(tc.key, tc.stored, tc.data, tc.strict)
+			if got != tc.wantType || mismatch != tc.wantMismatch {
+				t.Fatalf("got (%q, %v) want (%q, %v)", got, mismatch, tc.wantType, tc.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestFilterCustomFonts(t *testing.T) {
+	settings := map[string]any{
+		"custom_fonts": []any{
+			map[string]any{"family": " Brand Sans ", "object_key": "user-assets/7/a.woff2"},
+			map[string]any{"family": "Other", "object_key": "user-assets/8/b.woff2"},
+			map[string]any{"family": "Image", "object_key": "user-assets/7/c.png"},
+			map[string]any{"family": `x"}body{`, "object_key": "user-assets/7/d.ttf"},
+			"not-an-object",
+		},
+	}
+
+	filterCustomFonts(assetOwner{UserID: 7}, settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	fonts, ok := settings["custom_fonts"].([]map[string]any)
+	if !ok || len(fonts) != 1 {
+		t.Fatalf("expected one font to remain, got %#v", settings["custom_fonts"])
+	}
+	if fonts[0]["family"] != "Brand Sans" || fonts[0]["object_key"] != "user-assets/7/a.woff2" {
+		t.Fatalf("unexpected font %#v", fonts[0])
+	}
+
+	settings = map[string]any{"custom_fonts": []any{map[string]any{"family": "Other", "object_key": "user-assets/8/b.woff2"}}}
+	filterCustomFonts(assetOwner{UserID: 7}, settings, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, exists := settings["custom_fonts"]; exists {
+		t.Fatalf("expected custom_fonts to be dropped when no font is valid")
+	}
+}
+
+func TestIsValidUserAssetObjectKey_AcceptsFonts(t *testing.T) {
+	owner := assetOwner{UserID: 1}
+	if !isValidAssetObjectKey("user-assets/1/a.woff2") || !isValidUserFontObjectKey(owner, "user-assets/1/a.ttf") {
+		t.Fatal("expected font keys to be valid assets")
+	}
+	if isValidUserImageObjectKey(owner, "user-assets/1/a.ttf") {
+		t.Fatal("font keys must not be inlined as images")
+	}
+	if isValidUserFontObjectKey(owner, "user-assets/1/a.otf") {
+		t.Fatal("only ttf/woff2 fonts are supported")
+	}
+}
+
+func TestAssetOwner_OpaqueDir(t *testing.T) {
+	dir, err := newAssetKeyDir()
+	if err != nil {
+		t.Fatalf("new asset key dir: %v", err)
+	}
+	owner := assetOwner{UserID: 1, Dir: dir}
+	other := assetOwner{UserID: 2}
+
+	opaque := "user-assets/" + dir + "/a.png"
+	if !isValidAssetObjectKey(opaque) || !isValidUserImageObjectKey(owner, opaque) {
+		t.Fatalf("expected opaque key %q to belong to its owner", opaque)
+	}
+	if isValidUserImageObjectKey(other, opaque) {
+		t.Fatal("opaque key must not belong to another user")
+	}
+	// 开启不透明目录前上传的资产仍可引用。
+	if !isValidUserImageObjectKey(owner, "user-assets/1/legacy.png") {
+		t.Fatal("legacy key must stay valid for its owner")
+	}
+	for _, key := range []string{"user-assets/ABC/a.png", "user-assets/../1/a.png", "user-assets//a.png", "user-assets/1/", "other/1/a.png"} {
+		if isValidAssetObjectKey(key) {
+			t.Fatalf("expected %q to be rejected", key)
+		}
+	}
+}
+
+func TestSanitizeRichTextHTML(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "keeps formatting", input: `<p class="x" dir="ltr"><strong>Go</strong> &amp; <em>Rust</em><br></p><ul><li value="1">a</li></ul>`, want: `<p class="x" dir="ltr"><strong>Go</strong> &amp; <em>Rust</em><br></p><ul><li value="1">a</li></ul>`},
+		{name: "drops script with content", input: `<p>hi<script>alert(1)</script></p>`, want: `<p>hi</p>`},
+		{name: "drops style element", input: `<style>body{display:none}</style><p>x</p>`, want: `<p>x</p>`},
+		{name: "drops event handlers", input: `<span onclick="alert(1)" onmouseover=x>t</span><img src=x onerror="alert(1)">`, want: `<span>t</span>`},
+		{name: "drops javascript href", input: `<a href=" JaVaScRiPt:alert(1)">x</a><a href="https://example.com/?a=1&b=2">y</a>`, want: `<a>x</a><a href="https://example.com/?a=1&amp;b=2">y</a>`},
+		{name: "drops data href", input: `<a href="data:text/html,<script>alert(1)</script>">x</a>`, want: `<a>x</a>`},
+		{name: "filters inline style", input: `<span style="color: red; background-image: url(https://evil); width: expression(alert(1)); font-size: 14px; background-color: url(javascript:alert(1))">t</span>`, want: `<span style="color: red; font-size: 14px">t</span>`},
+		{name: "drops nested iframe and svg", input: `<div><iframe src="https://evil">x</iframe><svg><svg></svg><script>alert(1)</script></svg>ok</div>`, want: `<div>ok</div>`},
+		{name: "unwraps unknown tags", input: `<font color="red"><blink>t</blink></font>`, want: `t`},
+		{name: "escapes text and drops comments", input: `<!-- <script>x</script> --><p>1 &lt; 2 <b>&quot;</b></p>`, want: `<p>1 &lt; 2 <b>&#34;</b></p>`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sanitizeRichTextHTML(tc.input); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildPrintData_DispatchesItemTypes(t *testing.T) {
+	raw := []byte(`{"layout_settings":{},"items":[
+		{"id":"t1","type":"text","content":"<p onclick=\"x()\">hi<script>alert(1)</script></p>"},
+		{"id":"t2","type":"section_title","content":"<h2><img src=x onerror=alert(1)>Skills</h2>"},
+		{"id":"d1","type":"divider","content":"<script>kept as-is</script>"},
+		{"id":"q1","type":"shape","content":"circle"},
+		{"id":"u1","content":"<b>legacy</b><script>x</script>"}
+	]}`)
+
+	data, removed, err := BuildPrintData(context.Background(), nil, 1, raw, PrintDataOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err != nil {
+		t.Fatalf("BuildPrintData: %v", err)
+	}
+	if len(removed) != 0 || len(data.Items) != 4 {
+		t.Fatalf("unexpected items=%d removed=%v", len(data.Items), removed)
+	}
+	// 未登记的 shape 类型被剔除；缺省类型按 text 处理。
+	want := []string{`<p>hi</p>`, `<h2>Skills</h2>`, `<script>kept as-is</script>`, `<b>legacy</b>`}
+	for i, w := range want {
+		if got := data.Items[i]["content"]; got != w {
+			t.Fatalf("item %d content = %q want %q", i, got, w)
+		}
+	}
+}
+
+func TestBuildPrintData_ClampsOutOfBoundsItems(t *testing.T) {
+	raw := []byte(`{"layout_settings":{"columns":48},"items":[
+		{"id":"ok","type":"divider","layout":{"x":0,"y":0,"w":24,"h":2}},
+		{"id":"wide","type":"divider","layout":{"x":20,"y":5,"w":10,"h":1}},
+		{"id":"off","type":"divider","layout":{"x":-3,"y":120,"w":0,"h":30}}
+	]}`)
+
+	var logs bytes.Buffer
+	data, _, err := BuildPrintData(context.Background(), nil, 1, raw, PrintDataOptions{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		Grid:   resumepkg.GridLimits{MaxColumns: 24, MaxRows: 100},
+	})
+	if err != nil {
+		t.Fatalf("BuildPrintData: %v", err)
+	}
+	if got := data.LayoutSettings["columns"]; got != 24 {
+		t.Fatalf("columns = %v want 24", got)
+	}
+	// 未越界的 item 保持解码后的 float64；被收敛的 item 整体改写为 int。
+	want := map[string][4]any{
+		"ok":   {float64(0), float64(0), float64(24), float64(2)},
+		"wide": {20, 5, 4, 1},
+		"off":  {0, 99, 1, 1},
+	}
+	for _, item := range data.Items {
+		layout := item["layout"].(map[string]any)
+		got := [4]any{layout["x"], layout["y"], layout["w"], layout["h"]}
+		if got != want[item["id"].(string)] {
+			t.Fatalf("item %v layout = %v want %v", item["id"], got, want[item["id"].(string)])
+		}
+	}
+	if strings.Count(logs.String(), "print item layout clamped") != 2 || !strings.Contains(logs.String(), "print layout columns clamped") {
+		t.Fatalf("expected clamp warnings, got %s", logs.String())
+	}
+}
+
+func TestSanitizeItemStyle(t *testing.T) {
+	item := map[string]any{
+		"style": map[string]any{
+			"color":             "#333",
+			"fontSize":          "400pt",
+			"fontWeight":        float64(700),
+			"lineHeight":        float64(12),
+			"backgroundOpacity": "1.5",
+			"borderTop":         "2px solid #d4d4d8",
+			"borderWidth":       "-3px",
+			"paddingLeft":       float64(4),
+			"transform":         "scale(5)",
+			"transformOrigin":   "30% 40%",
+			"position":          "fixed",
+			"zIndex":            float64(-1),
+			"top":               "-100px",
+			"width":             "9999px",
+			"backgroundColor":   "red; position: fixed",
+			"fontFamily":        "x\\3b position:fixed",
+			"objectPosition":    "url(https://evil)",
+			"textAlign":         map[string]any{"nested": true},
+		},
+	}
+
+	dropped := sanitizeItemStyle(item)
+
+	wantDropped := []string{"backgroundColor", "fontFamily", "objectPosition", "position", "textAlign", "top", "width", "zIndex"}
+	if len(dropped) != len(wantDropped) {
+		t.Fatalf("dropped = %v want %v", dropped, wantDropped)
+	}
+	for i := range wantDropped {
+		if dropped[i] != wantDropped[i] {
+			t.Fatalf("dropped = %v want %v", dropped, wantDropped)
+		}
+	}
+
+	want := map[string]any{
+		"color":             "#333",
+		"fontSize":          "96pt",
+		"fontWeight":        float64(700),
+		"lineHeight":        float64(4),
+		"backgroundOpacity": float64(1),
+		"borderTop":         "2px solid #d4d4d8",
+		"borderWidth":       "0px",
+		"paddingLeft":       float64(4),
+		"transform":         "scale(2)",
+		"transformOrigin":   "30% 40%",
+	}
+	style := item["style"].(map[string]any)
+	if len(style) != len(want) {
+		t.Fatalf("style = %v want %v", style, want)
+	}
+	for k, v := range want {
+		if style[k] != v {
+			t.Fatalf("style[%q] = %#v want %#v", k, style[k], v)
+		}
+	}
+}
+
+func TestSanitizeItemStyle_ReplacesInvalidStyle(t *testing.T) {
+	item := map[string]any{"style": "position:fixed"}
+	if dropped := sanitizeItemStyle(item); len(dropped) != 1 || dropped[0] != "style" {
+		t.Fatalf("unexpected dropped %v", dropped)
+	}
+	if style, ok := item["style"].(map[string]any); !ok || len(style) != 0 {
+		t.Fatalf("expected empty style got %#v", item["style"])
+	}
+
+	missing := map[string]any{}
+	if dropped := sanitizeItemStyle(missing); dropped != nil {
+		t.Fatalf("unexpected dropped %v", dropped)
+	}
+	if _, ok := missing["style"].(map[string]any); !ok {
+		t.Fatalf("expected style object got %#v", missing["style"])
+	}
+}
+
+func TestBuildPrintData_RendersQRCodeItems(t *testing.T) {
+	raw := []byte(`{"layout_settings":{},"items":[
+		{"id":"q1","type":"qrcode","content":" https://example.com/portfolio "},
+		{"id":"q2","type":"qrcode","content":"javascript:alert(1)"},
+		{"id":"q3","type":"qrcode","content":"https://example.com/` + strings.Repeat("a", maxQRCodeURLLength) + `"},
+		{"id":"q4","type":"qrcode","content":""}
+	]}`)
+
+	data, removed, err := BuildPrintData(context.Background(), nil, 1, raw, PrintDataOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err != nil {
+		t.Fatalf("BuildPrintData: %v", err)
+	}
+	if len(data.Items) != 1 {
+		t.Fatalf("expected 1 item got %d", len(data.Items))
+	}
+	if len(removed) != 3 || removed[0].ItemID != "q2" || removed[1].ItemID != "q3" || removed[2].ItemID != "q4" {
+		t.Fatalf("unexpected removed %+v", removed)
+	}
+	if len(data.Warnings) != 1 || data.Warnings[0].Code != errcode.ResourceMissing {
+		t.Fatalf("unexpected warnings %+v", data.Warnings)
+	}
+
+	item := data.Items[0]
+	if item["type"] != "image" {
+		t.Fatalf("expected type image got %v", item["type"])
+	}
+	content := item["content"].(string)
+	encoded, ok := strings.CutPrefix(content, "data:image/png;base64,")
+	if !ok {
+		t.Fatalf("unexpected content prefix %q", content[:min(len(content), 32)])
+	}
+	pngBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != b.Dy() || b.Dx()%qrCodeModulePx != 0 {
+		t.Fatalf("unexpected qrcode bounds %v", b)
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skip2/go-qrcode"
+
+	"phResume/internal/storage"
+)
+
+// printItemContext 为 item 处理器提供构建打印数据时的共享依赖。
+type printItemContext struct {
+	ctx     context.Context
+	storage printObjectGetter
+	owner   assetOwner
+	opts    PrintDataOptions
+	log     *slog.Logger
+}
+
+// printItemHandler 处理单个 item（可原地改写 content 等字段）。
+// 返回非 nil 的 RemovedImageItem 表示移除该 item 并计入 4004 告警；返回 error 时终止整个打印数据构建。
+type printItemHandler func(pc *printItemContext, item map[string]any, itemID string) (*RemovedImageItem, error)
+
+// printItemHandlers 为 item 类型到处理器的注册表；新增 item 类型只需在此登记。
+// 未登记的类型会被记录 warn 日志并从打印数据中剔除，不再原样透传给打印页。
+var printItemHandlers = map[string]printItemHandler{
+	"text":          handleRichTextItem,
+	"section_title": handleRichTextItem,
+	"divider":       handlePlainItem,
+	"image":         handleImageItem,
+	"qrcode":        handleQRCodeItem,
+}
+
+// handlePlainItem 仅将 content 规范化为字符串。
+func handlePlainItem(_ *printItemContext, item map[string]any, _ string) (*RemovedImageItem, error) {
+	normalizeContentField(item)
+	return nil, nil
+}
+
+// handleRichTextItem 规范化 content 并按白名单清洗其中的 HTML。
+func handleRichTextItem(_ *printItemContext, item map[string]any, _ string) (*RemovedImageItem, error) {
+	normalizeContentField(item)
+	item["content"] = sanitizeRichTextHTML(itemString(item, "content"))
+	return nil, nil
+}
+
+// maxQRCodeURLLength 限制二维码链接长度，避免生成模块过密、打印后难以扫描的二维码。
+const maxQRCodeURLLength = 200
+
+// qrCodeModulePx 为二维码每个模块（含静区）渲染的像素数，足够打印清晰。
+const qrCodeModulePx = 8
+
+// handleQRCodeItem 将 content 中的 http(s) 链接在服务端编码为 PNG 二维码并内联为 data URI，
+// 同时将类型改写为 image，打印页按图片渲染即可，无需前端二维码库。链接非法或超长时移除该 item。
+func handleQRCodeItem(_ *printItemContext, item map[string]any, itemID string) (*RemovedImageItem, error) {
+	rawURL := strings.TrimSpace(itemString(item, "content"))
+	if rawURL == "" {
+		return &RemovedImageItem{ItemID: itemID, Reason: "qrcode content 为空"}, nil
+	}
+	if utf8.RuneCountInString(rawURL) > maxQRCodeURLLength {
+		return &RemovedImageItem{ItemID: itemID, Reason: "qrcode url 过长"}, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &RemovedImageItem{ItemID: itemID, Reason: "qrcode url 非法"}, nil
+	}
+
+	code, err := qrcode.New(rawURL, qrcode.Medium)
+	if err != nil {
+		return &RemovedImageItem{ItemID: itemID, Reason: "qrcode 编码失败"}, nil
+	}
+	pngBytes, err := code.PNG(len(code.Bitmap()) * qrCodeModulePx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qrcode: %w", err)
+	}
+
+	item["type"] = "image"
+	item["content"] = "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngBytes)
+	return nil, nil
+}
+
+// handleImageItem 读取 content 中的对象 key 并内联为 data URI。
+// 约定：
+// - 对象不存在(NoSuchKey) / key 非法 => 移除该 item
+// - Bucket 不存在(NoSuchBucket) 或其它存储错误 => 返回 error
+func handleImageItem(pc *printItemContext, item map[string]any, itemID string) (*RemovedImageItem, error) {
+	rawContent, ok := item["content"]
+	if rawContent == nil || !ok {
+		return &RemovedImageItem{
+			ItemID: itemID,
+			Reason: "image content 为空",
+		}, nil
+	}
+
+	contentStr, ok := rawContent.(string)
+	if !ok {
+		return &RemovedImageItem{
+			ItemID: itemID,
+			Reason: "image content 类型非法",
+		}, nil
+	}
+
+	objectKey := strings.TrimSpace(contentStr)
+	if objectKey == "" {
+		return &RemovedImageItem{
+			ItemID: itemID,
+			Reason: "image content 为空字符串",
+		}, nil
+	}
+
+	// key 格式不合法：直接移除该 item，计入 4004。
+	if !isValidUserImageObjectKey(pc.owner, objectKey) {
+		return &RemovedImageItem{
+			ItemID: itemID,
+			Key:    objectKey,
+			Reason: "image object key 格式不合法",
+		}, nil
+	}
+
+	obj, err := pc.storage.GetObject(pc.ctx, objectKey)
+	if err != nil {
+		if storage.IsNoSuchBucket(err) {
+			return nil, fmt.Errorf("minio bucket does not exist: %w", err)
+		}
+		if storage.IsNoSuchKey(err) {
+			return &RemovedImageItem{
+				ItemID: itemID,
+				Key:    objectKey,
+				Reason: "image object 不存在",
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+
+	stat, statErr := obj.Stat()
+	if statErr != nil {
+		_ = obj.Close()
+		if storage.IsNoSuchBucket(statErr) {
+			return nil, fmt.Errorf("minio bucket does not exist: %w", statErr)
+		}
+		if storage.IsNoSuchKey(statErr) {
+			return &RemovedImageItem{
+				ItemID: itemID,
+				Key:    objectKey,
+				Reason: "image object 不存在",
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to stat image: %w", statErr)
+	}
+
+	imageBytes, readErr := io.ReadAll(obj)
+	_ = obj.Close()
+	if readErr != nil {
+		if storage.IsNoSuchBucket(readErr) {
+			return nil, fmt.Errorf("minio bucket does not exist: %w", readErr)
+		}
+		if storage.IsNoSuchKey(readErr) {
+			return &RemovedImageItem{
+				ItemID: itemID,
+				Key:    objectKey,
+				Reason: "image object 不存在",
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read image: %w", readErr)
+	}
+
+	contentType, mismatch := resolveImageContentType(objectKey, stat.ContentType, imageBytes, pc.opts.StrictImageMIME)
+	if mismatch {
+		pc.log.Warn("print image content-type mismatch",
+			slog.String("item_id", itemID),
+			slog.String("object_key", objectKey),
+			slog.String("stored_content_type", stat.ContentType),
+			slog.String("used_content_type", contentType),
+		)
+	}
+
+	base64Image := base64.StdEncoding.EncodeToString(imageBytes)
+	dataURI := fmt.Sprintf("data:%s;base64,%s", contentType, base64Image)
+	item["content"] = dataURI
+	return nil, nil
+}
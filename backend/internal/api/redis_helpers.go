@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -22,3 +23,35 @@ func incrWithTTL(ctx context.Context, client redisRateCounter, key string, ttl t
 	}
 	return count, nil
 }
+
+type redisBatchRateCounter interface {
+	IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+}
+
+// incrByWithTTL 与 incrWithTTL 相同，但一次计入 n 次；计数器由本次创建时设置过期时间。
+func incrByWithTTL(ctx context.Context, client redisBatchRateCounter, key string, n int64, ttl time.Duration) (int64, error) {
+	count, err := client.IncrBy(ctx, key, n).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == n {
+		_ = client.Expire(ctx, key, ttl).Err()
+	}
+	return count, nil
+}
+
+type redisKeyDeleter interface {
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// delEach 逐个删除 key：Redis Cluster 下跨 slot 的多 key DEL 会返回 CROSSSLOT，逐个删除在各种部署下均可用。
+func delEach(ctx context.Context, client redisKeyDeleter, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := client.Del(ctx, key).Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/auth"
+)
+
+// 刷新令牌设备绑定策略（API_REFRESH_BINDING）。
+const (
+	RefreshBindingOff     = "off"
+	RefreshBindingWarn    = "warn"
+	RefreshBindingEnforce = "enforce"
+)
+
+// refreshBindingKeyPrefix 为刷新令牌设备指纹在 Redis 中的 key 前缀（按 jti），TTL 与令牌有效期一致。
+const refreshBindingKeyPrefix = "auth:refresh:binding:"
+
+// clientIDHeader 为客户端自报的安装标识，参与设备指纹；缺省时只按 User-Agent 绑定。
+const (
+	clientIDHeader    = "X-Client-ID"
+	maxClientIDLength = 128
+)
+
+// refreshBindingStore 为设备绑定所需的 Redis 命令。
+type refreshBindingStore interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+}
+
+// refreshBinder 在签发刷新令牌时记录设备指纹，刷新时比对：warn 模式只记录安全日志，enforce 模式拒绝刷新。
+// 绑定记录缺失（开启前签发的令牌、Redis 数据丢失）时放行，与频控的容错策略一致。
+type refreshBinder struct {
+	mode  string
+	store refreshBindingStore
+}
+
+func refreshBindingKey(jti string) string { return refreshBindingKeyPrefix + jti }
+
+func (b refreshBinder) enabled() bool {
+	return (b.mode == RefreshBindingWarn || b.mode == RefreshBindingEnforce) && b.store != nil
+}
+
+// bind 记录刷新令牌 jti 对应的设备指纹。
+func (b refreshBinder) bind(ctx context.Context, pair auth.TokenPair, fingerprint string) error {
+	if !b.enabled() || pair.RefreshTokenID == "" {
+		return nil
+	}
+	ttl := time.Until(pair.RefreshExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return b.store.Set(ctx, refreshBindingKey(pair.RefreshTokenID), fingerprint, ttl).Err()
+}
+
+// mismatch 报告 jti 记录的设备指纹是否与本次请求不同；没有记录时返回 false。
+func (b refreshBinder) mismatch(ctx context.Context, jti, fingerprint string) (bool, error) {
+	if !b.enabled() || jti == "" {
+		return false, nil
+	}
+	stored, err := b.store.Get(ctx, refreshBindingKey(jti)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !auth.SecretEqual(stored, fingerprint), nil
+}
+
+// deviceFingerprint 由粗粒度的 User-Agent 与客户端标识计算设备指纹（SHA-256 十六进制），不保存原始 UA。
+func deviceFingerprint(userAgent, clientID string) string {
+	clientID = strings.TrimSpace(clientID)
+	if len(clientID) > maxClientIDLength {
+		clientID = clientID[:maxClientIDLength]
+	}
+	sum := sha256.Sum256([]byte(coarseUserAgent(userAgent) + "\x00" + clientID))
+	return hex.EncodeToString(sum[:])
+}
+
+// coarseUserAgent 去掉 User-Agent 中的版本号（数字与 . _ 分隔符）并统一小写，
+// 使浏览器与系统的常规升级不改变指纹，只保留浏览器/系统类型。
+func coarseUserAgent(userAgent string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == '.' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, strings.TrimSpace(userAgent))
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/auth"
+)
+
+// memoryBindingStore 为 refreshBindingStore 的内存实现，记录值与 TTL。
+type memoryBindingStore struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func (s *memoryBindingStore) Get(_ context.Context, key string) *redis.StringCmd {
+	v, ok := s.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (s *memoryBindingStore) Set(_ context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	s.values[key] = value.(string)
+	s.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+const (
+	chromeMac120 = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	chromeMac121 = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.6167.85 Safari/537.36"
+	firefoxWin   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0"
+)
+
+func TestDeviceFingerprint_IgnoresVersionUpdates(t *testing.T) {
+	if deviceFingerprint(chromeMac120, "") != deviceFingerprint(chromeMac121, "") {
+		t.Fatalf("browser version update should not change the fingerprint")
+	}
+	if deviceFingerprint(chromeMac120, "") == deviceFingerprint(firefoxWin, "") {
+		t.Fatalf("different browsers should produce different fingerprints")
+	}
+	if deviceFingerprint(chromeMac120, "install-a") == deviceFingerprint(chromeMac120, "install-b") {
+		t.Fatalf("client id should be part of the fingerprint")
+	}
+}
+
+func TestRefreshBinder_MatchAndMismatch(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryBindingStore{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	binder := refreshBinder{mode: RefreshBindingEnforce, store: store}
+
+	pair := auth.TokenPair{RefreshTokenID: "jti-1", RefreshExpiresAt: time.Now().Add(time.Hour)}
+	if err := binder.bind(ctx, pair, deviceFingerprint(chromeMac120, "install-a")); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if ttl := store.ttls[refreshBindingKey("jti-1")]; ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatalf("binding ttl should follow token expiry, got %v", ttl)
+	}
+
+	cases := []struct {
+		name      string
+		jti       string
+		userAgent string
+		clientID  string
+		mismatch  bool
+	}{
+		{"same device", "jti-1", chromeMac120, "install-a", false},
+		{"browser updated", "jti-1", chromeMac121, "install-a", false},
+		{"other browser", "jti-1", firefoxWin, "install-a", true},
+		{"other client id", "jti-1", chromeMac120, "install-b", true},
+		{"no binding recorded", "jti-2", firefoxWin, "", false},
+	}
+	for _, tc := range cases {
+		got, err := binder.mismatch(ctx, tc.jti, deviceFingerprint(tc.userAgent, tc.clientID))
+		if err != nil {
+			t.Fatalf("%s: mismatch: %v", tc.name, err)
+		}
+		if got != tc.mismatch {
+			t.Errorf("%s: mismatch = %v, want %v", tc.name, got, tc.mismatch)
+		}
+	}
+
+	// off 模式既不记录也不比对。
+	off := refreshBinder{mode: RefreshBindingOff, store: store}
+	if err := off.bind(ctx, auth.TokenPair{RefreshTokenID: "jti-3", RefreshExpiresAt: time.Now().Add(time.Hour)}, "fp"); err != nil {
+		t.Fatalf("bind off: %v", err)
+	}
+	if _, ok := store.values[refreshBindingKey("jti-3")]; ok {
+		t.Fatalf("off mode should not record bindings")
+	}
+	if got, _ := off.mismatch(ctx, "jti-1", deviceFingerprint(firefoxWin, "")); got {
+		t.Fatalf("off mode should not report mismatches")
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/auth"
+)
+
+// 注册策略（API_REGISTRATION_MODE）。
+const (
+	RegistrationOpen     = "open"
+	RegistrationInvite   = "invite"
+	RegistrationDisabled = "disabled"
+)
+
+// inviteCodeKeyPrefix 为一次性邀请码在 Redis 中的 key 前缀，注册成功消费（DEL）后失效。
+const inviteCodeKeyPrefix = "invite:code:"
+
+var (
+	errRegistrationDisabled = errors.New("registration is disabled")
+	errInviteCodeInvalid    = errors.New("invalid invite code")
+)
+
+// inviteCodeStore 为消费一次性邀请码所需的 Redis 命令。
+type inviteCodeStore interface {
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// registrationGate 按注册策略校验注册请求：invite 模式下邀请码可命中静态列表（可重复使用），
+// 或命中 Redis 中的一次性邀请码（原子删除，只能成功一次）。
+type registrationGate struct {
+	mode        string
+	staticCodes []string
+	store       inviteCodeStore
+}
+
+// check 在 disabled 模式下返回 errRegistrationDisabled；invite 模式下邀请码无效时返回 errInviteCodeInvalid。
+// 一次性邀请码在此处即被消费，调用方应在完成用户名等校验之后再调用。
+func (g registrationGate) check(ctx context.Context, inviteCode string) error {
+	switch g.mode {
+	case RegistrationDisabled:
+		return errRegistrationDisabled
+	case RegistrationInvite:
+	default:
+		return nil
+	}
+
+	code := strings.TrimSpace(inviteCode)
+	if code == "" {
+		return errInviteCodeInvalid
+	}
+	for _, static := range g.staticCodes {
+		if auth.SecretEqual(code, static) {
+			return nil
+		}
+	}
+	if g.store == nil {
+		return errInviteCodeInvalid
+	}
+	deleted, err := g.store.Del(ctx, inviteCodeKeyPrefix+code).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errInviteCodeInvalid
+	}
+	return nil
+}
+
+// CreateInviteCode 生成一次性邀请码并写入 Redis，ttl 到期后自动失效；供 cmd/admin --create-invite 使用。
+func CreateInviteCode(ctx context.Context, store interface {
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) *redis.BoolCmd
+}, ttl time.Duration) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := base64.RawURLEncoding.EncodeToString(buf)
+	ok, err := store.SetNX(ctx, inviteCodeKeyPrefix+code, time.Now().UTC().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.New("invite code collision, retry")
+	}
+	return code, nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/database"
+	"phResume/internal/errcode"
+)
+
+// fakeInviteStore 以内存集合模拟 Redis 中的一次性邀请码。
+type fakeInviteStore struct {
+	keys map[string]bool
+}
+
+func (s *fakeInviteStore) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var deleted int64
+	for _, key := range keys {
+		if s.keys[key] {
+			delete(s.keys, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func registerWithInvite(h *AuthHandler, username, inviteCode string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	body, _ := json.Marshal(map[string]string{"username": username, "password": "correct-password", "invite_code": inviteCode})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/auth/register", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	h.Register(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestRegister_RegistrationModes(t *testing.T) {
+	newHandler := func(t *testing.T, gate registrationGate) *AuthHandler {
+		return &AuthHandler{db: newTestDB(t), authService: newTestAuthService(t), logger: slog.Default(), registration: gate}
+	}
+
+	t.Run("open", func(t *testing.T) {
+		h := newHandler(t, registrationGate{mode: RegistrationOpen})
+		if w := registerWithInvite(h, "alice", ""); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 got %d %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := newHandler(t, registrationGate{mode: RegistrationDisabled, staticCodes: []string{"team-code"}})
+		w := registerWithInvite(h, "alice", "team-code")
+		if w.Code != http.StatusForbidden || errorCodeOf(t, w) != errcode.RegistrationDisabled {
+			t.Fatalf("expected 403/%d got %d %s", errcode.RegistrationDisabled, w.Code, w.Body.String())
+		}
+		var count int64
+		h.db.Model(&database.User{}).Count(&count)
+		if count != 0 {
+			t.Fatalf("no user must be created when registration is disabled")
+		}
+	})
+
+	t.Run("invite", func(t *testing.T) {
+		store := &fakeInviteStore{keys: map[string]bool{inviteCodeKeyPrefix + "once": true}}
+		h := newHandler(t, registrationGate{mode: RegistrationInvite, staticCodes: []string{"team-code"}, store: store})
+
+		for _, code := range []string{"", "wrong"} {
+			w := registerWithInvite(h, "alice", code)
+			if w.Code != http.StatusForbidden || errorCodeOf(t, w) != errcode.InviteCodeInvalid {
+				t.Fatalf("invite %q: expected 403/%d got %d %s", code, errcode.InviteCodeInvalid, w.Code, w.Body.String())
+			}
+		}
+
+		// 静态邀请码可重复使用。
+		for _, username := range []string{"alice", "bob"} {
+			if w := registerWithInvite(h, username, "team-code"); w.Code != http.StatusCreated {
+				t.Fatalf("static invite for %s: expected 201 got %d %s", username, w.Code, w.Body.String())
+			}
+		}
+
+		// 用户名冲突时不消耗一次性邀请码。
+		if w := registerWithInvite(h, "alice", "once"); w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 for taken username, got %d", w.Code)
+		}
+		if !store.keys[inviteCodeKeyPrefix+"once"] {
+			t.Fatalf("single-use code must survive a rejected registration")
+		}
+
+		if w := registerWithInvite(h, "carol", "once"); w.Code != http.StatusCreated {
+			t.Fatalf("single-use invite: expected 201 got %d %s", w.Code, w.Body.String())
+		}
+		if w := registerWithInvite(h, "dave", "once"); w.Code != http.StatusForbidden {
+			t.Fatalf("reused single-use invite: expected 403 got %d", w.Code)
+		}
+	})
+}
@@ -1,22 +1,69 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/errcode"
+	"phResume/internal/i18n"
 )
 
-func Error(c *gin.Context, status int, msg string) {
-	c.JSON(status, gin.H{"error": msg})
+// Error 写入统一错误响应 {"error":{"code":N,"message":"..."}}；code 省略时按 HTTP 状态码推导，
+// msg 为英文源消息，按请求语言本地化后输出。
+func Error(c *gin.Context, status int, msg string, code ...int) {
+	c.JSON(status, errorBody(c, status, msg, code))
 }
 
 func AbortUnauthorized(c *gin.Context) {
-	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, http.StatusUnauthorized, "unauthorized", nil))
+}
+
+// errorBody 构造错误响应体，message 按 LocaleMiddleware 协商的语言渲染。
+func errorBody(c *gin.Context, status int, msg string, code []int) gin.H {
+	resolved := errcode.FromHTTPStatus(status)
+	if len(code) > 0 {
+		resolved = code[0]
+	}
+	message := i18n.Translate(middleware.GetLocale(c), resolved, msg)
+	return gin.H{"error": errcode.Detail{Code: resolved, Message: message}}
 }
 
-func Unauthorized(c *gin.Context)           { Error(c, http.StatusUnauthorized, "unauthorized") }
-func BadRequest(c *gin.Context, msg string) { Error(c, http.StatusBadRequest, msg) }
-func Forbidden(c *gin.Context, msg string)  { Error(c, http.StatusForbidden, msg) }
-func NotFound(c *gin.Context, msg string)   { Error(c, http.StatusNotFound, msg) }
-func Conflict(c *gin.Context, msg string)   { Error(c, http.StatusConflict, msg) }
-func Internal(c *gin.Context, msg string)   { Error(c, http.StatusInternalServerError, msg) }
+func Unauthorized(c *gin.Context) { Error(c, http.StatusUnauthorized, "unauthorized") }
+
+func BadRequest(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusBadRequest, msg, code...)
+}
+
+func Forbidden(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusForbidden, msg, code...)
+}
+
+func NotFound(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusNotFound, msg, code...)
+}
+
+func Conflict(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusConflict, msg, code...)
+}
+
+func Internal(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusInternalServerError, msg, code...)
+}
+
+func TooManyRequests(c *gin.Context, msg string, code ...int) {
+	Error(c, http.StatusTooManyRequests, msg, code...)
+}
+
+// BindError 响应请求体绑定失败：超出 BodyLimitMiddleware 上限返回 413，其余按 400 处理，
+// 校验失败的字段与原因见 error.fields（不透出 validator 的原始错误文本）。
+func BindError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		Error(c, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	writeValidationError(c, err)
+}
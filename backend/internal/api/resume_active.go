@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+type activeResumeResponse struct {
+	ActiveResumeID *uint `json:"active_resume_id"`
+}
+
+// ActivateResume 将指定简历设为当前简历，不返回简历内容；供列表页直接“置顶”某份简历。
+func (h *ResumeHandler) ActivateResume(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	if err := setActiveResumeID(h.db.WithContext(c.Request.Context()), userID, &resume.ID); err != nil {
+		Internal(c, "failed to mark active resume")
+		return
+	}
+	c.JSON(http.StatusOK, activeResumeResponse{ActiveResumeID: &resume.ID})
+}
+
+// GetActiveResume 返回当前简历 ID，没有当前简历（或其已不存在）时为 null；只读，不会像 /latest 那样回填。
+func (h *ResumeHandler) GetActiveResume(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var user database.User
+	if err := h.db.WithContext(ctx).
+		Select("id", "active_resume_id").
+		First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			AbortUnauthorized(c)
+			return
+		}
+		Internal(c, "failed to query active resume")
+		return
+	}
+
+	if user.ActiveResumeID != nil {
+		var count int64
+		if err := h.db.WithContext(ctx).
+			Model(&database.Resume{}).
+			Where("id = ? AND user_id = ?", *user.ActiveResumeID, userID).
+			Count(&count).Error; err != nil {
+			Internal(c, "failed to query active resume")
+			return
+		}
+		if count == 0 {
+			user.ActiveResumeID = nil
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, activeResumeResponse{ActiveResumeID: user.ActiveResumeID})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/database"
+	resumepkg "phResume/internal/resume"
+	"phResume/internal/tasks"
+)
+
+// DownloadAllResumes 将当前用户全部简历的批量 PDF 打包任务入队并立即返回 202。
+// 内容已过期、需要重新渲染的简历按份数计入每小时 PDF 频控（入队成功后才计次）；全部命中缓存时不计次。
+// 每个用户同时只允许一个打包任务（Redis 在途标记，任务结束后由 Worker 释放），完成后通过 pdf_bundle 通知下发 zip 的预签名链接。
+func (h *ResumeHandler) DownloadAllResumes(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var resumes []database.Resume
+	if err := h.db.WithContext(ctx).
+		Select("id", "content", "pdf_url", "pdf_content_hash").
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&resumes).Error; err != nil {
+		Internal(c, "failed to query resumes")
+		return
+	}
+	if len(resumes) == 0 {
+		NotFound(c, "no resumes to download")
+		return
+	}
+
+	resumeIDs := make([]uint, 0, len(resumes))
+	stale := 0
+	for i := range resumes {
+		resumeIDs = append(resumeIDs, resumes[i].ID)
+		if !resumePDFCached(&resumes[i], resumepkg.ContentHash(resumes[i].Content)) {
+			stale++
+		}
+	}
+
+	logger := middleware.LoggerFromContext(c)
+	window := time.Now().UTC().Format("2006010215")
+	rateKey := fmt.Sprintf("rate:pdf:%d:%s", userID, window)
+	if stale > 0 {
+		count, err := h.redisClient.Get(ctx, rateKey).Int64()
+		if err != nil {
+			count = 0
+		}
+		if count+int64(stale) > int64(h.pdfRateLimitPerHour) {
+			TooManyRequests(c, "rate limit exceeded")
+			return
+		}
+	}
+
+	// 每次请求使用新的 task id：失败归档的旧任务不会占用 id，重复请求由在途标记拦截。
+	correlationID := middleware.GetCorrelationID(c)
+	taskID := uuid.NewString()
+	claimed := false
+	if h.pdfBundleInflight != nil {
+		_, ok, err := h.pdfBundleInflight.Claim(ctx, userID, tasks.PDFInflight{TaskID: taskID, CorrelationID: correlationID})
+		switch {
+		case err != nil:
+			logger.Warn("claim pdf bundle inflight slot failed", slog.Any("error", err))
+		case !ok:
+			Error(c, http.StatusConflict, "pdf bundle already in progress")
+			return
+		default:
+			claimed = true
+		}
+	}
+	releaseClaim := func() {
+		if !claimed {
+			return
+		}
+		if err := h.pdfBundleInflight.Release(ctx, userID, taskID); err != nil {
+			logger.Warn("release pdf bundle inflight slot failed", slog.Any("error", err))
+		}
+	}
+
+	task, err := tasks.NewPDFBundleTask(tasks.PDFBundlePayload{
+		UserID:        userID,
+		ResumeIDs:     resumeIDs,
+		CorrelationID: correlationID,
+		TraceParent:   middleware.GetTraceParent(c),
+	})
+	if err != nil {
+		releaseClaim()
+		Internal(c, "failed to create task")
+		return
+	}
+
+	info, err := h.asynqClient.Enqueue(task, asynq.TaskID(taskID), asynq.MaxRetry(3))
+	if err != nil {
+		releaseClaim()
+		logger.Error("enqueue pdf bundle failed", slog.Any("error", err))
+		Internal(c, "failed to enqueue pdf bundle")
+		return
+	}
+
+	if stale > 0 {
+		if _, err := incrByWithTTL(ctx, h.redisClient, rateKey, int64(stale), time.Hour); err != nil {
+			logger.Warn("charge pdf rate limit failed", slog.Any("error", err))
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":        "PDF bundle request accepted",
+		"task_id":        info.ID,
+		"correlation_id": correlationID,
+		"resume_count":   len(resumeIDs),
+		"render_count":   stale,
+	})
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	resumepkg "phResume/internal/resume"
+)
+
+type diffResumeRequest struct {
+	Content datatypes.JSON `json:"content" binding:"required"`
+}
+
+// DiffResume 比较已保存的简历内容（before）与请求体中的 content（after），返回元素与页面设置的结构化差异；
+// 不写库，供客户端在保存或恢复前预览改动。
+func (h *ResumeHandler) DiffResume(c *gin.Context) {
+	var req diffResumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	after, err := resumepkg.ValidateContent(req.Content, resumepkg.ContentLimits{})
+	if err != nil {
+		BadRequest(c, "invalid content")
+		return
+	}
+	// 已保存的内容解析失败属于历史脏数据，按空内容比较，让客户端仍能看到完整的新增项。
+	var before resumepkg.Content
+	if parsed, err := resumepkg.ValidateContent(resume.Content, resumepkg.ContentLimits{}); err == nil {
+		before = *parsed
+	}
+
+	c.JSON(http.StatusOK, resumepkg.DiffContent(before, *after))
+}
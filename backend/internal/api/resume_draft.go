@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+type saveDraftRequest struct {
+	Content datatypes.JSON `json:"content" binding:"required"`
+}
+
+type resumeDraftResponse struct {
+	Content datatypes.JSON `json:"content"`
+	SavedAt time.Time      `json:"saved_at"`
+	// ResumeUpdatedAt 为简历正式内容的更新时间，客户端据此判断草稿是否基于最新版本。
+	ResumeUpdatedAt time.Time `json:"resume_updated_at"`
+}
+
+// SaveDraft 保存编辑中的草稿，只写 draft 列，不改动正式内容与 updated_at（ETag 与 PDF 缓存保持不变）。
+func (h *ResumeHandler) SaveDraft(c *gin.Context) {
+	var req saveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	savedAt := time.Now()
+	// UpdateColumns 跳过钩子与 updated_at 的自动更新。
+	if err := h.db.WithContext(c.Request.Context()).
+		Model(&database.Resume{}).
+		Where("id = ?", resume.ID).
+		UpdateColumns(map[string]any{
+			"draft":          req.Content,
+			"draft_saved_at": savedAt,
+		}).Error; err != nil {
+		Internal(c, "failed to save draft")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_at": savedAt})
+}
+
+// GetDraft 返回简历尚未正式保存的草稿；没有草稿时返回 404。
+func (h *ResumeHandler) GetDraft(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	if len(resume.Draft) == 0 || resume.DraftSavedAt == nil {
+		NotFound(c, "draft not found")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, resumeDraftResponse{
+		Content:         resume.Draft,
+		SavedAt:         *resume.DraftSavedAt,
+		ResumeUpdatedAt: resume.UpdatedAt,
+	})
+}
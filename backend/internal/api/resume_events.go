@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+const (
+	defaultResumeEventPageSize = 50
+	maxResumeEventPageSize     = 200
+)
+
+type resumeEventItem struct {
+	ID            uint      `json:"id"`
+	Type          string    `json:"type"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Detail        string    `json:"detail,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ListResumeEvents 按时间倒序返回简历的生命周期事件（创建、更新、PDF 入队/完成/失败）；
+// next_cursor 非空时可用 ?cursor= 继续翻页。
+func (h *ResumeHandler) ListResumeEvents(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultResumeEventPageSize)))
+	if err != nil || limit <= 0 {
+		limit = defaultResumeEventPageSize
+	}
+	if limit > maxResumeEventPageSize {
+		limit = maxResumeEventPageSize
+	}
+
+	query := h.db.WithContext(c.Request.Context()).
+		Where("resume_id = ? AND user_id = ?", resume.ID, userID)
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		before, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			BadRequest(c, "invalid cursor")
+			return
+		}
+		query = query.Where("id < ?", before)
+	}
+
+	// 多取一条用于判断是否还有下一页；id 自增，与写入顺序一致。
+	var events []database.ResumeEvent
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&events).Error; err != nil {
+		Internal(c, "failed to list resume events")
+		return
+	}
+	var nextCursor *string
+	if len(events) > limit {
+		events = events[:limit]
+		cursor := strconv.FormatUint(uint64(events[limit-1].ID), 10)
+		nextCursor = &cursor
+	}
+
+	items := make([]resumeEventItem, 0, len(events))
+	for _, event := range events {
+		items = append(items, resumeEventItem{
+			ID:            event.ID,
+			Type:          event.Type,
+			CorrelationID: event.CorrelationID,
+			Detail:        event.Detail,
+			CreatedAt:     event.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":       items,
+		"next_cursor": nextCursor,
+	})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/database"
+	"phResume/internal/resumehtml"
+)
+
+// exportFormatHTML 为目前支持的导出格式。
+const exportFormatHTML = "html"
+
+// ExportResume 将简历导出为可离线打开的单文件 HTML：复用打印数据构建（图片内联为 data URI）与 Worker 的服务端模板，
+// 自定义字体同样内联，文件不依赖任何外部资源。
+func (h *ResumeHandler) ExportResume(c *gin.Context) {
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", exportFormatHTML)))
+	if format != exportFormatHTML {
+		BadRequest(c, "unsupported export format")
+		return
+	}
+
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	resume, err := h.getResumeForUser(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	assetDir, err := database.LoadAssetKeyDir(h.db.WithContext(ctx), resume.UserID)
+	if err != nil {
+		Internal(c, "failed to query asset dir")
+		return
+	}
+	log := middleware.LoggerFromContext(c).With(slog.Uint64("resume_id", uint64(resume.ID)))
+	printData, removed, err := BuildPrintData(ctx, h.storage, resume.UserID, resume.Content, PrintDataOptions{
+		StrictImageMIME: h.strictImageMIME,
+		Logger:          log,
+		Grid:            h.grid,
+		AssetDir:        assetDir,
+	})
+	if err != nil {
+		if status, ok := statusFromInlineError(err); ok {
+			Error(c, status, err.Error())
+			return
+		}
+		Internal(c, "failed to build export data")
+		return
+	}
+	LogRemovedImageItems(log, removed)
+
+	raw, err := json.Marshal(printData)
+	if err != nil {
+		Internal(c, "failed to build export data")
+		return
+	}
+	html, err := resumehtml.Build(raw, embeddedFontFaceCSS(ctx, h.storage, printData.LayoutSettings, log))
+	if err != nil {
+		Internal(c, "failed to render export")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"Resume-%d.html\"", resume.ID))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
+
+// embeddedFontFaceCSS 为已由 BuildPrintData 过滤的自定义字体生成 @font-face 规则，字体文件以 data URI 内联；
+// 单个字体读取失败只记录日志并跳过（回退默认字体）。
+func embeddedFontFaceCSS(ctx context.Context, storage printObjectGetter, settings map[string]any, log *slog.Logger) string {
+	fonts, _ := settings["custom_fonts"].([]map[string]any)
+	var b strings.Builder
+	for _, font := range fonts {
+		family := itemString(font, "family")
+		objectKey := itemString(font, "object_key")
+		format := resumehtml.FontFormat(objectKey)
+		if format == "" {
+			continue
+		}
+		data, err := readPrintObject(ctx, storage, objectKey)
+		if err != nil {
+			log.Warn("export custom font skipped", slog.String("object_key", objectKey), slog.Any("error", err))
+			continue
+		}
+		mime := "font/ttf"
+		if format == "woff2" {
+			mime = "font/woff2"
+		}
+		src := "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data)
+		b.WriteString(resumehtml.FontFaceRule(family, src, format))
+	}
+	return b.String()
+}
+
+func readPrintObject(ctx context.Context, storage printObjectGetter, objectKey string) ([]byte, error) {
+	obj, err := storage.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -14,8 +13,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/datatypes"
@@ -23,42 +24,86 @@ import (
 
 	"phResume/internal/api/middleware"
 	"phResume/internal/database"
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
 	"phResume/internal/storage"
 	"phResume/internal/tasks"
 )
 
+// resumeStorage 抽象 ResumeHandler 依赖的对象存储能力，便于在测试中替换。
+type resumeStorage interface {
+	printObjectGetter
+	DeleteObject(ctx context.Context, objectKey string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// taskEnqueuer 抽象任务入队能力，便于在测试中替换 asynq.Client。
+type taskEnqueuer interface {
+	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
 // ResumeHandler 负责处理与简历相关的 API 请求。
 type ResumeHandler struct {
 	db                  *gorm.DB
-	asynqClient         *asynq.Client
-	storage             *storage.Client
+	asynqClient         taskEnqueuer
+	pdfInflight         pdfInflightStore
+	pdfBundleInflight   pdfInflightStore
+	previewInflight     pdfInflightStore
+	storage             resumeStorage
 	internalSecret      string
 	maxResumes          int
-	redisClient         *redis.Client
+	redisClient         redis.UniversalClient
 	pdfRateLimitPerHour int
 	pdfDownloadTokenTTL time.Duration
+	strictImageMIME     bool
+	// rejectDuplicateTitle 开启后，创建与已有简历同名的简历返回 409，除非请求显式 allow_duplicate_title。
+	rejectDuplicateTitle bool
+	// previewURLs 在读取时按 object key 签发缩略图链接；为 nil 时直接返回行上保存的链接。
+	previewURLs *previewURLSigner
+	// defaultContent 为运维配置的起始内容，用户尚无简历时由 GetLatestResume 返回；为空时按请求语言选用内置起始简历。
+	defaultContent datatypes.JSON
+	// grid 为保存时校验、打印时收敛越界 item 的网格约束。
+	grid resumepkg.GridLimits
+}
+
+// ResumeHandlerOptions 为 ResumeHandler 的配置项；新增配置在此加字段。
+type ResumeHandlerOptions struct {
+	// InternalSecret 为 Worker 调用内部打印数据 / 取消接口时携带的 X-Internal-Secret。
+	InternalSecret string
+	MaxResumes     int
+	// PdfRateLimitPerHour 为每用户每小时 PDF 渲染次数上限；PdfDownloadTokenTTL 为一次性下载 Token 有效期。
+	PdfRateLimitPerHour int
+	PdfDownloadTokenTTL time.Duration
+	// StrictImageMIME 开启后打印数据校验图片扩展名与 content-type 一致性。
+	StrictImageMIME bool
+	// RejectDuplicateTitle 开启后创建同名简历返回 409。
+	RejectDuplicateTitle bool
+	// PreviewURLTTL 为读取简历时签发的缩略图链接有效期。
+	PreviewURLTTL time.Duration
+	// DefaultContent 为空时按请求语言使用内置的起始简历。
+	DefaultContent []byte
+	Grid           resumepkg.GridLimits
 }
 
 // NewResumeHandler 构造 ResumeHandler。
-func NewResumeHandler(
-	db *gorm.DB,
-	asynqClient *asynq.Client,
-	storageClient *storage.Client,
-	internalSecret string,
-	maxResumes int,
-	redisClient *redis.Client,
-	pdfRateLimitPerHour int,
-	pdfDownloadTokenTTL time.Duration,
-) *ResumeHandler {
+func NewResumeHandler(db *gorm.DB, asynqClient *asynq.Client, storageClient *storage.Client, redisClient redis.UniversalClient, opts ResumeHandlerOptions) *ResumeHandler {
 	return &ResumeHandler{
-		db:                  db,
-		asynqClient:         asynqClient,
-		storage:             storageClient,
-		internalSecret:      internalSecret,
-		maxResumes:          maxResumes,
-		redisClient:         redisClient,
-		pdfRateLimitPerHour: pdfRateLimitPerHour,
-		pdfDownloadTokenTTL: pdfDownloadTokenTTL,
+		db:                   db,
+		asynqClient:          asynqClient,
+		pdfInflight:          newRedisPDFInflightStore(redisClient),
+		pdfBundleInflight:    newRedisPDFBundleInflightStore(redisClient),
+		previewInflight:      newRedisResumePreviewInflightStore(redisClient),
+		storage:              storageClient,
+		internalSecret:       opts.InternalSecret,
+		maxResumes:           opts.MaxResumes,
+		redisClient:          redisClient,
+		pdfRateLimitPerHour:  opts.PdfRateLimitPerHour,
+		pdfDownloadTokenTTL:  opts.PdfDownloadTokenTTL,
+		strictImageMIME:      opts.StrictImageMIME,
+		rejectDuplicateTitle: opts.RejectDuplicateTitle,
+		previewURLs:          newPreviewURLSigner(storageClient, redisClient, opts.PreviewURLTTL),
+		defaultContent:       datatypes.JSON(opts.DefaultContent),
+		grid:                 opts.Grid,
 	}
 }
 
@@ -68,12 +113,16 @@ type createResumeRequest struct {
 	Title           string         `json:"title" binding:"required"`
 	Content         datatypes.JSON `json:"content" binding:"required"`
 	PreviewImageURL *string        `json:"preview_image_url"`
+	Tags            *[]string      `json:"tags"` // nil 表示不修改；空数组清空标签
+	// AllowDuplicateTitle 仅对创建生效：跳过同名校验。
+	AllowDuplicateTitle bool `json:"allow_duplicate_title"`
 }
 
 type resumeListItem struct {
 	ID              uint      `json:"id"`
 	Title           string    `json:"title"`
 	PreviewImageURL string    `json:"preview_image_url,omitempty"`
+	Tags            []string  `json:"tags"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -82,6 +131,8 @@ type resumeResponse struct {
 	Title           string         `json:"title"`
 	Content         datatypes.JSON `json:"content"`
 	PreviewImageURL string         `json:"preview_image_url,omitempty"`
+	Tags            []string       `json:"tags"`
+	HasDraft        bool           `json:"has_draft"` // 存在未正式保存的草稿，可经 GET /v1/resume/:id/draft 恢复
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 }
@@ -90,7 +141,7 @@ type resumeResponse struct {
 func (h *ResumeHandler) CreateResume(c *gin.Context) {
 	var req createResumeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
 		return
 	}
 
@@ -100,6 +151,20 @@ func (h *ResumeHandler) CreateResume(c *gin.Context) {
 		return
 	}
 
+	if !h.validateContent(c, req.Content) {
+		return
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		normalized, err := normalizeResumeTags(*req.Tags)
+		if err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+		tags = normalized
+	}
+
 	ctx := c.Request.Context()
 
 	var count int64
@@ -112,10 +177,29 @@ func (h *ResumeHandler) CreateResume(c *gin.Context) {
 	}
 
 	if h.maxResumes > 0 && count >= int64(h.maxResumes) {
-		Forbidden(c, "resume limit reached")
+		Forbidden(c, "resume limit reached", errcode.LimitReached)
 		return
 	}
 
+	if h.rejectDuplicateTitle && !req.AllowDuplicateTitle {
+		var existing database.Resume
+		err := h.db.WithContext(ctx).
+			Select("id").
+			Where("user_id = ? AND title = ?", userID, req.Title).
+			Order("id ASC").
+			Take(&existing).Error
+		switch {
+		case err == nil:
+			body := errorBody(c, http.StatusConflict, "resume title already exists", []int{errcode.DuplicateTitle})
+			body["existing_id"] = existing.ID
+			c.JSON(http.StatusConflict, body)
+			return
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			Internal(c, "failed to check resume title")
+			return
+		}
+	}
+
 	resume := database.Resume{
 		Title:   req.Title,
 		Content: req.Content,
@@ -125,20 +209,30 @@ func (h *ResumeHandler) CreateResume(c *gin.Context) {
 		resume.PreviewImageURL = *req.PreviewImageURL
 	}
 
-	if err := h.db.WithContext(ctx).Create(&resume).Error; err != nil {
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&resume).Error; err != nil {
+			return err
+		}
+		if err := replaceResumeTags(tx, userID, resume.ID, tags); err != nil {
+			return err
+		}
+		if err := setActiveResumeID(tx, userID, &resume.ID); err != nil {
+			return err
+		}
+		return database.RecordResumeEvent(tx, resume.ID, userID, database.ResumeEventCreated, middleware.GetCorrelationID(c), "")
+	}); err != nil {
 		Internal(c, "failed to create resume")
 		return
 	}
 
-	if err := h.setActiveResumeID(ctx, userID, &resume.ID); err != nil {
-		Internal(c, "failed to mark active resume")
-		return
+	resp := h.newResumeResponse(c, resume)
+	if tags != nil {
+		resp.Tags = tags
 	}
-
-	c.JSON(http.StatusCreated, newResumeResponse(resume))
+	c.JSON(http.StatusCreated, resp)
 }
 
-// GetLatestResume 返回用户最近的简历，或默认模板。
+// GetLatestResume 返回用户最近的简历，或默认模板；支持 If-None-Match 条件请求。
 func (h *ResumeHandler) GetLatestResume(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -150,10 +244,18 @@ func (h *ResumeHandler) GetLatestResume(c *gin.Context) {
 	resume, err := h.findActiveOrLatestResume(ctx, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			locale := middleware.GetLocale(c)
+			content := h.defaultContent
+			if len(content) == 0 {
+				content = datatypes.JSON(resumepkg.StarterContent(locale))
+			}
+			c.Header("Cache-Control", "no-cache")
+			c.Writer.Header().Add("Vary", "Accept-Language")
 			c.JSON(http.StatusOK, resumeResponse{
 				ID:        0,
-				Title:     defaultResumeTitle,
-				Content:   defaultResumeContent(),
+				Title:     resumepkg.StarterTitle(locale),
+				Content:   content,
+				Tags:      []string{},
 				CreatedAt: time.Time{},
 				UpdatedAt: time.Time{},
 			})
@@ -163,10 +265,18 @@ func (h *ResumeHandler) GetLatestResume(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, newResumeResponse(*resume))
+	if notModified(c, h.resumeETag(*resume)) {
+		return
+	}
+	resp, err := h.resumeResponseWithTags(c, *resume)
+	if err != nil {
+		Internal(c, "failed to query latest resume")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// ListResumes 列出用户全部简历。
+// ListResumes 列出用户全部简历；?tag= 仅返回带该标签的简历。
 func (h *ResumeHandler) ListResumes(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -175,21 +285,45 @@ func (h *ResumeHandler) ListResumes(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	query := h.db.WithContext(ctx).Where("user_id = ?", userID)
+	if raw, ok := c.GetQuery("tag"); ok {
+		tag := strings.ToLower(strings.TrimSpace(raw))
+		if tag == "" {
+			BadRequest(c, "invalid tag")
+			return
+		}
+		query = query.Where("id IN (?)", h.db.Model(&database.ResumeTag{}).
+			Select("resume_id").
+			Where("user_id = ? AND tag = ?", userID, tag))
+	}
+
 	var resumes []database.Resume
-	if err := h.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Find(&resumes).Error; err != nil {
+	if err := query.Order("created_at DESC").Find(&resumes).Error; err != nil {
+		Internal(c, "failed to list resumes")
+		return
+	}
+
+	ids := make([]uint, 0, len(resumes))
+	for _, r := range resumes {
+		ids = append(ids, r.ID)
+	}
+	tagsByResume, err := h.loadResumeTags(ctx, ids...)
+	if err != nil {
 		Internal(c, "failed to list resumes")
 		return
 	}
 
 	items := make([]resumeListItem, 0, len(resumes))
 	for _, r := range resumes {
+		tags := tagsByResume[r.ID]
+		if tags == nil {
+			tags = []string{}
+		}
 		items = append(items, resumeListItem{
 			ID:              r.ID,
 			Title:           r.Title,
-			PreviewImageURL: r.PreviewImageURL,
+			PreviewImageURL: h.previewURLs.url(c, r.PreviewObjectKey, r.PreviewImageURL),
+			Tags:            tags,
 			CreatedAt:       r.CreatedAt,
 		})
 	}
@@ -197,7 +331,7 @@ func (h *ResumeHandler) ListResumes(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
-// GetResume 返回指定 ID 的简历并标记为当前正在编辑。
+// GetResume 返回指定 ID 的简历并标记为当前正在编辑；If-None-Match 命中时返回 304。
 func (h *ResumeHandler) GetResume(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -218,19 +352,27 @@ func (h *ResumeHandler) GetResume(c *gin.Context) {
 		return
 	}
 
-	if err := h.setActiveResumeID(c.Request.Context(), userID, &resume.ID); err != nil {
+	if err := setActiveResumeID(h.db.WithContext(c.Request.Context()), userID, &resume.ID); err != nil {
 		Internal(c, "failed to mark active resume")
 		return
 	}
 
-	c.JSON(http.StatusOK, newResumeResponse(*resume))
+	if notModified(c, h.resumeETag(*resume)) {
+		return
+	}
+	resp, err := h.resumeResponseWithTags(c, *resume)
+	if err != nil {
+		Internal(c, "failed to query resume")
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // UpdateResume 覆盖指定简历。
 func (h *ResumeHandler) UpdateResume(c *gin.Context) {
 	var req createResumeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
 		return
 	}
 
@@ -253,31 +395,60 @@ func (h *ResumeHandler) UpdateResume(c *gin.Context) {
 		return
 	}
 
+	// 显式保存会替换正式内容并提升草稿，与创建一样校验网格约束。
+	if !h.validateContent(c, req.Content) {
+		return
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		normalized, err := normalizeResumeTags(*req.Tags)
+		if err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+		tags = normalized
+	}
+
+	// 显式保存即提升草稿：正式内容以请求为准，同时清空自动保存的草稿。
 	updates := map[string]any{
-		"title":   req.Title,
-		"content": req.Content,
+		"title":          req.Title,
+		"content":        req.Content,
+		"draft":          nil,
+		"draft_saved_at": nil,
 	}
 	if req.PreviewImageURL != nil {
 		updates["preview_image_url"] = *req.PreviewImageURL
 	}
 
 	ctx := c.Request.Context()
-	if err := h.db.WithContext(ctx).Model(resume).Updates(updates).Error; err != nil {
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(resume).Updates(updates).Error; err != nil {
+			return err
+		}
+		if req.Tags != nil {
+			if err := replaceResumeTags(tx, userID, resume.ID, tags); err != nil {
+				return err
+			}
+		}
+		if err := tx.First(resume, resume.ID).Error; err != nil {
+			return err
+		}
+		if err := setActiveResumeID(tx, userID, &resume.ID); err != nil {
+			return err
+		}
+		return database.RecordResumeEvent(tx, resume.ID, userID, database.ResumeEventUpdated, middleware.GetCorrelationID(c), "")
+	}); err != nil {
 		Internal(c, "failed to update resume")
 		return
 	}
 
-	if err := h.db.WithContext(ctx).First(resume, resume.ID).Error; err != nil {
+	resp, err := h.resumeResponseWithTags(c, *resume)
+	if err != nil {
 		Internal(c, "failed to reload resume")
 		return
 	}
-
-	if err := h.setActiveResumeID(ctx, userID, &resume.ID); err != nil {
-		Internal(c, "failed to mark active resume")
-		return
-	}
-
-	c.JSON(http.StatusOK, newResumeResponse(*resume))
+	c.JSON(http.StatusOK, resp)
 }
 
 // DeleteResume 删除指定简历，并尝试回落到最近一份。
@@ -307,67 +478,173 @@ func (h *ResumeHandler) DeleteResume(c *gin.Context) {
 		slog.Uint64("resume_id", uint64(resume.ID)),
 	)
 
-	previewKey := strings.TrimSpace(resume.PreviewObjectKey)
-	previewPrefix := fmt.Sprintf("thumbnails/resume/%d/", resume.ID)
-	if previewKey == "" {
-		previewPrefix = fmt.Sprintf("resume/%d/", resume.ID)
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resume_id = ?", resume.ID).Delete(&database.ResumeTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&database.Resume{}, resume.ID).Error; err != nil {
+			return err
+		}
+		if err := database.AdjustStorageBytes(tx, resume.UserID, -(resume.PdfSize + resume.PreviewSize)); err != nil {
+			return err
+		}
+		if err := database.RecordResumeEvent(tx, resume.ID, userID, database.ResumeEventDeleted, middleware.GetCorrelationID(c), ""); err != nil {
+			return err
+		}
+		return assignLatestResumeAsActive(tx, userID)
+	}); err != nil {
+		logger.Error("delete resume record failed", slog.Any("error", err))
+		Internal(c, "failed to delete resume")
+		return
 	}
 
-	// 原子语义（口径 A）：先删 MinIO，成功后再删 DB。
-	if previewKey != "" {
-		if err := h.storage.DeleteObject(ctx, previewKey); err != nil {
-			logger.Error("delete resume preview object failed", slog.String("object_key", previewKey), slog.Any("error", err))
-			Internal(c, "failed to delete resume preview")
+	// 对象清理为尽力而为：DB 记录已删除，存储异常只记日志，不影响本次请求结果。
+	h.deleteResumeObjects(ctx, logger, resume)
+
+	if err := h.markPDFTasksCancelled(ctx, resume.ID); err != nil {
+		logger.Warn("mark pdf tasks cancelled failed", slog.Any("error", err))
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GeneratePreview 仅入队简历缩略图生成任务，不触发 PDF 渲染。
+func (h *ResumeHandler) GeneratePreview(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := middleware.LoggerFromContext(c).With(slog.Uint64("resume_id", uint64(resume.ID)))
+	correlationID := middleware.GetCorrelationID(c)
+	taskID := uuid.NewString()
+	inflight := tasks.PDFInflight{TaskID: taskID, CorrelationID: correlationID, ContentHash: resumepkg.ContentHash(resume.Content)}
+
+	// 同一内容在 ResumePreviewInflightTTL 内已入队过缩略图任务：合并请求，避免反复点击占满渲染队列。
+	claimed := false
+	if h.previewInflight != nil {
+		existing, ok, err := h.previewInflight.Claim(ctx, resume.ID, inflight)
+		switch {
+		case err != nil:
+			logger.Warn("claim preview inflight slot failed", slog.Any("error", err))
+		case !ok:
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":      "resume preview generation already scheduled",
+				"task_id":      existing.TaskID,
+				"deduplicated": true,
+			})
 			return
+		default:
+			claimed = true
 		}
-	} else {
-		if err := h.storage.DeletePrefix(ctx, previewPrefix); err != nil {
-			logger.Error("delete resume preview prefix failed", slog.String("prefix", previewPrefix), slog.Any("error", err))
-			Internal(c, "failed to delete resume preview")
+	}
+	releaseClaim := func() {
+		if !claimed {
 			return
 		}
+		if err := h.previewInflight.Release(ctx, resume.ID, taskID); err != nil {
+			logger.Warn("release preview inflight slot failed", slog.Any("error", err))
+		}
 	}
 
-	if err := h.db.WithContext(ctx).Delete(&database.Resume{}, resume.ID).Error; err != nil {
-		logger.Error("delete resume record failed", slog.Any("error", err))
-		Internal(c, "failed to delete resume")
+	task, err := tasks.NewResumePreviewTask(resume.ID, resume.UserID, correlationID, middleware.GetTraceParent(c))
+	if err != nil {
+		releaseClaim()
+		Internal(c, "failed to create preview task")
 		return
 	}
 
-	if err := h.assignLatestResumeAsActive(ctx, userID); err != nil {
-		logger.Error("update active resume after delete failed", slog.Any("error", err))
-		Internal(c, "failed to update active resume")
+	info, err := h.asynqClient.Enqueue(task, asynq.TaskID(taskID), asynq.MaxRetry(5))
+	if err != nil {
+		releaseClaim()
+		logger.Error("enqueue resume preview failed", slog.Any("error", err))
+		Internal(c, "failed to enqueue preview task")
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "resume preview generation scheduled",
+		"task_id": info.ID,
+	})
 }
 
-func (h *ResumeHandler) setActiveResumeID(ctx context.Context, userID uint, resumeID *uint) error {
+// deleteResumeObjects 删除简历关联的预览图与已生成的 PDF，失败仅记录日志。
+func (h *ResumeHandler) deleteResumeObjects(ctx context.Context, logger *slog.Logger, resume *database.Resume) {
+	if previewKey := strings.TrimSpace(resume.PreviewObjectKey); previewKey != "" {
+		if err := h.storage.DeleteObject(ctx, previewKey); err != nil {
+			logger.Warn("delete resume preview object failed", slog.String("object_key", previewKey), slog.Any("error", err))
+		}
+	}
+	// PreviewObjectKey 可能为空或已被新预览覆盖，按前缀兜底清理该简历的全部缩略图。
+	previewPrefix := resumePreviewPrefix(resume.ID)
+	if err := h.storage.DeletePrefix(ctx, previewPrefix); err != nil {
+		logger.Warn("delete resume preview prefix failed", slog.String("prefix", previewPrefix), slog.Any("error", err))
+	}
+
+	// 旧版本 PDF 直接位于 generated-resumes/{userID}/ 下，只能按 PdfUrl 精确删除。
+	if pdfKey := strings.TrimSpace(resume.PdfUrl); pdfKey != "" {
+		if err := h.storage.DeleteObject(ctx, pdfKey); err != nil {
+			logger.Warn("delete resume pdf object failed", slog.String("object_key", pdfKey), slog.Any("error", err))
+		}
+	}
+	pdfPrefix := generatedResumePDFPrefix(resume.UserID, resume.ID)
+	if err := h.storage.DeletePrefix(ctx, pdfPrefix); err != nil {
+		logger.Warn("delete resume pdf prefix failed", slog.String("prefix", pdfPrefix), slog.Any("error", err))
+	}
+}
+
+// resumePreviewPrefix 返回某份简历缩略图的对象前缀，需与 worker 的命名保持一致。
+func resumePreviewPrefix(resumeID uint) string {
+	return fmt.Sprintf("thumbnails/resume/%d/", resumeID)
+}
+
+// generatedResumePDFPrefix 返回某份简历生成 PDF 的对象前缀，需与 worker 的命名保持一致。
+func generatedResumePDFPrefix(userID, resumeID uint) string {
+	return fmt.Sprintf("generated-resumes/%d/%d/", userID, resumeID)
+}
+
+// setActiveResumeID 更新用户的当前简历；db 可为事务句柄，以便与简历写入一并提交或回滚。
+func setActiveResumeID(db *gorm.DB, userID uint, resumeID *uint) error {
 	var value any
 	if resumeID != nil {
 		value = *resumeID
 	} else {
 		value = nil
 	}
-	return h.db.WithContext(ctx).Model(&database.User{}).
+	return db.Model(&database.User{}).
 		Where("id = ?", userID).
 		Update("active_resume_id", value).Error
 }
 
-func (h *ResumeHandler) assignLatestResumeAsActive(ctx context.Context, userID uint) error {
+// assignLatestResumeAsActive 将最近更新的简历设为当前简历，没有简历时清空；db 可为事务句柄。
+func assignLatestResumeAsActive(db *gorm.DB, userID uint) error {
 	var resume database.Resume
-	err := h.db.WithContext(ctx).
+	err := db.
 		Where("user_id = ?", userID).
 		Order("updated_at desc").
 		First(&resume).Error
 	switch {
 	case errors.Is(err, gorm.ErrRecordNotFound):
-		return h.setActiveResumeID(ctx, userID, nil)
+		return setActiveResumeID(db, userID, nil)
 	case err != nil:
 		return err
 	default:
-		return h.setActiveResumeID(ctx, userID, &resume.ID)
+		return setActiveResumeID(db, userID, &resume.ID)
 	}
 }
 
@@ -397,12 +674,12 @@ func (h *ResumeHandler) findActiveOrLatestResume(ctx context.Context, userID uin
 		First(&latest).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			_ = h.setActiveResumeID(ctx, userID, nil)
+			_ = setActiveResumeID(h.db.WithContext(ctx), userID, nil)
 		}
 		return nil, err
 	}
 
-	if err := h.setActiveResumeID(ctx, userID, &latest.ID); err != nil {
+	if err := setActiveResumeID(h.db.WithContext(ctx), userID, &latest.ID); err != nil {
 		return nil, err
 	}
 	return &latest, nil
@@ -417,18 +694,6 @@ func (h *ResumeHandler) DownloadResume(c *gin.Context) {
 		return
 	}
 
-	// 每用户每小时 3 次限制
-	window := time.Now().UTC().Format("2006010215")
-	rateKey := fmt.Sprintf("rate:pdf:%d:%s", userID, window)
-	count, err := incrWithTTL(c.Request.Context(), h.redisClient, rateKey, time.Hour)
-	if err != nil {
-		count = 0
-	}
-	if count > int64(h.pdfRateLimitPerHour) {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
-		return
-	}
-
 	resume, err := h.getResumeForUser(c.Request.Context(), c.Param("id"), userID)
 	if err != nil {
 		switch {
@@ -442,19 +707,90 @@ func (h *ResumeHandler) DownloadResume(c *gin.Context) {
 		return
 	}
 
+	// 内容未变化且已有对应 PDF：直接返回，不入队也不计入频控。
+	contentHash := resumepkg.ContentHash(resume.Content)
+	if resumePDFCached(resume, contentHash) {
+		c.JSON(http.StatusOK, gin.H{
+			"message":   "PDF is up to date",
+			"cached":    true,
+			"resume_id": resume.ID,
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	logger := middleware.LoggerFromContext(c).With(slog.Uint64("resume_id", uint64(resume.ID)))
 	correlationID := middleware.GetCorrelationID(c)
-	task, err := tasks.NewPDFGenerateTask(resume.ID, correlationID)
+	// task id 在占用名额前生成：Worker 可能在 Enqueue 返回前就执行完并按该 id 释放标记。
+	taskID := uuid.NewString()
+	inflight := tasks.PDFInflight{TaskID: taskID, CorrelationID: correlationID, ContentHash: contentHash}
+
+	// 同一内容已有任务在途：合并请求，返回原任务的 task_id / correlation_id，便于前端继续等待同一条通知。
+	claimed := false
+	if h.pdfInflight != nil {
+		existing, ok, err := h.pdfInflight.Claim(ctx, resume.ID, inflight)
+		switch {
+		case err != nil:
+			logger.Warn("claim pdf inflight slot failed", slog.Any("error", err))
+		case !ok:
+			c.JSON(http.StatusAccepted, gin.H{
+				"message":        "PDF generation already in progress",
+				"task_id":        existing.TaskID,
+				"resume_id":      resume.ID,
+				"correlation_id": existing.CorrelationID,
+				"deduplicated":   true,
+			})
+			return
+		default:
+			claimed = true
+		}
+	}
+	releaseClaim := func() {
+		if !claimed {
+			return
+		}
+		if err := h.pdfInflight.Release(ctx, resume.ID, taskID); err != nil {
+			logger.Warn("release pdf inflight slot failed", slog.Any("error", err))
+		}
+	}
+
+	// 每用户每小时 3 次限制
+	window := time.Now().UTC().Format("2006010215")
+	rateKey := fmt.Sprintf("rate:pdf:%d:%s", userID, window)
+	count, err := incrWithTTL(ctx, h.redisClient, rateKey, time.Hour)
+	if err != nil {
+		count = 0
+	}
+	if count > int64(h.pdfRateLimitPerHour) {
+		releaseClaim()
+		TooManyRequests(c, "rate limit exceeded")
+		return
+	}
+
+	// 新任务入队前清除旧的取消标记，避免被历史取消误伤。
+	if err := h.clearPDFTasksCancelled(ctx, resume.ID); err != nil {
+		logger.Warn("clear pdf cancel flag failed", slog.Any("error", err))
+	}
+
+	task, err := tasks.NewPDFGenerateTask(resume.ID, resume.UserID, correlationID, middleware.GetTraceParent(c), contentHash)
 	if err != nil {
+		releaseClaim()
 		Internal(c, "failed to create task")
 		return
 	}
 
-	info, err := h.asynqClient.Enqueue(task, asynq.MaxRetry(5))
+	info, err := h.asynqClient.Enqueue(task, asynq.TaskID(taskID), asynq.MaxRetry(5))
 	if err != nil {
+		releaseClaim()
 		Internal(c, "failed to enqueue pdf generation")
 		return
 	}
 
+	// 生命周期事件仅用于展示与审计，任务已入队，写入失败不影响本次请求。
+	if err := database.RecordResumeEvent(h.db.WithContext(ctx), resume.ID, userID, database.ResumeEventRenderQueued, correlationID, ""); err != nil {
+		logger.Warn("record render queued event failed", slog.Any("error", err))
+	}
+
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":        "PDF generation request accepted",
 		"task_id":        info.ID,
@@ -463,6 +799,42 @@ func (h *ResumeHandler) DownloadResume(c *gin.Context) {
 	})
 }
 
+// CancelPDFTasks 供内部调用：标记该简历排队中的 PDF 任务为已取消，Worker 会在启动浏览器前跳过。
+func (h *ResumeHandler) CancelPDFTasks(c *gin.Context) {
+	resumeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		BadRequest(c, "invalid resume id")
+		return
+	}
+
+	if err := h.markPDFTasksCancelled(c.Request.Context(), uint(resumeID)); err != nil {
+		middleware.LoggerFromContext(c).Error("mark pdf tasks cancelled failed", slog.Uint64("resume_id", resumeID), slog.Any("error", err))
+		Internal(c, "failed to cancel pdf tasks")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ResumeHandler) markPDFTasksCancelled(ctx context.Context, resumeID uint) error {
+	if h.redisClient == nil {
+		return nil
+	}
+	return h.redisClient.Set(ctx, tasks.PDFCancelKey(resumeID), 1, tasks.PDFCancelTTL).Err()
+}
+
+func (h *ResumeHandler) clearPDFTasksCancelled(ctx context.Context, resumeID uint) error {
+	if h.redisClient == nil {
+		return nil
+	}
+	return h.redisClient.Del(ctx, tasks.PDFCancelKey(resumeID)).Err()
+}
+
+// resumePDFCached 判断已生成的 PDF 是否对应当前内容。
+func resumePDFCached(resume *database.Resume, contentHash string) bool {
+	return strings.TrimSpace(resume.PdfUrl) != "" && resume.PdfContentHash != "" && resume.PdfContentHash == contentHash
+}
+
 func userIDFromContext(c *gin.Context) (uint, bool) {
 	value, exists := c.Get("userID")
 	if !exists {
@@ -580,18 +952,22 @@ func (h *ResumeHandler) consumeDownloadToken(ctx context.Context, userID, resume
 	return res, nil
 }
 
+// sanitizeDownloadFilename 清洗下载文件名：去掉路径与控制字符，按字符截断到 160 个并强制 .pdf；
+// 为空时回退 Resume-<id>.pdf。非 ASCII 字符保留，由 storage.AttachmentDisposition 编码。
 func sanitizeDownloadFilename(name string, resumeID uint) string {
-	name = strings.TrimSpace(name)
-	if name == "" {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = strings.TrimSpace(path.Base(strings.TrimSpace(name)))
+	if strings.Trim(name, "./") == "" {
 		return fmt.Sprintf("Resume-%d.pdf", resumeID)
 	}
-	name = strings.ReplaceAll(name, "\r", "")
-	name = strings.ReplaceAll(name, "\n", "")
-	name = strings.ReplaceAll(name, "\\", "/")
-	name = path.Base(name)
-	name = strings.ReplaceAll(name, `"`, "")
-	if len(name) > 160 {
-		name = name[:160]
+	if runes := []rune(name); len(runes) > 160 {
+		name = string(runes[:160])
 	}
 	if !strings.HasSuffix(strings.ToLower(name), ".pdf") {
 		name += ".pdf"
@@ -636,7 +1012,7 @@ func (h *ResumeHandler) DownloadResumeFile(c *gin.Context) {
 
 	var resume database.Resume
 	if err := h.db.WithContext(ctx).
-		Select("id", "user_id", "pdf_url").
+		Select("id", "user_id", "title", "pdf_url").
 		Where("id = ? AND user_id = ?", resumeID, userID).
 		First(&resume).Error; err != nil {
 		NotFound(c, "download link expired")
@@ -647,10 +1023,19 @@ func (h *ResumeHandler) DownloadResumeFile(c *gin.Context) {
 		return
 	}
 
-	filename := sanitizeDownloadFilename(c.Query("filename"), resumeID)
+	// 未指定 filename 时以简历标题命名，避免浏览器按对象名保存。
+	filename := c.Query("filename")
+	if strings.TrimSpace(filename) == "" {
+		filename = resume.Title
+	}
+	filename = sanitizeDownloadFilename(filename, resumeID)
 
 	obj, err := h.storage.GetObject(ctx, resume.PdfUrl)
 	if err != nil {
+		if storage.IsNoSuchKey(err) {
+			NotFound(c, "download link expired")
+			return
+		}
 		Internal(c, "failed to download pdf")
 		return
 	}
@@ -671,7 +1056,7 @@ func (h *ResumeHandler) DownloadResumeFile(c *gin.Context) {
 	c.Header("X-Content-Type-Options", "nosniff")
 
 	headers := map[string]string{
-		"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", filename),
+		"Content-Disposition": storage.AttachmentDisposition(filename),
 	}
 	c.DataFromReader(http.StatusOK, info.Size, "application/pdf", io.LimitReader(obj, info.Size), headers)
 }
@@ -683,6 +1068,11 @@ func (h *ResumeHandler) GetPrintResumeData(c *gin.Context) {
 		BadRequest(c, "invalid resume id")
 		return
 	}
+	ownerID, ok := printOwnerFromRequest(c)
+	if !ok {
+		BadRequest(c, "missing or invalid print owner")
+		return
+	}
 
 	var resumeModel database.Resume
 	ctx := c.Request.Context()
@@ -696,7 +1086,29 @@ func (h *ResumeHandler) GetPrintResumeData(c *gin.Context) {
 		return
 	}
 
-	printData, removed, err := BuildPrintData(ctx, h.storage, resumeModel.UserID, resumeModel.Content)
+	log := middleware.LoggerFromContext(c).With(
+		slog.Int("resume_id", int(resumeModel.ID)),
+		slog.Uint64("user_id", uint64(resumeModel.UserID)),
+	)
+
+	// 所有权不匹配时按不存在处理，避免内部接口被用于探测其他用户的简历。
+	if resumeModel.UserID != ownerID {
+		log.Warn("print data owner mismatch", slog.Uint64("expected_user_id", uint64(ownerID)))
+		NotFound(c, "resume not found")
+		return
+	}
+
+	assetDir, err := database.LoadAssetKeyDir(h.db.WithContext(ctx), resumeModel.UserID)
+	if err != nil {
+		Internal(c, "failed to query asset dir")
+		return
+	}
+	printData, removed, err := BuildPrintData(ctx, h.storage, resumeModel.UserID, resumeModel.Content, PrintDataOptions{
+		StrictImageMIME: h.strictImageMIME,
+		Logger:          log,
+		Grid:            h.grid,
+		AssetDir:        assetDir,
+	})
 	if err != nil {
 		if status, ok := statusFromInlineError(err); ok {
 			Error(c, status, err.Error())
@@ -706,15 +1118,83 @@ func (h *ResumeHandler) GetPrintResumeData(c *gin.Context) {
 		return
 	}
 
-	log := middleware.LoggerFromContext(c).With(
-		slog.Int("resume_id", int(resumeModel.ID)),
-		slog.Uint64("user_id", uint64(resumeModel.UserID)),
-	)
 	LogRemovedImageItems(log, removed)
 
 	c.JSON(http.StatusOK, printData)
 }
 
+// removedPrintItem 为 ValidatePrint 返回的被剔除图片项。
+type removedPrintItem struct {
+	ItemID    string `json:"item_id"`
+	ObjectKey string `json:"object_key,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// ValidatePrint 以与内部打印接口相同的逻辑构造打印数据（不入队、不上传），返回告警与会被剔除的图片项，
+// 供前端在触发导出前做预检。
+func (h *ResumeHandler) ValidatePrint(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	ctx := c.Request.Context()
+	resume, err := h.getResumeForUser(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidResumeID):
+			BadRequest(c, "invalid resume id")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "resume not found")
+		default:
+			Internal(c, "failed to query resume")
+		}
+		return
+	}
+
+	log := middleware.LoggerFromContext(c).With(
+		slog.Int("resume_id", int(resume.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	assetDir, err := database.LoadAssetKeyDir(h.db.WithContext(ctx), resume.UserID)
+	if err != nil {
+		Internal(c, "failed to query asset dir")
+		return
+	}
+	printData, removed, err := BuildPrintData(ctx, h.storage, resume.UserID, resume.Content, PrintDataOptions{
+		StrictImageMIME: h.strictImageMIME,
+		Logger:          log,
+		Grid:            h.grid,
+		AssetDir:        assetDir,
+	})
+	if err != nil {
+		if status, ok := statusFromInlineError(err); ok {
+			Error(c, status, err.Error())
+			return
+		}
+		log.Error("validate print data failed", slog.Any("error", err))
+		Internal(c, "failed to validate print data")
+		return
+	}
+
+	items := make([]removedPrintItem, 0, len(removed))
+	for _, r := range removed {
+		items = append(items, removedPrintItem{ItemID: r.ItemID, ObjectKey: r.Key, Reason: r.Reason})
+	}
+	warnings := printData.Warnings
+	if warnings == nil {
+		warnings = []PrintWarning{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ok":            len(warnings) == 0,
+		"warnings":      warnings,
+		"removed_items": items,
+	})
+}
+
 func (h *ResumeHandler) getResumeForUser(ctx context.Context, idParam string, userID uint) (*database.Resume, error) {
 	resumeID, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
@@ -731,94 +1211,28 @@ func (h *ResumeHandler) getResumeForUser(ctx context.Context, idParam string, us
 	return &resume, nil
 }
 
-const defaultResumeTitle = "我的第一份简历"
-
-func defaultResumeContent() datatypes.JSON {
-	layoutSettings := map[string]any{
-		"columns":       24,
-		"row_height_px": 10,
-		"accent_color":  "#3388ff",
-		"font_family":   "Arial",
-		"font_size_pt":  10,
-		"margin_px":     36,
-	}
-
-	items := []map[string]any{
-		{
-			"id":      "item-1",
-			"type":    "text",
-			"content": "你的名字",
-			"style": map[string]any{
-				"fontSize":          "24pt",
-				"fontWeight":        "bold",
-				"backgroundColor":   "#f5e8ff",
-				"backgroundOpacity": 0.75,
-			},
-			"layout": map[string]any{
-				"x": 0,
-				"y": 2,
-				"w": 16,
-				"h": 6,
-			},
-		},
-		{
-			"id":      "item-2",
-			"type":    "text",
-			"content": "你的职位/头衔",
-			"style": map[string]any{
-				"fontSize":          "14pt",
-				"backgroundColor":   "#fff7d6",
-				"backgroundOpacity": 0.68,
-			},
-			"layout": map[string]any{
-				"x": 0,
-				"y": 8,
-				"w": 16,
-				"h": 4,
-			},
-		},
-		{
-			"id":      "item-3",
-			"type":    "text",
-			"content": "你的联系方式：\n电话: 123-456-7890\n邮箱: hello@example.com",
-			"style": map[string]any{
-				"fontSize":          "10pt",
-				"backgroundColor":   "#e7fbff",
-				"backgroundOpacity": 0.72,
-			},
-			"layout": map[string]any{
-				"x": 16,
-				"y": 2,
-				"w": 8,
-				"h": 10,
-			},
-		},
-	}
-
-	for _, item := range items {
-		if _, ok := item["style"]; !ok || item["style"] == nil {
-			item["style"] = map[string]any{}
-		}
-	}
-
-	template := map[string]any{
-		"layout_settings": layoutSettings,
-		"items":           items,
-	}
-
-	data, err := json.Marshal(template)
-	if err != nil {
-		return datatypes.JSON([]byte("{}"))
+// validateContent 按网格约束校验即将成为正式内容的 content，不合法时写入 400 并返回 false。
+func (h *ResumeHandler) validateContent(c *gin.Context, content []byte) bool {
+	if _, err := resumepkg.ValidateContent(content, resumepkg.ContentLimits{Grid: h.grid}); err != nil {
+		BadRequest(c, "invalid resume content")
+		return false
 	}
-	return datatypes.JSON(data)
+	return true
+}
+
+// resumeETag 在行数据 ETag 上并入缩略图签名窗口，避免客户端凭 304 沿用已过期的 preview_image_url。
+func (h *ResumeHandler) resumeETag(resume database.Resume) string {
+	return withETagSuffix(resumeETag(resume), h.previewURLs.etagWindow(resume.PreviewObjectKey, time.Now()))
 }
 
-func newResumeResponse(resume database.Resume) resumeResponse {
+func (h *ResumeHandler) newResumeResponse(c *gin.Context, resume database.Resume) resumeResponse {
 	return resumeResponse{
 		ID:              resume.ID,
 		Title:           resume.Title,
 		Content:         resume.Content,
-		PreviewImageURL: resume.PreviewImageURL,
+		PreviewImageURL: h.previewURLs.url(c, resume.PreviewObjectKey, resume.PreviewImageURL),
+		Tags:            []string{},
+		HasDraft:        len(resume.Draft) > 0,
 		CreatedAt:       resume.CreatedAt,
 		UpdatedAt:       resume.UpdatedAt,
 	}
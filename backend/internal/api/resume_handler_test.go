@@ -0,0 +1,1157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/minio/minio-go/v7"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+	"phResume/internal/tasks"
+)
+
+func (s *fakeStorage) GetObject(_ context.Context, objectKey string) (*minio.Object, error) {
+	return nil, errors.New("fake storage: get object not supported: " + objectKey)
+}
+
+func (s *fakeStorage) DeletePrefix(_ context.Context, prefix string) error {
+	s.deletedPrefixes = append(s.deletedPrefixes, prefix)
+	return s.deleteErr
+}
+
+func seedResume(t *testing.T, h *ResumeHandler, resume database.Resume) database.Resume {
+	t.Helper()
+	user := database.User{Model: gorm.Model{ID: resume.UserID}, Username: "user-" + strconv.Itoa(int(resume.UserID))}
+	if err := h.db.Create(&user).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if resume.Content == nil {
+		resume.Content = datatypes.JSON(`{}`)
+	}
+	if err := h.db.Create(&resume).Error; err != nil {
+		t.Fatalf("seed resume: %v", err)
+	}
+	return resume
+}
+
+// deleteResumeRequest 返回最终写出的状态码；c.Status 不写 body 时 recorder 拿不到 204。
+func deleteResumeRequest(h *ResumeHandler, userID, resumeID uint) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	id := strconv.FormatUint(uint64(resumeID), 10)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/v1/resume/"+id, nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set("userID", userID)
+	h.DeleteResume(c)
+	return c.Writer.Status()
+}
+
+func TestDeleteResume_RemovesPreviewAndPDFs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := newFakeStorage()
+	h := &ResumeHandler{db: newTestDB(t), storage: storage}
+
+	resume := seedResume(t, h, database.Resume{
+		UserID:           1,
+		Title:            "r",
+		PdfUrl:           "generated-resumes/1/legacy.pdf",
+		PreviewObjectKey: "thumbnails/resume/9/preview.jpg",
+	})
+
+	if code := deleteResumeRequest(h, 1, resume.ID); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+
+	assertContains(t, storage.deleted, resume.PreviewObjectKey)
+	assertContains(t, storage.deleted, resume.PdfUrl)
+	assertContains(t, storage.deletedPrefixes, generatedResumePDFPrefix(1, resume.ID))
+	assertContains(t, storage.deletedPrefixes, resumePreviewPrefix(resume.ID))
+}
+
+func TestDeleteResume_WithoutPreviewKeyDeletesPreviewPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := newFakeStorage()
+	h := &ResumeHandler{db: newTestDB(t), storage: storage}
+
+	resume := seedResume(t, h, database.Resume{UserID: 3, Title: "r"})
+
+	if code := deleteResumeRequest(h, 3, resume.ID); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+
+	if len(storage.deleted) != 0 {
+		t.Fatalf("expected no exact-key deletes, got %v", storage.deleted)
+	}
+	assertContains(t, storage.deletedPrefixes, "thumbnails/resume/"+strconv.Itoa(int(resume.ID))+"/")
+	assertContains(t, storage.deletedPrefixes, generatedResumePDFPrefix(3, resume.ID))
+}
+
+func TestDeleteResume_StorageFailureDoesNotBlockDelete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	storage := newFakeStorage()
+	storage.deleteErr = errors.New("minio unavailable")
+	h := &ResumeHandler{db: newTestDB(t), storage: storage}
+
+	resume := seedResume(t, h, database.Resume{
+		UserID:           2,
+		Title:            "r",
+		PreviewObjectKey: "thumbnails/resume/1/preview.jpg",
+	})
+
+	if code := deleteResumeRequest(h, 2, resume.ID); code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", code)
+	}
+
+	var count int64
+	if err := h.db.Model(&database.Resume{}).Where("id = ?", resume.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count resumes: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("resume record should be deleted despite storage failure")
+	}
+}
+
+type fakeEnqueuer struct {
+	tasks []*asynq.Task
+	// onEnqueue 在返回前调用，用于模拟 Worker 在 Enqueue 返回前就处理完任务。
+	onEnqueue func(id string)
+	// err 非空时模拟入队失败。
+	err error
+}
+
+// Enqueue 与 asynq 一致：带 asynq.TaskID 时沿用该 id，否则生成一个。
+func (f *fakeEnqueuer) Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.tasks = append(f.tasks, task)
+	id := "task-" + strconv.Itoa(len(f.tasks))
+	for _, opt := range opts {
+		if opt.Type() == asynq.TaskIDOpt {
+			id = opt.Value().(string)
+		}
+	}
+	if f.onEnqueue != nil {
+		f.onEnqueue(id)
+	}
+	return &asynq.TaskInfo{ID: id}, nil
+}
+
+func downloadResumeRequest(h *ResumeHandler, userID, resumeID uint, correlationID string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	id := strconv.FormatUint(uint64(resumeID), 10)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/"+id+"/download", nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set("userID", userID)
+	c.Set("correlationID", correlationID)
+	h.DownloadResume(c)
+	return w
+}
+
+// memoryPDFInflightStore 以内存模拟 redisPDFInflightStore 的语义。
+type memoryPDFInflightStore struct {
+	entries map[uint]tasks.PDFInflight
+}
+
+func newMemoryPDFInflightStore() *memoryPDFInflightStore {
+	return &memoryPDFInflightStore{entries: map[uint]tasks.PDFInflight{}}
+}
+
+func (s *memoryPDFInflightStore) Claim(_ context.Context, resumeID uint, want tasks.PDFInflight) (tasks.PDFInflight, bool, error) {
+	if existing, ok := s.entries[resumeID]; ok && existing.ContentHash == want.ContentHash {
+		return existing, false, nil
+	}
+	s.entries[resumeID] = want
+	return tasks.PDFInflight{}, true, nil
+}
+
+func (s *memoryPDFInflightStore) Release(_ context.Context, resumeID uint, taskID string) error {
+	if existing, ok := s.entries[resumeID]; ok && existing.TaskID == taskID {
+		delete(s.entries, resumeID)
+	}
+	return nil
+}
+
+func decodeJSONBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestDownloadResume_DeduplicatesInflightRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enqueuer := &fakeEnqueuer{}
+	inflight := newMemoryPDFInflightStore()
+	h := &ResumeHandler{
+		db:                  newTestDB(t),
+		asynqClient:         enqueuer,
+		pdfInflight:         inflight,
+		redisClient:         newRedisCounter(t),
+		pdfRateLimitPerHour: 10,
+	}
+	resume := seedResume(t, h, database.Resume{UserID: 1, Title: "r"})
+
+	first := downloadResumeRequest(h, 1, resume.ID, "corr-1")
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("first: expected 202 got %d body=%s", first.Code, first.Body.String())
+	}
+	second := downloadResumeRequest(h, 1, resume.ID, "corr-2")
+	if second.Code != http.StatusAccepted {
+		t.Fatalf("second: expected 202 got %d body=%s", second.Code, second.Body.String())
+	}
+	if len(enqueuer.tasks) != 1 {
+		t.Fatalf("duplicate request must not enqueue, got %d tasks", len(enqueuer.tasks))
+	}
+	firstTaskID, _ := decodeJSONBody(t, first)["task_id"].(string)
+	if firstTaskID == "" || inflight.entries[resume.ID].TaskID != firstTaskID {
+		t.Fatalf("claim must carry the enqueued task id: resp=%q marker=%+v", firstTaskID, inflight.entries[resume.ID])
+	}
+	body := decodeJSONBody(t, second)
+	if body["deduplicated"] != true || body["task_id"] != firstTaskID || body["correlation_id"] != "corr-1" {
+		t.Fatalf("duplicate response must point at the in-flight task: %v", body)
+	}
+
+	// 其他任务的释放不能删除标记；Worker 按本任务 id 释放后，新的请求重新入队。
+	if err := inflight.Release(context.Background(), resume.ID, "other-task"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, ok := inflight.entries[resume.ID]; !ok {
+		t.Fatalf("release with a foreign task id must keep the marker")
+	}
+	if err := inflight.Release(context.Background(), resume.ID, firstTaskID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if w := downloadResumeRequest(h, 1, resume.ID, "corr-3"); w.Code != http.StatusAccepted || len(enqueuer.tasks) != 2 {
+		t.Fatalf("after release: code=%d tasks=%d", w.Code, len(enqueuer.tasks))
+	}
+
+	// 内容变化后不应合并到旧任务。
+	if err := h.db.Model(&database.Resume{}).Where("id = ?", resume.ID).Update("content", datatypes.JSON(`{"items":[1]}`)).Error; err != nil {
+		t.Fatalf("update content: %v", err)
+	}
+	if w := downloadResumeRequest(h, 1, resume.ID, "corr-4"); w.Code != http.StatusAccepted || len(enqueuer.tasks) != 3 {
+		t.Fatalf("after edit: code=%d tasks=%d", w.Code, len(enqueuer.tasks))
+	}
+}
+
+func TestDownloadResume_WorkerReleaseBeforeEnqueueReturns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	inflight := newMemoryPDFInflightStore()
+	enqueuer := &fakeEnqueuer{}
+	h := &ResumeHandler{
+		db:                  newTestDB(t),
+		asynqClient:         enqueuer,
+		pdfInflight:         inflight,
+		redisClient:         newRedisCounter(t),
+		pdfRateLimitPerHour: 10,
+	}
+	resume := seedResume(t, h, database.Resume{UserID: 1, Title: "r"})
+
+	// Worker 在 Enqueue 返回前就完成任务并按自身 task id 释放标记。
+	enqueuer.onEnqueue = func(id string) {
+		if err := inflight.Release(context.Background(), resume.ID, id); err != nil {
+			t.Fatalf("release: %v", err)
+		}
+	}
+	if w := downloadResumeRequest(h, 1, resume.ID, "corr-1"); w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d body=%s", w.Code, w.Body.String())
+	}
+	if existing, ok := inflight.entries[resume.ID]; ok {
+		t.Fatalf("early worker release must not leave a stale marker: %+v", existing)
+	}
+	if w := downloadResumeRequest(h, 1, resume.ID, "corr-2"); w.Code != http.StatusAccepted || len(enqueuer.tasks) != 2 {
+		t.Fatalf("next request must enqueue again: code=%d tasks=%d", w.Code, len(enqueuer.tasks))
+	}
+}
+
+func TestDownloadResume_PDFCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	content := datatypes.JSON(`{"layout_settings":{},"items":[]}`)
+	hash := resumepkg.ContentHash(content)
+
+	cases := []struct {
+		name        string
+		pdfURL      string
+		cachedHash  string
+		wantStatus  int
+		wantEnqueue bool
+	}{
+		{name: "hit", pdfURL: "generated-resumes/1/1/a.pdf", cachedHash: hash, wantStatus: http.StatusOK},
+		{name: "miss without pdf", wantStatus: http.StatusAccepted, wantEnqueue: true},
+		{name: "miss after edit", pdfURL: "generated-resumes/1/1/a.pdf", cachedHash: "stale", wantStatus: http.StatusAccepted, wantEnqueue: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			enqueuer := &fakeEnqueuer{}
+			h := &ResumeHandler{
+				db:                  newTestDB(t),
+				asynqClient:         enqueuer,
+				redisClient:         newRedisCounter(t),
+				pdfRateLimitPerHour: 3,
+			}
+			resume := seedResume(t, h, database.Resume{
+				UserID:         1,
+				Title:          "r",
+				Content:        content,
+				PdfUrl:         tc.pdfURL,
+				PdfContentHash: tc.cachedHash,
+			})
+
+			w := downloadResumeRequest(h, 1, resume.ID, "corr")
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected %d got %d body=%s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if got := len(enqueuer.tasks) > 0; got != tc.wantEnqueue {
+				t.Fatalf("enqueued=%v want %v", got, tc.wantEnqueue)
+			}
+			if !tc.wantEnqueue {
+				return
+			}
+			var payload tasks.PDFGeneratePayload
+			if err := json.Unmarshal(enqueuer.tasks[0].Payload(), &payload); err != nil {
+				t.Fatalf("decode payload: %v", err)
+			}
+			if payload.ContentHash != hash {
+				t.Fatalf("payload hash %q want %q", payload.ContentHash, hash)
+			}
+		})
+	}
+}
+
+func assertContains(t *testing.T, values []string, want string) {
+	t.Helper()
+	for _, v := range values {
+		if v == want {
+			return
+		}
+	}
+	t.Fatalf("%q not found in %v", want, values)
+}
+
+func TestGeneratePreview_EnqueuesPreviewTaskOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enqueuer := &fakeEnqueuer{}
+	inflight := newMemoryPDFInflightStore()
+	h := &ResumeHandler{db: newTestDB(t), asynqClient: enqueuer, previewInflight: inflight}
+	resume := seedResume(t, h, database.Resume{UserID: 4, Title: "r"})
+
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+	generate := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume/"+id+"/generate-preview", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(4))
+		h.GeneratePreview(c)
+		return w
+	}
+	w := generate()
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d %s", w.Code, w.Body.String())
+	}
+	if len(enqueuer.tasks) != 1 || enqueuer.tasks[0].Type() != tasks.TypeResumePreview {
+		t.Fatalf("expected a single resume preview task, got %v", enqueuer.tasks)
+	}
+	var payload tasks.ResumePreviewPayload
+	if err := json.Unmarshal(enqueuer.tasks[0].Payload(), &payload); err != nil || payload.ResumeID != resume.ID || payload.UserID != 4 {
+		t.Fatalf("unexpected payload %+v err=%v", payload, err)
+	}
+
+	// 同一内容的重复请求合并到已入队的任务。
+	taskID, _ := decodeJSONBody(t, w)["task_id"].(string)
+	dup := generate()
+	if body := decodeJSONBody(t, dup); dup.Code != http.StatusAccepted || body["deduplicated"] != true || body["task_id"] != taskID {
+		t.Fatalf("duplicate preview request must be merged: code=%d body=%v", dup.Code, body)
+	}
+	if len(enqueuer.tasks) != 1 {
+		t.Fatalf("duplicate preview request must not enqueue, got %d tasks", len(enqueuer.tasks))
+	}
+
+	// 内容变化后重新入队。
+	if err := h.db.Model(&database.Resume{}).Where("id = ?", resume.ID).Update("content", datatypes.JSON(`{"items":[1]}`)).Error; err != nil {
+		t.Fatalf("update content: %v", err)
+	}
+	if w := generate(); w.Code != http.StatusAccepted || len(enqueuer.tasks) != 2 {
+		t.Fatalf("after edit: code=%d tasks=%d", w.Code, len(enqueuer.tasks))
+	}
+
+	// 他人的简历返回 404，不入队。
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume/"+id+"/generate-preview", nil)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set("userID", uint(5))
+	h.GeneratePreview(c)
+	if len(enqueuer.tasks) != 2 {
+		t.Fatalf("expected no task for foreign resume, got %d", len(enqueuer.tasks))
+	}
+}
+
+func TestValidatePrint_ReportsRemovedImages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), storage: newFakeStorage()}
+	resume := seedResume(t, h, database.Resume{
+		UserID: 6,
+		Title:  "r",
+		Content: datatypes.JSON(`{"layout_settings":{},"items":[
+			{"id":"t1","type":"text","content":"hello"},
+			{"id":"i1","type":"image","content":"user-assets/7/foreign.png"}
+		]}`),
+	})
+
+	validate := func(userID uint) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		id := strconv.FormatUint(uint64(resume.ID), 10)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/"+id+"/validate-print", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", userID)
+		h.ValidatePrint(c)
+		return w
+	}
+
+	w := validate(6)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		OK           bool               `json:"ok"`
+		Warnings     []PrintWarning     `json:"warnings"`
+		RemovedItems []removedPrintItem `json:"removed_items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.OK || len(body.Warnings) != 1 || len(body.RemovedItems) != 1 {
+		t.Fatalf("unexpected validation result %+v", body)
+	}
+	assertContains(t, body.Warnings[0].MissingKeys, "user-assets/7/foreign.png")
+	if body.RemovedItems[0].ItemID != "i1" {
+		t.Fatalf("unexpected removed item %+v", body.RemovedItems[0])
+	}
+
+	if w := validate(7); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for foreign resume, got %d", w.Code)
+	}
+}
+
+func TestGetPrintResumeData_RejectsOwnerMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), storage: newFakeStorage()}
+	resume := seedResume(t, h, database.Resume{
+		UserID:  8,
+		Title:   "r",
+		Content: datatypes.JSON(`{"layout_settings":{},"items":[]}`),
+	})
+
+	printData := func(owner string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		id := strconv.FormatUint(uint64(resume.ID), 10)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/print/"+id, nil)
+		if owner != "" {
+			c.Request.Header.Set(printOwnerHeader, owner)
+		}
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		h.GetPrintResumeData(c)
+		return w.Code
+	}
+
+	if code := printData("8"); code != http.StatusOK {
+		t.Fatalf("expected 200 for matching owner, got %d", code)
+	}
+	if code := printData("9"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for mismatched owner, got %d", code)
+	}
+	if code := printData(""); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without owner header, got %d", code)
+	}
+}
+
+func TestGetResume_ConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	resume := seedResume(t, h, database.Resume{UserID: 10, Title: "r"})
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/"+id, nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(10))
+		h.GetResume(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("expected 200 with etag and no-cache, got %d headers=%v", first.Code, first.Header())
+	}
+
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected 304 without body, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get(`"other", ` + strings.TrimPrefix(etag, "W/")); w.Code != http.StatusNotModified {
+		t.Fatalf("expected weak comparison to match strong form, got %d", w.Code)
+	}
+
+	if err := h.db.Model(&database.Resume{}).Where("id = ?", resume.ID).
+		Update("updated_at", resume.UpdatedAt.Add(time.Second)).Error; err != nil {
+		t.Fatalf("touch resume: %v", err)
+	}
+	if w := get(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected fresh 200 after update, got %d etag=%s", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestResumeSave_RejectsOutOfGridItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), grid: resumepkg.GridLimits{MaxColumns: 24, MaxRows: 100}}
+	resume := seedResume(t, h, database.Resume{UserID: 12, Title: "r", Content: datatypes.JSON(`{"items":[]}`)})
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+
+	send := func(method, path, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(12))
+		handle(c)
+		return w
+	}
+
+	outOfBounds := []string{
+		`{"title":"x","content":{"items":[{"id":"a","layout":{"x":20,"y":0,"w":6,"h":2}}]}}`,
+		`{"title":"x","content":{"items":[{"id":"a","layout":{"x":0,"y":99,"w":4,"h":2}}]}}`,
+		`{"title":"x","content":{"items":[{"id":"a","layout":{"x":-1,"y":0,"w":4,"h":2}}]}}`,
+		`{"title":"x","content":{"layout_settings":{"columns":48},"items":[]}}`,
+	}
+	for _, body := range outOfBounds {
+		if w := send(http.MethodPost, "/v1/resume", body, h.CreateResume); w.Code != http.StatusBadRequest || errorCodeOf(t, w) != errcode.InvalidRequest {
+			t.Fatalf("create %s: expected 400 got %d %s", body, w.Code, w.Body.String())
+		}
+		if w := send(http.MethodPut, "/v1/resume/"+id, body, h.UpdateResume); w.Code != http.StatusBadRequest {
+			t.Fatalf("update %s: expected 400 got %d %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	var count int64
+	h.db.Model(&database.Resume{}).Where("user_id = ?", 12).Count(&count)
+	var stored database.Resume
+	if err := h.db.First(&stored, resume.ID).Error; err != nil {
+		t.Fatalf("reload resume: %v", err)
+	}
+	if count != 1 || string(stored.Content) != `{"items":[]}` {
+		t.Fatalf("rejected saves must not write, count=%d content=%s", count, stored.Content)
+	}
+
+	inBounds := `{"title":"ok","content":{"items":[{"id":"a","layout":{"x":20,"y":98,"w":4,"h":2}}]}}`
+	if w := send(http.MethodPost, "/v1/resume", inBounds, h.CreateResume); w.Code != http.StatusCreated {
+		t.Fatalf("create in-bounds: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+	if w := send(http.MethodPut, "/v1/resume/"+id, inBounds, h.UpdateResume); w.Code != http.StatusOK {
+		t.Fatalf("update in-bounds: expected 200 got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResumeTags_CreateFilterAndCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	if err := h.db.Create(&database.User{Model: gorm.Model{ID: 11}, Username: "u11"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("userID", uint(11))
+		h.CreateResume(c)
+		return w
+	}
+
+	if w := create(`{"title":"a","content":{},"tags":["Backend"," go ","backend"]}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d %s", w.Code, w.Body.String())
+	} else {
+		var resp resumeResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Tags) != 2 || resp.Tags[0] != "backend" || resp.Tags[1] != "go" {
+			t.Fatalf("expected normalized tags, got %v", resp.Tags)
+		}
+	}
+	if w := create(`{"title":"b","content":{},"tags":["backend"]}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", w.Code)
+	}
+	if w := create(`{"title":"c","content":{},"tags":["` + strings.Repeat("x", maxResumeTagLength+1) + `"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for overlong tag, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume?tag=GO", nil)
+	c.Set("userID", uint(11))
+	h.ListResumes(c)
+	var items []resumeListItem
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil || len(items) != 1 || items[0].Title != "a" {
+		t.Fatalf("expected only resume a for tag go, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/tags", nil)
+	c.Set("userID", uint(11))
+	h.ListTags(c)
+	var counts []resumeTagCount
+	if err := json.Unmarshal(w.Body.Bytes(), &counts); err != nil {
+		t.Fatalf("decode tags: %v", err)
+	}
+	if len(counts) != 2 || counts[0] != (resumeTagCount{Tag: "backend", Count: 2}) || counts[1] != (resumeTagCount{Tag: "go", Count: 1}) {
+		t.Fatalf("unexpected tag counts %+v", counts)
+	}
+}
+
+func TestCreateResume_DuplicateTitleGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), rejectDuplicateTitle: true}
+	existing := seedResume(t, h, database.Resume{UserID: 12, Title: "我的第一份简历"})
+
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("userID", uint(12))
+		h.CreateResume(c)
+		return w
+	}
+
+	w := create(`{"title":"我的第一份简历","content":{}}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 got %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Error      errcode.Detail `json:"error"`
+		ExistingID uint           `json:"existing_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Error.Code != errcode.DuplicateTitle || body.ExistingID != existing.ID {
+		t.Fatalf("unexpected conflict body %+v", body)
+	}
+
+	if w := create(`{"title":"我的第一份简历","content":{},"allow_duplicate_title":true}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected override to create, got %d", w.Code)
+	}
+
+	h.rejectDuplicateTitle = false
+	if w := create(`{"title":"我的第一份简历","content":{}}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected guard to be off by default, got %d", w.Code)
+	}
+}
+
+// failActiveResumeUpdates 让写 users.active_resume_id 的 UPDATE 失败，模拟标记当前简历这一步出错。
+func failActiveResumeUpdates(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Callback().Update().Before("gorm:update").Register("test:fail_active_resume", func(tx *gorm.DB) {
+		if updates, ok := tx.Statement.Dest.(map[string]any); ok {
+			if _, ok := updates["active_resume_id"]; ok {
+				tx.AddError(errors.New("active resume update failed"))
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+}
+
+func TestResumeWrites_RollBackWhenActiveResumeUpdateFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), storage: newFakeStorage()}
+	existing := seedResume(t, h, database.Resume{UserID: 21, Title: "original"})
+	failActiveResumeUpdates(t, h.db)
+
+	send := func(method, path, id, body string, handle gin.HandlerFunc) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if id != "" {
+			c.Params = gin.Params{{Key: "id", Value: id}}
+		}
+		c.Set("userID", uint(21))
+		handle(c)
+		return c.Writer.Status()
+	}
+	id := strconv.FormatUint(uint64(existing.ID), 10)
+
+	if code := send(http.MethodPost, "/v1/resume", "", `{"title":"new","content":{},"tags":["go"]}`, h.CreateResume); code != http.StatusInternalServerError {
+		t.Fatalf("create: expected 500 got %d", code)
+	}
+	var count int64
+	h.db.Model(&database.Resume{}).Where("user_id = ?", 21).Count(&count)
+	if count != 1 {
+		t.Fatalf("create should roll back, got %d resumes", count)
+	}
+	h.db.Model(&database.ResumeTag{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("create should roll back tags, got %d", count)
+	}
+
+	if code := send(http.MethodPut, "/v1/resume/"+id, id, `{"title":"renamed","content":{}}`, h.UpdateResume); code != http.StatusInternalServerError {
+		t.Fatalf("update: expected 500 got %d", code)
+	}
+	var stored database.Resume
+	if err := h.db.First(&stored, existing.ID).Error; err != nil {
+		t.Fatalf("reload resume: %v", err)
+	}
+	if stored.Title != "original" {
+		t.Fatalf("update should roll back, got title %q", stored.Title)
+	}
+
+	if code := send(http.MethodDelete, "/v1/resume/"+id, id, "", h.DeleteResume); code != http.StatusInternalServerError {
+		t.Fatalf("delete: expected 500 got %d", code)
+	}
+	if err := h.db.First(&stored, existing.ID).Error; err != nil {
+		t.Fatalf("delete should roll back: %v", err)
+	}
+}
+
+func TestResumeWrites_MaintainActiveResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), storage: newFakeStorage()}
+	older := seedResume(t, h, database.Resume{UserID: 22, Title: "older"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume", strings.NewReader(`{"title":"newer","content":{}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", uint(22))
+	h.CreateResume(c)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201 got %d %s", w.Code, w.Body.String())
+	}
+	created := decodeJSONBody(t, w)
+
+	activeID := func() *uint {
+		var user database.User
+		if err := h.db.First(&user, 22).Error; err != nil {
+			t.Fatalf("load user: %v", err)
+		}
+		return user.ActiveResumeID
+	}
+	if got := activeID(); got == nil || float64(*got) != created["id"].(float64) {
+		t.Fatalf("expected created resume to be active, got %v", got)
+	}
+
+	if code := deleteResumeRequest(h, 22, uint(created["id"].(float64))); code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204 got %d", code)
+	}
+	if got := activeID(); got == nil || *got != older.ID {
+		t.Fatalf("expected fallback to older resume, got %v", got)
+	}
+}
+
+func TestResumeDraft_SaveGetAndPromote(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	resume := seedResume(t, h, database.Resume{UserID: 23, Title: "r", Content: datatypes.JSON(`{"items":[]}`)})
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+
+	send := func(method, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/v1/resume/"+id+"/draft", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(23))
+		handle(c)
+		return w
+	}
+
+	if w := send(http.MethodGet, "", h.GetDraft); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before any draft, got %d", w.Code)
+	}
+	if w := send(http.MethodPatch, `{"content":{"items":[{"id":"a"}]}}`, h.SaveDraft); w.Code != http.StatusOK {
+		t.Fatalf("save draft: expected 200 got %d %s", w.Code, w.Body.String())
+	}
+
+	var stored database.Resume
+	if err := h.db.First(&stored, resume.ID).Error; err != nil {
+		t.Fatalf("reload resume: %v", err)
+	}
+	if string(stored.Content) != `{"items":[]}` || !stored.UpdatedAt.Equal(resume.UpdatedAt) {
+		t.Fatalf("draft save must not touch content or updated_at: %s %v", stored.Content, stored.UpdatedAt)
+	}
+	if resumeETag(stored) == resumeETag(resume) {
+		t.Fatal("expected etag to change once a draft exists")
+	}
+
+	w := send(http.MethodGet, "", h.GetDraft)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get draft: expected 200 got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if items := body["content"].(map[string]any)["items"].([]any); len(items) != 1 {
+		t.Fatalf("unexpected draft content %v", body["content"])
+	}
+
+	if w := send(http.MethodPut, `{"title":"r","content":{"items":[{"id":"a"}]}}`, h.UpdateResume); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200 got %d %s", w.Code, w.Body.String())
+	} else if decodeJSONBody(t, w)["has_draft"] != false {
+		t.Fatal("expected explicit save to clear the draft")
+	}
+	if w := send(http.MethodGet, "", h.GetDraft); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after promote, got %d", w.Code)
+	}
+}
+
+func TestResumeEvents_RecordedAndListed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	if err := h.db.Create(&database.User{Model: gorm.Model{ID: 24}, Username: "user-24"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	send := func(method, path, id, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if id != "" {
+			c.Params = gin.Params{{Key: "id", Value: id}}
+		}
+		c.Set("userID", uint(24))
+		handle(c)
+		return w
+	}
+
+	w := send(http.MethodPost, "/v1/resume", "", `{"title":"r","content":{}}`, h.CreateResume)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201 got %d", w.Code)
+	}
+	id := strconv.FormatUint(uint64(decodeJSONBody(t, w)["id"].(float64)), 10)
+	if w := send(http.MethodPut, "/v1/resume/"+id, id, `{"title":"r2","content":{}}`, h.UpdateResume); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200 got %d", w.Code)
+	}
+	resumeID, _ := strconv.ParseUint(id, 10, 64)
+	if err := database.RecordResumeEvent(h.db, uint(resumeID), 24, database.ResumeEventRenderFailed, strings.Repeat("c", 100), "boom"); err != nil {
+		t.Fatalf("record event: %v", err)
+	}
+
+	list := func(query string) map[string]any {
+		w := send(http.MethodGet, "/v1/resume/"+id+"/events"+query, id, "", h.ListResumeEvents)
+		if w.Code != http.StatusOK {
+			t.Fatalf("list events: expected 200 got %d", w.Code)
+		}
+		return decodeJSONBody(t, w)
+	}
+
+	body := list("")
+	items := body["items"].([]any)
+	var types []string
+	for _, item := range items {
+		types = append(types, item.(map[string]any)["type"].(string))
+	}
+	if strings.Join(types, ",") != "render_failed,updated,created" {
+		t.Fatalf("unexpected timeline %v", types)
+	}
+	if got := items[0].(map[string]any); got["detail"] != "boom" || len(got["correlation_id"].(string)) != 64 {
+		t.Fatalf("unexpected failure event %v", got)
+	}
+	if body["next_cursor"] != nil {
+		t.Fatalf("expected single page, got cursor %v", body["next_cursor"])
+	}
+
+	page := list("?limit=2")
+	cursor, ok := page["next_cursor"].(string)
+	if !ok || len(page["items"].([]any)) != 2 {
+		t.Fatalf("expected a second page, got %v", page)
+	}
+	rest := list("?cursor=" + cursor)["items"].([]any)
+	if len(rest) != 1 || rest[0].(map[string]any)["type"] != "created" {
+		t.Fatalf("unexpected second page %v", rest)
+	}
+}
+
+func TestActiveResume_ActivateAndRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	first := seedResume(t, h, database.Resume{UserID: 13, Title: "first"})
+	second := database.Resume{UserID: 13, Title: "second", Content: datatypes.JSON(`{}`)}
+	if err := h.db.Create(&second).Error; err != nil {
+		t.Fatalf("seed second resume: %v", err)
+	}
+	other := seedResume(t, h, database.Resume{UserID: 14, Title: "other"})
+
+	activate := func(resumeID uint) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		id := strconv.FormatUint(uint64(resumeID), 10)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume/"+id+"/activate", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(13))
+		h.ActivateResume(c)
+		return w
+	}
+	active := func() any {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/active", nil)
+		c.Set("userID", uint(13))
+		h.GetActiveResume(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("get active: expected 200 got %d", w.Code)
+		}
+		return decodeJSONBody(t, w)["active_resume_id"]
+	}
+
+	if got := active(); got != nil {
+		t.Fatalf("expected no active resume, got %v", got)
+	}
+	if w := activate(second.ID); w.Code != http.StatusOK || decodeJSONBody(t, w)["active_resume_id"] != float64(second.ID) {
+		t.Fatalf("activate: unexpected %d %s", w.Code, w.Body.String())
+	}
+	if got := active(); got != float64(second.ID) {
+		t.Fatalf("expected active %d, got %v", second.ID, got)
+	}
+	if w := activate(other.ID); w.Code != http.StatusNotFound {
+		t.Fatalf("activate foreign resume: expected 404 got %d", w.Code)
+	}
+	if w := activate(first.ID); w.Code != http.StatusOK {
+		t.Fatalf("activate first: expected 200 got %d", w.Code)
+	}
+
+	// 当前简历被删除（绕过 DeleteResume 的重新指派）时读到 null。
+	if err := h.db.Unscoped().Delete(&database.Resume{}, first.ID).Error; err != nil {
+		t.Fatalf("delete resume: %v", err)
+	}
+	if got := active(); got != nil {
+		t.Fatalf("expected null for deleted active resume, got %v", got)
+	}
+}
+
+func TestDiffResume_ComparesSavedContentWithPayload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t)}
+	resume := seedResume(t, h, database.Resume{UserID: 15, Title: "r", Content: datatypes.JSON(
+		`{"layout_settings":{"columns":24},"items":[{"id":"a","type":"text","content":"old"},{"id":"b","type":"divider"}]}`)})
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+
+	diff := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume/"+id+"/diff", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(15))
+		h.DiffResume(c)
+		return w
+	}
+
+	w := diff(`{"content":{"layout_settings":{"columns":12},"items":[{"id":"a","type":"text","content":"new"},{"id":"c","type":"text"}]}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+	}
+	var got resumepkg.ContentDiff
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode diff: %v", err)
+	}
+	if len(got.Added) != 1 || got.Added[0].ID != "c" || len(got.Removed) != 1 || got.Removed[0].ID != "b" {
+		t.Fatalf("unexpected added/removed %+v", got)
+	}
+	if len(got.Modified) != 1 || got.Modified[0].Changes[0].Field != "content" {
+		t.Fatalf("unexpected modified %+v", got.Modified)
+	}
+	if len(got.LayoutSettings) != 1 || got.LayoutSettings[0].Field != "columns" {
+		t.Fatalf("unexpected layout changes %+v", got.LayoutSettings)
+	}
+
+	if w := diff(`{"content":"not an object"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid content, got %d", w.Code)
+	}
+}
+
+func TestExportResume_HTML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ResumeHandler{db: newTestDB(t), storage: newFakeStorage()}
+	resume := seedResume(t, h, database.Resume{UserID: 16, Title: "r", Content: datatypes.JSON(`{
+		"layout_settings":{"columns":24,"custom_fonts":[{"family":"Brand","object_key":"user-assets/16/font.woff2"}]},
+		"items":[{"id":"t","type":"text","content":"<p>Hello<script>x()</script></p>","layout":{"x":0,"y":0,"w":24,"h":4}}]}`)})
+	id := strconv.FormatUint(uint64(resume.ID), 10)
+
+	export := func(format string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/resume/"+id+"/export?format="+format, nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("userID", uint(16))
+		h.ExportResume(c)
+		return w
+	}
+
+	w := export("html")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="Resume-`+id+`.html"` {
+		t.Fatalf("unexpected content disposition %q", cd)
+	}
+	body := w.Body.String()
+	// 富文本经打印数据清洗；字体读取失败时跳过，不影响导出。
+	if !strings.Contains(body, "<p>Hello</p>") || strings.Contains(body, "<script>") || strings.Contains(body, "@font-face") {
+		t.Fatalf("unexpected export body:\n%s", body)
+	}
+
+	if w := export("docx"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unsupported format, got %d", w.Code)
+	}
+}
+
+func downloadAllResumesRequest(h *ResumeHandler, userID uint) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/resume/download-all", nil)
+	c.Set("userID", userID)
+	c.Set("correlationID", "corr-bundle")
+	h.DownloadAllResumes(c)
+	return w
+}
+
+func TestDownloadAllResumes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enqueuer := &fakeEnqueuer{}
+	inflight := newMemoryPDFInflightStore()
+	h := &ResumeHandler{
+		db:                  newTestDB(t),
+		asynqClient:         enqueuer,
+		pdfBundleInflight:   inflight,
+		redisClient:         newRedisCounter(t),
+		pdfRateLimitPerHour: 3,
+	}
+
+	if w := downloadAllResumesRequest(h, 1); w.Code != http.StatusNotFound {
+		t.Fatalf("no resumes: expected 404 got %d body=%s", w.Code, w.Body.String())
+	}
+
+	content := datatypes.JSON(`{"layout_settings":{},"items":[]}`)
+	stale := seedResume(t, h, database.Resume{UserID: 1, Title: "a", Content: content})
+	cached := database.Resume{
+		UserID:         1,
+		Title:          "b",
+		Content:        content,
+		PdfUrl:         "generated-resumes/1/2/b.pdf",
+		PdfContentHash: resumepkg.ContentHash(content),
+	}
+	if err := h.db.Create(&cached).Error; err != nil {
+		t.Fatalf("seed cached resume: %v", err)
+	}
+
+	w := downloadAllResumesRequest(h, 1)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d body=%s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if body["resume_count"] != float64(2) || body["render_count"] != float64(1) || body["correlation_id"] != "corr-bundle" {
+		t.Fatalf("unexpected response: %v", body)
+	}
+	if len(enqueuer.tasks) != 1 || enqueuer.tasks[0].Type() != tasks.TypePDFBundle {
+		t.Fatalf("expected one bundle task, got %d", len(enqueuer.tasks))
+	}
+	var payload tasks.PDFBundlePayload
+	if err := json.Unmarshal(enqueuer.tasks[0].Payload(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.UserID != 1 || len(payload.ResumeIDs) != 2 || payload.ResumeIDs[0] != stale.ID || payload.ResumeIDs[1] != cached.ID {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	// 打包进行中：重复请求返回 409 且不入队。
+	taskID, _ := body["task_id"].(string)
+	if taskID == "" || inflight.entries[1].TaskID != taskID {
+		t.Fatalf("claim must carry the enqueued task id: resp=%q marker=%+v", taskID, inflight.entries[1])
+	}
+	if w := downloadAllResumesRequest(h, 1); w.Code != http.StatusConflict || len(enqueuer.tasks) != 1 {
+		t.Fatalf("in progress: code=%d tasks=%d", w.Code, len(enqueuer.tasks))
+	}
+
+	// Worker 结束后释放标记，新的请求使用新的 task id 入队（归档的旧任务不再占用 id）。
+	if err := inflight.Release(context.Background(), 1, taskID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	w = downloadAllResumesRequest(h, 1)
+	if w.Code != http.StatusAccepted || len(enqueuer.tasks) != 2 {
+		t.Fatalf("after release: code=%d tasks=%d body=%s", w.Code, len(enqueuer.tasks), w.Body.String())
+	}
+	if next, _ := decodeJSONBody(t, w)["task_id"].(string); next == "" || next == taskID {
+		t.Fatalf("expected a fresh task id, got %q (previous %q)", next, taskID)
+	}
+}
+
+func TestDownloadAllResumes_EnqueueFailureReleasesClaim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	inflight := newMemoryPDFInflightStore()
+	h := &ResumeHandler{
+		db:                  newTestDB(t),
+		asynqClient:         &fakeEnqueuer{err: errors.New("redis down")},
+		pdfBundleInflight:   inflight,
+		redisClient:         newRedisCounter(t),
+		pdfRateLimitPerHour: 3,
+	}
+	seedResume(t, h, database.Resume{UserID: 1, Title: "a", Content: datatypes.JSON(`{"items":[]}`)})
+
+	if w := downloadAllResumesRequest(h, 1); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 got %d body=%s", w.Code, w.Body.String())
+	}
+	if _, ok := inflight.entries[1]; ok {
+		t.Fatalf("failed enqueue must release the bundle claim")
+	}
+}
+
+func TestSanitizeDownloadFilename(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", "Resume-7.pdf"},
+		{"  ", "Resume-7.pdf"},
+		{"../..", "Resume-7.pdf"},
+		{`C:\tmp\cv.PDF`, "cv.PDF"},
+		{"后端\r\n简历", "后端简历.pdf"},
+		{`my "cv"`, "my cv.pdf"},
+		{strings.Repeat("简", 200), strings.Repeat("简", 160) + ".pdf"},
+	}
+	for _, tc := range cases {
+		if got := sanitizeDownloadFilename(tc.in, 7); got != tc.want {
+			t.Errorf("sanitizeDownloadFilename(%q) = %q want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+)
+
+// 简历标签限制：单份简历最多 maxResumeTags 个，单个标签 1..maxResumeTagLength 个字符。
+const (
+	maxResumeTags      = 10
+	maxResumeTagLength = 32
+)
+
+var errInvalidResumeTags = errors.New("invalid resume tags")
+
+// normalizeResumeTags 去除首尾空白并统一为小写，按出现顺序去重；空标签、超长、含控制字符或超出数量上限时报错。
+func normalizeResumeTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, value := range raw {
+		tag := strings.ToLower(strings.TrimSpace(value))
+		if tag == "" || utf8.RuneCountInString(tag) > maxResumeTagLength || strings.ContainsFunc(tag, unicode.IsControl) {
+			return nil, fmt.Errorf("%w: each tag must be 1-%d characters", errInvalidResumeTags, maxResumeTagLength)
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxResumeTags {
+		return nil, fmt.Errorf("%w: at most %d tags per resume", errInvalidResumeTags, maxResumeTags)
+	}
+	return tags, nil
+}
+
+// replaceResumeTags 以 tags 整体覆盖简历标签，需在事务内调用。
+func replaceResumeTags(tx *gorm.DB, userID, resumeID uint, tags []string) error {
+	if err := tx.Where("resume_id = ?", resumeID).Delete(&database.ResumeTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]database.ResumeTag, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, database.ResumeTag{ResumeID: resumeID, UserID: userID, Tag: tag})
+	}
+	return tx.Create(&rows).Error
+}
+
+// loadResumeTags 批量读取简历标签，返回 resumeID -> 标签（按写入顺序）。
+func (h *ResumeHandler) loadResumeTags(ctx context.Context, resumeIDs ...uint) (map[uint][]string, error) {
+	result := make(map[uint][]string, len(resumeIDs))
+	if len(resumeIDs) == 0 {
+		return result, nil
+	}
+	var rows []database.ResumeTag
+	if err := h.db.WithContext(ctx).
+		Where("resume_id IN ?", resumeIDs).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.ResumeID] = append(result[row.ResumeID], row.Tag)
+	}
+	return result, nil
+}
+
+// resumeResponseWithTags 在简历详情中附带标签与按需签发的缩略图链接。
+func (h *ResumeHandler) resumeResponseWithTags(c *gin.Context, resume database.Resume) (resumeResponse, error) {
+	resp := h.newResumeResponse(c, resume)
+	tags, err := h.loadResumeTags(c.Request.Context(), resume.ID)
+	if err != nil {
+		return resp, err
+	}
+	if t := tags[resume.ID]; t != nil {
+		resp.Tags = t
+	}
+	return resp, nil
+}
+
+type resumeTagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// ListTags 返回当前用户简历上出现过的标签及使用次数，按次数倒序、标签名升序。
+func (h *ResumeHandler) ListTags(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	counts := make([]resumeTagCount, 0)
+	if err := h.db.WithContext(c.Request.Context()).
+		Model(&database.ResumeTag{}).
+		Select("resume_tags.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN resumes ON resumes.id = resume_tags.resume_id AND resumes.deleted_at IS NULL").
+		Where("resume_tags.user_id = ?", userID).
+		Group("resume_tags.tag").
+		Order("count DESC, tag ASC").
+		Scan(&counts).Error; err != nil {
+		Internal(c, "failed to list resume tags")
+		return
+	}
+	c.JSON(http.StatusOK, counts)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,62 +12,116 @@ import (
 
 	"phResume/internal/api/middleware"
 	"phResume/internal/auth"
+	resumepkg "phResume/internal/resume"
 	"phResume/internal/storage"
 )
 
+// RegisterFallbackHandlers 为未匹配的路径与方法返回与 API 一致的 JSON 错误，而非 Gin 默认的纯文本。
+func RegisterFallbackHandlers(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(c *gin.Context) {
+		NotFound(c, "not found")
+	})
+	router.NoMethod(func(c *gin.Context) {
+		Error(c, http.StatusMethodNotAllowed, "method not allowed")
+	})
+}
+
+// RouteOptions 汇总 RegisterRoutes 的配置项；新增配置在此（或 Auth / Asset 子结构中）加字段，不再扩充函数参数。
+type RouteOptions struct {
+	// InternalAPISecret 为 Worker 调用内部打印数据接口时携带的 X-Internal-Secret。
+	InternalAPISecret string
+	// AllowedOrigins 为 WebSocket 握手允许的 Origin 列表。
+	AllowedOrigins []string
+	// MaxJSONBodyBytes 为 JSON 请求体上限（上传接口除外）。
+	MaxJSONBodyBytes int
+	// PasswordGateDBCheck 开启后改密闸门按用户 ID 实时复核 must_change_password。
+	PasswordGateDBCheck bool
+	// PrintStrictImageMIME 开启后打印数据校验图片扩展名与 content-type 一致性。
+	PrintStrictImageMIME bool
+	// PreviewURLTTL 为读取简历/模板时签发的缩略图链接有效期。
+	PreviewURLTTL time.Duration
+	// GridLimits 为简历/模板保存时校验、打印时收敛的网格约束。
+	GridLimits resumepkg.GridLimits
+
+	MaxResumes          int
+	PdfRateLimitPerHour int
+	PdfDownloadTokenTTL time.Duration
+	// RejectDuplicateResumeTitle 开启后创建同名简历返回 409。
+	RejectDuplicateResumeTitle bool
+	// DefaultResumeContent 为用户尚无简历时返回的起始内容；为空时按请求语言使用内置起始简历。
+	DefaultResumeContent []byte
+
+	MaxTemplates          int
+	TemplateMaxBytes      int
+	TemplateMaxItems      int
+	TemplatePublishReview bool
+
+	Auth  AuthHandlerOptions
+	Asset AssetHandlerOptions
+}
+
+// RouteDeps 汇总 RegisterRoutes 依赖的外部客户端，由 cmd/api 初始化后传入。
+type RouteDeps struct {
+	DB           *gorm.DB
+	Asynq        *asynq.Client
+	AuthService  *auth.AuthService
+	Redis        redis.UniversalClient
+	Logger       *slog.Logger
+	Storage      *storage.Client
+	VirusScanner VirusScanner
+}
+
 // RegisterRoutes 注册 API 路由，不包含 /api 前缀。
-func RegisterRoutes(
-	router *gin.Engine,
-	db *gorm.DB,
-	asynqClient *asynq.Client,
-	authService *auth.AuthService,
-	redisClient *redis.Client,
-	logger *slog.Logger,
-	storageClient *storage.Client,
-	internalAPISecret string,
-	clamdAddr string,
-	maxResumes int,
-	maxTemplates int,
-	maxAssetsPerUser int,
-	maxUploadsPerDay int,
-	allowedOrigins []string,
-	loginRateLimitPerHour int,
-	loginLockThreshold int,
-	loginLockTTL time.Duration,
-	pdfRateLimitPerHour int,
-	pdfDownloadTokenTTL time.Duration,
-	uploadMaxBytes int,
-	uploadMIMEWhitelist []string,
-	cookieDomain string,
-) {
-	resumeHandler := NewResumeHandler(
-		db,
-		asynqClient,
-		storageClient,
-		internalAPISecret,
-		maxResumes,
-		redisClient,
-		pdfRateLimitPerHour,
-		pdfDownloadTokenTTL,
-	)
-	authHandler := NewAuthHandler(
-		db,
-		authService,
-		redisClient,
-		logger,
-		loginRateLimitPerHour,
-		loginLockThreshold,
-		loginLockTTL,
-		cookieDomain,
-	)
-	wsHandler := NewWsHandler(redisClient, authService, logger, allowedOrigins)
-	authMiddleware := middleware.AuthMiddleware(authService)
-	assetHandler := NewAssetHandler(db, storageClient, logger, clamdAddr, redisClient, maxAssetsPerUser, maxUploadsPerDay, uploadMaxBytes, uploadMIMEWhitelist)
-	templateHandler := NewTemplateHandler(db, asynqClient, storageClient, internalAPISecret, maxTemplates)
+func RegisterRoutes(router *gin.Engine, deps RouteDeps, opts RouteOptions) {
+	db, redisClient := deps.DB, deps.Redis
+	resumeHandler := NewResumeHandler(db, deps.Asynq, deps.Storage, redisClient, ResumeHandlerOptions{
+		InternalSecret:       opts.InternalAPISecret,
+		MaxResumes:           opts.MaxResumes,
+		PdfRateLimitPerHour:  opts.PdfRateLimitPerHour,
+		PdfDownloadTokenTTL:  opts.PdfDownloadTokenTTL,
+		StrictImageMIME:      opts.PrintStrictImageMIME,
+		RejectDuplicateTitle: opts.RejectDuplicateResumeTitle,
+		PreviewURLTTL:        opts.PreviewURLTTL,
+		DefaultContent:       opts.DefaultResumeContent,
+		Grid:                 opts.GridLimits,
+	})
+	authHandler := NewAuthHandler(db, deps.AuthService, redisClient, deps.Logger, deps.Storage, deps.Asynq, opts.Auth)
+	wsHandler := NewWsHandler(redisClient, deps.AuthService, deps.Logger, opts.AllowedOrigins)
+	eventsHandler := NewEventsHandler(redisClient)
+	var revokedUsers middleware.RevokedUserLookup
+	if redisClient != nil {
+		revokedUsers = newRevokedUserLookup(redisClient)
+	}
+	authMiddleware := middleware.AuthMiddleware(deps.AuthService, revokedUsers)
+	var mustChangeLookup middleware.MustChangePasswordLookup
+	if opts.PasswordGateDBCheck {
+		mustChangeLookup = newMustChangePasswordLookup(db)
+	}
+	passwordGate := middleware.RequirePasswordChangeCompletedMiddleware(mustChangeLookup)
+	assetHandler := NewAssetHandler(db, deps.Storage, deps.Logger, deps.VirusScanner, redisClient, opts.Asset)
+	templateHandler := NewTemplateHandler(db, deps.Asynq, deps.Storage, redisClient, TemplateHandlerOptions{
+		InternalSecret:  opts.InternalAPISecret,
+		MaxTemplates:    opts.MaxTemplates,
+		StrictImageMIME: opts.PrintStrictImageMIME,
+		ContentLimits: resumepkg.ContentLimits{
+			MaxBytes: opts.TemplateMaxBytes,
+			MaxItems: opts.TemplateMaxItems,
+			Grid:     opts.GridLimits,
+		},
+		PublishReview: opts.TemplatePublishReview,
+		PreviewURLTTL: opts.PreviewURLTTL,
+	})
 
 	v1 := router.Group("/v1")
+	// 上传接口为 multipart，单独受 uploadMaxBytes 约束，不套用 JSON 请求体上限。
+	v1.Use(middleware.BodyLimitMiddleware(int64(opts.MaxJSONBodyBytes), "/v1/assets/upload"))
 	{
 		v1.GET("/ws", wsHandler.HandleConnection)
+		v1.GET("/events", middleware.AccessTokenQueryMiddleware("access_token"), authMiddleware, passwordGate, eventsHandler.Stream)
+		v1.GET("/meta/error-codes", ListErrorCodes)
+		v1.GET("/meta/resume-schema", ResumeContentSchema)
+		v1.GET("/openapi.json", OpenAPISpec)
 
 		authGroup := v1.Group("/auth")
 		{
@@ -75,44 +130,72 @@ func RegisterRoutes(
 			authGroup.POST("/refresh", authHandler.Refresh)
 			authGroup.POST("/logout", authMiddleware, authHandler.Logout)
 			authGroup.POST("/change-password", authMiddleware, authHandler.ChangePassword)
+			authGroup.GET("/me", authMiddleware, authHandler.Me)
+			authGroup.PUT("/username", authMiddleware, passwordGate, authHandler.ChangeUsername)
+			authGroup.DELETE("/account", authMiddleware, authHandler.DeleteAccount)
 		}
 
 		v1.GET("/resume/print/:id", middleware.InternalSecretMiddleware(resumeHandler.internalSecret), resumeHandler.GetPrintResumeData)
 		v1.GET("/templates/print/:id", middleware.InternalSecretMiddleware(templateHandler.internalSecret), templateHandler.GetPrintTemplateData)
+		v1.POST("/resume/:id/cancel-pdf", middleware.InternalSecretMiddleware(resumeHandler.internalSecret), resumeHandler.CancelPDFTasks)
 
 		// PDF 下载中转（不依赖 Authorization Header，依赖短时效一次性 Token）
 		v1.GET("/resume/:id/download-file", resumeHandler.DownloadResumeFile)
 
 		resumeGroup := v1.Group("/resume")
-		resumeGroup.Use(authMiddleware, middleware.RequirePasswordChangeCompletedMiddleware())
+		resumeGroup.Use(authMiddleware, passwordGate)
 		{
 			resumeGroup.GET("", resumeHandler.ListResumes)
 			resumeGroup.GET("/latest", resumeHandler.GetLatestResume)
+			resumeGroup.GET("/active", resumeHandler.GetActiveResume)
+			resumeGroup.GET("/tags", resumeHandler.ListTags)
+			resumeGroup.POST("/download-all", resumeHandler.DownloadAllResumes)
 			resumeGroup.POST("", resumeHandler.CreateResume)
 			resumeGroup.GET("/:id", resumeHandler.GetResume)
 			resumeGroup.PUT("/:id", resumeHandler.UpdateResume)
 			resumeGroup.DELETE("/:id", resumeHandler.DeleteResume)
+			resumeGroup.POST("/:id/activate", resumeHandler.ActivateResume)
+			resumeGroup.GET("/:id/draft", resumeHandler.GetDraft)
+			resumeGroup.PATCH("/:id/draft", resumeHandler.SaveDraft)
+			resumeGroup.GET("/:id/events", resumeHandler.ListResumeEvents)
+			resumeGroup.POST("/:id/diff", resumeHandler.DiffResume)
 			resumeGroup.GET("/:id/download", resumeHandler.DownloadResume)
 			resumeGroup.GET("/:id/download-link", resumeHandler.GetDownloadLink)
+			resumeGroup.GET("/:id/export", resumeHandler.ExportResume)
+			resumeGroup.POST("/:id/generate-preview", resumeHandler.GeneratePreview)
+			resumeGroup.GET("/:id/validate-print", resumeHandler.ValidatePrint)
 		}
 
 		assetGroup := v1.Group("/assets")
-		assetGroup.Use(authMiddleware, middleware.RequirePasswordChangeCompletedMiddleware())
+		assetGroup.Use(authMiddleware, passwordGate)
 		{
 			assetGroup.GET("", assetHandler.ListAssets)
+			assetGroup.GET("/usage", assetHandler.GetUsage)
 			assetGroup.POST("/upload", assetHandler.UploadAsset)
 			assetGroup.GET("/view", assetHandler.GetAssetURL)
 			assetGroup.DELETE("", assetHandler.DeleteAsset)
+			assetGroup.DELETE("/batch", assetHandler.BatchDeleteAssets)
 		}
 
 		templatesGroup := v1.Group("/templates")
-		templatesGroup.Use(authMiddleware, middleware.RequirePasswordChangeCompletedMiddleware())
+		templatesGroup.Use(authMiddleware, passwordGate)
 		{
 			templatesGroup.GET("", templateHandler.ListTemplates)
 			templatesGroup.GET("/:id", templateHandler.GetTemplate)
 			templatesGroup.POST("", templateHandler.CreateTemplate)
+			templatesGroup.PATCH("/:id/visibility", templateHandler.UpdateTemplateVisibility)
 			templatesGroup.POST("/:id/generate-preview", templateHandler.GeneratePreview)
 			templatesGroup.DELETE("/:id", templateHandler.DeleteTemplate)
 		}
+
+		// 管理端接口：要求 access token 的角色声明为 admin（通过 cmd/admin 授予）。
+		adminGroup := v1.Group("/admin")
+		adminGroup.Use(authMiddleware, passwordGate, middleware.RequireAdmin())
+		{
+			adminGroup.GET("/templates/pending", templateHandler.ListPendingTemplates)
+			adminGroup.POST("/templates/:id/approve", templateHandler.ApproveTemplate)
+			adminGroup.POST("/templates/:id/reject", templateHandler.RejectTemplate)
+			adminGroup.POST("/users/:id/unlock", authHandler.UnlockUser)
+		}
 	}
 }
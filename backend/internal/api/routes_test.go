@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+)
+
+// newTestRouter 用零值依赖注册全部路由，仅用于路由表与兜底处理的断言，不会真正处理业务请求。
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterFallbackHandlers(router)
+	RegisterRoutes(router, RouteDeps{Logger: slog.Default()}, RouteOptions{
+		InternalAPISecret: "secret",
+		MaxResumes:        3,
+		MaxTemplates:      2,
+		MaxJSONBodyBytes:  1024 * 1024,
+		GridLimits:        resumepkg.GridLimits{MaxColumns: 24, MaxRows: 1000},
+		Auth: AuthHandlerOptions{
+			RegistrationMode: RegistrationOpen,
+			RefreshBinding:   RefreshBindingOff,
+		},
+		Asset: AssetHandlerOptions{
+			ExtensionCheck: UploadExtensionLenient,
+		},
+	})
+	return router
+}
+
+// assertRoutesRegistered 通过 router.Routes() 枚举已注册路由，防止路由被意外删除。
+func assertRoutesRegistered(t *testing.T, router *gin.Engine, expected []string) {
+	t.Helper()
+	registered := make(map[string]struct{})
+	for _, r := range router.Routes() {
+		registered[r.Method+" "+r.Path] = struct{}{}
+	}
+	for _, route := range expected {
+		if _, ok := registered[route]; !ok {
+			t.Errorf("route %q is not registered", route)
+		}
+	}
+}
+
+func TestRegisterRoutes_ExpectedEndpoints(t *testing.T) {
+	router := newTestRouter(t)
+	assertRoutesRegistered(t, router, []string{
+		"GET /v1/ws",
+		"GET /v1/meta/error-codes",
+		"GET /v1/meta/resume-schema",
+		"GET /v1/openapi.json",
+		"POST /v1/auth/register",
+		"POST /v1/auth/login",
+		"POST /v1/auth/refresh",
+		"POST /v1/auth/logout",
+		"POST /v1/auth/change-password",
+		"GET /v1/auth/me",
+		"PUT /v1/auth/username",
+		"GET /v1/resume/print/:id",
+		"GET /v1/templates/print/:id",
+		"POST /v1/resume/:id/cancel-pdf",
+		"GET /v1/resume/:id/download-file",
+		"GET /v1/resume",
+		"GET /v1/resume/latest",
+		"GET /v1/resume/tags",
+		"POST /v1/resume",
+		"GET /v1/resume/:id",
+		"PUT /v1/resume/:id",
+		"DELETE /v1/resume/:id",
+		"GET /v1/resume/:id/download",
+		"GET /v1/resume/:id/download-link",
+		"GET /v1/assets",
+		"GET /v1/assets/usage",
+		"POST /v1/assets/upload",
+		"GET /v1/assets/view",
+		"DELETE /v1/assets",
+		"DELETE /v1/assets/batch",
+		"GET /v1/templates",
+		"GET /v1/templates/:id",
+		"POST /v1/templates",
+		"PATCH /v1/templates/:id/visibility",
+		"POST /v1/templates/:id/generate-preview",
+		"DELETE /v1/templates/:id",
+		"GET /v1/admin/templates/pending",
+		"POST /v1/admin/templates/:id/approve",
+		"POST /v1/admin/templates/:id/reject",
+		"POST /v1/admin/users/:id/unlock",
+	})
+}
+
+func TestFallbackHandlers_ReturnJSON(t *testing.T) {
+	router := newTestRouter(t)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   int
+		msg    string
+	}{
+		{name: "unknown path", method: http.MethodGet, path: "/v1/does-not-exist", status: http.StatusNotFound, code: errcode.NotFound, msg: "not found"},
+		{name: "wrong method", method: http.MethodPatch, path: "/v1/auth/login", status: http.StatusMethodNotAllowed, code: errcode.MethodNotAllowed, msg: "method not allowed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			if w.Code != tc.status {
+				t.Fatalf("expected %d got %d body=%s", tc.status, w.Code, w.Body.String())
+			}
+			var body struct {
+				Error errcode.Detail `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %q: %v", w.Body.String(), err)
+			}
+			if body.Error.Code != tc.code || body.Error.Message != tc.msg {
+				t.Fatalf("expected error %d %q got %+v", tc.code, tc.msg, body.Error)
+			}
+		})
+	}
+}
+
+func TestListErrorCodes_ReturnsCatalog(t *testing.T) {
+	router := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/error-codes", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Codes map[string]errcode.Entry `json:"codes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Codes) != len(errcode.Catalog()) {
+		t.Fatalf("expected %d codes got %d", len(errcode.Catalog()), len(body.Codes))
+	}
+	if entry := body.Codes["4031"]; entry.Name != "PasswordChangeRequired" {
+		t.Fatalf("unexpected entry for 4031: %+v", entry)
+	}
+}
+
+// TestOpenAPISpec_CoversRegisteredRoutes 保证 OpenAPI 文档与已注册的 /v1 路由一一对应：
+// 新增路由未写入文档、或文档残留已删除的路由都会失败。
+func TestOpenAPISpec_CoversRegisteredRoutes(t *testing.T) {
+	router := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Fatalf("unexpected openapi version %q", spec.OpenAPI)
+	}
+
+	documented := make(map[string]struct{})
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			documented[strings.ToUpper(method)+" "+path] = struct{}{}
+		}
+	}
+
+	registered := make(map[string]struct{})
+	for _, r := range router.Routes() {
+		if !strings.HasPrefix(r.Path, "/v1/") {
+			continue
+		}
+		key := r.Method + " " + ginPathToOpenAPI(r.Path)
+		registered[key] = struct{}{}
+		if _, ok := documented[key]; !ok {
+			t.Errorf("route %s is missing from openapi.json", key)
+		}
+	}
+	for key := range documented {
+		if _, ok := registered[key]; !ok {
+			t.Errorf("openapi.json documents %s but it is not registered", key)
+		}
+	}
+}
+
+// ginPathToOpenAPI 将 gin 的 :param 路径参数转换为 OpenAPI 的 {param}。
+func ginPathToOpenAPI(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			parts[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -7,14 +7,19 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"phResume/internal/api/middleware"
 	"phResume/internal/database"
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
 	"phResume/internal/storage"
 	"phResume/internal/tasks"
 )
@@ -26,21 +31,42 @@ type TemplateHandler struct {
 	storage        *storage.Client
 	internalSecret string
 	maxTemplates   int
+
+	strictImageMIME bool
+	// contentLimits 与简历共用 resume.ValidateContent 的规则，约束模板 content 的体积。
+	contentLimits resumepkg.ContentLimits
+	// publishReview 开启时，用户发布模板仅标记为待审核，不会直接公开。
+	publishReview bool
+	// previewURLs 在读取时按 object key 签发缩略图链接；为 nil 时直接返回行上保存的链接。
+	previewURLs *previewURLSigner
+}
+
+// TemplateHandlerOptions 为 TemplateHandler 的配置项；新增配置在此加字段。
+type TemplateHandlerOptions struct {
+	// InternalSecret 为 Worker 调用内部打印数据接口时携带的 X-Internal-Secret。
+	InternalSecret string
+	MaxTemplates   int
+	// StrictImageMIME 开启后打印数据校验图片扩展名与 content-type 一致性。
+	StrictImageMIME bool
+	ContentLimits   resumepkg.ContentLimits
+	// PublishReview 开启后公开模板需经管理员审核。
+	PublishReview bool
+	// PreviewURLTTL 为读取模板时签发的缩略图链接有效期。
+	PreviewURLTTL time.Duration
 }
 
-func NewTemplateHandler(
-	db *gorm.DB,
-	asynqClient *asynq.Client,
-	storageClient *storage.Client,
-	internalSecret string,
-	maxTemplates int,
-) *TemplateHandler {
+// NewTemplateHandler 构造 TemplateHandler。
+func NewTemplateHandler(db *gorm.DB, asynqClient *asynq.Client, storageClient *storage.Client, redisClient redis.UniversalClient, opts TemplateHandlerOptions) *TemplateHandler {
 	return &TemplateHandler{
-		db:             db,
-		asynqClient:    asynqClient,
-		storage:        storageClient,
-		internalSecret: internalSecret,
-		maxTemplates:   maxTemplates,
+		db:              db,
+		asynqClient:     asynqClient,
+		storage:         storageClient,
+		internalSecret:  opts.InternalSecret,
+		maxTemplates:    opts.MaxTemplates,
+		strictImageMIME: opts.StrictImageMIME,
+		contentLimits:   opts.ContentLimits,
+		publishReview:   opts.PublishReview,
+		previewURLs:     newPreviewURLSigner(storageClient, redisClient, opts.PreviewURLTTL),
 	}
 }
 
@@ -50,11 +76,24 @@ type createTemplateRequest struct {
 	// 目前创建默认私有，若后续需要开放，可增加 IsPublic 入参并严格校验
 }
 
+type updateTemplateVisibilityRequest struct {
+	IsPublic *bool `json:"is_public" binding:"required"`
+}
+
+type templateVisibilityResponse struct {
+	ID            uint   `json:"id"`
+	IsPublic      bool   `json:"is_public"`
+	Status        string `json:"status"`
+	PendingReview bool   `json:"pending_review"`
+}
+
 type templateListItem struct {
 	ID              uint   `json:"id"`
 	Title           string `json:"title"`
 	PreviewImageURL string `json:"preview_image_url,omitempty"`
 	IsOwner         bool   `json:"is_owner"`
+	IsPublic        bool   `json:"is_public"`
+	Status          string `json:"status,omitempty"` // 审核状态，仅对 Owner 返回
 }
 
 type templateDetailResponse struct {
@@ -75,7 +114,18 @@ func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
 
 	var req createTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		BadRequest(c, err.Error())
+		BindError(c, err)
+		return
+	}
+
+	// 模板同样交由 Worker 渲染，超大 content 与简历有相同的内存风险。
+	if _, err := resumepkg.ValidateContent(req.Content, h.contentLimits); err != nil {
+		switch {
+		case errors.Is(err, resumepkg.ErrContentTooLarge):
+			Error(c, http.StatusRequestEntityTooLarge, "template content too large")
+		default:
+			BadRequest(c, "invalid template content")
+		}
 		return
 	}
 
@@ -84,6 +134,7 @@ func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
 		Content:  req.Content,
 		UserID:   userID,
 		IsPublic: false,
+		Status:   database.TemplateStatusDraft,
 	}
 
 	var count int64
@@ -95,7 +146,7 @@ func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
 		return
 	}
 	if h.maxTemplates > 0 && count >= int64(h.maxTemplates) {
-		Forbidden(c, "template limit reached")
+		Forbidden(c, "template limit reached", errcode.LimitReached)
 		return
 	}
 
@@ -164,7 +215,12 @@ func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
 		}
 	}
 
-	if err := h.db.WithContext(ctx).Delete(&database.Template{}, model.ID).Error; err != nil {
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&database.Template{}, model.ID).Error; err != nil {
+			return err
+		}
+		return database.AdjustStorageBytes(tx, model.UserID, -model.PreviewSize)
+	}); err != nil {
 		logger.Error("delete template record failed", slog.Any("error", err))
 		Internal(c, "failed to delete template")
 		return
@@ -173,8 +229,48 @@ func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// GET /v1/templates
-// 列表：返回当前用户模板 ∪ 所有公开模板（去重由主键自然保证）。
+// templateSortColumns 为 ?sort= 允许的取值到列名的白名单；排序列只从这里取，绝不拼接用户输入。
+var templateSortColumns = map[string]string{
+	"created": "created_at",
+	"updated": "updated_at",
+	"title":   "title",
+}
+
+// templateListOrder 解析 ?sort= 与 ?order=，默认 updated DESC；非法取值返回 false。
+func templateListOrder(sort, order string) (clause.OrderBy, bool) {
+	sort = strings.ToLower(strings.TrimSpace(sort))
+	if sort == "" {
+		sort = "updated"
+	}
+	column, ok := templateSortColumns[sort]
+	if !ok {
+		return clause.OrderBy{}, false
+	}
+
+	var desc bool
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", "desc":
+		desc = true
+	case "asc":
+		desc = false
+	default:
+		return clause.OrderBy{}, false
+	}
+
+	return clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: column}, Desc: desc},
+		// 同值时按 id 稳定排序，避免翻页/刷新时顺序抖动。
+		{Column: clause.Column{Name: "id"}, Desc: desc},
+	}}, true
+}
+
+// templateVisibleToOthers 报告模板是否出现在公开列表中：Owner 已公开且审核通过。
+func templateVisibleToOthers(model database.Template) bool {
+	return model.IsPublic && model.Status == database.TemplateStatusApproved
+}
+
+// GET /v1/templates?sort=created|updated|title&order=asc|desc
+// 列表：返回当前用户模板 ∪ 所有公开且审核通过的模板（去重由主键自然保证），默认按 updated_at 倒序。
 func (h *TemplateHandler) ListTemplates(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -182,10 +278,16 @@ func (h *TemplateHandler) ListTemplates(c *gin.Context) {
 		return
 	}
 
+	orderBy, ok := templateListOrder(c.Query("sort"), c.Query("order"))
+	if !ok {
+		BadRequest(c, "invalid sort or order")
+		return
+	}
+
 	var templates []database.Template
 	if err := h.db.WithContext(c.Request.Context()).
-		Where("user_id = ? OR is_public = ?", userID, true).
-		Order("updated_at DESC").
+		Where("user_id = ? OR (is_public = ? AND status = ?)", userID, true, database.TemplateStatusApproved).
+		Clauses(orderBy).
 		Find(&templates).Error; err != nil {
 		Internal(c, "failed to list templates")
 		return
@@ -193,18 +295,23 @@ func (h *TemplateHandler) ListTemplates(c *gin.Context) {
 
 	items := make([]templateListItem, 0, len(templates))
 	for _, t := range templates {
-		items = append(items, templateListItem{
+		item := templateListItem{
 			ID:              t.ID,
 			Title:           t.Title,
-			PreviewImageURL: t.PreviewImageURL,
+			PreviewImageURL: h.previewURLs.url(c, t.PreviewObjectKey, t.PreviewImageURL),
 			IsOwner:         t.UserID == userID,
-		})
+			IsPublic:        t.IsPublic,
+		}
+		if item.IsOwner {
+			item.Status = t.Status
+		}
+		items = append(items, item)
 	}
 	c.JSON(http.StatusOK, items)
 }
 
 // GET /v1/templates/:id
-// 详情：允许 Owner 访问，或公开模板允许任何已登录用户访问。
+// 详情：允许 Owner 访问，或公开且审核通过的模板允许任何已登录用户访问。
 func (h *TemplateHandler) GetTemplate(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
 	if !ok {
@@ -230,7 +337,7 @@ func (h *TemplateHandler) GetTemplate(c *gin.Context) {
 		return
 	}
 
-	if model.UserID != userID && !model.IsPublic {
+	if model.UserID != userID && !templateVisibleToOthers(model) {
 		Forbidden(c, "access denied")
 		return
 	}
@@ -239,10 +346,88 @@ func (h *TemplateHandler) GetTemplate(c *gin.Context) {
 		ID:              model.ID,
 		Title:           model.Title,
 		Content:         model.Content,
-		PreviewImageURL: model.PreviewImageURL,
+		PreviewImageURL: h.previewURLs.url(c, model.PreviewObjectKey, model.PreviewImageURL),
+	})
+}
+
+// PATCH /v1/templates/:id/visibility
+// 发布/取消发布模板，仅 Owner 可操作。开启发布审核时，发布只标记为待审核；
+// 取消发布会同时撤销待审核状态，模板仍对 Owner 可见。
+func (h *TemplateHandler) UpdateTemplateVisibility(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		AbortUnauthorized(c)
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		BadRequest(c, "invalid template id")
+		return
+	}
+
+	var req updateTemplateVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BindError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	var model database.Template
+	if err := h.db.WithContext(ctx).First(&model, uint(id)).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			NotFound(c, "template not found")
+		default:
+			Internal(c, "failed to query template")
+		}
+		return
+	}
+
+	if model.UserID != userID {
+		Forbidden(c, "access denied")
+		return
+	}
+
+	isPublic, status := nextTemplateVisibility(model, *req.IsPublic, h.publishReview)
+	if err := h.db.WithContext(ctx).Model(&model).Updates(map[string]interface{}{
+		"is_public": isPublic,
+		"status":    status,
+	}).Error; err != nil {
+		Internal(c, "failed to update template visibility")
+		return
+	}
+
+	middleware.LoggerFromContext(c).Info("template visibility updated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("template_id", uint64(model.ID)),
+		slog.Bool("is_public", isPublic),
+		slog.String("status", status),
+	)
+
+	c.JSON(http.StatusOK, templateVisibilityResponse{
+		ID:            model.ID,
+		IsPublic:      isPublic,
+		Status:        status,
+		PendingReview: status == database.TemplateStatusPending,
 	})
 }
 
+// nextTemplateVisibility 计算发布/取消发布后的 is_public 与 status：
+// 取消发布回到 draft；开启审核时发布进入 pending（已通过审核的公开模板保持 approved），否则直接 approved。
+func nextTemplateVisibility(model database.Template, publish, review bool) (bool, string) {
+	switch {
+	case !publish:
+		return false, database.TemplateStatusDraft
+	case model.IsPublic && model.Status == database.TemplateStatusApproved:
+		return true, database.TemplateStatusApproved
+	case review:
+		return true, database.TemplateStatusPending
+	default:
+		return true, database.TemplateStatusApproved
+	}
+}
+
 // POST /v1/templates/:id/generate-preview
 func (h *TemplateHandler) GeneratePreview(c *gin.Context) {
 	userID, ok := userIDFromContext(c)
@@ -275,7 +460,7 @@ func (h *TemplateHandler) GeneratePreview(c *gin.Context) {
 	}
 
 	correlationID := middleware.GetCorrelationID(c)
-	task, err := tasks.NewTemplatePreviewTask(model.ID, correlationID)
+	task, err := tasks.NewTemplatePreviewTask(model.ID, model.UserID, correlationID, middleware.GetTraceParent(c))
 	if err != nil {
 		Internal(c, "failed to create preview task")
 		return
@@ -300,6 +485,11 @@ func (h *TemplateHandler) GetPrintTemplateData(c *gin.Context) {
 		BadRequest(c, "invalid template id")
 		return
 	}
+	ownerID, ok := printOwnerFromRequest(c)
+	if !ok {
+		BadRequest(c, "missing or invalid print owner")
+		return
+	}
 
 	var templateModel database.Template
 	ctx := c.Request.Context()
@@ -313,7 +503,29 @@ func (h *TemplateHandler) GetPrintTemplateData(c *gin.Context) {
 		return
 	}
 
-	printData, removed, err := BuildPrintData(ctx, h.storage, templateModel.UserID, templateModel.Content)
+	log := middleware.LoggerFromContext(c).With(
+		slog.Int("template_id", int(templateModel.ID)),
+		slog.Uint64("user_id", uint64(templateModel.UserID)),
+	)
+
+	// 公开且审核通过的模板任何所有者均可渲染；私有模板必须与声明的所有者一致。
+	if templateModel.UserID != ownerID && !templateVisibleToOthers(templateModel) {
+		log.Warn("print data owner mismatch", slog.Uint64("expected_user_id", uint64(ownerID)))
+		NotFound(c, "template not found")
+		return
+	}
+
+	assetDir, err := database.LoadAssetKeyDir(h.db.WithContext(ctx), templateModel.UserID)
+	if err != nil {
+		Internal(c, "failed to query asset dir")
+		return
+	}
+	printData, removed, err := BuildPrintData(ctx, h.storage, templateModel.UserID, templateModel.Content, PrintDataOptions{
+		StrictImageMIME: h.strictImageMIME,
+		Logger:          log,
+		Grid:            h.contentLimits.Grid,
+		AssetDir:        assetDir,
+	})
 	if err != nil {
 		if status, ok := statusFromInlineError(err); ok {
 			Error(c, status, err.Error())
@@ -323,10 +535,6 @@ func (h *TemplateHandler) GetPrintTemplateData(c *gin.Context) {
 		return
 	}
 
-	log := middleware.LoggerFromContext(c).With(
-		slog.Int("template_id", int(templateModel.ID)),
-		slog.Uint64("user_id", uint64(templateModel.UserID)),
-	)
 	LogRemovedImageItems(log, removed)
 
 	c.JSON(http.StatusOK, printData)
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	resumepkg "phResume/internal/resume"
+)
+
+func TestListTemplates_SortAndOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &TemplateHandler{db: newTestDB(t)}
+	if err := h.db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []database.Template{
+		{Model: gorm.Model{CreatedAt: base, UpdatedAt: base.Add(3 * time.Hour)}, UserID: 1, Title: "Beta"},
+		{Model: gorm.Model{CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour)}, UserID: 1, Title: "Alpha"},
+		{Model: gorm.Model{CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(2 * time.Hour)}, UserID: 1, Title: "Gamma"},
+	}
+	for i := range seed {
+		if err := h.db.Create(&seed[i]).Error; err != nil {
+			t.Fatalf("seed template: %v", err)
+		}
+	}
+
+	list := func(query string) (int, []string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/templates"+query, nil)
+		c.Set("userID", uint(1))
+		h.ListTemplates(c)
+		var items []templateListItem
+		_ = json.Unmarshal(w.Body.Bytes(), &items)
+		titles := make([]string, 0, len(items))
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		return w.Code, titles
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: []string{"Beta", "Gamma", "Alpha"}},
+		{query: "?sort=created", want: []string{"Gamma", "Alpha", "Beta"}},
+		{query: "?sort=title&order=asc", want: []string{"Alpha", "Beta", "Gamma"}},
+		{query: "?sort=UPDATED&order=asc", want: []string{"Alpha", "Gamma", "Beta"}},
+	}
+	for _, tc := range cases {
+		code, titles := list(tc.query)
+		if code != http.StatusOK {
+			t.Fatalf("%q: expected 200 got %d", tc.query, code)
+		}
+		if len(titles) != len(tc.want) {
+			t.Fatalf("%q: unexpected titles %v", tc.query, titles)
+		}
+		for i := range titles {
+			if titles[i] != tc.want[i] {
+				t.Fatalf("%q: expected %v got %v", tc.query, tc.want, titles)
+			}
+		}
+	}
+
+	for _, query := range []string{"?sort=user_id", "?sort=title%3BDROP%20TABLE%20templates", "?order=sideways"} {
+		if code, _ := list(query); code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400 got %d", query, code)
+		}
+	}
+}
+
+func TestCreateTemplate_EnforcesContentLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &TemplateHandler{
+		db:            newTestDB(t),
+		maxTemplates:  5,
+		contentLimits: resumepkg.ContentLimits{MaxBytes: 512, MaxItems: 2},
+	}
+	if err := h.db.Create(&database.User{Model: gorm.Model{ID: 1}, Username: "u1"}).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	create := func(content string) int {
+		body := `{"title":"T","content":` + content + `}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/templates", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("userID", uint(1))
+		h.CreateTemplate(c)
+		return w.Code
+	}
+
+	item := `{"id":"a","type":"text","content":"x","layout":{"x":0,"y":0,"w":1,"h":1}}`
+	cases := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "ok", content: `{"layout_settings":{"columns":24},"items":[` + item + `]}`, want: http.StatusCreated},
+		{name: "too many items", content: `{"items":[` + item + `,` + item + `,` + item + `]}`, want: http.StatusBadRequest},
+		{name: "not an object", content: `[1,2,3]`, want: http.StatusBadRequest},
+		{name: "too large", content: `{"items":[],"pad":"` + strings.Repeat("x", 600) + `"}`, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		if got := create(tc.content); got != tc.want {
+			t.Fatalf("%s: expected %d got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestUpdateTemplateVisibility(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	for _, u := range []database.User{{Model: gorm.Model{ID: 1}, Username: "u1"}, {Model: gorm.Model{ID: 2}, Username: "u2"}} {
+		if err := db.Create(&u).Error; err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	tpl := database.Template{UserID: 1, Title: "Mine"}
+	if err := db.Create(&tpl).Error; err != nil {
+		t.Fatalf("seed template: %v", err)
+	}
+
+	patch := func(h *TemplateHandler, userID uint, body string) (int, templateVisibilityResponse) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/v1/templates/1/visibility", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: strconv.FormatUint(uint64(tpl.ID), 10)}}
+		c.Set("userID", userID)
+		h.UpdateTemplateVisibility(c)
+		var resp templateVisibilityResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	reload := func() database.Template {
+		var got database.Template
+		if err := db.First(&got, tpl.ID).Error; err != nil {
+			t.Fatalf("reload template: %v", err)
+		}
+		return got
+	}
+
+	direct := &TemplateHandler{db: db}
+	if code, _ := patch(direct, 2, `{"is_public":true}`); code != http.StatusForbidden {
+		t.Fatalf("non-owner: expected 403 got %d", code)
+	}
+	if code, _ := patch(direct, 1, `{}`); code != http.StatusBadRequest {
+		t.Fatalf("missing is_public: expected 400 got %d", code)
+	}
+	if code, resp := patch(direct, 1, `{"is_public":true}`); code != http.StatusOK || !resp.IsPublic || resp.PendingReview {
+		t.Fatalf("publish: code=%d resp=%+v", code, resp)
+	}
+	if got := reload(); !templateVisibleToOthers(got) {
+		t.Fatalf("expected template to be public, got %+v", got)
+	}
+	if code, resp := patch(direct, 1, `{"is_public":false}`); code != http.StatusOK || resp.IsPublic {
+		t.Fatalf("unpublish: code=%d resp=%+v", code, resp)
+	}
+
+	reviewed := &TemplateHandler{db: db, publishReview: true}
+	if code, resp := patch(reviewed, 1, `{"is_public":true}`); code != http.StatusOK || !resp.PendingReview || resp.Status != database.TemplateStatusPending {
+		t.Fatalf("publish with review: code=%d resp=%+v", code, resp)
+	}
+	if got := reload(); !got.IsPublic || got.Status != database.TemplateStatusPending || templateVisibleToOthers(got) {
+		t.Fatalf("expected pending template hidden from others, got %+v", got)
+	}
+	if code, resp := patch(reviewed, 1, `{"is_public":false}`); code != http.StatusOK || resp.PendingReview {
+		t.Fatalf("withdraw: code=%d resp=%+v", code, resp)
+	}
+	if got := reload(); got.IsPublic || got.Status != database.TemplateStatusDraft {
+		t.Fatalf("expected private template after withdraw, got %+v", got)
+	}
+}
+
+func TestTemplateModeration_ApproveAndReject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newTestDB(t)
+	for _, u := range []database.User{{Model: gorm.Model{ID: 1}, Username: "u1"}, {Model: gorm.Model{ID: 2}, Username: "u2"}} {
+		if err := db.Create(&u).Error; err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	seed := []database.Template{
+		{UserID: 1, Title: "Pending A", IsPublic: true, Status: database.TemplateStatusPending},
+		{UserID: 1, Title: "Pending B", IsPublic: true, Status: database.TemplateStatusPending},
+		{UserID: 1, Title: "Draft", Status: database.TemplateStatusDraft},
+	}
+	for i := range seed {
+		if err := db.Create(&seed[i]).Error; err != nil {
+			t.Fatalf("seed template: %v", err)
+		}
+	}
+	h := &TemplateHandler{db: db}
+
+	call := func(handler gin.HandlerFunc, method string, id uint) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/v1/admin/templates", nil)
+		c.Params = gin.Params{{Key: "id", Value: strconv.FormatUint(uint64(id), 10)}}
+		handler(c)
+		return w
+	}
+	galleryFor := func(userID uint) []string {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/templates?sort=title&order=asc", nil)
+		c.Set("userID", userID)
+		h.ListTemplates(c)
+		var items []templateListItem
+		_ = json.Unmarshal(w.Body.Bytes(), &items)
+		titles := make([]string, 0, len(items))
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		return titles
+	}
+
+	w := call(h.ListPendingTemplates, http.MethodGet, 0)
+	var pending []pendingTemplateItem
+	if err := json.Unmarshal(w.Body.Bytes(), &pending); err != nil || len(pending) != 2 {
+		t.Fatalf("expected 2 pending templates, code=%d body=%s", w.Code, w.Body.String())
+	}
+	if got := galleryFor(2); len(got) != 0 {
+		t.Fatalf("pending templates must not be visible to others, got %v", got)
+	}
+
+	if w := call(h.ApproveTemplate, http.MethodPost, seed[0].ID); w.Code != http.StatusOK {
+		t.Fatalf("approve: expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	if w := call(h.RejectTemplate, http.MethodPost, seed[1].ID); w.Code != http.StatusOK {
+		t.Fatalf("reject: expected 200 got %d body=%s", w.Code, w.Body.String())
+	}
+	if got := galleryFor(2); len(got) != 1 || got[0] != "Pending A" {
+		t.Fatalf("expected only the approved template in the gallery, got %v", got)
+	}
+	if got := galleryFor(1); len(got) != 3 {
+		t.Fatalf("owner should still see all own templates, got %v", got)
+	}
+
+	var rejected database.Template
+	if err := db.First(&rejected, seed[1].ID).Error; err != nil {
+		t.Fatalf("reload rejected: %v", err)
+	}
+	if rejected.IsPublic || rejected.Status != database.TemplateStatusRejected {
+		t.Fatalf("expected rejected private template, got %+v", rejected)
+	}
+
+	if w := call(h.ApproveTemplate, http.MethodPost, seed[2].ID); w.Code != http.StatusConflict {
+		t.Fatalf("approve draft: expected 409 got %d", w.Code)
+	}
+	if w := call(h.ApproveTemplate, http.MethodPost, 999); w.Code != http.StatusNotFound {
+		t.Fatalf("approve missing: expected 404 got %d", w.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/database"
+)
+
+type pendingTemplateItem struct {
+	ID              uint      `json:"id"`
+	Title           string    `json:"title"`
+	UserID          uint      `json:"user_id"`
+	PreviewImageURL string    `json:"preview_image_url,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// GET /v1/admin/templates/pending
+// 审核队列：按提交时间（updated_at）正序列出待审核模板。
+func (h *TemplateHandler) ListPendingTemplates(c *gin.Context) {
+	var templates []database.Template
+	if err := h.db.WithContext(c.Request.Context()).
+		Where("status = ?", database.TemplateStatusPending).
+		Order("updated_at ASC, id ASC").
+		Find(&templates).Error; err != nil {
+		Internal(c, "failed to list pending templates")
+		return
+	}
+
+	items := make([]pendingTemplateItem, 0, len(templates))
+	for _, t := range templates {
+		items = append(items, pendingTemplateItem{
+			ID:              t.ID,
+			Title:           t.Title,
+			UserID:          t.UserID,
+			PreviewImageURL: h.previewURLs.url(c, t.PreviewObjectKey, t.PreviewImageURL),
+			UpdatedAt:       t.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /v1/admin/templates/:id/approve
+// 审核通过：模板进入公开列表。
+func (h *TemplateHandler) ApproveTemplate(c *gin.Context) {
+	h.reviewTemplate(c, database.TemplateStatusApproved, true)
+}
+
+// POST /v1/admin/templates/:id/reject
+// 驳回：模板回到私有，Owner 可修改后重新发布。
+func (h *TemplateHandler) RejectTemplate(c *gin.Context) {
+	h.reviewTemplate(c, database.TemplateStatusRejected, false)
+}
+
+// reviewTemplate 以 status = pending 为条件更新，避免与 Owner 取消发布或并发审核相互覆盖。
+func (h *TemplateHandler) reviewTemplate(c *gin.Context, status string, isPublic bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		BadRequest(c, "invalid template id")
+		return
+	}
+
+	ctx := c.Request.Context()
+	result := h.db.WithContext(ctx).
+		Model(&database.Template{}).
+		Where("id = ? AND status = ?", uint(id), database.TemplateStatusPending).
+		Updates(map[string]interface{}{
+			"status":    status,
+			"is_public": isPublic,
+		})
+	if result.Error != nil {
+		Internal(c, "failed to review template")
+		return
+	}
+	if result.RowsAffected == 0 {
+		var model database.Template
+		if err := h.db.WithContext(ctx).Select("id").First(&model, uint(id)).Error; err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				NotFound(c, "template not found")
+			default:
+				Internal(c, "failed to query template")
+			}
+			return
+		}
+		Conflict(c, "template is not pending review")
+		return
+	}
+
+	middleware.LoggerFromContext(c).Info("template reviewed",
+		slog.Uint64("template_id", id),
+		slog.String("status", status),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":        uint(id),
+		"status":    status,
+		"is_public": isPublic,
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// 上传文件扩展名一致性检查（API_UPLOAD_EXTENSION_CHECK）。
+const (
+	// UploadExtensionStrict 拒绝声明扩展名与嗅探类型不一致的上传。
+	UploadExtensionStrict = "strict"
+	// UploadExtensionLenient 仅记录告警，按嗅探类型决定存储扩展名。
+	UploadExtensionLenient = "lenient"
+)
+
+// uploadExtensions 为嗅探类型对应的合法扩展名，首个为存储时使用的规范扩展名。
+var uploadExtensions = map[string][]string{
+	"image/png":  {".png"},
+	"image/jpeg": {".jpg", ".jpeg", ".jpe", ".jfif"},
+	"image/webp": {".webp"},
+	"image/gif":  {".gif"},
+	"font/ttf":   {".ttf"},
+	"font/woff2": {".woff2"},
+}
+
+// uploadObjectExtension 返回嗅探类型的规范扩展名；未登记的类型沿用 .png。
+func uploadObjectExtension(sniffed string) string {
+	if exts := uploadExtensions[sniffed]; len(exts) > 0 {
+		return exts[0]
+	}
+	return ".png"
+}
+
+// uploadExtensionMatches 报告文件名声明的扩展名与嗅探类型是否一致，并返回小写的声明扩展名。
+// 文件名没有扩展名、或嗅探类型未登记时无从比较，视为一致。
+func uploadExtensionMatches(filename, sniffed string) (string, bool) {
+	declared := strings.ToLower(filepath.Ext(strings.TrimSpace(filename)))
+	exts, known := uploadExtensions[sniffed]
+	if declared == "" || !known {
+		return declared, true
+	}
+	return declared, slices.Contains(exts, declared)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/api/middleware"
+)
+
+// revokedUserKeyPrefix 为"账号已注销"标记的 Redis key 前缀：注销后该用户此前签发的 access token 一律拒绝。
+const revokedUserKeyPrefix = "auth:revoked_user:"
+
+// revokedUserStore 为注销标记所需的 Redis 子集，便于测试替换。
+type revokedUserStore interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Exists(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+func revokedUserKey(userID uint) string {
+	return revokedUserKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+// revokeUserTokens 写入注销标记；ttl 取 access token 有效期，过期后注销前签发的令牌均已失效。
+func revokeUserTokens(ctx context.Context, store revokedUserStore, userID uint, ttl time.Duration) error {
+	return store.Set(ctx, revokedUserKey(userID), 1, ttl).Err()
+}
+
+// newRevokedUserLookup 返回供 AuthMiddleware 使用的注销标记查询函数。
+func newRevokedUserLookup(store revokedUserStore) middleware.RevokedUserLookup {
+	return func(ctx context.Context, userID uint) (bool, error) {
+		n, err := store.Exists(ctx, revokedUserKey(userID)).Result()
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/errcode"
+	"phResume/internal/i18n"
+)
+
+func init() {
+	// 校验错误中的字段名使用 JSON 字段名（如 new_password），与请求体一致，便于前端定位输入框。
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// validationReasons 为各校验 tag 的原因模板，%s 为 tag 参数；长度类 tag 对字符串/数组与数值分别使用不同措辞。
+var validationReasons = map[string]map[string]string{
+	i18n.EN: {
+		"required":   "is required",
+		"min.len":    "must be at least %s characters",
+		"max.len":    "must be at most %s characters",
+		"min.value":  "must be at least %s",
+		"max.value":  "must be at most %s",
+		"oneof":      "must be one of: %s",
+		"type":       "must be a %s",
+		"default":    "is invalid",
+		"min.items":  "must contain at least %s items",
+		"max.items":  "must contain at most %s items",
+		"malformed":  "request body is not valid JSON",
+		"empty body": "request body is required",
+	},
+	i18n.ZhCN: {
+		"required":   "为必填项",
+		"min.len":    "长度不能少于 %s 个字符",
+		"max.len":    "长度不能超过 %s 个字符",
+		"min.value":  "不能小于 %s",
+		"max.value":  "不能大于 %s",
+		"oneof":      "必须是以下取值之一：%s",
+		"type":       "类型应为 %s",
+		"default":    "不合法",
+		"min.items":  "至少需要 %s 项",
+		"max.items":  "最多 %s 项",
+		"malformed":  "请求体不是合法的 JSON",
+		"empty body": "缺少请求体",
+	},
+}
+
+// validationError 描述一次请求体绑定失败：fields 为字段名 -> 原因；无法归属到字段时 message 说明整体原因。
+// message 与 fields 均已按请求语言渲染。
+type validationError struct {
+	message string
+	fields  map[string]string
+}
+
+// describeBindError 将 ShouldBindJSON 的错误转换为结构化结果；validator 的内部描述（结构体名、tag）不会出现在响应中。
+func describeBindError(locale string, err error) validationError {
+	reasons, ok := validationReasons[locale]
+	if !ok {
+		reasons = validationReasons[i18n.DefaultLocale]
+	}
+	invalid := i18n.Translate(locale, errcode.InvalidRequest, "invalid request")
+
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		fields := make(map[string]string, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			name := fieldPath(fe)
+			if _, exists := fields[name]; exists {
+				continue
+			}
+			fields[name] = validationReason(reasons, fe)
+		}
+		return validationError{message: invalid, fields: fields}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return validationError{
+			message: invalid,
+			fields:  map[string]string{typeErr.Field: fmt.Sprintf(reasons["type"], jsonTypeName(typeErr.Type))},
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return validationError{message: reasons["empty body"]}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || typeErr != nil {
+		return validationError{message: reasons["malformed"]}
+	}
+	return validationError{message: invalid}
+}
+
+// fieldPath 返回去掉顶层请求结构体名的字段路径，如 content.layout_settings。
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
+
+func validationReason(reasons map[string]string, fe validator.FieldError) string {
+	switch tag := fe.Tag(); tag {
+	case "required":
+		return reasons["required"]
+	case "min", "max", "gte", "lte":
+		bound := "min"
+		if tag == "max" || tag == "lte" {
+			bound = "max"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf(reasons[bound+".len"], fe.Param())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf(reasons[bound+".items"], fe.Param())
+		default:
+			return fmt.Sprintf(reasons[bound+".value"], fe.Param())
+		}
+	case "oneof":
+		return fmt.Sprintf(reasons["oneof"], strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return reasons["default"]
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// writeValidationError 写入 400 {"error":{"code":4000,"message":"...","fields":{...}}}，字段级原因放在 fields 中。
+func writeValidationError(c *gin.Context, err error) {
+	described := describeBindError(middleware.GetLocale(c), err)
+	c.JSON(http.StatusBadRequest, gin.H{"error": errcode.Detail{
+		Code:    errcode.InvalidRequest,
+		Message: described.message,
+		Fields:  described.fields,
+	}})
+}
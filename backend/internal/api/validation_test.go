@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"phResume/internal/api/middleware"
+	"phResume/internal/errcode"
+	"phResume/internal/i18n"
+)
+
+func bindCall(t *testing.T, locale, body string, target any) errcode.Detail {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(middleware.LocaleMiddleware(i18n.DefaultLocale))
+	router.POST("/bind", func(c *gin.Context) {
+		if err := c.ShouldBindJSON(target); err != nil {
+			BindError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", locale)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "Request.") || strings.Contains(w.Body.String(), "Key:") {
+		t.Fatalf("validator internals leaked: %s", w.Body.String())
+	}
+	var resp struct {
+		Error errcode.Detail `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error.Code != errcode.InvalidRequest {
+		t.Fatalf("expected code %d got %d", errcode.InvalidRequest, resp.Error.Code)
+	}
+	return resp.Error
+}
+
+func TestBindError_FieldErrors(t *testing.T) {
+	detail := bindCall(t, "en", `{"username":"al"}`, &registerRequest{})
+	if detail.Message != "invalid request" {
+		t.Fatalf("unexpected message %q", detail.Message)
+	}
+	want := map[string]string{
+		"username": "must be at least 3 characters",
+		"password": "is required",
+	}
+	if len(detail.Fields) != len(want) {
+		t.Fatalf("unexpected fields %v", detail.Fields)
+	}
+	for field, reason := range want {
+		if detail.Fields[field] != reason {
+			t.Fatalf("field %s: expected %q got %q", field, reason, detail.Fields[field])
+		}
+	}
+
+	detail = bindCall(t, "zh-CN", `{"current_password":"12345678","new_password":"short","confirm_password":"12345678"}`, &changePasswordRequest{})
+	if got := detail.Fields["new_password"]; got != "长度不能少于 8 个字符" {
+		t.Fatalf("unexpected zh-CN reason %q (fields %v)", got, detail.Fields)
+	}
+}
+
+func TestBindError_MalformedAndTypeErrors(t *testing.T) {
+	detail := bindCall(t, "en", `{"username":`, &loginRequest{})
+	if detail.Message != "request body is not valid JSON" || len(detail.Fields) != 0 {
+		t.Fatalf("unexpected malformed detail %+v", detail)
+	}
+
+	detail = bindCall(t, "en", ``, &loginRequest{})
+	if detail.Message != "request body is required" {
+		t.Fatalf("unexpected empty body detail %+v", detail)
+	}
+
+	detail = bindCall(t, "en", `{"username":123,"password":"x"}`, &loginRequest{})
+	if detail.Fields["username"] != "must be a string" {
+		t.Fatalf("unexpected type error detail %+v", detail)
+	}
+}
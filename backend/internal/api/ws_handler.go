@@ -15,11 +15,15 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"phResume/internal/auth"
+	"phResume/internal/notify"
 )
 
+// wsHeartbeatInterval 为 WebSocket ping 间隔。
+const wsHeartbeatInterval = 30 * time.Second
+
 // WsHandler 负责处理 WebSocket 鉴权与消息转发。
 type WsHandler struct {
-	redisClient    *redis.Client
+	redisClient    redis.UniversalClient
 	authService    *auth.AuthService
 	logger         *slog.Logger
 	upgrader       websocket.Upgrader
@@ -27,7 +31,7 @@ type WsHandler struct {
 }
 
 // NewWsHandler 构造 WebSocket 处理器。
-func NewWsHandler(redisClient *redis.Client, authService *auth.AuthService, logger *slog.Logger, allowedOrigins []string) *WsHandler {
+func NewWsHandler(redisClient redis.UniversalClient, authService *auth.AuthService, logger *slog.Logger, allowedOrigins []string) *WsHandler {
 	h := &WsHandler{
 		redisClient:    redisClient,
 		authService:    authService,
@@ -61,6 +65,14 @@ func NewWsHandler(redisClient *redis.Client, authService *auth.AuthService, logg
 type wsAuthMessage struct {
 	Type  string `json:"type"`
 	Token string `json:"token"`
+	// LastEventID 为客户端上次收到的通知 id，重连时携带以补发断线期间的通知。
+	LastEventID string `json:"last_event_id"`
+}
+
+// wsSession 为鉴权通过后的连接信息。
+type wsSession struct {
+	userID      uint
+	lastEventID string
 }
 
 // HandleConnection 负责升级连接并启动读写循环。
@@ -79,12 +91,12 @@ func (h *WsHandler) HandleConnection(c *gin.Context) {
 		slog.String("client_ip", c.ClientIP()),
 	)
 
-	userIDCh := make(chan uint, 1)
+	sessionCh := make(chan wsSession, 1)
 	errCh := make(chan error, 1)
 
-	go h.readLoop(ctx, conn, userIDCh, errCh, cancel, baseLog)
+	go h.readLoop(ctx, conn, sessionCh, errCh, cancel, baseLog)
 
-	var userID uint
+	var session wsSession
 	select {
 	case <-ctx.Done():
 		return
@@ -93,11 +105,11 @@ func (h *WsHandler) HandleConnection(c *gin.Context) {
 			baseLog.Warn("websocket authentication failed", slog.Any("error", err))
 		}
 		return
-	case userID = <-userIDCh:
+	case session = <-sessionCh:
 	}
 
-	userLog := baseLog.With(slog.Uint64("user_id", uint64(userID)))
-	go h.subscribeLoop(ctx, conn, userID, errCh, cancel, userLog)
+	userLog := baseLog.With(slog.Uint64("user_id", uint64(session.userID)))
+	go h.subscribeLoop(ctx, conn, session, errCh, cancel, userLog)
 
 	select {
 	case <-ctx.Done():
@@ -113,7 +125,7 @@ func (h *WsHandler) HandleConnection(c *gin.Context) {
 func (h *WsHandler) readLoop(
 	ctx context.Context,
 	conn *websocket.Conn,
-	userIDCh chan<- uint,
+	sessionCh chan<- wsSession,
 	errCh chan<- error,
 	cancel context.CancelFunc,
 	log *slog.Logger,
@@ -171,7 +183,7 @@ func (h *WsHandler) readLoop(
 			}
 
 			authenticated = true
-			userIDCh <- claims.UserID
+			sessionCh <- wsSession{userID: claims.UserID, lastEventID: strings.TrimSpace(authMsg.LastEventID)}
 			log.Info("websocket authenticated", slog.Uint64("user_id", uint64(claims.UserID)))
 			continue
 		}
@@ -188,44 +200,97 @@ func writeClose(conn *websocket.Conn, code int, text string) {
 func (h *WsHandler) subscribeLoop(
 	ctx context.Context,
 	conn *websocket.Conn,
-	userID uint,
+	session wsSession,
 	errCh chan<- error,
 	cancel context.CancelFunc,
 	log *slog.Logger,
 ) {
-	channel := fmt.Sprintf("user_notify:%d", userID)
-	pubsub := h.redisClient.Subscribe(ctx, channel)
+	err := forwardUserNotifications(ctx, h.redisClient, session.userID, session.lastEventID, wsHeartbeatInterval, log,
+		func(event notify.Event) error {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(event.Payload)); err != nil {
+				return fmt.Errorf("write message: %w", err)
+			}
+			return nil
+		},
+		func() error {
+			deadline := time.Now().Add(5 * time.Second)
+			if err := conn.WriteControl(websocket.PingMessage, []byte("ping"), deadline); err != nil {
+				return fmt.Errorf("write ping: %w", err)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		errCh <- err
+		cancel()
+	}
+}
+
+// forwardUserNotifications 订阅用户通知频道，将每条消息 payload 原样交给 send，并按 heartbeatInterval 调用 heartbeat 保活。
+// lastEventID 非空时先从通知日志补发其后的通知，再转发实时消息（按 id 去重）。
+// WebSocket 与 SSE 共用；ctx 结束时返回 nil，订阅中断或 send/heartbeat 失败时返回 error。
+func forwardUserNotifications(
+	ctx context.Context,
+	redisClient redis.UniversalClient,
+	userID uint,
+	lastEventID string,
+	heartbeatInterval time.Duration,
+	log *slog.Logger,
+	send func(event notify.Event) error,
+	heartbeat func() error,
+) error {
+	channel := notify.Channel(userID)
+	pubsub := redisClient.Subscribe(ctx, channel)
 	defer pubsub.Close()
 
+	// 先确认订阅生效再读取日志，避免两者之间发布的通知丢失；两边都收到的通知由下方按 id 去重。
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe %q: %w", channel, err)
+	}
 	log.Info("subscribed to redis channel", slog.String("channel", channel))
 
+	lastSent := ""
+	if lastEventID != "" {
+		events, err := notify.Since(ctx, redisClient, userID, lastEventID)
+		if err != nil {
+			log.Warn("replay notifications failed", slog.String("last_event_id", lastEventID), slog.Any("error", err))
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+			lastSent = event.ID
+		}
+		if len(events) > 0 {
+			log.Info("replayed missed notifications", slog.Int("count", len(events)))
+		}
+	}
+
 	ch := pubsub.Channel()
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(heartbeatInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case msg, ok := <-ch:
 			if !ok {
-				errCh <- fmt.Errorf("pubsub channel closed")
-				cancel()
-				return
+				return fmt.Errorf("pubsub channel closed")
+			}
+
+			event := notify.EventFromPayload(msg.Payload)
+			if lastSent != "" && event.ID != "" && !notify.After(event.ID, lastSent) {
+				continue
 			}
 
 			log.Info("forwarding message to client", slog.String("channel", channel))
-			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
-				errCh <- fmt.Errorf("write message: %w", err)
-				cancel()
-				return
+			if err := send(event); err != nil {
+				return err
 			}
 		case <-ticker.C:
-			deadline := time.Now().Add(5 * time.Second)
-			if err := conn.WriteControl(websocket.PingMessage, []byte("ping"), deadline); err != nil {
-				errCh <- fmt.Errorf("write ping: %w", err)
-				cancel()
-				return
+			if err := heartbeat(); err != nil {
+				return err
 			}
 		}
 	}
@@ -1,7 +1,8 @@
 package auth
 
 import (
-	"crypto/rsa"
+	"crypto"
+	"crypto/elliptic"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,18 +12,43 @@ import (
 	"github.com/google/uuid"
 )
 
+// 支持的 JWT 签名算法。
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
 // AuthService 负责处理密码哈希、JWT 生成与校验。
 type AuthService struct {
-	privateKey      *rsa.PrivateKey
-	publicKey       *rsa.PublicKey
+	signingMethod   jwt.SigningMethod
+	privateKey      crypto.PrivateKey
+	publicKey       crypto.PublicKey
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	sessionLifetime time.Duration
 }
 
-// TokenPair 封装访问令牌与刷新令牌。
+// TokenPair 封装访问令牌与刷新令牌；RefreshTokenID / RefreshExpiresAt 为刷新令牌的 jti 与过期时间，
+// 便于调用方按 jti 记录与令牌同生命周期的附加状态。
 type TokenPair struct {
-	AccessToken  string
-	RefreshToken string
+	AccessToken      string
+	RefreshToken     string
+	RefreshTokenID   string
+	RefreshExpiresAt time.Time
+}
+
+// 令牌中的角色声明。
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// RoleFor 根据用户的管理员标记返回角色声明。
+func RoleFor(isAdmin bool) string {
+	if isAdmin {
+		return RoleAdmin
+	}
+	return RoleUser
 }
 
 // TokenClaims 表示 JWT 中的业务字段，便于中间件读取用户信息。
@@ -30,11 +56,15 @@ type TokenClaims struct {
 	UserID             uint   `json:"user_id"`
 	TokenType          string `json:"token_type"`
 	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	Role               string `json:"role,omitempty"`
+	// AuthTime 为会话最初登录的时间，刷新轮换时原样沿用，用于限制会话的绝对有效期。
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// NewAuthService 解析 PEM 密钥并构造服务实例。
-func NewAuthService(privateKeyPEM, publicKeyPEM []byte, accessTTL, refreshTTL time.Duration) (*AuthService, error) {
+// NewAuthService 按算法解析 PEM 密钥并构造服务实例；algorithm 为空时默认 RS256。
+// sessionLifetime 为自登录起的会话绝对有效期，超过后刷新被拒绝、须重新登录；0 表示不限制。
+func NewAuthService(algorithm string, privateKeyPEM, publicKeyPEM []byte, accessTTL, refreshTTL, sessionLifetime time.Duration) (*AuthService, error) {
 	if len(privateKeyPEM) == 0 {
 		return nil, errors.New("private key pem is required")
 	}
@@ -42,20 +72,47 @@ func NewAuthService(privateKeyPEM, publicKeyPEM []byte, accessTTL, refreshTTL ti
 		return nil, errors.New("public key pem is required")
 	}
 
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
-	if err != nil {
-		return nil, fmt.Errorf("parse rsa private key: %w", err)
-	}
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
-	if err != nil {
-		return nil, fmt.Errorf("parse rsa public key: %w", err)
+	var (
+		signingMethod jwt.SigningMethod
+		privateKey    crypto.PrivateKey
+		publicKey     crypto.PublicKey
+		err           error
+	)
+	switch algorithm {
+	case "", AlgorithmRS256:
+		signingMethod = jwt.SigningMethodRS256
+		if privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM); err != nil {
+			return nil, fmt.Errorf("parse rsa private key: %w", err)
+		}
+		if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM); err != nil {
+			return nil, fmt.Errorf("parse rsa public key: %w", err)
+		}
+	case AlgorithmES256:
+		signingMethod = jwt.SigningMethodES256
+		ecPrivate, err := jwt.ParseECPrivateKeyFromPEM(privateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse ecdsa private key: %w", err)
+		}
+		ecPublic, err := jwt.ParseECPublicKeyFromPEM(publicKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse ecdsa public key: %w", err)
+		}
+		// ES256 只定义在 P-256 上；其它曲线的密钥签出的 token 会被标准实现拒绝，启动时即报错。
+		if ecPrivate.Curve != elliptic.P256() || ecPublic.Curve != elliptic.P256() {
+			return nil, errors.New("es256 requires p-256 keys")
+		}
+		privateKey, publicKey = ecPrivate, ecPublic
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
 	}
 
 	return &AuthService{
+		signingMethod:   signingMethod,
 		privateKey:      privateKey,
 		publicKey:       publicKey,
 		accessTokenTTL:  accessTTL,
 		refreshTokenTTL: refreshTTL,
+		sessionLifetime: sessionLifetime,
 	}, nil
 }
 
@@ -69,14 +126,50 @@ func (s *AuthService) CheckPasswordHash(password, hash string) bool {
 	return CheckPasswordHash(password, hash)
 }
 
-// GenerateTokenPair 创建访问令牌与刷新令牌。
-func (s *AuthService) GenerateTokenPair(userID uint, mustChangePassword bool) (TokenPair, error) {
+// GenerateTokenPair 为一次新登录创建访问令牌与刷新令牌；role 仅写入访问令牌，刷新时由调用方按数据库重新计算。
+func (s *AuthService) GenerateTokenPair(userID uint, mustChangePassword bool, role string) (TokenPair, error) {
 	now := time.Now()
+	return s.generateTokenPair(userID, mustChangePassword, role, now, now)
+}
+
+// RotateTokenPair 在刷新时签发新令牌对，沿用原会话的 authTime；刷新令牌的过期时间不超过会话绝对有效期。
+func (s *AuthService) RotateTokenPair(userID uint, mustChangePassword bool, role string, authTime time.Time) (TokenPair, error) {
+	return s.generateTokenPair(userID, mustChangePassword, role, authTime, time.Now())
+}
+
+// SessionAuthTime 返回令牌所属会话的登录时间；缺少 auth_time 的旧令牌以签发时间代替。
+func SessionAuthTime(claims *TokenClaims) time.Time {
+	if claims.AuthTime != nil {
+		return claims.AuthTime.Time
+	}
+	if claims.IssuedAt != nil {
+		return claims.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
+// SessionExpired 报告令牌所属会话在 now 时是否已超过绝对有效期；未配置有效期时恒为 false。
+func (s *AuthService) SessionExpired(claims *TokenClaims, now time.Time) bool {
+	if s.sessionLifetime <= 0 {
+		return false
+	}
+	return !now.Before(SessionAuthTime(claims).Add(s.sessionLifetime))
+}
+
+func (s *AuthService) generateTokenPair(userID uint, mustChangePassword bool, role string, authTime, now time.Time) (TokenPair, error) {
+	refreshExpiresAt := now.Add(s.refreshTokenTTL)
+	if s.sessionLifetime > 0 {
+		if sessionEnd := authTime.Add(s.sessionLifetime); sessionEnd.Before(refreshExpiresAt) {
+			refreshExpiresAt = sessionEnd
+		}
+	}
 
 	accessClaims := TokenClaims{
 		UserID:             userID,
 		TokenType:          "access",
 		MustChangePassword: mustChangePassword,
+		Role:               role,
+		AuthTime:           jwt.NewNumericDate(authTime),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.FormatUint(uint64(userID), 10),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -87,11 +180,12 @@ func (s *AuthService) GenerateTokenPair(userID uint, mustChangePassword bool) (T
 		UserID:             userID,
 		TokenType:          "refresh",
 		MustChangePassword: mustChangePassword,
+		AuthTime:           jwt.NewNumericDate(authTime),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.FormatUint(uint64(userID), 10),
 			ID:        uuid.NewString(),
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshTokenTTL)),
+			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
 		},
 	}
 
@@ -105,8 +199,10 @@ func (s *AuthService) GenerateTokenPair(userID uint, mustChangePassword bool) (T
 	}
 
 	return TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RefreshTokenID:   refreshClaims.ID,
+		RefreshExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
@@ -117,7 +213,8 @@ func (s *AuthService) ValidateToken(tokenString string) (*TokenClaims, error) {
 	}
 
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+		// 只接受配置的算法，防止算法混淆攻击。
+		if token.Method.Alg() != s.signingMethod.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
 		}
 		return s.publicKey, nil
@@ -135,7 +232,7 @@ func (s *AuthService) ValidateToken(tokenString string) (*TokenClaims, error) {
 }
 
 func (s *AuthService) signClaims(claims TokenClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token := jwt.NewWithClaims(s.signingMethod, claims)
 	signed, err := token.SignedString(s.privateKey)
 	if err != nil {
 		return "", fmt.Errorf("sign token: %w", err)
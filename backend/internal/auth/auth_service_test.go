@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKeyPEMs(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal rsa public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
+
+func ecKeyPEMs(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	return ecKeyPEMsOnCurve(t, elliptic.P256())
+}
+
+func ecKeyPEMsOnCurve(t *testing.T, curve elliptic.Curve) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	priv, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal ecdsa private key: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal ecdsa public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: priv}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
+
+func TestAuthService_GenerateAndValidate(t *testing.T) {
+	rsaPriv, rsaPub := rsaKeyPEMs(t)
+	ecPriv, ecPub := ecKeyPEMs(t)
+
+	cases := []struct {
+		name      string
+		algorithm string
+		priv, pub []byte
+	}{
+		{name: "default", algorithm: "", priv: rsaPriv, pub: rsaPub},
+		{name: "rs256", algorithm: AlgorithmRS256, priv: rsaPriv, pub: rsaPub},
+		{name: "es256", algorithm: AlgorithmES256, priv: ecPriv, pub: ecPub},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, err := NewAuthService(tc.algorithm, tc.priv, tc.pub, time.Minute, time.Hour, 0)
+			if err != nil {
+				t.Fatalf("new auth service: %v", err)
+			}
+			pair, err := svc.GenerateTokenPair(42, true, RoleUser)
+			if err != nil {
+				t.Fatalf("generate token pair: %v", err)
+			}
+
+			claims, err := svc.ValidateToken(pair.AccessToken)
+			if err != nil {
+				t.Fatalf("validate access token: %v", err)
+			}
+			if claims.UserID != 42 || claims.TokenType != "access" || !claims.MustChangePassword {
+				t.Fatalf("unexpected access claims: %+v", claims)
+			}
+
+			claims, err = svc.ValidateToken(pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("validate refresh token: %v", err)
+			}
+			if claims.TokenType != "refresh" || claims.ID == "" {
+				t.Fatalf("unexpected refresh claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestAuthService_RejectsOtherAlgorithm(t *testing.T) {
+	rsaPriv, rsaPub := rsaKeyPEMs(t)
+	ecPriv, ecPub := ecKeyPEMs(t)
+
+	rsaSvc, err := NewAuthService(AlgorithmRS256, rsaPriv, rsaPub, time.Minute, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("new rs256 service: %v", err)
+	}
+	ecSvc, err := NewAuthService(AlgorithmES256, ecPriv, ecPub, time.Minute, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("new es256 service: %v", err)
+	}
+
+	ecPair, err := ecSvc.GenerateTokenPair(1, false, RoleUser)
+	if err != nil {
+		t.Fatalf("generate es256 pair: %v", err)
+	}
+	if _, err := rsaSvc.ValidateToken(ecPair.AccessToken); err == nil {
+		t.Fatal("rs256 service accepted es256 token")
+	}
+
+	rsaPair, err := rsaSvc.GenerateTokenPair(1, false, RoleUser)
+	if err != nil {
+		t.Fatalf("generate rs256 pair: %v", err)
+	}
+	if _, err := ecSvc.ValidateToken(rsaPair.AccessToken); err == nil {
+		t.Fatal("es256 service accepted rs256 token")
+	}
+}
+
+func TestNewAuthService_RejectsMismatchedKeys(t *testing.T) {
+	rsaPriv, rsaPub := rsaKeyPEMs(t)
+	if _, err := NewAuthService(AlgorithmES256, rsaPriv, rsaPub, time.Minute, time.Hour, 0); err == nil {
+		t.Fatal("expected es256 with rsa keys to fail")
+	}
+	p384Priv, p384Pub := ecKeyPEMsOnCurve(t, elliptic.P384())
+	if _, err := NewAuthService(AlgorithmES256, p384Priv, p384Pub, time.Minute, time.Hour, 0); err == nil {
+		t.Fatal("expected es256 with p-384 keys to fail")
+	}
+	if _, err := NewAuthService("HS256", rsaPriv, rsaPub, time.Minute, time.Hour, 0); err == nil {
+		t.Fatal("expected unsupported algorithm to fail")
+	}
+}
+
+func TestAuthService_SessionLifetime(t *testing.T) {
+	priv, pub := rsaKeyPEMs(t)
+	svc, err := NewAuthService(AlgorithmRS256, priv, pub, time.Minute, 2*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("new auth service: %v", err)
+	}
+
+	// 新登录：刷新令牌过期时间被会话有效期截断。
+	pair, err := svc.GenerateTokenPair(1, false, RoleUser)
+	if err != nil {
+		t.Fatalf("generate token pair: %v", err)
+	}
+	claims, err := svc.ValidateToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("validate refresh token: %v", err)
+	}
+	if claims.AuthTime == nil || !claims.ExpiresAt.Time.Equal(claims.AuthTime.Time.Add(time.Hour)) {
+		t.Fatalf("expected refresh expiry capped at auth_time+1h, got auth_time=%v exp=%v", claims.AuthTime, claims.ExpiresAt)
+	}
+	if pair.RefreshTokenID != claims.ID || pair.RefreshExpiresAt.Unix() != claims.ExpiresAt.Unix() {
+		t.Fatalf("token pair should expose refresh jti/exp, got %q %v", pair.RefreshTokenID, pair.RefreshExpiresAt)
+	}
+
+	// 轮换沿用原登录时间，不延长会话。
+	authTime := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	pair, err = svc.RotateTokenPair(1, false, RoleUser, authTime)
+	if err != nil {
+		t.Fatalf("rotate token pair: %v", err)
+	}
+	claims, err = svc.ValidateToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("validate rotated token: %v", err)
+	}
+	if !SessionAuthTime(claims).Equal(authTime) || !claims.ExpiresAt.Time.Equal(authTime.Add(time.Hour)) {
+		t.Fatalf("expected auth_time %v preserved and exp %v, got %v / %v", authTime, authTime.Add(time.Hour), SessionAuthTime(claims), claims.ExpiresAt.Time)
+	}
+
+	// 边界：恰好到达有效期即视为过期。
+	login := time.Now()
+	session := &TokenClaims{AuthTime: jwt.NewNumericDate(login)}
+	if svc.SessionExpired(session, login.Add(time.Hour-time.Second)) {
+		t.Fatalf("session should still be valid just before the lifetime elapses")
+	}
+	if !svc.SessionExpired(session, login.Add(time.Hour)) {
+		t.Fatalf("session should expire once the lifetime elapses")
+	}
+
+	// 缺少 auth_time 的旧令牌按签发时间计算。
+	legacy := &TokenClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(login.Add(-2 * time.Hour))}}
+	if !svc.SessionExpired(legacy, login) {
+		t.Fatalf("legacy token should fall back to iat")
+	}
+
+	unlimited, err := NewAuthService(AlgorithmRS256, priv, pub, time.Minute, 2*time.Hour, 0)
+	if err != nil {
+		t.Fatalf("new auth service: %v", err)
+	}
+	if unlimited.SessionExpired(legacy, login.Add(24*time.Hour)) {
+		t.Fatalf("session lifetime 0 should not expire sessions")
+	}
+}
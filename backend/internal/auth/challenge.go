@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 支持的人机验证提供方。
+const (
+	ChallengeProviderNoop = "noop"
+	ChallengeProviderStub = "stub"
+)
+
+// ChallengeVerifier 校验登录请求携带的人机验证（CAPTCHA）token。
+// 接入真实服务（如 hCaptcha/Turnstile）时实现该接口即可，remoteIP 供提供方做风控校验。
+type ChallengeVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// NoopChallengeVerifier 不做任何校验，所有 token（包括空值）均视为通过，即不启用人机验证。
+type NoopChallengeVerifier struct{}
+
+func (NoopChallengeVerifier) Verify(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+// StubChallengeVerifier 仅接受预设 token，用于开发联调与测试。
+type StubChallengeVerifier struct {
+	Token string
+}
+
+func (v StubChallengeVerifier) Verify(_ context.Context, token, _ string) (bool, error) {
+	return SecretEqual(strings.TrimSpace(token), v.Token), nil
+}
+
+// NewChallengeVerifier 按配置的提供方构造校验器。
+func NewChallengeVerifier(provider, stubToken string) (ChallengeVerifier, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", ChallengeProviderNoop:
+		return NoopChallengeVerifier{}, nil
+	case ChallengeProviderStub:
+		return StubChallengeVerifier{Token: strings.TrimSpace(stubToken)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported challenge provider %q", provider)
+	}
+}
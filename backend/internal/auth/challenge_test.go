@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewChallengeVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	noop, err := NewChallengeVerifier("", "")
+	if err != nil {
+		t.Fatalf("noop verifier: %v", err)
+	}
+	if ok, _ := noop.Verify(ctx, "", "10.0.0.1"); !ok {
+		t.Fatalf("noop verifier should accept any token")
+	}
+
+	stub, err := NewChallengeVerifier("stub", "pass-me")
+	if err != nil {
+		t.Fatalf("stub verifier: %v", err)
+	}
+	if ok, _ := stub.Verify(ctx, "pass-me", "10.0.0.1"); !ok {
+		t.Fatalf("stub verifier should accept the configured token")
+	}
+	if ok, _ := stub.Verify(ctx, "wrong", "10.0.0.1"); ok {
+		t.Fatalf("stub verifier should reject other tokens")
+	}
+
+	if _, err := NewChallengeVerifier("recaptcha", ""); err == nil {
+		t.Fatalf("expected error for unsupported provider")
+	}
+}
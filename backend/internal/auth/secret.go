@@ -0,0 +1,12 @@
+package auth
+
+import "crypto/subtle"
+
+// SecretEqual 以常量时间比较两个密钥/令牌，任一为空时视为不匹配。
+// 内部密钥、分享令牌等所有秘密值的相等判断都应使用该函数，避免 == 泄露时序信息。
+func SecretEqual(got, want string) bool {
+	if got == "" || want == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
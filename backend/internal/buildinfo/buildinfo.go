@@ -0,0 +1,45 @@
+// Package buildinfo 描述正在运行的构建版本：版本号、提交与构建时间由各进程入口通过 -ldflags -X 注入，
+// 未注入（如 go run）时使用占位值。
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Info 为 /version 的响应结构。
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// New 组装构建信息；空值替换为 "dev" / "unknown"，GoVersion 取当前运行时版本。
+func New(version, commit, buildTime string) Info {
+	return Info{
+		Version:   orDefault(version, "dev"),
+		Commit:    orDefault(commit, "unknown"),
+		BuildTime: orDefault(buildTime, "unknown"),
+		GoVersion: runtime.Version(),
+	}
+}
+
+// Handler 以 JSON 返回 info，供 API 路由与 Worker 指标服务共用。
+func Handler(info Info) http.HandlerFunc {
+	body, _ := json.Marshal(info)
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(body)
+	}
+}
+
+func orDefault(value, fallback string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,35 @@
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestHandler_ReturnsInjectedValues(t *testing.T) {
+	info := New("v1.4.0", "3d52c57", "2026-10-15T08:00:00Z")
+
+	w := httptest.NewRecorder()
+	Handler(info).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+
+	var got Info
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := Info{Version: "v1.4.0", Commit: "3d52c57", BuildTime: "2026-10-15T08:00:00Z", GoVersion: runtime.Version()}
+	if got != want {
+		t.Fatalf("expected %+v got %+v", want, got)
+	}
+}
+
+func TestNew_FallsBackWhenNotInjected(t *testing.T) {
+	info := New("", " ", "")
+	if info.Version != "dev" || info.Commit != "unknown" || info.BuildTime != "unknown" {
+		t.Fatalf("unexpected fallback values %+v", info)
+	}
+}
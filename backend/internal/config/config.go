@@ -7,8 +7,12 @@ import (
 	"os"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/spf13/viper"
+
+	"phResume/internal/auth"
+	"phResume/internal/i18n"
 )
 
 // Config aggregates application settings that may be sourced from files or environment variables.
@@ -20,30 +24,65 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	ClamAV   ClamAVConfig   `mapstructure:"clamav"`
 	Worker   WorkerConfig   `mapstructure:"worker"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
 
 	InternalAPISecret string `mapstructure:"internal_api_secret"`
 }
 
 // APIConfig contains HTTP server settings.
 type APIConfig struct {
-	Port                   int           `mapstructure:"port"`
-	MaxResumes             int           `mapstructure:"max_resumes"`
-	MaxTemplates           int           `mapstructure:"max_templates"`
-	LoginRateLimitPerHour  int           `mapstructure:"login_rate_limit_per_hour"`
-	LoginLockThreshold     int           `mapstructure:"login_lock_threshold"`
-	LoginLockTTLRaw        string        `mapstructure:"login_lock_ttl"`
-	LoginLockTTL           time.Duration `mapstructure:"-"`
-	AllowedOriginsRaw      string        `mapstructure:"allowed_origins"`
-	AllowedOrigins         []string      `mapstructure:"-"`
-	UploadMaxBytes         int           `mapstructure:"upload_max_bytes"`
-	UploadMIMEWhitelistRaw string        `mapstructure:"upload_mime_whitelist"`
-	UploadMIMEWhitelist    []string      `mapstructure:"-"`
-	PdfRateLimitPerHour    int           `mapstructure:"pdf_rate_limit_per_hour"`
-	PdfDownloadTokenTTLRaw string        `mapstructure:"pdf_download_token_ttl"`
-	PdfDownloadTokenTTL    time.Duration `mapstructure:"-"`
-	MaxAssetsPerUser       int           `mapstructure:"max_assets_per_user"`
-	MaxUploadsPerDay       int           `mapstructure:"max_uploads_per_day"`
-	CookieDomain           string        `mapstructure:"cookie_domain"`
+	Port                    int           `mapstructure:"port"`
+	MaxResumes              int           `mapstructure:"max_resumes"`
+	MaxTemplates            int           `mapstructure:"max_templates"`
+	TemplateMaxBytes        int           `mapstructure:"template_max_bytes"`      // 模板 content 原始 JSON 的字节上限
+	TemplateMaxItems        int           `mapstructure:"template_max_items"`      // 模板 content.items 的元素数量上限
+	GridMaxColumns          int           `mapstructure:"grid_max_columns"`        // 网格列数上限，需与前端 GRID_COLS 一致
+	GridMaxRows             int           `mapstructure:"grid_max_rows"`           // 网格行数上限（item 的 y+h 不得超过）
+	TemplatePublishReview   bool          `mapstructure:"template_publish_review"` // 用户发布模板需管理员审核后才公开
+	LoginRateLimitPerHour   int           `mapstructure:"login_rate_limit_per_hour"`
+	LoginLockThreshold      int           `mapstructure:"login_lock_threshold"`
+	LoginLockTTLRaw         string        `mapstructure:"login_lock_ttl"`
+	LoginLockTTL            time.Duration `mapstructure:"-"`
+	LoginUsernameLock       bool          `mapstructure:"login_username_lock"`
+	LoginLockDBFallback     bool          `mapstructure:"login_lock_db_fallback"` // 账号锁定同时落库，Redis 丢失状态时仍生效
+	LoginIPLockThreshold    int           `mapstructure:"login_ip_lock_threshold"`
+	LoginIPLockTTLRaw       string        `mapstructure:"login_ip_lock_ttl"`
+	LoginIPLockTTL          time.Duration `mapstructure:"-"`
+	LoginChallengeAfter     int           `mapstructure:"login_challenge_after"`
+	LoginChallengeProvider  string        `mapstructure:"login_challenge_provider"`
+	LoginChallengeToken     string        `mapstructure:"login_challenge_token"`
+	AllowedOriginsRaw       string        `mapstructure:"allowed_origins"`
+	AllowedOrigins          []string      `mapstructure:"-"`
+	UploadMaxBytes          int           `mapstructure:"upload_max_bytes"`
+	UploadMIMEWhitelistRaw  string        `mapstructure:"upload_mime_whitelist"`
+	UploadMIMEWhitelist     []string      `mapstructure:"-"`
+	UploadExtensionCheck    string        `mapstructure:"upload_extension_check"` // 扩展名与嗅探类型不一致时：strict 拒绝，lenient 告警并按嗅探类型存储
+	AssetOpaqueKeys         bool          `mapstructure:"asset_opaque_keys"`      // 新上传资产使用随机目录，object key 不含用户 ID
+	RefreshBinding          string        `mapstructure:"refresh_binding"`        // 刷新令牌设备绑定：off / warn（仅记录安全日志）/ enforce（拒绝刷新）
+	PdfRateLimitPerHour     int           `mapstructure:"pdf_rate_limit_per_hour"`
+	PdfDownloadTokenTTLRaw  string        `mapstructure:"pdf_download_token_ttl"`
+	PdfDownloadTokenTTL     time.Duration `mapstructure:"-"`
+	MaxAssetsPerUser        int           `mapstructure:"max_assets_per_user"`
+	MaxUploadsPerDay        int           `mapstructure:"max_uploads_per_day"`
+	StorageQuotaBytes       int64         `mapstructure:"storage_quota_bytes"` // 每用户存储字节配额，0 表示不限制
+	CookieDomain            string        `mapstructure:"cookie_domain"`
+	AccessTokenCookie       bool          `mapstructure:"access_token_cookie"` // 登录/刷新时额外以 HttpOnly Cookie 下发 access token
+	CookieSameSite          string        `mapstructure:"cookie_samesite"`     // 认证 Cookie 的 SameSite：lax/strict/none
+	CookieSecure            string        `mapstructure:"cookie_secure"`       // 认证 Cookie 的 Secure：auto 按 TLS/X-Forwarded-Proto 推断，true/false 强制
+	PrintStrictImageMIME    bool          `mapstructure:"print_strict_image_mime"`
+	PasswordGateDBCheck     bool          `mapstructure:"password_gate_db_check"`
+	MaxJSONBodyBytes        int           `mapstructure:"max_json_body_bytes"`
+	GzipMinBytes            int           `mapstructure:"gzip_min_bytes"`          // 响应体达到该字节数才压缩，0 表示全部压缩
+	RejectDupResumeTitle    bool          `mapstructure:"reject_dup_resume_title"` // 创建同名简历时返回 409（可被 allow_duplicate_title 覆盖）
+	DefaultResumeContent    string        `mapstructure:"default_resume_content"`  // 新用户起始简历 content 的 JSON 文件路径，空值使用内置默认
+	DefaultLocale           string        `mapstructure:"default_locale"`
+	SlowRequestThresholdRaw string        `mapstructure:"slow_request_threshold"`
+	SlowRequestThreshold    time.Duration `mapstructure:"-"` // 请求耗时超过该值时以 Warn 记录，0 表示关闭
+	// RegistrationMode 为注册策略：open 开放注册；invite 需携带邀请码；disabled 关闭注册（仅 cmd/admin 可建号）。
+	RegistrationMode string `mapstructure:"registration_mode"`
+	// RegistrationInviteCodes 为 invite 模式下可重复使用的静态邀请码；一次性邀请码由 cmd/admin 写入 Redis。
+	RegistrationInviteCodesRaw string   `mapstructure:"registration_invite_codes"`
+	RegistrationInviteCodes    []string `mapstructure:"-"`
 }
 
 // InternalConfig contains internal-only secrets shared between components.
@@ -59,12 +98,32 @@ type DatabaseConfig struct {
 	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
 	SSLMode  string `mapstructure:"sslmode"`
+	// MaxOpenConns / MaxIdleConns 为连接池上限与空闲连接数，空闲数不得超过上限。
+	MaxOpenConns int `mapstructure:"max_open_conns"`
+	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// ConnMaxLifetime 为单个连接的最长复用时间；0 表示不限制。
+	ConnMaxLifetimeRaw string        `mapstructure:"conn_max_lifetime"`
+	ConnMaxLifetime    time.Duration `mapstructure:"-"`
+	// ConnectAttempts / ConnectBackoff 控制启动时连接数据库的重试：第 n 次失败后等待 n×ConnectBackoff，
+	// 避免容器编排中数据库晚于 API/Worker 就绪时进程反复崩溃重启。
+	ConnectAttempts   int           `mapstructure:"connect_attempts"`
+	ConnectBackoffRaw string        `mapstructure:"connect_backoff"`
+	ConnectBackoff    time.Duration `mapstructure:"-"`
+	// AutoMigrate 为 true 时 API/Worker 启动时执行迁移（开发便利）；生产环境保持关闭，由 cmd/migrate 在发布前单独执行。
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 // RedisConfig 包含 Redis 连接配置。
 type RedisConfig struct {
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
+	// Mode 为部署拓扑：standalone 连接 Host:Port；sentinel 经 Addrs 中的哨兵发现 SentinelMaster 主节点；cluster 以 Addrs 为种子节点。
+	Mode           string   `mapstructure:"mode"`
+	AddrsRaw       string   `mapstructure:"addrs"`
+	Addrs          []string `mapstructure:"-"`
+	SentinelMaster string   `mapstructure:"sentinel_master"`
+	// NotifyNamespace 为用户通知频道与重放日志 key 的前缀（以冒号连接），多个部署共用一个 Redis 时用于隔离；API 与 Worker 必须一致。
+	NotifyNamespace string `mapstructure:"notify_namespace"`
 }
 
 // MinIOConfig contains connection options for MinIO/S3-compatible storage.
@@ -78,12 +137,39 @@ type MinIOConfig struct {
 	Region           string `mapstructure:"region"`
 	BucketLookup     string `mapstructure:"bucket_lookup"`
 	AutoCreateBucket bool   `mapstructure:"auto_create_bucket"`
+	PresignFallback  bool   `mapstructure:"presign_fallback"` // public endpoint 探测失败时改用内网 endpoint 签名
+
+	// 预签名链接有效期（duration，MinIO/S3 上限 7 天）。
+	PresignAssetListTTLRaw string `mapstructure:"presign_asset_list_ttl"`
+	PresignAssetViewTTLRaw string `mapstructure:"presign_asset_view_ttl"`
+	PresignPreviewTTLRaw   string `mapstructure:"presign_preview_ttl"`
+
+	PresignAssetListTTL time.Duration `mapstructure:"-"`
+	PresignAssetViewTTL time.Duration `mapstructure:"-"`
+	PresignPreviewTTL   time.Duration `mapstructure:"-"`
+
+	// 运行时单次操作超时（duration）与幂等操作（stat/get/delete/list）在瞬时错误上的重试次数。
+	RequestTimeoutRaw string `mapstructure:"request_timeout"`
+	UploadTimeoutRaw  string `mapstructure:"upload_timeout"`
+	RequestRetries    int    `mapstructure:"request_retries"`
+
+	RequestTimeout time.Duration `mapstructure:"-"`
+	UploadTimeout  time.Duration `mapstructure:"-"`
 }
 
+// maxPresignTTL 为 S3 V4 签名允许的最长有效期。
+const maxPresignTTL = 7 * 24 * time.Hour
+
 // ClamAVConfig contains connection options for ClamAV scanning service.
 type ClamAVConfig struct {
 	Host string `mapstructure:"host"`
 	Port string `mapstructure:"port"`
+
+	ScanTimeoutRaw string        `mapstructure:"scan_timeout"`
+	ScanTimeout    time.Duration `mapstructure:"-"` // 单次上传扫描的最长等待时间，超时返回 503
+
+	// ReadyRequired 为 true 时 clamd 不可达会使 /ready 返回 503；默认仅标记为降级，避免扫描服务故障把 API 摘出负载均衡。
+	ReadyRequired bool `mapstructure:"ready_required"`
 }
 
 // WorkerConfig 包含 Worker 运行参数（主要用于 PDF/预览渲染）。
@@ -92,19 +178,68 @@ type WorkerConfig struct {
 	FrontendBaseURL    string `mapstructure:"frontend_base_url"`
 	MetricsAddr        string `mapstructure:"metrics_addr"`
 	Concurrency        int    `mapstructure:"concurrency"`
+	MaxRendersPerUser  int    `mapstructure:"max_renders_per_user"`
+
+	// MaxConcurrentRenders 为进程内同时运行的浏览器渲染上限（PDF 与预览共享），独立于 Concurrency。
+	MaxConcurrentRenders int `mapstructure:"max_renders"`
+
+	BrowserLaunchAttempts   int           `mapstructure:"launch_attempts"`
+	BrowserLaunchBackoffRaw string        `mapstructure:"launch_backoff"`
+	BrowserLaunchBackoff    time.Duration `mapstructure:"-"`
+
+	// BrowserBin 为 Chromium 可执行文件路径，留空时自动查找；BrowserFlags 为追加的启动参数（空白分隔）。
+	BrowserBin      string   `mapstructure:"browser_bin"`
+	BrowserFlagsRaw string   `mapstructure:"browser_flags"`
+	BrowserFlags    []string `mapstructure:"-"`
+
+	// RenderMode 为 PDF 渲染方式：frontend（前端打印页）、auto（前端失败时改用服务端模板）、server（仅服务端模板）。
+	RenderMode string `mapstructure:"render_mode"`
+
+	// ShutdownTimeout 为收到退出信号后等待在途任务完成的时长，超时未完成的任务交还队列重试。
+	ShutdownTimeoutRaw string        `mapstructure:"shutdown_timeout"`
+	ShutdownTimeout    time.Duration `mapstructure:"-"`
+
+	// MaintenanceInterval 为周期性维护任务（缩略图对象校验、失效状态清理）的间隔。
+	MaintenanceIntervalRaw string        `mapstructure:"maintenance_interval"`
+	MaintenanceInterval    time.Duration `mapstructure:"-"`
+
+	// ResumeEventRetention 为简历生命周期事件的保留时长，由维护任务清理更早的事件；0 表示不清理。
+	ResumeEventRetentionRaw string        `mapstructure:"resume_event_retention"`
+	ResumeEventRetention    time.Duration `mapstructure:"-"`
+
+	// PDFBundleLinkTTL 为批量打包 zip 预签名下载链接的有效期（MinIO 上限 7 天）。
+	PDFBundleLinkTTLRaw string        `mapstructure:"pdf_bundle_link_ttl"`
+	PDFBundleLinkTTL    time.Duration `mapstructure:"-"`
+
+	// PreviewFormat 为缩略图格式（jpeg / webp），PreviewQuality 为压缩质量（1..100）。
+	PreviewFormat  string `mapstructure:"preview_format"`
+	PreviewQuality int    `mapstructure:"preview_quality"`
+
+	// HTMLToPDFAddr 为内部 HTML 转 PDF 接口的独立监听地址，留空表示不开放；HTMLToPDFMaxBytes 为其请求体上限。
+	HTMLToPDFAddr     string `mapstructure:"html_pdf_addr"`
+	HTMLToPDFMaxBytes int    `mapstructure:"html_pdf_max_bytes"`
+}
+
+// TracingConfig 包含链路追踪导出配置，API 与 Worker 共用。
+type TracingConfig struct {
+	// OTLPEndpoint 为 OTLP/HTTP 接收端基地址（如 http://otel-collector:4318）；为空时不导出 span。
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"`
 }
 
 // JWTConfig 包含 JWT 密钥与时效配置。
 type JWTConfig struct {
+	Algorithm          string `mapstructure:"algorithm"`
 	PrivateKeyBase64   string `mapstructure:"private_key"`
 	PublicKeyBase64    string `mapstructure:"public_key"`
 	AccessTokenTTLRaw  string `mapstructure:"access_token_ttl"`
 	RefreshTokenTTLRaw string `mapstructure:"refresh_token_ttl"`
+	SessionLifetimeRaw string `mapstructure:"session_lifetime"` // 自登录起的会话绝对有效期，0 表示不限制
 
 	PrivateKeyPEM   []byte        `mapstructure:"-"`
 	PublicKeyPEM    []byte        `mapstructure:"-"`
 	AccessTokenTTL  time.Duration `mapstructure:"-"`
 	RefreshTokenTTL time.Duration `mapstructure:"-"`
+	SessionLifetime time.Duration `mapstructure:"-"`
 }
 
 // DSN builds a lib/pq compatible connection string.
@@ -142,10 +277,26 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("prepare api config: %w", err)
 	}
 
+	if err := cfg.Database.prepare(); err != nil {
+		return nil, fmt.Errorf("prepare database config: %w", err)
+	}
+
 	if err := cfg.JWT.prepare(); err != nil {
 		return nil, fmt.Errorf("prepare jwt config: %w", err)
 	}
 
+	if err := cfg.MinIO.prepare(); err != nil {
+		return nil, fmt.Errorf("prepare minio config: %w", err)
+	}
+
+	if err := cfg.ClamAV.prepare(); err != nil {
+		return nil, fmt.Errorf("prepare clamav config: %w", err)
+	}
+
+	if err := cfg.Worker.prepare(); err != nil {
+		return nil, fmt.Errorf("prepare worker config: %w", err)
+	}
+
 	if err := validate(cfg); err != nil {
 		return nil, err
 	}
@@ -172,9 +323,27 @@ func applyFirstNonEmptyEnv(target *string, envs ...string) {
 
 func normalize(cfg *Config) {
 	cfg.InternalAPISecret = strings.TrimSpace(cfg.InternalAPISecret)
+	if locale, ok := i18n.Normalize(cfg.API.DefaultLocale); ok {
+		cfg.API.DefaultLocale = locale
+	}
+	cfg.API.LoginChallengeProvider = strings.ToLower(strings.TrimSpace(cfg.API.LoginChallengeProvider))
+	cfg.API.LoginChallengeToken = strings.TrimSpace(cfg.API.LoginChallengeToken)
+	cfg.API.CookieSameSite = strings.ToLower(strings.TrimSpace(cfg.API.CookieSameSite))
+	cfg.API.CookieSecure = strings.ToLower(strings.TrimSpace(cfg.API.CookieSecure))
+	cfg.API.RegistrationMode = strings.ToLower(strings.TrimSpace(cfg.API.RegistrationMode))
+	cfg.API.UploadExtensionCheck = strings.ToLower(strings.TrimSpace(cfg.API.UploadExtensionCheck))
+	cfg.API.RefreshBinding = strings.ToLower(strings.TrimSpace(cfg.API.RefreshBinding))
 	cfg.Worker.InternalAPIBaseURL = normalizeBaseURL(cfg.Worker.InternalAPIBaseURL)
 	cfg.Worker.FrontendBaseURL = normalizeBaseURL(cfg.Worker.FrontendBaseURL)
 	cfg.Worker.MetricsAddr = strings.TrimSpace(cfg.Worker.MetricsAddr)
+	cfg.Worker.PreviewFormat = strings.ToLower(strings.TrimSpace(cfg.Worker.PreviewFormat))
+	cfg.Worker.RenderMode = strings.ToLower(strings.TrimSpace(cfg.Worker.RenderMode))
+	cfg.Tracing.OTLPEndpoint = normalizeBaseURL(cfg.Tracing.OTLPEndpoint)
+	cfg.Redis.NotifyNamespace = strings.TrimSuffix(strings.TrimSpace(cfg.Redis.NotifyNamespace), ":")
+	cfg.Redis.Mode = strings.ToLower(strings.TrimSpace(cfg.Redis.Mode))
+	cfg.Redis.Addrs = splitAndTrim(cfg.Redis.AddrsRaw)
+	cfg.Redis.SentinelMaster = strings.TrimSpace(cfg.Redis.SentinelMaster)
+	cfg.JWT.Algorithm = strings.ToUpper(strings.TrimSpace(cfg.JWT.Algorithm))
 }
 
 func normalizeBaseURL(value string) string {
@@ -194,25 +363,64 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("api.port", 8080)
 	v.SetDefault("api.max_resumes", 3)
 	v.SetDefault("api.max_templates", 2)
+	v.SetDefault("api.template_max_bytes", 256*1024)
+	v.SetDefault("api.template_max_items", 200)
+	v.SetDefault("api.grid_max_columns", 24)
+	v.SetDefault("api.grid_max_rows", 1000)
+	v.SetDefault("api.template_publish_review", false)
 	v.SetDefault("api.login_rate_limit_per_hour", 10)
 	v.SetDefault("api.login_lock_threshold", 5)
 	v.SetDefault("api.login_lock_ttl", "30m")
+	v.SetDefault("api.login_username_lock", true)
+	v.SetDefault("api.login_lock_db_fallback", false)
+	v.SetDefault("api.login_ip_lock_threshold", 20)
+	v.SetDefault("api.login_ip_lock_ttl", "30m")
+	v.SetDefault("api.login_challenge_after", 3)
+	v.SetDefault("api.login_challenge_provider", "noop")
+	v.SetDefault("api.login_challenge_token", "")
 	v.SetDefault("api.allowed_origins", "")
 	v.SetDefault("api.upload_max_bytes", 5*1024*1024)
-	v.SetDefault("api.upload_mime_whitelist", "image/png,image/jpeg,image/webp")
+	v.SetDefault("api.upload_mime_whitelist", "image/png,image/jpeg,image/webp,font/ttf,font/woff2")
 	v.SetDefault("api.pdf_rate_limit_per_hour", 3)
 	v.SetDefault("api.pdf_download_token_ttl", "60s")
 	v.SetDefault("api.max_assets_per_user", 4)
 	v.SetDefault("api.max_uploads_per_day", 4)
+	v.SetDefault("api.storage_quota_bytes", 50*1024*1024)
 	v.SetDefault("api.cookie_domain", "")
+	v.SetDefault("api.access_token_cookie", false)
+	v.SetDefault("api.cookie_samesite", "lax")
+	v.SetDefault("api.registration_mode", "open")
+	v.SetDefault("api.upload_extension_check", "lenient")
+	v.SetDefault("api.asset_opaque_keys", false)
+	v.SetDefault("api.refresh_binding", "off")
+	v.SetDefault("api.registration_invite_codes", "")
+	v.SetDefault("api.cookie_secure", "auto")
+	v.SetDefault("api.print_strict_image_mime", false)
+	v.SetDefault("api.password_gate_db_check", false)
+	v.SetDefault("api.max_json_body_bytes", 1024*1024)
+	v.SetDefault("api.gzip_min_bytes", 1024)
+	v.SetDefault("api.reject_dup_resume_title", false)
+	v.SetDefault("api.default_resume_content", "")
+	v.SetDefault("api.default_locale", i18n.DefaultLocale)
+	v.SetDefault("api.slow_request_threshold", "1s")
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", 5432)
 	v.SetDefault("database.name", "phresume")
 	v.SetDefault("database.user", "phresume")
 	v.SetDefault("database.password", "phresume")
 	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.max_open_conns", 25)
+	v.SetDefault("database.max_idle_conns", 5)
+	v.SetDefault("database.conn_max_lifetime", "30m")
+	v.SetDefault("database.connect_attempts", 10)
+	v.SetDefault("database.connect_backoff", "1s")
+	v.SetDefault("database.auto_migrate", false)
 	v.SetDefault("redis.host", "localhost")
 	v.SetDefault("redis.port", 6379)
+	v.SetDefault("redis.notify_namespace", "")
+	v.SetDefault("redis.mode", "standalone")
+	v.SetDefault("redis.addrs", "")
+	v.SetDefault("redis.sentinel_master", "")
 	v.SetDefault("minio.endpoint", "localhost:9000")
 	v.SetDefault("minio.use_ssl", false)
 	v.SetDefault("minio.bucket", "resumes")
@@ -220,14 +428,40 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("minio.region", "us-east-1")
 	v.SetDefault("minio.bucket_lookup", "auto")
 	v.SetDefault("minio.auto_create_bucket", true)
+	v.SetDefault("minio.presign_fallback", false)
+	v.SetDefault("minio.presign_asset_list_ttl", "10m")
+	v.SetDefault("minio.presign_asset_view_ttl", "15m")
+	v.SetDefault("minio.presign_preview_ttl", "1h")
+	v.SetDefault("minio.request_timeout", "10s")
+	v.SetDefault("minio.upload_timeout", "60s")
+	v.SetDefault("minio.request_retries", 2)
+	v.SetDefault("jwt.algorithm", "RS256")
 	v.SetDefault("jwt.access_token_ttl", "15m")
 	v.SetDefault("jwt.refresh_token_ttl", "168h")
+	v.SetDefault("jwt.session_lifetime", "720h")
 	v.SetDefault("clamav.host", "clamav")
 	v.SetDefault("clamav.port", "3310")
+	v.SetDefault("clamav.scan_timeout", "30s")
+	v.SetDefault("clamav.ready_required", false)
 	v.SetDefault("worker.internal_api_base_url", "http://api:8080")
 	v.SetDefault("worker.frontend_base_url", "http://frontend:3000")
 	v.SetDefault("worker.metrics_addr", ":9100")
 	v.SetDefault("worker.concurrency", 10)
+	v.SetDefault("worker.max_renders_per_user", 2)
+	v.SetDefault("worker.max_renders", 3)
+	v.SetDefault("worker.launch_attempts", 3)
+	v.SetDefault("worker.launch_backoff", "500ms")
+	v.SetDefault("worker.browser_bin", "")
+	v.SetDefault("worker.browser_flags", "")
+	v.SetDefault("worker.render_mode", "frontend")
+	v.SetDefault("worker.shutdown_timeout", "30s")
+	v.SetDefault("worker.maintenance_interval", "6h")
+	v.SetDefault("worker.resume_event_retention", "720h")
+	v.SetDefault("worker.pdf_bundle_link_ttl", "1h")
+	v.SetDefault("worker.preview_format", "jpeg")
+	v.SetDefault("worker.preview_quality", 80)
+	v.SetDefault("worker.html_pdf_addr", "")
+	v.SetDefault("worker.html_pdf_max_bytes", 2<<20)
 }
 
 func bindEnv(v *viper.Viper) error {
@@ -235,9 +469,21 @@ func bindEnv(v *viper.Viper) error {
 		"api.port":                      {"API_PORT"},
 		"api.max_resumes":               {"API_MAX_RESUMES"},
 		"api.max_templates":             {"API_MAX_TEMPLATES"},
+		"api.template_max_bytes":        {"API_TEMPLATE_MAX_BYTES"},
+		"api.template_max_items":        {"API_TEMPLATE_MAX_ITEMS"},
+		"api.grid_max_columns":          {"API_GRID_MAX_COLUMNS"},
+		"api.grid_max_rows":             {"API_GRID_MAX_ROWS"},
+		"api.template_publish_review":   {"API_TEMPLATE_PUBLISH_REVIEW"},
 		"api.login_rate_limit_per_hour": {"API_LOGIN_RATE_LIMIT_PER_HOUR"},
 		"api.login_lock_threshold":      {"API_LOGIN_LOCK_THRESHOLD"},
 		"api.login_lock_ttl":            {"API_LOGIN_LOCK_TTL"},
+		"api.login_username_lock":       {"API_LOGIN_USERNAME_LOCK"},
+		"api.login_lock_db_fallback":    {"API_LOGIN_LOCK_DB_FALLBACK"},
+		"api.login_ip_lock_threshold":   {"API_LOGIN_IP_LOCK_THRESHOLD"},
+		"api.login_ip_lock_ttl":         {"API_LOGIN_IP_LOCK_TTL"},
+		"api.login_challenge_after":     {"API_LOGIN_CHALLENGE_AFTER"},
+		"api.login_challenge_provider":  {"API_LOGIN_CHALLENGE_PROVIDER"},
+		"api.login_challenge_token":     {"API_LOGIN_CHALLENGE_TOKEN"},
 		"api.allowed_origins":           {"API_ALLOWED_ORIGINS"},
 		"api.upload_max_bytes":          {"API_UPLOAD_MAX_BYTES"},
 		"api.upload_mime_whitelist":     {"API_UPLOAD_MIME_WHITELIST"},
@@ -245,15 +491,42 @@ func bindEnv(v *viper.Viper) error {
 		"api.pdf_download_token_ttl":    {"API_PDF_DOWNLOAD_TOKEN_TTL"},
 		"api.max_assets_per_user":       {"API_MAX_ASSETS_PER_USER"},
 		"api.max_uploads_per_day":       {"API_MAX_UPLOADS_PER_DAY"},
+		"api.storage_quota_bytes":       {"API_STORAGE_QUOTA_BYTES"},
 		"api.cookie_domain":             {"API_COOKIE_DOMAIN"},
+		"api.access_token_cookie":       {"API_ACCESS_TOKEN_COOKIE"},
+		"api.cookie_samesite":           {"API_COOKIE_SAMESITE"},
+		"api.registration_mode":         {"API_REGISTRATION_MODE"},
+		"api.upload_extension_check":    {"API_UPLOAD_EXTENSION_CHECK"},
+		"api.asset_opaque_keys":         {"API_ASSET_OPAQUE_KEYS"},
+		"api.refresh_binding":           {"API_REFRESH_BINDING"},
+		"api.registration_invite_codes": {"API_REGISTRATION_INVITE_CODES"},
+		"api.cookie_secure":             {"API_COOKIE_SECURE"},
+		"api.print_strict_image_mime":   {"API_PRINT_STRICT_IMAGE_MIME"},
+		"api.password_gate_db_check":    {"API_PASSWORD_GATE_DB_CHECK"},
+		"api.max_json_body_bytes":       {"API_MAX_JSON_BODY_BYTES"},
+		"api.gzip_min_bytes":            {"API_GZIP_MIN_BYTES"},
+		"api.reject_dup_resume_title":   {"API_REJECT_DUPLICATE_RESUME_TITLE"},
+		"api.default_resume_content":    {"API_DEFAULT_RESUME_CONTENT_FILE"},
+		"api.default_locale":            {"API_DEFAULT_LOCALE"},
+		"api.slow_request_threshold":    {"API_SLOW_REQUEST_THRESHOLD"},
 		"database.host":                 {"DATABASE_HOST"},
 		"database.port":                 {"DATABASE_PORT"},
 		"database.name":                 {"POSTGRES_DB", "DB_NAME"},
 		"database.user":                 {"POSTGRES_USER", "DB_USER"},
 		"database.password":             {"POSTGRES_PASSWORD", "DB_PASSWORD"},
 		"database.sslmode":              {"DATABASE_SSLMODE"},
+		"database.max_open_conns":       {"DATABASE_MAX_OPEN_CONNS"},
+		"database.max_idle_conns":       {"DATABASE_MAX_IDLE_CONNS"},
+		"database.conn_max_lifetime":    {"DATABASE_CONN_MAX_LIFETIME"},
+		"database.connect_attempts":     {"DATABASE_CONNECT_ATTEMPTS"},
+		"database.connect_backoff":      {"DATABASE_CONNECT_BACKOFF"},
+		"database.auto_migrate":         {"DATABASE_AUTO_MIGRATE"},
 		"redis.host":                    {"REDIS_HOST"},
 		"redis.port":                    {"REDIS_PORT"},
+		"redis.notify_namespace":        {"REDIS_NOTIFY_NAMESPACE"},
+		"redis.mode":                    {"REDIS_MODE"},
+		"redis.addrs":                   {"REDIS_ADDRS"},
+		"redis.sentinel_master":         {"REDIS_SENTINEL_MASTER"},
 		"minio.endpoint":                {"MINIO_ENDPOINT"},
 		"minio.access_key_id":           {"MINIO_ACCESS_KEY_ID", "MINIO_ROOT_USER"},
 		"minio.secret_access_key":       {"MINIO_SECRET_ACCESS_KEY", "MINIO_ROOT_PASSWORD"},
@@ -263,17 +536,44 @@ func bindEnv(v *viper.Viper) error {
 		"minio.region":                  {"MINIO_REGION"},
 		"minio.bucket_lookup":           {"MINIO_BUCKET_LOOKUP"},
 		"minio.auto_create_bucket":      {"MINIO_AUTO_CREATE_BUCKET"},
+		"minio.presign_fallback":        {"MINIO_PRESIGN_FALLBACK"},
+		"minio.presign_asset_list_ttl":  {"MINIO_PRESIGN_ASSET_LIST_TTL"},
+		"minio.presign_asset_view_ttl":  {"MINIO_PRESIGN_ASSET_VIEW_TTL"},
+		"minio.presign_preview_ttl":     {"MINIO_PRESIGN_PREVIEW_TTL"},
+		"minio.request_timeout":         {"MINIO_REQUEST_TIMEOUT"},
+		"minio.upload_timeout":          {"MINIO_UPLOAD_TIMEOUT"},
+		"minio.request_retries":         {"MINIO_REQUEST_RETRIES"},
+		"jwt.algorithm":                 {"JWT_ALGORITHM"},
 		"jwt.private_key":               {"JWT_PRIVATE_KEY"},
 		"jwt.public_key":                {"JWT_PUBLIC_KEY"},
 		"jwt.access_token_ttl":          {"JWT_ACCESS_TOKEN_TTL"},
 		"jwt.refresh_token_ttl":         {"JWT_REFRESH_TOKEN_TTL"},
+		"jwt.session_lifetime":          {"JWT_SESSION_LIFETIME"},
 		"clamav.host":                   {"CLAMAV_HOST"},
 		"clamav.port":                   {"CLAMAV_PORT"},
+		"clamav.scan_timeout":           {"CLAMAV_SCAN_TIMEOUT"},
+		"clamav.ready_required":         {"CLAMAV_READY_REQUIRED"},
 		"worker.internal_api_base_url":  {"WORKER_INTERNAL_API_BASE_URL"},
 		"worker.frontend_base_url":      {"WORKER_FRONTEND_BASE_URL"},
 		"worker.metrics_addr":           {"WORKER_METRICS_ADDR"},
 		"worker.concurrency":            {"WORKER_CONCURRENCY"},
+		"worker.max_renders_per_user":   {"WORKER_MAX_RENDERS_PER_USER"},
+		"worker.max_renders":            {"WORKER_MAX_CONCURRENT_RENDERS"},
+		"worker.launch_attempts":        {"WORKER_BROWSER_LAUNCH_ATTEMPTS"},
+		"worker.launch_backoff":         {"WORKER_BROWSER_LAUNCH_BACKOFF"},
+		"worker.browser_bin":            {"WORKER_BROWSER_BIN"},
+		"worker.browser_flags":          {"WORKER_BROWSER_FLAGS"},
+		"worker.render_mode":            {"WORKER_RENDER_MODE"},
+		"worker.maintenance_interval":   {"WORKER_MAINTENANCE_INTERVAL"},
+		"worker.resume_event_retention": {"WORKER_RESUME_EVENT_RETENTION"},
+		"worker.pdf_bundle_link_ttl":    {"WORKER_PDF_BUNDLE_LINK_TTL"},
+		"worker.shutdown_timeout":       {"WORKER_SHUTDOWN_TIMEOUT"},
+		"worker.preview_format":         {"WORKER_PREVIEW_FORMAT"},
+		"worker.preview_quality":        {"WORKER_PREVIEW_QUALITY"},
+		"worker.html_pdf_addr":          {"WORKER_HTML_TO_PDF_ADDR"},
+		"worker.html_pdf_max_bytes":     {"WORKER_HTML_TO_PDF_MAX_BYTES"},
 		"internal_api_secret":           {"INTERNAL_API_SECRET"},
+		"tracing.otlp_endpoint":         {"OTEL_EXPORTER_OTLP_ENDPOINT"},
 	}
 
 	for key, envs := range mappings {
@@ -296,6 +596,18 @@ func validate(cfg Config) error {
 	if cfg.API.MaxTemplates <= 0 {
 		return errors.New("api max templates must be positive")
 	}
+	if cfg.API.TemplateMaxBytes <= 0 {
+		return errors.New("api template max bytes must be positive")
+	}
+	if cfg.API.TemplateMaxItems <= 0 {
+		return errors.New("api template max items must be positive")
+	}
+	if cfg.API.GridMaxColumns <= 0 {
+		return errors.New("api grid max columns must be positive")
+	}
+	if cfg.API.GridMaxRows <= 0 {
+		return errors.New("api grid max rows must be positive")
+	}
 	if cfg.API.LoginRateLimitPerHour <= 0 {
 		return errors.New("api login rate limit per hour must be positive")
 	}
@@ -305,9 +617,75 @@ func validate(cfg Config) error {
 	if cfg.API.LoginLockTTL <= 0 {
 		return errors.New("api login lock ttl must be positive")
 	}
+	if cfg.API.LoginIPLockThreshold <= 0 {
+		return errors.New("api login ip lock threshold must be positive")
+	}
+	if cfg.API.LoginIPLockTTL <= 0 {
+		return errors.New("api login ip lock ttl must be positive")
+	}
+	if cfg.API.LoginChallengeAfter < 0 {
+		return errors.New("api login challenge after must not be negative")
+	}
+	switch cfg.API.LoginChallengeProvider {
+	case auth.ChallengeProviderNoop:
+	case auth.ChallengeProviderStub:
+		if cfg.API.LoginChallengeToken == "" {
+			return errors.New("api login challenge token is required for the stub provider")
+		}
+	default:
+		return errors.New("api login challenge provider must be one of: noop,stub")
+	}
+	switch cfg.API.CookieSameSite {
+	case "lax", "strict", "none":
+	default:
+		return errors.New("api cookie samesite must be one of: lax,strict,none")
+	}
+	switch cfg.API.CookieSecure {
+	case "auto", "true", "false":
+	default:
+		return errors.New("api cookie secure must be one of: auto,true,false")
+	}
+	switch cfg.API.RegistrationMode {
+	case "open", "invite", "disabled":
+	default:
+		return errors.New("api registration mode must be one of: open,invite,disabled")
+	}
+	switch cfg.API.UploadExtensionCheck {
+	case "strict", "lenient":
+	default:
+		return errors.New("api upload extension check must be one of: strict,lenient")
+	}
+	switch cfg.API.RefreshBinding {
+	case "off", "warn", "enforce":
+	default:
+		return errors.New("api refresh binding must be one of: off,warn,enforce")
+	}
+	// 浏览器会拒绝未带 Secure 的 SameSite=None Cookie，auto 也无法保证每次都带上。
+	if cfg.API.CookieSameSite == "none" && cfg.API.CookieSecure != "true" {
+		return errors.New("api cookie samesite=none requires cookie secure=true")
+	}
+	// Cookie 携带的 access token 没有 CSRF 防护，只能依赖 SameSite 阻止跨站请求自动带上凭证。
+	if cfg.API.AccessTokenCookie && cfg.API.CookieSameSite == "none" {
+		return errors.New("api access token cookie cannot be used with cookie samesite=none")
+	}
 	if cfg.API.UploadMaxBytes <= 0 {
 		return errors.New("api upload max bytes must be positive")
 	}
+	if cfg.API.StorageQuotaBytes < 0 {
+		return errors.New("api storage quota bytes must not be negative")
+	}
+	if cfg.API.MaxJSONBodyBytes <= 0 {
+		return errors.New("api max json body bytes must be positive")
+	}
+	if cfg.API.SlowRequestThreshold < 0 {
+		return errors.New("api slow request threshold must not be negative")
+	}
+	if cfg.API.GzipMinBytes < 0 {
+		return errors.New("api gzip min bytes must not be negative")
+	}
+	if !i18n.Supported(cfg.API.DefaultLocale) {
+		return fmt.Errorf("api default locale must be one of: %s,%s", i18n.EN, i18n.ZhCN)
+	}
 	if len(cfg.API.UploadMIMEWhitelist) == 0 {
 		return errors.New("api upload mime whitelist must not be empty")
 	}
@@ -341,11 +719,48 @@ func validate(cfg Config) error {
 	if cfg.Database.SSLMode == "" {
 		return errors.New("database sslmode is required")
 	}
-	if cfg.Redis.Host == "" {
-		return errors.New("redis host is required")
+	if cfg.Database.MaxOpenConns <= 0 {
+		return errors.New("database max open conns must be positive")
+	}
+	if cfg.Database.MaxIdleConns < 0 {
+		return errors.New("database max idle conns must be non-negative")
+	}
+	if cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns {
+		return errors.New("database max idle conns must not exceed max open conns")
+	}
+	if cfg.Database.ConnMaxLifetime < 0 {
+		return errors.New("database conn max lifetime must be non-negative")
 	}
-	if cfg.Redis.Port <= 0 {
-		return errors.New("redis port must be positive")
+	if cfg.Database.ConnectAttempts <= 0 {
+		return errors.New("database connect attempts must be positive")
+	}
+	if cfg.Database.ConnectBackoff < 0 {
+		return errors.New("database connect backoff must be non-negative")
+	}
+	switch cfg.Redis.Mode {
+	case "standalone":
+		if cfg.Redis.Host == "" {
+			return errors.New("redis host is required")
+		}
+		if cfg.Redis.Port <= 0 {
+			return errors.New("redis port must be positive")
+		}
+	case "sentinel":
+		if len(cfg.Redis.Addrs) == 0 {
+			return errors.New("redis addrs are required in sentinel mode")
+		}
+		if cfg.Redis.SentinelMaster == "" {
+			return errors.New("redis sentinel master is required in sentinel mode")
+		}
+	case "cluster":
+		if len(cfg.Redis.Addrs) == 0 {
+			return errors.New("redis addrs are required in cluster mode")
+		}
+	default:
+		return errors.New("redis mode must be one of: standalone,sentinel,cluster")
+	}
+	if strings.ContainsFunc(cfg.Redis.NotifyNamespace, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) {
+		return errors.New("redis notify namespace must not contain whitespace")
 	}
 	if cfg.MinIO.Endpoint == "" {
 		return errors.New("minio endpoint is required")
@@ -370,12 +785,33 @@ func validate(cfg Config) error {
 	default:
 		return errors.New("minio bucket lookup must be one of: auto,dns,path")
 	}
+	if cfg.MinIO.PresignAssetListTTL <= 0 || cfg.MinIO.PresignAssetListTTL > maxPresignTTL {
+		return errors.New("minio presign asset list ttl must be within (0, 168h]")
+	}
+	if cfg.MinIO.PresignAssetViewTTL <= 0 || cfg.MinIO.PresignAssetViewTTL > maxPresignTTL {
+		return errors.New("minio presign asset view ttl must be within (0, 168h]")
+	}
+	if cfg.MinIO.PresignPreviewTTL <= 0 || cfg.MinIO.PresignPreviewTTL > maxPresignTTL {
+		return errors.New("minio presign preview ttl must be within (0, 168h]")
+	}
+	if cfg.MinIO.RequestTimeout <= 0 {
+		return errors.New("minio request timeout must be positive")
+	}
+	if cfg.MinIO.UploadTimeout <= 0 {
+		return errors.New("minio upload timeout must be positive")
+	}
+	if cfg.MinIO.RequestRetries < 0 || cfg.MinIO.RequestRetries > 5 {
+		return errors.New("minio request retries must be within [0, 5]")
+	}
 	if cfg.ClamAV.Host == "" {
 		return errors.New("clamav host is required")
 	}
 	if cfg.ClamAV.Port == "" {
 		return errors.New("clamav port is required")
 	}
+	if cfg.ClamAV.ScanTimeout <= 0 {
+		return errors.New("clamav scan timeout must be positive")
+	}
 	if strings.TrimSpace(cfg.Worker.InternalAPIBaseURL) == "" {
 		return errors.New("worker internal api base url is required")
 	}
@@ -391,6 +827,14 @@ func validate(cfg Config) error {
 	if strings.TrimSpace(cfg.InternalAPISecret) == "" {
 		return errors.New("internal api secret is required")
 	}
+	if endpoint := cfg.Tracing.OTLPEndpoint; endpoint != "" && !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return errors.New("otel exporter otlp endpoint must start with http:// or https://")
+	}
+	switch cfg.JWT.Algorithm {
+	case "RS256", "ES256":
+	default:
+		return errors.New("jwt algorithm must be one of: RS256,ES256")
+	}
 	if len(cfg.JWT.PrivateKeyPEM) == 0 {
 		return errors.New("jwt private key is required")
 	}
@@ -403,6 +847,9 @@ func validate(cfg Config) error {
 	if cfg.JWT.RefreshTokenTTL <= 0 {
 		return errors.New("jwt refresh token ttl must be positive")
 	}
+	if cfg.JWT.SessionLifetime < 0 {
+		return errors.New("jwt session lifetime must not be negative")
+	}
 	if strings.TrimSpace(cfg.Worker.InternalAPIBaseURL) == "" {
 		return errors.New("worker internal api base url is required")
 	}
@@ -415,6 +862,54 @@ func validate(cfg Config) error {
 	if cfg.Worker.Concurrency <= 0 {
 		return errors.New("worker concurrency must be positive")
 	}
+	if cfg.Worker.MaxRendersPerUser <= 0 {
+		return errors.New("worker max renders per user must be positive")
+	}
+	if cfg.Worker.MaxConcurrentRenders <= 0 {
+		return errors.New("worker max concurrent renders must be positive")
+	}
+	if cfg.Worker.BrowserLaunchAttempts <= 0 {
+		return errors.New("worker browser launch attempts must be positive")
+	}
+	if cfg.Worker.BrowserLaunchBackoff < 0 {
+		return errors.New("worker browser launch backoff must not be negative")
+	}
+	for _, flag := range cfg.Worker.BrowserFlags {
+		if name, _, _ := strings.Cut(strings.TrimLeft(flag, "-"), "="); name == "" {
+			return fmt.Errorf("worker browser flag %q must have a name", flag)
+		}
+	}
+	if cfg.Worker.ShutdownTimeout <= 0 {
+		return errors.New("worker shutdown timeout must be positive")
+	}
+	if cfg.Worker.MaintenanceInterval <= 0 {
+		return errors.New("worker maintenance interval must be positive")
+	}
+	if cfg.Worker.ResumeEventRetention < 0 {
+		return errors.New("worker resume event retention must not be negative")
+	}
+	if cfg.Worker.PDFBundleLinkTTL <= 0 || cfg.Worker.PDFBundleLinkTTL > 7*24*time.Hour {
+		return errors.New("worker pdf bundle link ttl must be between 1s and 168h")
+	}
+	switch cfg.Worker.RenderMode {
+	case "frontend", "auto", "server":
+	default:
+		return errors.New("worker render mode must be one of: frontend,auto,server")
+	}
+	switch cfg.Worker.PreviewFormat {
+	case "jpeg", "webp":
+	default:
+		return errors.New("worker preview format must be one of: jpeg,webp")
+	}
+	if cfg.Worker.PreviewQuality < 1 || cfg.Worker.PreviewQuality > 100 {
+		return errors.New("worker preview quality must be within [1, 100]")
+	}
+	if cfg.Worker.HTMLToPDFAddr != "" && cfg.Worker.HTMLToPDFAddr == cfg.Worker.MetricsAddr {
+		return errors.New("worker html to pdf addr must differ from worker metrics addr")
+	}
+	if cfg.Worker.HTMLToPDFMaxBytes <= 0 || cfg.Worker.HTMLToPDFMaxBytes > 10<<20 {
+		return errors.New("worker html to pdf max bytes must be within (0, 10485760]")
+	}
 	return nil
 }
 
@@ -428,6 +923,12 @@ func (a *APIConfig) prepare() error {
 	}
 	a.LoginLockTTL = d
 
+	ipLockTTL, err := time.ParseDuration(strings.TrimSpace(a.LoginIPLockTTLRaw))
+	if err != nil {
+		return fmt.Errorf("parse api login ip lock ttl: %w", err)
+	}
+	a.LoginIPLockTTL = ipLockTTL
+
 	if strings.TrimSpace(a.PdfDownloadTokenTTLRaw) == "" {
 		return errors.New("api pdf download token ttl is required")
 	}
@@ -437,6 +938,12 @@ func (a *APIConfig) prepare() error {
 	}
 	a.PdfDownloadTokenTTL = tokenTTL
 
+	slowThreshold, err := time.ParseDuration(strings.TrimSpace(a.SlowRequestThresholdRaw))
+	if err != nil {
+		return fmt.Errorf("parse api slow request threshold: %w", err)
+	}
+	a.SlowRequestThreshold = slowThreshold
+
 	if a.AllowedOriginsRaw != "" {
 		parts := []string{}
 		for _, p := range splitAndTrim(a.AllowedOriginsRaw) {
@@ -452,8 +959,10 @@ func (a *APIConfig) prepare() error {
 	if a.UploadMIMEWhitelistRaw != "" {
 		a.UploadMIMEWhitelist = splitAndTrim(a.UploadMIMEWhitelistRaw)
 	} else {
-		a.UploadMIMEWhitelist = []string{"image/png", "image/jpeg", "image/webp"}
+		a.UploadMIMEWhitelist = []string{"image/png", "image/jpeg", "image/webp", "font/ttf", "font/woff2"}
 	}
+
+	a.RegistrationInviteCodes = splitAndTrim(a.RegistrationInviteCodesRaw)
 	return nil
 }
 
@@ -477,6 +986,101 @@ func splitAndTrim(s string) []string {
 	return out
 }
 
+func (m *MinIOConfig) prepare() error {
+	listTTL, err := time.ParseDuration(strings.TrimSpace(m.PresignAssetListTTLRaw))
+	if err != nil {
+		return fmt.Errorf("parse minio presign asset list ttl: %w", err)
+	}
+	m.PresignAssetListTTL = listTTL
+
+	viewTTL, err := time.ParseDuration(strings.TrimSpace(m.PresignAssetViewTTLRaw))
+	if err != nil {
+		return fmt.Errorf("parse minio presign asset view ttl: %w", err)
+	}
+	m.PresignAssetViewTTL = viewTTL
+
+	previewTTL, err := time.ParseDuration(strings.TrimSpace(m.PresignPreviewTTLRaw))
+	if err != nil {
+		return fmt.Errorf("parse minio presign preview ttl: %w", err)
+	}
+	m.PresignPreviewTTL = previewTTL
+
+	requestTimeout, err := time.ParseDuration(strings.TrimSpace(m.RequestTimeoutRaw))
+	if err != nil {
+		return fmt.Errorf("parse minio request timeout: %w", err)
+	}
+	m.RequestTimeout = requestTimeout
+
+	uploadTimeout, err := time.ParseDuration(strings.TrimSpace(m.UploadTimeoutRaw))
+	if err != nil {
+		return fmt.Errorf("parse minio upload timeout: %w", err)
+	}
+	m.UploadTimeout = uploadTimeout
+
+	return nil
+}
+
+func (c *ClamAVConfig) prepare() error {
+	timeout, err := time.ParseDuration(strings.TrimSpace(c.ScanTimeoutRaw))
+	if err != nil {
+		return fmt.Errorf("parse clamav scan timeout: %w", err)
+	}
+	c.ScanTimeout = timeout
+	return nil
+}
+
+func (w *WorkerConfig) prepare() error {
+	backoff, err := time.ParseDuration(strings.TrimSpace(w.BrowserLaunchBackoffRaw))
+	if err != nil {
+		return fmt.Errorf("parse worker browser launch backoff: %w", err)
+	}
+	w.BrowserLaunchBackoff = backoff
+	w.BrowserBin = strings.TrimSpace(w.BrowserBin)
+	w.HTMLToPDFAddr = strings.TrimSpace(w.HTMLToPDFAddr)
+	// 按空白分隔：--disable-features=A,B 这类参数值本身含逗号。
+	w.BrowserFlags = strings.Fields(w.BrowserFlagsRaw)
+
+	shutdownTimeout, err := time.ParseDuration(strings.TrimSpace(w.ShutdownTimeoutRaw))
+	if err != nil {
+		return fmt.Errorf("parse worker shutdown timeout: %w", err)
+	}
+	w.ShutdownTimeout = shutdownTimeout
+
+	maintenanceInterval, err := time.ParseDuration(strings.TrimSpace(w.MaintenanceIntervalRaw))
+	if err != nil {
+		return fmt.Errorf("parse worker maintenance interval: %w", err)
+	}
+	w.MaintenanceInterval = maintenanceInterval
+
+	eventRetention, err := time.ParseDuration(strings.TrimSpace(w.ResumeEventRetentionRaw))
+	if err != nil {
+		return fmt.Errorf("parse worker resume event retention: %w", err)
+	}
+	w.ResumeEventRetention = eventRetention
+
+	bundleLinkTTL, err := time.ParseDuration(strings.TrimSpace(w.PDFBundleLinkTTLRaw))
+	if err != nil {
+		return fmt.Errorf("parse worker pdf bundle link ttl: %w", err)
+	}
+	w.PDFBundleLinkTTL = bundleLinkTTL
+	return nil
+}
+
+func (d *DatabaseConfig) prepare() error {
+	lifetime, err := time.ParseDuration(strings.TrimSpace(d.ConnMaxLifetimeRaw))
+	if err != nil {
+		return fmt.Errorf("parse database conn max lifetime: %w", err)
+	}
+	d.ConnMaxLifetime = lifetime
+
+	backoff, err := time.ParseDuration(strings.TrimSpace(d.ConnectBackoffRaw))
+	if err != nil {
+		return fmt.Errorf("parse database connect backoff: %w", err)
+	}
+	d.ConnectBackoff = backoff
+	return nil
+}
+
 func (j *JWTConfig) prepare() error {
 	if j.PrivateKeyBase64 == "" {
 		return errors.New("jwt private key base64 is required")
@@ -514,5 +1118,11 @@ func (j *JWTConfig) prepare() error {
 	}
 	j.RefreshTokenTTL = refreshTTL
 
+	sessionLifetime, err := time.ParseDuration(strings.TrimSpace(j.SessionLifetimeRaw))
+	if err != nil {
+		return fmt.Errorf("parse jwt session lifetime: %w", err)
+	}
+	j.SessionLifetime = sessionLifetime
+
 	return nil
 }
@@ -0,0 +1,34 @@
+package database
+
+import "gorm.io/gorm"
+
+// LoadAssetKeyDir 返回用户的不透明资产目录；尚未分配或用户不存在时返回空字符串。
+func LoadAssetKeyDir(db *gorm.DB, userID uint) (string, error) {
+	var dirs []string
+	if err := db.Model(&User{}).Where("id = ?", userID).Limit(1).Pluck("asset_key_dir", &dirs).Error; err != nil {
+		return "", err
+	}
+	if len(dirs) == 0 {
+		return "", nil
+	}
+	return dirs[0], nil
+}
+
+// EnsureAssetKeyDir 返回用户的不透明资产目录，尚未分配时写入 candidate。
+// 仅在目录为空时写入并回读，并发上传只会保留先写入的一个目录。
+func EnsureAssetKeyDir(db *gorm.DB, userID uint, candidate string) (string, error) {
+	dir, err := LoadAssetKeyDir(db, userID)
+	if err != nil || dir != "" {
+		return dir, err
+	}
+	if err := db.Model(&User{}).
+		Where("id = ? AND (asset_key_dir = '' OR asset_key_dir IS NULL)", userID).
+		Update("asset_key_dir", candidate).Error; err != nil {
+		return "", err
+	}
+	dir, err = LoadAssetKeyDir(db, userID)
+	if err == nil && dir == "" {
+		err = gorm.ErrRecordNotFound
+	}
+	return dir, err
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -12,7 +13,43 @@ import (
 )
 
 // InitDatabase 使用配置初始化 PostgreSQL 连接，并返回 GORM 数据库实例。
+// 连接或 Ping 失败时按 ConnectAttempts / ConnectBackoff 重试，每次尝试都会记录日志。
 func InitDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	return connectWithRetry(slog.Default(), cfg.ConnectAttempts, cfg.ConnectBackoff, time.Sleep, func() (*gorm.DB, error) {
+		return openDatabase(cfg)
+	})
+}
+
+// connectWithRetry 最多调用 connect attempts 次，第 n 次失败后等待 n×backoff；sleep 便于测试替换。
+func connectWithRetry(logger *slog.Logger, attempts int, backoff time.Duration, sleep func(time.Duration), connect func() (*gorm.DB, error)) (*gorm.DB, error) {
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		db, err := connect()
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("database connected after retry", slog.Int("attempt", attempt))
+			}
+			return db, nil
+		}
+		lastErr = err
+
+		logger.Warn("database connect attempt failed",
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", attempts),
+			slog.Any("error", err),
+		)
+		if attempt < attempts && backoff > 0 {
+			sleep(time.Duration(attempt) * backoff)
+		}
+	}
+	return nil, fmt.Errorf("connect database failed after %d attempts: %w", attempts, lastErr)
+}
+
+func openDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
@@ -25,13 +62,23 @@ func InitDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("unwrap db: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetConnMaxLifetime(30 * time.Minute)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err := sqlDB.Ping(); err != nil {
+		// 关闭本次失败的连接池，避免重试期间泄漏连接。
+		_ = sqlDB.Close()
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
 	return db, nil
 }
+
+// BackfillTemplateStatus 将引入审核状态之前就已公开的模板标记为 approved，
+// 避免 AutoMigrate 以默认值 draft 补列后这些模板从公开列表中消失。可重复执行。
+func BackfillTemplateStatus(db *gorm.DB) error {
+	return db.Model(&Template{}).
+		Where("is_public = ? AND (status = ? OR status IS NULL)", true, TemplateStatusDraft).
+		Update("status", TemplateStatusApproved).Error
+}
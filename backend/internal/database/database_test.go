@@ -0,0 +1,154 @@
+package database
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestConnectWithRetry_WaitsForDatabase(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	want := &gorm.DB{}
+
+	var sleeps []time.Duration
+	calls := 0
+	db, err := connectWithRetry(logger, 5, time.Second, func(d time.Duration) { sleeps = append(sleeps, d) }, func() (*gorm.DB, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return want, nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if db != want || calls != 3 {
+		t.Fatalf("expected third attempt to succeed, calls=%d", calls)
+	}
+	if len(sleeps) != 2 || sleeps[0] != time.Second || sleeps[1] != 2*time.Second {
+		t.Fatalf("expected linear backoff [1s 2s], got %v", sleeps)
+	}
+}
+
+func TestConnectWithRetry_GivesUpAfterAttempts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	refused := errors.New("connection refused")
+
+	var sleeps int
+	calls := 0
+	_, err := connectWithRetry(logger, 3, time.Second, func(time.Duration) { sleeps++ }, func() (*gorm.DB, error) {
+		calls++
+		return nil, refused
+	})
+	if !errors.Is(err, refused) {
+		t.Fatalf("expected wrapped last error, got %v", err)
+	}
+	if calls != 3 || sleeps != 2 {
+		t.Fatalf("expected 3 attempts and 2 waits, got calls=%d sleeps=%d", calls, sleeps)
+	}
+}
+
+func TestMigrate_CreatesTablesAndBackfillsTemplateStatus(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:migrate?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, model := range Models() {
+		if !db.Migrator().HasTable(model) {
+			t.Fatalf("expected table for %T", model)
+		}
+	}
+
+	public := Template{Title: "t", IsPublic: true, Status: TemplateStatusDraft}
+	if err := db.Create(&public).Error; err != nil {
+		t.Fatalf("seed template: %v", err)
+	}
+	// 重复执行应保持幂等，并回填已公开模板的审核状态。
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate again: %v", err)
+	}
+	var got Template
+	db.First(&got, public.ID)
+	if got.Status != TemplateStatusApproved {
+		t.Fatalf("expected backfilled status approved, got %q", got.Status)
+	}
+}
+
+func TestMigrate_ReplacesLegacyUsernameIndex(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:legacy_username?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// 模拟旧版本的表结构：username 上的全表唯一索引。
+	if err := db.Exec("CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, username varchar(64))").Error; err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX idx_users_username ON users(username)").Error; err != nil {
+		t.Fatalf("create legacy index: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if db.Migrator().HasIndex(&User{}, "idx_users_username") {
+		t.Fatalf("legacy username index should be dropped")
+	}
+	if !db.Migrator().HasIndex(&User{}, "idx_users_username_active") {
+		t.Fatalf("expected partial username index")
+	}
+
+	deleted := User{Username: "alice"}
+	if err := db.Create(&deleted).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if err := db.Delete(&deleted).Error; err != nil {
+		t.Fatalf("soft delete user: %v", err)
+	}
+	if err := db.Create(&User{Username: "alice"}).Error; err != nil {
+		t.Fatalf("username of a soft-deleted user should be reusable: %v", err)
+	}
+	if err := db.Create(&User{Username: "alice"}).Error; err == nil {
+		t.Fatalf("expected unique violation for two active users with the same username")
+	}
+}
+
+func TestNormalizeUsernames_BackfillsWithoutConflicts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:normalize_usernames?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if !db.Migrator().HasIndex(&User{}, "idx_users_username_lower") {
+		t.Fatalf("expected lower(username) index")
+	}
+	for _, name := range []string{"Dave", "Eve", "eve"} {
+		if err := db.Create(&User{Username: name}).Error; err != nil {
+			t.Fatalf("seed %s: %v", name, err)
+		}
+	}
+
+	if err := NormalizeUsernames(db); err != nil {
+		t.Fatalf("normalize usernames: %v", err)
+	}
+	var names []string
+	db.Model(&User{}).Order("id").Pluck("username", &names)
+	// "Eve" 与 "eve" 冲突，保持原样待人工处理。
+	if len(names) != 3 || names[0] != "dave" || names[1] != "Eve" || names[2] != "eve" {
+		t.Fatalf("unexpected usernames %v", names)
+	}
+
+	var found User
+	if err := WhereUsername(db, " DAVE ").First(&found).Error; err != nil {
+		t.Fatalf("case-insensitive lookup: %v", err)
+	}
+}
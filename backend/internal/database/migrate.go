@@ -0,0 +1,43 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Models 返回需要迁移的全部模型，新增表时在此登记。
+func Models() []any {
+	return []any{
+		&User{},
+		&Resume{},
+		&ResumeTag{},
+		&ResumeEvent{},
+		&Template{},
+		&Asset{},
+	}
+}
+
+// legacyUsernameIndex 为旧版本在 users.username 上建立的全表唯一索引，软删除的账号也会占用用户名。
+const legacyUsernameIndex = "idx_users_username"
+
+// Migrate 执行表结构迁移与随迁移引入的数据回填，可重复执行。
+// 生产环境由 cmd/migrate 在发布前单独执行；DATABASE_AUTO_MIGRATE 开启时 API/Worker 启动时也会调用。
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return fmt.Errorf("auto migrate: %w", err)
+	}
+	// AutoMigrate 不会删除索引，需显式移除旧索引，改由 idx_users_username_active（仅约束未删除账号）保证唯一。
+	if db.Migrator().HasIndex(&User{}, legacyUsernameIndex) {
+		if err := db.Migrator().DropIndex(&User{}, legacyUsernameIndex); err != nil {
+			return fmt.Errorf("drop legacy username index: %w", err)
+		}
+	}
+	if err := NormalizeUsernames(db); err != nil {
+		return fmt.Errorf("normalize usernames: %w", err)
+	}
+	if err := BackfillTemplateStatus(db); err != nil {
+		return fmt.Errorf("backfill template status: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -8,10 +10,19 @@ import (
 // User 表示系统中的账号信息。
 type User struct {
 	gorm.Model
-	Username           string   `gorm:"uniqueIndex;size:64"`
-	PasswordHash       string   `gorm:"size:255"`
-	MustChangePassword bool     `gorm:"default:false"`
-	Resumes            []Resume `gorm:"constraint:OnDelete:CASCADE"`
+	// Username 只在未删除的账号间唯一（部分唯一索引）：注销账号走硬删除，
+	// 但历史或后续引入的软删除记录不应占用用户名、阻止重新注册。
+	// 存储 NormalizeUsername 后的小写形式；lower(username) 表达式索引服务于大小写不敏感的查找。
+	Username           string     `gorm:"uniqueIndex:idx_users_username_active,where:deleted_at IS NULL;index:idx_users_username_lower,expression:lower(username);size:64"`
+	PasswordHash       string     `gorm:"size:255"`
+	MustChangePassword bool       `gorm:"default:false"`
+	IsAdmin            bool       `gorm:"default:false"`      // 管理员：可访问 /v1/admin 接口，仅能通过 CLI 授予
+	StorageBytes       int64      `gorm:"not null;default:0"` // 已占用存储字节数（资产 + 当前 PDF/缩略图），随写入/删除增量维护
+	AssetCount         int64      `gorm:"not null;default:0"` // 未删除的资产数，随上传/删除增量维护
+	FailedLoginCount   int        `gorm:"not null;default:0"` // 连续登录失败次数（API_LOGIN_LOCK_DB_FALLBACK 开启时维护）
+	LockedUntil        *time.Time // 账号锁定截止时间，作为 Redis 锁定状态的持久化兜底
+	AssetKeyDir        string     `gorm:"size:64"` // 不透明资产目录（user-assets/<dir>/），开启 API_ASSET_OPAQUE_KEYS 后首次上传时分配
+	Resumes            []Resume   `gorm:"constraint:OnDelete:CASCADE"`
 	ActiveResumeID     *uint
 }
 
@@ -23,13 +34,57 @@ type Resume struct {
 	UserID           uint           `gorm:"index"`
 	User             User           `gorm:"constraint:OnDelete:CASCADE"`
 	PdfUrl           string         `gorm:"size:512"`
+	PdfContentHash   string         `gorm:"size:64"`            // 生成 PdfUrl 时的内容哈希，用于 PDF 缓存命中判断
+	PdfSize          int64          `gorm:"not null;default:0"` // 当前 PdfUrl 对象的字节数，计入用户存储用量
 	Status           string         `gorm:"size:32"`
 	PreviewImageURL  string         `gorm:"size:512"`
 	PreviewObjectKey string         `gorm:"size:512"`
+	PreviewSize      int64          `gorm:"not null;default:0"` // 当前缩略图对象的字节数，计入用户存储用量
+	Draft            datatypes.JSON `gorm:"type:jsonb"`         // 自动保存的草稿内容，显式保存（PUT）时清空；不影响 Content 与 UpdatedAt
+	DraftSavedAt     *time.Time
+}
+
+// ResumeTag 表示简历上的一个标签（一简历多标签）；UserID 冗余存储，便于按用户聚合标签。
+type ResumeTag struct {
+	ID       uint   `gorm:"primaryKey"`
+	ResumeID uint   `gorm:"uniqueIndex:idx_resume_tag;not null"`
+	UserID   uint   `gorm:"index:idx_resume_tags_user_tag;not null"`
+	Tag      string `gorm:"uniqueIndex:idx_resume_tag;index:idx_resume_tags_user_tag;size:32;not null"`
 }
 
+// 简历生命周期事件类型。
+const (
+	ResumeEventCreated         = "created"
+	ResumeEventUpdated         = "updated"
+	ResumeEventDeleted         = "deleted"
+	ResumeEventRenderQueued    = "render_queued"
+	ResumeEventRenderCompleted = "render_completed"
+	ResumeEventRenderFailed    = "render_failed"
+)
+
+// ResumeEvent 记录简历的生命周期变化，供前端时间线与运维审计使用；
+// 简历软删除后仍保留，由 Worker 维护任务按保留期清理，账号注销时随账号删除。
+type ResumeEvent struct {
+	ID            uint      `gorm:"primaryKey"`
+	ResumeID      uint      `gorm:"index:idx_resume_events_resume_created;not null"`
+	UserID        uint      `gorm:"index;not null"`
+	Type          string    `gorm:"size:32;not null"`
+	CorrelationID string    `gorm:"size:64"`  // 触发该事件的请求 correlation id，可与日志、trace 关联
+	Detail        string    `gorm:"size:512"` // 失败原因等补充说明
+	CreatedAt     time.Time `gorm:"index:idx_resume_events_resume_created;index"`
+}
+
+// 模板审核状态：IsPublic 表示 Owner 希望公开，Status 表示审核结论；
+// 只有 IsPublic 且 Status 为 approved 的模板才对其他用户可见。
+const (
+	TemplateStatusDraft    = "draft"
+	TemplateStatusPending  = "pending"
+	TemplateStatusApproved = "approved"
+	TemplateStatusRejected = "rejected"
+)
+
 // Template 表示可复用的简历模板。
-// 支持私有与公开模板（IsPublic），并归属于创建者（UserID）。
+// 支持私有与公开模板（IsPublic + Status），并归属于创建者（UserID）。
 type Template struct {
 	gorm.Model
 	Title            string         `gorm:"size:255"`
@@ -37,6 +92,8 @@ type Template struct {
 	PreviewObjectKey string         `gorm:"size:512"`
 	Content          datatypes.JSON `gorm:"type:jsonb"` // JSONB 存储 layout_settings 与 items
 	IsPublic         bool           `gorm:"default:false"`
+	Status           string         `gorm:"size:16;default:draft;index"` // draft/pending/approved/rejected
+	PreviewSize      int64          `gorm:"not null;default:0"`          // 当前缩略图对象的字节数，计入 Owner 的存储用量
 	UserID           uint           `gorm:"index"`
 	User             User           `gorm:"constraint:OnDelete:CASCADE"`
 }
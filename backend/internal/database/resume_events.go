@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 与 ResumeEvent 对应列宽一致（按字符计）；correlation id 来自客户端请求头，长度不受控。
+const (
+	maxResumeEventCorrelationID = 64
+	maxResumeEventDetail        = 512
+)
+
+// RecordResumeEvent 写入一条简历生命周期事件；需要与简历写入保持一致时传入事务句柄。
+func RecordResumeEvent(db *gorm.DB, resumeID, userID uint, eventType, correlationID, detail string) error {
+	return db.Create(&ResumeEvent{
+		ResumeID:      resumeID,
+		UserID:        userID,
+		Type:          eventType,
+		CorrelationID: truncateRunes(correlationID, maxResumeEventCorrelationID),
+		Detail:        truncateRunes(detail, maxResumeEventDetail),
+	}).Error
+}
+
+// PruneResumeEvents 删除早于 before 的生命周期事件，返回删除条数。
+func PruneResumeEvents(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("created_at < ?", before).Delete(&ResumeEvent{})
+	return result.RowsAffected, result.Error
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
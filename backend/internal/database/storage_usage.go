@@ -0,0 +1,51 @@
+package database
+
+import "gorm.io/gorm"
+
+// ReserveStorageBytes 在用量加上 delta 后不超过 quota 时原子地累加用量，返回是否成功；quota <= 0 表示不限制。
+// 判断与累加在同一条 UPDATE 中完成，并发上传不会同时通过配额检查。
+func ReserveStorageBytes(tx *gorm.DB, userID uint, delta, quota int64) (bool, error) {
+	if quota <= 0 || delta <= 0 {
+		return true, AdjustStorageBytes(tx, userID, delta)
+	}
+	result := tx.Model(&User{}).
+		Where("id = ? AND storage_bytes + ? <= ?", userID, delta, quota).
+		Update("storage_bytes", gorm.Expr("storage_bytes + ?", delta))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AdjustStorageBytes 以增量方式更新用户的存储用量（delta 可为负），结果不低于 0。
+// 调用方应与对应的记录写入放在同一事务中，保证用量与记录一致。
+func AdjustStorageBytes(tx *gorm.DB, userID uint, delta int64) error {
+	if delta == 0 || userID == 0 {
+		return nil
+	}
+	return tx.Model(&User{}).
+		Where("id = ?", userID).
+		Update("storage_bytes", gorm.Expr("CASE WHEN storage_bytes + ? < 0 THEN 0 ELSE storage_bytes + ? END", delta, delta)).Error
+}
+
+// AdjustAssetCount 以增量方式更新用户的资产数（delta 可为负），结果不低于 0。
+// 与 AdjustStorageBytes 一样应与资产记录的写入放在同一事务中。
+func AdjustAssetCount(tx *gorm.DB, userID uint, delta int64) error {
+	if delta == 0 || userID == 0 {
+		return nil
+	}
+	return tx.Model(&User{}).
+		Where("id = ?", userID).
+		Update("asset_count", gorm.Expr("CASE WHEN asset_count + ? < 0 THEN 0 ELSE asset_count + ? END", delta, delta)).Error
+}
+
+// RecalculateStorageUsage 按资产与当前 PDF/缩略图重新汇总所有用户的存储用量与资产数，
+// 用于引入这些字段后的回填，以及修正增量维护中因异常产生的偏差。由 Worker 的周期维护任务调用。
+func RecalculateStorageUsage(db *gorm.DB) error {
+	return db.Exec(`UPDATE users SET storage_bytes =
+		(SELECT COALESCE(SUM(size), 0) FROM assets WHERE assets.user_id = users.id AND assets.deleted_at IS NULL) +
+		(SELECT COALESCE(SUM(pdf_size + preview_size), 0) FROM resumes WHERE resumes.user_id = users.id AND resumes.deleted_at IS NULL) +
+		(SELECT COALESCE(SUM(preview_size), 0) FROM templates WHERE templates.user_id = users.id AND templates.deleted_at IS NULL),
+		asset_count =
+		(SELECT COUNT(*) FROM assets WHERE assets.user_id = users.id AND assets.deleted_at IS NULL)`).Error
+}
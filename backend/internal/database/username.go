@@ -0,0 +1,29 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NormalizeUsername 返回用户名的规范形式（去除首尾空白并转小写）。
+// 注册与改名时按规范形式存储，登录、查重与登录锁定键均按规范形式比较，"Alice" 与 "alice" 视为同一账号。
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// WhereUsername 按规范形式匹配用户名；用 lower(username) 比较以兼容规范化之前写入、因冲突未能回填的大小写混合记录。
+func WhereUsername(db *gorm.DB, username string) *gorm.DB {
+	return db.Where("lower(username) = ?", NormalizeUsername(username))
+}
+
+// NormalizeUsernames 将历史上大小写混合的用户名回填为规范形式，可重复执行。
+// 与其他未删除账号冲突（如同时存在 "Alice" 与 "alice"）的记录保持原样，需人工处理；登录时按 id 取较早注册的账号。
+func NormalizeUsernames(db *gorm.DB) error {
+	return db.Exec(`UPDATE users SET username = lower(username)
+WHERE deleted_at IS NULL AND username <> lower(username)
+AND NOT EXISTS (
+	SELECT 1 FROM users other
+	WHERE other.id <> users.id AND other.deleted_at IS NULL AND lower(other.username) = lower(users.username)
+)`).Error
+}
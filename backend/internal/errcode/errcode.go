@@ -1,11 +1,110 @@
 package errcode
 
+import "net/http"
+
 // 错误码约定：
 // - 0：无错误
 // - 4xxx：业务可恢复/告警类错误（例如资源缺失但流程可继续）
 // - 5xxx：系统错误（需要中断流程）
 const (
-	OK              = 0
-	ResourceMissing = 4004
-	SystemError     = 5000
+	OK                = 0
+	ResourceMissing   = 4004
+	ContentSuperseded = 4009 // 任务入队后简历内容已被修改，本次导出作废，需重新发起
+	SystemError       = 5000
 )
+
+// HTTP 错误响应使用的错误码：HTTP 状态码×10，末位为细分序号。
+const (
+	InvalidRequest         = 4000
+	Unauthorized           = 4010
+	Forbidden              = 4030
+	PasswordChangeRequired = 4031
+	LimitReached           = 4032
+	ChallengeRequired      = 4033
+	ChallengeFailed        = 4034
+	RegistrationDisabled   = 4035
+	InviteCodeInvalid      = 4036
+	NotFound               = 4040
+	MethodNotAllowed       = 4050
+	Conflict               = 4090
+	DuplicateTitle         = 4091
+	PayloadTooLarge        = 4130
+	UnsupportedMediaType   = 4150
+	RateLimited            = 4290
+	AccountLocked          = 4291
+	LoginIPBlocked         = 4292
+)
+
+// Detail 为 HTTP 错误响应体 {"error":{"code":N,"message":"..."}} 中 error 字段的结构。
+type Detail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Fields 为请求体校验失败时的字段名 -> 原因（已本地化），其他错误不返回。
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Entry 描述一个错误码及其可读说明，供客户端同步错误码表。
+type Entry struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// catalog 为全部错误码的登记表，新增常量时需同步登记（按 code 升序）。
+var catalog = []Entry{
+	{Code: OK, Name: "OK", Description: "无错误"},
+	{Code: InvalidRequest, Name: "InvalidRequest", Description: "请求参数或请求体不合法"},
+	{Code: ResourceMissing, Name: "ResourceMissing", Description: "资源缺失，流程可继续（例如打印数据中的图片已被删除）"},
+	{Code: ContentSuperseded, Name: "ContentSuperseded", Description: "任务入队后简历内容已被修改，本次导出作废"},
+	{Code: Unauthorized, Name: "Unauthorized", Description: "未认证或凭证已失效"},
+	{Code: Forbidden, Name: "Forbidden", Description: "无权访问该资源"},
+	{Code: PasswordChangeRequired, Name: "PasswordChangeRequired", Description: "需先修改密码"},
+	{Code: LimitReached, Name: "LimitReached", Description: "数量上限已达（简历/模板/资产）"},
+	{Code: ChallengeRequired, Name: "ChallengeRequired", Description: "登录失败次数较多，需完成人机验证"},
+	{Code: ChallengeFailed, Name: "ChallengeFailed", Description: "人机验证未通过"},
+	{Code: RegistrationDisabled, Name: "RegistrationDisabled", Description: "已关闭公开注册"},
+	{Code: InviteCodeInvalid, Name: "InviteCodeInvalid", Description: "注册需要有效的邀请码（缺失、错误或已被使用）"},
+	{Code: NotFound, Name: "NotFound", Description: "资源不存在"},
+	{Code: MethodNotAllowed, Name: "MethodNotAllowed", Description: "请求方法不被允许"},
+	{Code: Conflict, Name: "Conflict", Description: "资源状态冲突"},
+	{Code: DuplicateTitle, Name: "DuplicateTitle", Description: "已存在同名简历（可通过 allow_duplicate_title 覆盖）"},
+	{Code: PayloadTooLarge, Name: "PayloadTooLarge", Description: "请求体或上传文件过大"},
+	{Code: UnsupportedMediaType, Name: "UnsupportedMediaType", Description: "不支持的文件类型"},
+	{Code: RateLimited, Name: "RateLimited", Description: "请求过于频繁"},
+	{Code: AccountLocked, Name: "AccountLocked", Description: "账号因多次登录失败被临时锁定"},
+	{Code: LoginIPBlocked, Name: "LoginIPBlocked", Description: "来源 IP 登录失败次数过多，被临时封禁"},
+	{Code: SystemError, Name: "SystemError", Description: "系统错误"},
+}
+
+// Catalog 返回错误码登记表的副本。
+func Catalog() []Entry {
+	return append([]Entry(nil), catalog...)
+}
+
+// FromHTTPStatus 返回 HTTP 状态码对应的默认错误码，调用方未指定细分错误码时使用。
+func FromHTTPStatus(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return InvalidRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return UnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return RateLimited
+	}
+	if status >= http.StatusInternalServerError {
+		return SystemError
+	}
+	return status * 10
+}
@@ -0,0 +1,32 @@
+package errcode
+
+import "testing"
+
+func TestCatalog_UniqueAndSorted(t *testing.T) {
+	entries := Catalog()
+	seen := make(map[int]string, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" || entry.Description == "" {
+			t.Fatalf("entry %d has empty label: %+v", entry.Code, entry)
+		}
+		if prev, ok := seen[entry.Code]; ok {
+			t.Fatalf("code %d registered twice (%s, %s)", entry.Code, prev, entry.Name)
+		}
+		seen[entry.Code] = entry.Name
+		if i > 0 && entries[i-1].Code >= entry.Code {
+			t.Fatalf("catalog not sorted at %d", entry.Code)
+		}
+	}
+}
+
+func TestCatalog_CoversHTTPDefaults(t *testing.T) {
+	registered := make(map[int]struct{})
+	for _, entry := range Catalog() {
+		registered[entry.Code] = struct{}{}
+	}
+	for _, status := range []int{400, 401, 403, 404, 405, 409, 413, 415, 429, 500, 503} {
+		if _, ok := registered[FromHTTPStatus(status)]; !ok {
+			t.Fatalf("default code for status %d is not registered", status)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package i18n 提供按 Accept-Language 协商的错误消息本地化。
+//
+// 英文为源语言：处理层仍以英文字符串构造错误消息，渲染时按请求语言查找译文；
+// 找不到逐条译文时回落到以 errcode 为键的通用消息，保证响应语言一致。
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"phResume/internal/errcode"
+)
+
+// 支持的语言。
+const (
+	EN   = "en"
+	ZhCN = "zh-CN"
+
+	// DefaultLocale 为未配置回退语言时使用的语言（与历史响应保持一致）。
+	DefaultLocale = EN
+)
+
+// codeMessages 以 errcode 为键的通用消息。
+var codeMessages = map[string]map[int]string{
+	EN: {
+		errcode.InvalidRequest:         "invalid request",
+		errcode.ResourceMissing:        "some image resources are missing or invalid and were skipped",
+		errcode.ContentSuperseded:      "resume content has changed, please export again",
+		errcode.Unauthorized:           "unauthorized",
+		errcode.Forbidden:              "access denied",
+		errcode.PasswordChangeRequired: "password change required",
+		errcode.LimitReached:           "limit reached",
+		errcode.ChallengeRequired:      "challenge required",
+		errcode.ChallengeFailed:        "challenge verification failed",
+		errcode.RegistrationDisabled:   "registration is disabled",
+		errcode.InviteCodeInvalid:      "invalid invite code",
+		errcode.NotFound:               "not found",
+		errcode.MethodNotAllowed:       "method not allowed",
+		errcode.Conflict:               "conflict",
+		errcode.DuplicateTitle:         "resume title already exists",
+		errcode.PayloadTooLarge:        "payload too large",
+		errcode.UnsupportedMediaType:   "unsupported media type",
+		errcode.RateLimited:            "rate limit exceeded",
+		errcode.AccountLocked:          "account temporarily locked",
+		errcode.LoginIPBlocked:         "too many failed login attempts",
+		errcode.SystemError:            "internal error",
+	},
+	ZhCN: {
+		errcode.InvalidRequest:         "请求参数不合法",
+		errcode.ResourceMissing:        "部分图片资源缺失/无效，已自动跳过并继续生成",
+		errcode.ContentSuperseded:      "简历内容已更新，请重新导出",
+		errcode.Unauthorized:           "未登录或登录已失效",
+		errcode.Forbidden:              "无权访问",
+		errcode.PasswordChangeRequired: "请先修改密码",
+		errcode.LimitReached:           "数量已达上限",
+		errcode.ChallengeRequired:      "请先完成人机验证",
+		errcode.ChallengeFailed:        "人机验证未通过，请重试",
+		errcode.RegistrationDisabled:   "当前未开放注册",
+		errcode.InviteCodeInvalid:      "邀请码无效或已被使用",
+		errcode.NotFound:               "资源不存在",
+		errcode.MethodNotAllowed:       "请求方法不被允许",
+		errcode.Conflict:               "资源状态冲突",
+		errcode.DuplicateTitle:         "已存在同名简历",
+		errcode.PayloadTooLarge:        "内容过大",
+		errcode.UnsupportedMediaType:   "不支持的文件类型",
+		errcode.RateLimited:            "操作过于频繁，请稍后再试",
+		errcode.AccountLocked:          "登录失败次数过多，账号已临时锁定",
+		errcode.LoginIPBlocked:         "登录失败次数过多，请稍后再试",
+		errcode.SystemError:            "系统错误，请稍后重试",
+	},
+}
+
+// messages 为逐条消息译文（英文源消息 -> 译文），仅收录面向用户、需要区分细节的消息。
+var messages = map[string]map[string]string{
+	ZhCN: {
+		"resume limit reached":                                 "简历数量已达上限",
+		"template limit reached":                               "模板数量已达上限",
+		"asset limit reached":                                  "图片资产数量已达上限",
+		"resume not found":                                     "简历不存在",
+		"template not found":                                   "模板不存在",
+		"invalid resume id":                                    "简历 ID 不合法",
+		"invalid template id":                                  "模板 ID 不合法",
+		"username already taken":                               "用户名已被占用",
+		"password confirmation does not match":                 "两次输入的密码不一致",
+		"new password must be different from current password": "新密码不能与当前密码相同",
+		"new username must be different from current username": "新用户名不能与当前用户名相同",
+		"refresh token missing":                                "缺少刷新令牌",
+		"download link expired":                                "下载链接已失效",
+		"pdf not ready":                                        "PDF 尚未生成",
+		"missing file":                                         "缺少上传文件",
+		"missing key":                                          "缺少资源 key",
+		"malicious file detected":                              "文件未通过安全扫描",
+		"request body too large":                               "请求内容过大",
+		"resume title already exists":                          "已存在同名简历",
+	},
+}
+
+// Supported 报告是否支持给定语言（需为规范化后的取值）。
+func Supported(locale string) bool {
+	_, ok := codeMessages[locale]
+	return ok
+}
+
+// Normalize 将语言标签（如 zh、zh-cn、zh-Hans-CN、en-US）映射到受支持的语言。
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", false
+	}
+	primary, rest, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	switch primary {
+	case "en":
+		return EN, true
+	case "zh":
+		// 繁体地区不回落到简体译文。
+		if strings.Contains(rest, "hant") || rest == "tw" || rest == "hk" || rest == "mo" {
+			return "", false
+		}
+		return ZhCN, true
+	}
+	return "", false
+}
+
+// Negotiate 按 Accept-Language 的权重选择受支持的语言，均不匹配时返回 fallback（非法时为 DefaultLocale）。
+func Negotiate(acceptLanguage, fallback string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if locale, ok := Normalize(c.tag); ok {
+			return locale
+		}
+	}
+	if locale, ok := Normalize(fallback); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Message 返回 code 在给定语言下的通用消息；语言不支持时按 DefaultLocale 查找。
+func Message(locale string, code int) string {
+	if !Supported(locale) {
+		locale = DefaultLocale
+	}
+	if msg, ok := codeMessages[locale][code]; ok {
+		return msg
+	}
+	return codeMessages[DefaultLocale][code]
+}
+
+// Translate 渲染错误消息：英文直接返回源消息；其他语言优先逐条译文，再回落到 code 的通用消息。
+func Translate(locale string, code int, msg string) string {
+	if !Supported(locale) {
+		locale = DefaultLocale
+	}
+	if locale == EN {
+		if msg == "" {
+			return Message(EN, code)
+		}
+		return msg
+	}
+	if translated, ok := messages[locale][msg]; ok {
+		return translated
+	}
+	if generic, ok := codeMessages[locale][code]; ok {
+		return generic
+	}
+	return msg
+}
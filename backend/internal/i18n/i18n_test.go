@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"testing"
+
+	"phResume/internal/errcode"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   string
+		fallback string
+		want     string
+	}{
+		{name: "empty header uses fallback", header: "", fallback: ZhCN, want: ZhCN},
+		{name: "browser zh", header: "zh-CN,zh;q=0.9,en;q=0.8", fallback: EN, want: ZhCN},
+		{name: "quality ordering", header: "zh;q=0.5,en-US;q=0.9", fallback: ZhCN, want: EN},
+		{name: "traditional chinese unsupported", header: "zh-TW", fallback: EN, want: EN},
+		{name: "unsupported only", header: "fr-FR,de;q=0.8", fallback: ZhCN, want: ZhCN},
+		{name: "invalid fallback", header: "fr", fallback: "fr", want: DefaultLocale},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Negotiate(tc.header, tc.fallback); got != tc.want {
+				t.Fatalf("Negotiate(%q, %q) = %q, want %q", tc.header, tc.fallback, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := Translate(EN, errcode.LimitReached, "resume limit reached"); got != "resume limit reached" {
+		t.Fatalf("english should keep the source message, got %q", got)
+	}
+	if got := Translate(ZhCN, errcode.LimitReached, "resume limit reached"); got != "简历数量已达上限" {
+		t.Fatalf("expected message-level translation, got %q", got)
+	}
+	if got := Translate(ZhCN, errcode.SystemError, "failed to query resume"); got != "系统错误，请稍后重试" {
+		t.Fatalf("expected code-level fallback, got %q", got)
+	}
+	if got := Translate("fr", errcode.NotFound, "resume not found"); got != "resume not found" {
+		t.Fatalf("unsupported locale should fall back to english, got %q", got)
+	}
+}
+
+func TestCodeMessages_CoverCatalog(t *testing.T) {
+	for locale, bundle := range codeMessages {
+		for _, entry := range errcode.Catalog() {
+			if entry.Code == errcode.OK {
+				continue
+			}
+			if _, ok := bundle[entry.Code]; !ok {
+				t.Errorf("locale %s has no message for code %d (%s)", locale, entry.Code, entry.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 登录结果（phresume_auth_logins_total 的 outcome 标签）。
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginLocked             = "locked"
+	LoginRateLimited        = "rate_limited"
+	LoginChallengeFailed    = "challenge_failed"
+	LoginError              = "error"
+)
+
+// 锁定范围（phresume_auth_lockouts_total 的 scope 标签）。
+const (
+	LockoutUsername = "username"
+	LockoutIP       = "ip"
+)
+
+// 刷新结果（phresume_auth_refresh_total 的 outcome 标签）。
+const (
+	RefreshSuccess = "success"
+	RefreshInvalid = "invalid"
+	RefreshRevoked = "revoked"
+	RefreshError   = "error"
+	// RefreshSessionExpired 表示会话超过绝对有效期（JWT_SESSION_LIFETIME），须重新登录。
+	RefreshSessionExpired = "session_expired"
+	// RefreshDeviceMismatch 表示设备指纹与签发时不一致而被拒绝（API_REFRESH_BINDING=enforce）。
+	RefreshDeviceMismatch = "device_mismatch"
+)
+
+// 标签只取上面的固定取值，不带用户名、IP 等高基数信息。
+var (
+	authLogins = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "phresume",
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "登录请求数，按结果分类。",
+		},
+		[]string{"outcome"},
+	)
+
+	authLockouts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "phresume",
+			Subsystem: "auth",
+			Name:      "lockouts_total",
+			Help:      "因连续登录失败触发的锁定次数（账号锁定或来源 IP 封禁）。",
+		},
+		[]string{"scope"},
+	)
+
+	authRefresh = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "phresume",
+			Subsystem: "auth",
+			Name:      "refresh_total",
+			Help:      "刷新令牌请求数，按结果分类。",
+		},
+		[]string{"outcome"},
+	)
+)
+
+// AuthLogin 记录一次登录结果；outcome 取 Login* 常量。
+func AuthLogin(outcome string) {
+	authLogins.WithLabelValues(outcome).Inc()
+}
+
+// AuthLockout 记录一次锁定；scope 取 Lockout* 常量。
+func AuthLockout(scope string) {
+	authLockouts.WithLabelValues(scope).Inc()
+}
+
+// AuthRefresh 记录一次刷新结果；outcome 取 Refresh* 常量。
+func AuthRefresh(outcome string) {
+	authRefresh.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	renderSlotsInUse = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "phresume",
+			Subsystem: "worker",
+			Name:      "render_slots_in_use",
+			Help:      "当前占用的浏览器渲染名额数量。",
+		},
+	)
+
+	renderSlotsCapacity = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "phresume",
+			Subsystem: "worker",
+			Name:      "render_slots_capacity",
+			Help:      "进程内浏览器渲染名额上限。",
+		},
+	)
+)
+
+// SetRenderSlotsCapacity 记录渲染名额上限。
+func SetRenderSlotsCapacity(n int) {
+	renderSlotsCapacity.Set(float64(n))
+}
+
+// RenderSlotAcquired 在占用渲染名额后调用。
+func RenderSlotAcquired() {
+	renderSlotsInUse.Inc()
+}
+
+// RenderSlotReleased 在归还渲染名额后调用。
+func RenderSlotReleased() {
+	renderSlotsInUse.Dec()
+}
@@ -0,0 +1,144 @@
+// Package notify 维护用户通知的重放日志：Worker 推送通知前将 payload 追加到按用户划分的 Redis Stream，
+// WebSocket / SSE 重连时按客户端回传的最后事件 id 补发断线期间错过的通知。
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// LogMaxLen 为每个用户保留的最近通知条数（近似裁剪）。
+	LogMaxLen = 50
+	// LogTTL 为通知日志的保留时间，每次追加时刷新；需覆盖一次 PDF 渲染加上客户端重连的时间。
+	LogTTL = 30 * time.Minute
+)
+
+// ErrInvalidID 表示客户端回传的事件 id 不是合法的 Redis Stream id。
+var ErrInvalidID = errors.New("invalid notification id")
+
+// Event 为一条带 id 的通知；Payload 为已注入 id 字段的 JSON。
+type Event struct {
+	ID      string
+	Payload string
+}
+
+// LogWriter 为追加通知日志所需的 Redis 命令，redis.UniversalClient 满足该接口。
+type LogWriter interface {
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+}
+
+// Append 将 payload 追加到用户的通知日志并刷新 TTL，返回分配的事件 id（Redis Stream entry id，单调递增）。
+func Append(ctx context.Context, rdb LogWriter, userID uint, payload []byte) (string, error) {
+	key := LogKey(userID)
+	id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: LogMaxLen,
+		Approx: true,
+		Values: map[string]any{"payload": payload},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("append notification log %q: %w", key, err)
+	}
+	if err := rdb.Expire(ctx, key, LogTTL).Err(); err != nil {
+		return "", fmt.Errorf("expire notification log %q: %w", key, err)
+	}
+	return id, nil
+}
+
+// Since 返回日志中 id 晚于 lastID 的通知（按时间顺序），payload 中注入各自的 id。
+func Since(ctx context.Context, rdb redis.Cmdable, userID uint, lastID string) ([]Event, error) {
+	if !ValidID(lastID) {
+		return nil, ErrInvalidID
+	}
+	entries, err := rdb.XRange(ctx, LogKey(userID), lastID, "+").Result()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, 0, len(entries))
+	for _, entry := range entries {
+		// XRANGE 的起点是闭区间，跳过客户端已收到的那条。
+		if !After(entry.ID, lastID) {
+			continue
+		}
+		raw, _ := entry.Values["payload"].(string)
+		payload, err := WithID([]byte(raw), entry.ID)
+		if err != nil {
+			continue
+		}
+		events = append(events, Event{ID: entry.ID, Payload: string(payload)})
+	}
+	return events, nil
+}
+
+// WithID 在 JSON 对象 payload 中写入 "id" 字段。
+func WithID(payload []byte, id string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("decode notification payload: %w", err)
+	}
+	encodedID, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	fields["id"] = encodedID
+	return json.Marshal(fields)
+}
+
+// EventFromPayload 从实时推送的 payload 中读取 id；没有 id（如日志写入失败时）的通知 ID 为空。
+func EventFromPayload(payload string) Event {
+	var fields struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal([]byte(payload), &fields)
+	if !ValidID(fields.ID) {
+		fields.ID = ""
+	}
+	return Event{ID: fields.ID, Payload: payload}
+}
+
+// ValidID 判断 id 是否为 "<毫秒>-<序号>" 形式的 Redis Stream id。
+func ValidID(id string) bool {
+	_, _, ok := parseID(id)
+	return ok
+}
+
+// After 判断 id a 是否晚于 b；任一 id 非法时返回 false。
+func After(a, b string) bool {
+	aMs, aSeq, ok := parseID(a)
+	if !ok {
+		return false
+	}
+	bMs, bSeq, ok := parseID(b)
+	if !ok {
+		return false
+	}
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+func parseID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
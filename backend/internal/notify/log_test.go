@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWithID(t *testing.T) {
+	out, err := WithID([]byte(`{"status":"completed","resume_id":3}`), "1700000000000-1")
+	if err != nil {
+		t.Fatalf("WithID: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(out, &fields); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if fields["id"] != "1700000000000-1" || fields["status"] != "completed" || fields["resume_id"] != float64(3) {
+		t.Fatalf("unexpected payload %s", out)
+	}
+
+	if _, err := WithID([]byte(`not json`), "1-0"); err == nil {
+		t.Fatal("expected error for non-object payload")
+	}
+}
+
+func TestEventFromPayload(t *testing.T) {
+	if got := EventFromPayload(`{"id":"5-2","status":"error"}`); got.ID != "5-2" {
+		t.Fatalf("expected id 5-2, got %q", got.ID)
+	}
+	for _, payload := range []string{`{"status":"error"}`, `{"id":"abc"}`, `garbage`} {
+		if got := EventFromPayload(payload); got.ID != "" || got.Payload != payload {
+			t.Fatalf("EventFromPayload(%q) = %+v", payload, got)
+		}
+	}
+}
+
+func TestAfterAndValidID(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"1700000000001-0", "1700000000000-5", true},
+		{"1700000000000-6", "1700000000000-5", true},
+		{"1700000000000-5", "1700000000000-5", false},
+		{"999-0", "1000-0", false},
+		{"bad", "1-0", false},
+		{"2-0", "bad", false},
+	}
+	for _, tc := range cases {
+		if got := After(tc.a, tc.b); got != tc.want {
+			t.Fatalf("After(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+
+	for id, want := range map[string]bool{"1-0": true, "1700000000000-12": true, "": false, "1": false, "-1": false, "a-b": false, "1--1": false} {
+		if got := ValidID(id); got != want {
+			t.Fatalf("ValidID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestSince_RejectsInvalidID(t *testing.T) {
+	if _, err := Since(context.Background(), nil, 1, "not-an-id"); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expected ErrInvalidID, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var namespace atomic.Pointer[string]
+
+// SetNamespace 设置进程级的通知命名空间（REDIS_NOTIFY_NAMESPACE），多个部署共用一个 Redis 时用于隔离频道与日志 key；
+// API 与 Worker 需在启动时以相同取值调用，空字符串表示不加前缀。
+func SetNamespace(ns string) {
+	namespace.Store(&ns)
+}
+
+// Namespace 返回当前的通知命名空间。
+func Namespace() string {
+	if ns := namespace.Load(); ns != nil {
+		return *ns
+	}
+	return ""
+}
+
+// Channel 返回用户通知的 Redis Pub/Sub 频道：Worker 发布与 API（WebSocket / SSE）订阅必须都经由此函数生成。
+func Channel(userID uint) string {
+	return withNamespace(fmt.Sprintf("user_notify:%d", userID))
+}
+
+// LogKey 返回用户通知重放日志的 Redis Stream key。
+func LogKey(userID uint) string {
+	return withNamespace(fmt.Sprintf("user_notify_log:%d", userID))
+}
+
+func withNamespace(name string) string {
+	if ns := Namespace(); ns != "" {
+		return ns + ":" + name
+	}
+	return name
+}
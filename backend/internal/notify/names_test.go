@@ -0,0 +1,22 @@
+package notify
+
+import "testing"
+
+func TestChannelAndLogKey_Namespace(t *testing.T) {
+	t.Cleanup(func() { SetNamespace("") })
+
+	if got := Channel(42); got != "user_notify:42" {
+		t.Fatalf("Channel(42) = %q", got)
+	}
+	if got := LogKey(42); got != "user_notify_log:42" {
+		t.Fatalf("LogKey(42) = %q", got)
+	}
+
+	SetNamespace("tenant-a")
+	if got := Channel(42); got != "tenant-a:user_notify:42" {
+		t.Fatalf("namespaced Channel(42) = %q", got)
+	}
+	if got := LogKey(42); got != "tenant-a:user_notify_log:42" {
+		t.Fatalf("namespaced LogKey(42) = %q", got)
+	}
+}
@@ -0,0 +1,15 @@
+// Package openapi 提供对外 /v1 接口的 OpenAPI 3 文档（手工维护的 openapi.json）。
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec 返回 OpenAPI 文档。新增、删除或修改 /v1 路由时需同步更新 openapi.json，
+// internal/api 的路由测试会校验文档与已注册路由一一对应。
+func Spec() []byte {
+	out := make([]byte, len(spec))
+	copy(out, spec)
+	return out
+}
@@ -0,0 +1,62 @@
+// Package redisclient 按 REDIS_MODE 构造 API、Worker 与 cmd/admin 共用的 Redis 连接，
+// 单机、哨兵与集群均返回 redis.UniversalClient，Asynq 使用同一拓扑的连接选项。
+package redisclient
+
+import (
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/config"
+)
+
+// 部署拓扑（REDIS_MODE）。
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// New 按配置构造 Redis 客户端：sentinel 返回自动跟随主从切换的 FailoverClient，cluster 返回 ClusterClient。
+func New(cfg config.RedisConfig) redis.UniversalClient {
+	switch cfg.Mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.SentinelMaster,
+			SentinelAddrs: cfg.Addrs,
+		})
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{Addrs: cfg.Addrs})
+	default:
+		return redis.NewClient(&redis.Options{Addr: standaloneAddr(cfg)})
+	}
+}
+
+// AsynqOpt 返回与 New 相同拓扑的 Asynq 连接选项。
+func AsynqOpt(cfg config.RedisConfig) asynq.RedisConnOpt {
+	switch cfg.Mode {
+	case ModeSentinel:
+		return asynq.RedisFailoverClientOpt{MasterName: cfg.SentinelMaster, SentinelAddrs: cfg.Addrs}
+	case ModeCluster:
+		return asynq.RedisClusterClientOpt{Addrs: cfg.Addrs}
+	default:
+		return asynq.RedisClientOpt{Addr: standaloneAddr(cfg)}
+	}
+}
+
+// Describe 返回便于日志输出的连接描述（不含凭据）。
+func Describe(cfg config.RedisConfig) string {
+	switch cfg.Mode {
+	case ModeSentinel:
+		return fmt.Sprintf("sentinel master=%s addrs=%v", cfg.SentinelMaster, cfg.Addrs)
+	case ModeCluster:
+		return fmt.Sprintf("cluster addrs=%v", cfg.Addrs)
+	default:
+		return "standalone addr=" + standaloneAddr(cfg)
+	}
+}
+
+func standaloneAddr(cfg config.RedisConfig) string {
+	return fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+}
@@ -0,0 +1,55 @@
+package redisclient
+
+import (
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/config"
+)
+
+func TestNewMatchesMode(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  config.RedisConfig
+		want func(redis.UniversalClient, asynq.RedisConnOpt) bool
+	}{
+		{
+			name: "standalone",
+			cfg:  config.RedisConfig{Mode: ModeStandalone, Host: "redis", Port: 6380},
+			want: func(c redis.UniversalClient, o asynq.RedisConnOpt) bool {
+				client, ok := c.(*redis.Client)
+				opt, optOK := o.(asynq.RedisClientOpt)
+				return ok && optOK && client.Options().Addr == "redis:6380" && opt.Addr == "redis:6380"
+			},
+		},
+		{
+			name: "sentinel",
+			cfg:  config.RedisConfig{Mode: ModeSentinel, SentinelMaster: "mymaster", Addrs: []string{"s1:26379", "s2:26379"}},
+			want: func(c redis.UniversalClient, o asynq.RedisConnOpt) bool {
+				_, ok := c.(*redis.Client)
+				opt, optOK := o.(asynq.RedisFailoverClientOpt)
+				return ok && optOK && opt.MasterName == "mymaster" && len(opt.SentinelAddrs) == 2
+			},
+		},
+		{
+			name: "cluster",
+			cfg:  config.RedisConfig{Mode: ModeCluster, Addrs: []string{"n1:6379", "n2:6379", "n3:6379"}},
+			want: func(c redis.UniversalClient, o asynq.RedisConnOpt) bool {
+				_, ok := c.(*redis.ClusterClient)
+				opt, optOK := o.(asynq.RedisClusterClientOpt)
+				return ok && optOK && len(opt.Addrs) == 3
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := New(tc.cfg)
+			defer client.Close()
+			if !tc.want(client, AsynqOpt(tc.cfg)) {
+				t.Fatalf("unexpected client %T / opt %T for %s", client, AsynqOpt(tc.cfg), Describe(tc.cfg))
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package resume
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"phResume/internal/i18n"
+)
+
+// starterFS 内嵌各语言的起始简历，文件名为 i18n 语言标签（如 en.json、zh-CN.json）。
+//
+//go:embed starters/*.json
+var starterFS embed.FS
+
+// starterTitles 为各语言起始简历的标题。
+var starterTitles = map[string]string{
+	i18n.EN:   "My First Resume",
+	i18n.ZhCN: "我的第一份简历",
+}
+
+// starterContents 在包初始化时读取并压缩内嵌的起始简历；内嵌文件非法属于编码错误。
+var starterContents = mustLoadStarters()
+
+func mustLoadStarters() map[string][]byte {
+	out := make(map[string][]byte, len(starterTitles))
+	for locale := range starterTitles {
+		raw, err := starterFS.ReadFile("starters/" + locale + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("read starter content %s: %v", locale, err))
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			panic(fmt.Sprintf("compact starter content %s: %v", locale, err))
+		}
+		out[locale] = buf.Bytes()
+	}
+	return out
+}
+
+// DefaultContent 返回内置的简体中文起始简历内容（JSON）。
+func DefaultContent() []byte {
+	return StarterContent(i18n.ZhCN)
+}
+
+// StarterContent 返回 locale（需为 i18n 规范化后的取值）对应的内置起始简历，不支持的语言使用 i18n.DefaultLocale。
+func StarterContent(locale string) []byte {
+	content, ok := starterContents[locale]
+	if !ok {
+		content = starterContents[i18n.DefaultLocale]
+	}
+	return bytes.Clone(content)
+}
+
+// StarterTitle 返回 locale 对应的起始简历标题，不支持的语言使用 i18n.DefaultLocale。
+func StarterTitle(locale string) string {
+	if title, ok := starterTitles[locale]; ok {
+		return title
+	}
+	return starterTitles[i18n.DefaultLocale]
+}
+
+// LoadDefaultContent 读取运维配置的起始简历 JSON 文件，按 limits 校验后返回压缩后的 JSON；
+// path 为空时返回 nil，表示按请求语言使用内置的 StarterContent。文件不可读或内容非法时返回 error，由调用方决定是否拒绝启动。
+func LoadDefaultContent(path string, limits ContentLimits) ([]byte, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read default content: %w", err)
+	}
+	content, err := ValidateContent(raw, limits)
+	if err != nil {
+		return nil, err
+	}
+	if content.LayoutSettings.Columns <= 0 {
+		return nil, fmt.Errorf("%w: layout_settings.columns must be positive", ErrInvalidContent)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package resume
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"phResume/internal/i18n"
+)
+
+func TestStarterContent_PerLocale(t *testing.T) {
+	limits := ContentLimits{MaxBytes: 256 * 1024, MaxItems: 200}
+	for _, locale := range []string{i18n.EN, i18n.ZhCN} {
+		content, err := ValidateContent(StarterContent(locale), limits)
+		if err != nil {
+			t.Fatalf("%s starter invalid: %v", locale, err)
+		}
+		if content.LayoutSettings.Columns <= 0 || len(content.Items) == 0 {
+			t.Fatalf("%s starter is empty: %+v", locale, content)
+		}
+		if StarterTitle(locale) == "" {
+			t.Fatalf("%s starter has no title", locale)
+		}
+	}
+
+	if !bytes.Contains(StarterContent(i18n.EN), []byte("Your Name")) {
+		t.Fatal("en starter should use English placeholders")
+	}
+	if !bytes.Contains(StarterContent(i18n.ZhCN), []byte("你的名字")) || StarterTitle(i18n.ZhCN) != "我的第一份简历" {
+		t.Fatal("zh-CN starter should keep the Chinese placeholders")
+	}
+	if !bytes.Equal(StarterContent("fr"), StarterContent(i18n.DefaultLocale)) || StarterTitle("fr") != StarterTitle(i18n.DefaultLocale) {
+		t.Fatal("unsupported locale should use the default locale starter")
+	}
+
+	// 返回副本，调用方修改不影响后续请求。
+	content := StarterContent(i18n.EN)
+	content[0] = 'x'
+	if StarterContent(i18n.EN)[0] != '{' {
+		t.Fatal("StarterContent must return a copy")
+	}
+}
+
+func TestLoadDefaultContent(t *testing.T) {
+	limits := ContentLimits{MaxBytes: 1024, MaxItems: 2}
+
+	builtIn, err := LoadDefaultContent("", limits)
+	if err != nil || builtIn != nil {
+		t.Fatalf("empty path should defer to built-in starters, got %s err=%v", builtIn, err)
+	}
+
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	custom := write("custom.json", `{
+  "layout_settings": {"columns": 12, "row_height_px": 10, "accent_color": "#000", "font_family": "Arial", "font_size_pt": 10, "margin_px": 24},
+  "items": [{"id": "a", "type": "text", "content": "Your name", "style": {}, "layout": {"x": 0, "y": 0, "w": 12, "h": 4}}]
+}`)
+	got, err := LoadDefaultContent(custom, limits)
+	if err != nil {
+		t.Fatalf("load custom default: %v", err)
+	}
+	if want := `{"layout_settings":{"columns":12,"row_height_px":10,"accent_color":"#000","font_family":"Arial","font_size_pt":10,"margin_px":24},"items":[{"id":"a","type":"text","content":"Your name","style":{},"layout":{"x":0,"y":0,"w":12,"h":4}}]}`; string(got) != want {
+		t.Fatalf("content = %s, want compacted file content", got)
+	}
+
+	cases := map[string]string{
+		"not json":   write("broken.json", `{"items": [`),
+		"no columns": write("columns.json", `{"layout_settings": {}, "items": []}`),
+		"too many":   write("items.json", `{"layout_settings": {"columns": 24}, "items": [{"id":"a"},{"id":"b"},{"id":"c"}]}`),
+	}
+	for name, path := range cases {
+		if _, err := LoadDefaultContent(path, limits); !errors.Is(err, ErrInvalidContent) {
+			t.Fatalf("%s: err = %v, want ErrInvalidContent", name, err)
+		}
+	}
+	if _, err := LoadDefaultContent(filepath.Join(dir, "missing.json"), limits); err == nil {
+		t.Fatal("missing file should fail")
+	}
+}
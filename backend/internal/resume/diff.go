@@ -0,0 +1,136 @@
+package resume
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ContentDiff 描述两份简历内容之间的差异：元素按 ID 配对，字段级变化以点分路径表示（如 layout.x、style.color）。
+type ContentDiff struct {
+	Added          []Item        `json:"added"`
+	Removed        []Item        `json:"removed"`
+	Modified       []ItemChange  `json:"modified"`
+	LayoutSettings []FieldChange `json:"layout_settings"`
+}
+
+// ItemChange 为同一 ID 的元素在前后两个版本间的字段变化。
+type ItemChange struct {
+	ID      string        `json:"id"`
+	Type    string        `json:"type"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange 为单个字段的变化；新增字段 Before 为 null，删除字段 After 为 null。
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Empty 报告两份内容是否没有差异。
+func (d ContentDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 && len(d.LayoutSettings) == 0
+}
+
+// DiffContent 比较 before 与 after：added/removed 按各自版本中的元素顺序，modified 按 after 中的顺序。
+// 没有 ID 的元素以位置（#0、#1…）配对。
+func DiffContent(before, after Content) ContentDiff {
+	diff := ContentDiff{
+		Added:          []Item{},
+		Removed:        []Item{},
+		Modified:       []ItemChange{},
+		LayoutSettings: diffFields(before.LayoutSettings, after.LayoutSettings),
+	}
+
+	beforeItems := make(map[string]Item, len(before.Items))
+	for i, item := range before.Items {
+		beforeItems[itemKey(item, i)] = item
+	}
+	seen := make(map[string]bool, len(after.Items))
+	for i, item := range after.Items {
+		key := itemKey(item, i)
+		seen[key] = true
+		prev, ok := beforeItems[key]
+		if !ok {
+			diff.Added = append(diff.Added, item)
+			continue
+		}
+		if changes := diffFields(prev, item); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, ItemChange{ID: item.ID, Type: item.Type, Changes: changes})
+		}
+	}
+	for i, item := range before.Items {
+		if !seen[itemKey(item, i)] {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+	return diff
+}
+
+func itemKey(item Item, index int) string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return "#" + strconv.Itoa(index)
+}
+
+// diffFields 将两侧按 JSON 形态展开为叶子字段后逐一比较；数组视为整体，避免下标错位产生噪声。
+func diffFields(before, after any) []FieldChange {
+	beforeFields := flattenJSON(before)
+	afterFields := flattenJSON(after)
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		b, a := beforeFields[name], afterFields[name]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: b, After: a})
+	}
+	return changes
+}
+
+func flattenJSON(value any) map[string]any {
+	out := map[string]any{}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return out
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return out
+	}
+	flattenInto(out, "", decoded)
+	return out
+}
+
+// flattenInto 只记录非 null 的叶子；null 与空对象视为缺省，不产生差异。
+func flattenInto(out map[string]any, prefix string, value any) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		if prefix != "" && value != nil {
+			out[prefix] = value
+		}
+		return
+	}
+	for key, child := range object {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		flattenInto(out, name, child)
+	}
+}
@@ -0,0 +1,59 @@
+package resume
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffContent(t *testing.T) {
+	before := Content{
+		LayoutSettings: LayoutSettings{Columns: 24, AccentColor: "#000000", FontSizePt: 10},
+		Items: []Item{
+			{ID: "name", Type: "text", Content: "Alice", Layout: Layout{X: 0, Y: 0, W: 12, H: 2}},
+			{ID: "photo", Type: "image", Content: "a.png", Style: map[string]interface{}{"borderRadius": "4px"}},
+			{ID: "old", Type: "divider"},
+		},
+	}
+	after := Content{
+		LayoutSettings: LayoutSettings{Columns: 24, AccentColor: "#ff0000", FontSizePt: 10, MultiPage: true},
+		Items: []Item{
+			{ID: "name", Type: "text", Content: "Alice Li", Layout: Layout{X: 0, Y: 1, W: 12, H: 2}},
+			{ID: "photo", Type: "image", Content: "a.png", Style: map[string]interface{}{"borderRadius": "4px"}},
+			{ID: "new", Type: "section_title", Content: "Skills"},
+		},
+	}
+
+	diff := DiffContent(before, after)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "new" {
+		t.Fatalf("unexpected added %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "old" {
+		t.Fatalf("unexpected removed %+v", diff.Removed)
+	}
+	wantItem := []ItemChange{{ID: "name", Type: "text", Changes: []FieldChange{
+		{Field: "content", Before: "Alice", After: "Alice Li"},
+		{Field: "layout.y", Before: float64(0), After: float64(1)},
+	}}}
+	if !reflect.DeepEqual(diff.Modified, wantItem) {
+		t.Fatalf("unexpected modified %+v", diff.Modified)
+	}
+	wantLayout := []FieldChange{
+		{Field: "accent_color", Before: "#000000", After: "#ff0000"},
+		{Field: "multi_page", Before: nil, After: true},
+	}
+	if !reflect.DeepEqual(diff.LayoutSettings, wantLayout) {
+		t.Fatalf("unexpected layout changes %+v", diff.LayoutSettings)
+	}
+	if diff.Empty() {
+		t.Fatal("expected non-empty diff")
+	}
+}
+
+func TestDiffContent_IdenticalAndNilStyle(t *testing.T) {
+	a := Content{Items: []Item{{ID: "x", Type: "text", Style: nil}, {Type: "divider"}}}
+	b := Content{Items: []Item{{ID: "x", Type: "text", Style: map[string]interface{}{}}, {Type: "divider"}}}
+	if diff := DiffContent(a, b); !diff.Empty() {
+		t.Fatalf("expected empty diff, got %+v", diff)
+	}
+}
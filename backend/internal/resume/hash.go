@@ -0,0 +1,13 @@
+package resume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ContentHash 返回简历 Content 的 SHA-256（hex）。
+// API 与 Worker 均对从数据库读出的原始 JSON 计算，jsonb 会规范化键序与空白，保证两端一致。
+func ContentHash(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
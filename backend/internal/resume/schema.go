@@ -0,0 +1,14 @@
+package resume
+
+import _ "embed"
+
+//go:embed content.schema.json
+var contentSchema []byte
+
+// ContentSchema 返回描述 Content 结构的 JSON Schema（draft 2020-12）。
+// 修改 Content/LayoutSettings/Item 或新增 item 类型时需同步更新 content.schema.json。
+func ContentSchema() []byte {
+	out := make([]byte, len(contentSchema))
+	copy(out, contentSchema)
+	return out
+}
@@ -1,5 +1,11 @@
 package resume
 
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
 // Content 表示存储在简历 Content(JSONB) 中的结构化数据。
 type Content struct {
 	LayoutSettings LayoutSettings `json:"layout_settings"`
@@ -15,6 +21,50 @@ type LayoutSettings struct {
 	FontSizePt      int    `json:"font_size_pt"`
 	MarginPx        int    `json:"margin_px"`
 	EnableWatermark bool   `json:"enable_watermark"`
+	PageSize        string `json:"page_size,omitempty"`  // 纸张尺寸提示：A4 / Letter，空值按 A4
+	MultiPage       bool   `json:"multi_page,omitempty"` // 内容可跨多页排版，导出时不再裁剪为单页
+
+	CustomFonts []CustomFont `json:"custom_fonts,omitempty"` // 用户上传的字体，打印时以 @font-face 注入
+}
+
+// CustomFont 描述一个用户上传的字体资产：Family 为 CSS font-family 名称，ObjectKey 指向 user-assets 下的 ttf/woff2 对象。
+type CustomFont struct {
+	Family    string `json:"family"`
+	ObjectKey string `json:"object_key"`
+}
+
+// MaxCustomFonts 为单份简历/模板可引用的自定义字体上限。
+const MaxCustomFonts = 8
+
+// NormalizeFontFamily 校验并规范化自定义字体名称：长度 1..64，且不含引号、分号、花括号等
+// 可能破坏 @font-face 声明的字符。
+func NormalizeFontFamily(value string) (string, bool) {
+	family := strings.TrimSpace(value)
+	if family == "" || utf8.RuneCountInString(family) > 64 {
+		return "", false
+	}
+	for _, r := range family {
+		if unicode.IsControl(r) || strings.ContainsRune(`"'\;{}<>`, r) {
+			return "", false
+		}
+	}
+	return family, true
+}
+
+// 支持的纸张尺寸。
+const (
+	PageSizeA4     = "A4"
+	PageSizeLetter = "Letter"
+)
+
+// NormalizePageSize 将纸张尺寸提示规范化为受支持的取值，未设置或未知时回落到 A4。
+func NormalizePageSize(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "letter":
+		return PageSizeLetter
+	default:
+		return PageSizeA4
+	}
 }
 
 // Item 表示页面中的单个元素。
@@ -0,0 +1,86 @@
+package resume
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrContentTooLarge 表示 content 的原始 JSON 超过字节上限。
+var ErrContentTooLarge = errors.New("content too large")
+
+// ErrInvalidContent 表示 content 结构不合法（无法解析、元素过多等）。
+var ErrInvalidContent = errors.New("invalid content")
+
+// ContentLimits 为简历/模板 content 的体积约束；字段为 0 表示不限制。
+type ContentLimits struct {
+	MaxBytes int
+	MaxItems int
+	Grid     GridLimits
+}
+
+// GridLimits 为网格布局约束，需与前端画布的网格假设（GRID_COLS 等）保持一致；字段为 0 表示不限制。
+type GridLimits struct {
+	MaxColumns int
+	MaxRows    int
+}
+
+// Columns 返回按 layout_settings.columns 实际生效的列数：未设置（<=0）或超过上限时取 MaxColumns。
+func (g GridLimits) Columns(columns int) int {
+	if g.MaxColumns > 0 && (columns <= 0 || columns > g.MaxColumns) {
+		return g.MaxColumns
+	}
+	return columns
+}
+
+// CheckContentSize 校验原始 JSON 的字节数不超过 maxBytes（<=0 表示不限制）。
+func CheckContentSize(raw []byte, maxBytes int) error {
+	if maxBytes > 0 && len(raw) > maxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds limit %d", ErrContentTooLarge, len(raw), maxBytes)
+	}
+	return nil
+}
+
+// ValidateContent 先检查字节上限，再解析为 Content 并检查元素数量与网格约束。
+// 超出字节上限返回 ErrContentTooLarge，其余问题返回 ErrInvalidContent（均可用 errors.Is 判断）。
+func ValidateContent(raw []byte, limits ContentLimits) (*Content, error) {
+	if err := CheckContentSize(raw, limits.MaxBytes); err != nil {
+		return nil, err
+	}
+
+	var content Content
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	if limits.MaxItems > 0 && len(content.Items) > limits.MaxItems {
+		return nil, fmt.Errorf("%w: %d items exceeds limit %d", ErrInvalidContent, len(content.Items), limits.MaxItems)
+	}
+	if err := validateGrid(&content, limits.Grid); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// validateGrid 检查列数不超过上限，且每个元素的位置非负、完整落在网格内（x+w <= 列数，y+h <= MaxRows）。
+func validateGrid(content *Content, grid GridLimits) error {
+	if grid == (GridLimits{}) {
+		return nil
+	}
+	if grid.MaxColumns > 0 && content.LayoutSettings.Columns > grid.MaxColumns {
+		return fmt.Errorf("%w: layout_settings.columns %d exceeds limit %d", ErrInvalidContent, content.LayoutSettings.Columns, grid.MaxColumns)
+	}
+	columns := grid.Columns(content.LayoutSettings.Columns)
+	for _, item := range content.Items {
+		layout := item.Layout
+		if layout.X < 0 || layout.Y < 0 || layout.W < 0 || layout.H < 0 {
+			return fmt.Errorf("%w: item %q has a negative layout", ErrInvalidContent, item.ID)
+		}
+		if columns > 0 && layout.X+layout.W > columns {
+			return fmt.Errorf("%w: item %q spans beyond column %d", ErrInvalidContent, item.ID, columns)
+		}
+		if grid.MaxRows > 0 && layout.Y+layout.H > grid.MaxRows {
+			return fmt.Errorf("%w: item %q spans beyond row %d", ErrInvalidContent, item.ID, grid.MaxRows)
+		}
+	}
+	return nil
+}
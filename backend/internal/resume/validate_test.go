@@ -0,0 +1,37 @@
+package resume
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateContent_Grid(t *testing.T) {
+	limits := ContentLimits{Grid: GridLimits{MaxColumns: 24, MaxRows: 100}}
+	cases := []struct {
+		name string
+		raw  string
+		ok   bool
+	}{
+		{"fits", `{"layout_settings":{"columns":24},"items":[{"id":"a","layout":{"x":12,"y":90,"w":12,"h":10}}]}`, true},
+		{"unset columns uses max", `{"layout_settings":{},"items":[{"id":"a","layout":{"x":0,"y":0,"w":24,"h":1}}]}`, true},
+		{"too many columns", `{"layout_settings":{"columns":48},"items":[]}`, false},
+		{"beyond right edge", `{"layout_settings":{"columns":24},"items":[{"id":"a","layout":{"x":20,"y":0,"w":6,"h":1}}]}`, false},
+		{"beyond narrower grid", `{"layout_settings":{"columns":12},"items":[{"id":"a","layout":{"x":0,"y":0,"w":13,"h":1}}]}`, false},
+		{"beyond last row", `{"layout_settings":{"columns":24},"items":[{"id":"a","layout":{"x":0,"y":95,"w":4,"h":6}}]}`, false},
+		{"negative position", `{"layout_settings":{"columns":24},"items":[{"id":"a","layout":{"x":-1,"y":0,"w":4,"h":1}}]}`, false},
+	}
+	for _, tc := range cases {
+		_, err := ValidateContent([]byte(tc.raw), limits)
+		if tc.ok && err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrInvalidContent) {
+			t.Fatalf("%s: expected ErrInvalidContent, got %v", tc.name, err)
+		}
+	}
+
+	// 未配置网格约束时不做检查。
+	if _, err := ValidateContent([]byte(`{"layout_settings":{"columns":48},"items":[{"layout":{"x":-1}}]}`), ContentLimits{}); err != nil {
+		t.Fatalf("expected no grid check without limits, got %v", err)
+	}
+}
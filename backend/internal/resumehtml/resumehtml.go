@@ -0,0 +1,342 @@
+// Package resumehtml 将打印数据渲染为自包含的 HTML 文档：Worker 在前端打印页不可用时据此生成 PDF，
+// API 的 HTML 导出也复用同一模板，保证两者版式一致。
+package resumehtml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	resumepkg "phResume/internal/resume"
+)
+
+// 服务端兜底渲染的默认版式，与前端 DEFAULT_LAYOUT_SETTINGS / editorStyles 保持一致。
+const (
+	defaultGridColumns   = 24
+	defaultRowHeightPx   = 10
+	defaultAccentColor   = "#3388ff"
+	defaultFontFamily    = "Arial"
+	defaultFontSizePt    = 10
+	defaultMarginPx      = 36
+	canvasWidthPx        = 794
+	canvasHeightPx       = 1123
+	cellPaddingPx        = 12
+	imageCellPaddingPx   = 8
+	cellRadiusPx         = 22
+	maxTemplateGridValue = 1000
+)
+
+// resumeTemplateFuncs 为服务端简历模板提供的函数：
+//   - add 用于把 0 起始的网格坐标换算为 CSS grid 的 1 起始行列号；
+//   - safeHTML/safeCSS/safeURL 将已由 API 清洗（或由 Worker 生成）的内容标记为可信，避免被 html/template 二次转义。
+func resumeTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"add":      func(a, b int) int { return a + b },
+		"safeHTML": func(s string) template.HTML { return template.HTML(s) },
+		"safeCSS":  func(s string) template.CSS { return template.CSS(s) },
+		"safeURL":  func(s string) template.URL { return template.URL(s) },
+	}
+}
+
+// resumeHTMLTemplate 为前端打印页不可用时的服务端兜底模板：按 layout_settings 生成 CSS grid，
+// 逐个渲染 text/section_title/divider/image 元素。只覆盖打印页的主要版式，不含水印以外的装饰细节。
+// CSP 禁止脚本执行，富文本中即便残留脚本也不会在 Worker 的浏览器中运行。
+const resumeHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="Content-Security-Policy" content="default-src 'none'; img-src data: http: https:; font-src data: http: https:; style-src 'unsafe-inline'">
+<style>
+{{safeCSS .FontFaceCSS}}
+@page { size: {{safeCSS .PageCSS}}; margin: 0; }
+* { box-sizing: border-box; -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+html, body { margin: 0; padding: 0; background: white; }
+#a4-container {
+  position: relative;
+  width: {{.CanvasWidthPx}}px;
+  height: {{.CanvasHeightPx}}px;
+  margin: 0 auto;
+  overflow: hidden;
+  background: white;
+  padding: {{.MarginPx}}px;
+  font-family: {{safeCSS .FontFamily}};
+  font-size: {{.FontSizePt}}pt;
+  color: {{safeCSS .AccentColor}};
+}
+#a4-container.multi-page { height: auto; min-height: {{.CanvasHeightPx}}px; overflow: visible; }
+.grid { display: grid; grid-template-columns: repeat({{.Columns}}, 1fr); grid-auto-rows: {{.RowHeightPx}}px; gap: 0; width: 100%; height: 100%; }
+.cell { min-width: 0; padding: {{.CellPaddingPx}}px; border-radius: {{.CellRadiusPx}}px; overflow: visible; }
+.cell.image { padding: {{.ImageCellPaddingPx}}px; overflow: hidden; }
+.text { white-space: pre-wrap; overflow-wrap: anywhere; word-break: break-word; padding-right: 2px; }
+.text p { margin: 0; }
+.section-title { position: relative; display: flex; align-items: flex-end; width: 100%; height: 100%; }
+.section-title::after { content: ""; position: absolute; left: 0; right: 0; bottom: 0; height: 1px; background: {{safeCSS .AccentColor}}; }
+.section-title > span { position: relative; z-index: 1; min-width: 100px; padding: 0 6px; font-weight: bold; line-height: 1.2; background: {{safeCSS .AccentColor}}; color: #ffffff; }
+.divider { display: flex; align-items: center; width: 100%; height: 100%; }
+.divider hr { width: 100%; margin: 8px 0; border: 0; border-top: 2px solid #d4d4d8; }
+.image img { display: block; width: 100%; height: 100%; object-fit: cover; border-radius: 0.75rem; }
+.watermark { position: absolute; right: 16px; bottom: 12px; font-size: 12px; color: #a78bfa; opacity: 0.8; }
+</style>
+</head>
+<body>
+<div id="a4-container"{{if .MultiPage}} class="multi-page"{{end}}>
+<div class="grid">
+{{- range .Items}}
+<div class="cell {{.Type}}" style="grid-column: {{add .X 1}} / span {{.W}}; grid-row: {{add .Y 1}} / span {{.H}};">
+{{- if eq .Type "image"}}<img src="{{safeURL .Content}}" alt="" style="{{safeCSS .Style}}">
+{{- else if eq .Type "divider"}}<div class="divider"><hr style="{{safeCSS .Style}}"></div>
+{{- else if eq .Type "section_title"}}<div class="section-title"><span style="{{safeCSS .Style}}">{{safeHTML .Content}}</span></div>
+{{- else}}<div class="text" style="{{safeCSS .Style}}">{{safeHTML .Content}}</div>
+{{- end}}</div>
+{{- end}}
+</div>
+{{- if .Watermark}}
+<div class="watermark">拼好历</div>
+{{- end}}
+</div>
+<div id="pdf-render-ready"></div>
+</body>
+</html>
+`
+
+// parsedResumeTemplate 在包初始化时解析一次；模板为常量，解析失败属于编码错误。
+var parsedResumeTemplate = template.Must(template.New("resume").Funcs(resumeTemplateFuncs()).Parse(resumeHTMLTemplate))
+
+// resumeTemplateData 为模板渲染所需的数据，样式均已换算为 CSS 声明字符串。
+type resumeTemplateData struct {
+	FontFaceCSS        string
+	PageCSS            string
+	MultiPage          bool
+	Watermark          bool
+	CanvasWidthPx      int
+	CanvasHeightPx     int
+	MarginPx           int
+	FontFamily         string
+	FontSizePt         int
+	AccentColor        string
+	Columns            int
+	RowHeightPx        int
+	CellPaddingPx      int
+	ImageCellPaddingPx int
+	CellRadiusPx       int
+	Items              []resumeTemplateItem
+}
+
+type resumeTemplateItem struct {
+	Type    string
+	Content string
+	Style   string
+	X, Y    int
+	W, H    int
+}
+
+// Build 将打印数据（API 已清洗富文本与样式、内联图片）渲染为完整的 HTML 文档，fontFaceCSS 原样注入 <style>。
+// 未识别的元素类型与非 data:image/http(s) 的图片地址会被跳过。
+func Build(printData []byte, fontFaceCSS string) ([]byte, error) {
+	var content resumepkg.Content
+	if err := json.Unmarshal(printData, &content); err != nil {
+		return nil, fmt.Errorf("decode print data: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := parsedResumeTemplate.Execute(&buf, newResumeTemplateData(content, fontFaceCSS)); err != nil {
+		return nil, fmt.Errorf("execute resume template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newResumeTemplateData(content resumepkg.Content, fontFaceCSS string) resumeTemplateData {
+	settings := content.LayoutSettings
+	data := resumeTemplateData{
+		FontFaceCSS:        fontFaceCSS,
+		PageCSS:            pageCSS(settings.PageSize),
+		MultiPage:          settings.MultiPage,
+		Watermark:          settings.EnableWatermark,
+		CanvasWidthPx:      canvasWidthPx,
+		CanvasHeightPx:     canvasHeightPx,
+		MarginPx:           clampInt(settings.MarginPx, 0, 200, defaultMarginPx),
+		FontFamily:         cssFontFamily(settings.FontFamily),
+		FontSizePt:         clampInt(settings.FontSizePt, 4, 72, defaultFontSizePt),
+		AccentColor:        cssColor(settings.AccentColor, defaultAccentColor),
+		Columns:            clampInt(settings.Columns, 1, 96, defaultGridColumns),
+		RowHeightPx:        clampInt(settings.RowHeightPx, 1, 200, defaultRowHeightPx),
+		CellPaddingPx:      cellPaddingPx,
+		ImageCellPaddingPx: imageCellPaddingPx,
+		CellRadiusPx:       cellRadiusPx,
+	}
+
+	for _, item := range content.Items {
+		tmplItem := resumeTemplateItem{
+			Type:    item.Type,
+			Content: item.Content,
+			Style:   itemStyleCSS(item.Style),
+			X:       clampInt(item.Layout.X, 0, maxTemplateGridValue, 0),
+			Y:       clampInt(item.Layout.Y, 0, maxTemplateGridValue, 0),
+			W:       clampInt(item.Layout.W, 1, maxTemplateGridValue, 4),
+			H:       clampInt(item.Layout.H, 1, maxTemplateGridValue, 4),
+		}
+		switch item.Type {
+		case "text", "section_title":
+		case "divider":
+			tmplItem.Content = ""
+		case "image":
+			if !isRenderableImageURL(item.Content) {
+				continue
+			}
+		default:
+			continue
+		}
+		data.Items = append(data.Items, tmplItem)
+	}
+	return data
+}
+
+// pageCSS 返回 @page size 使用的纸张关键字，未知尺寸按 A4。
+func pageCSS(pageSize string) string {
+	if resumepkg.NormalizePageSize(pageSize) == resumepkg.PageSizeLetter {
+		return "letter"
+	}
+	return "A4"
+}
+
+// clampInt 将 value 限制在 [lo, hi]；value 为 0（未设置）时返回 fallback。
+func clampInt(value, lo, hi, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return max(lo, min(hi, value))
+}
+
+func isRenderableImageURL(value string) bool {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	return strings.HasPrefix(lower, "data:image/") ||
+		strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "http://")
+}
+
+var cssColorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]{3,32}|(rgb|rgba|hsl|hsla)\([0-9.,%\s]+\))$`)
+
+// cssColor 只接受十六进制、颜色关键字与 rgb/hsl 函数，其余回退 fallback。
+func cssColor(value, fallback string) string {
+	value = strings.TrimSpace(value)
+	if cssColorPattern.MatchString(value) {
+		return value
+	}
+	return fallback
+}
+
+// cssFontFamily 将逗号分隔的字体列表逐个校验并加引号，末尾补 sans-serif；全部非法时使用默认字体。
+func cssFontFamily(value string) string {
+	var families []string
+	for _, part := range strings.Split(value, ",") {
+		family, ok := resumepkg.NormalizeFontFamily(strings.Trim(strings.TrimSpace(part), `"'`))
+		if ok {
+			families = append(families, CSSString(family))
+		}
+	}
+	if len(families) == 0 {
+		families = []string{CSSString(defaultFontFamily)}
+	}
+	return strings.Join(append(families, "sans-serif"), ", ")
+}
+
+// unitlessStyleProperties 为数字值不补 px 的属性（与 React style 的约定一致）。
+var unitlessStyleProperties = map[string]bool{
+	"fontWeight": true,
+	"lineHeight": true,
+	"opacity":    true,
+}
+
+// skippedStyleProperties 为前端自行解释、不能直接输出为 CSS 的属性。
+var skippedStyleProperties = map[string]bool{
+	"backgroundOpacity": true,
+}
+
+// itemStyleCSS 将 camelCase 的 item.style 转为 CSS 声明（按属性名排序，输出稳定）。
+// API 已按白名单清洗样式，这里仍拒绝可能注入新声明的字符作为兜底。
+func itemStyleCSS(style map[string]interface{}) string {
+	keys := make([]string, 0, len(style))
+	for key := range style {
+		if !skippedStyleProperties[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value, ok := cssStyleValue(key, style[key])
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s; ", cssPropertyName(key), value)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func cssStyleValue(key string, value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !unitlessStyleProperties[key] {
+			s += "px"
+		}
+		return s, true
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" || strings.ContainsAny(s, ";{}<>\\") {
+			return "", false
+		}
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+// cssPropertyName 将 camelCase 属性名转为 kebab-case，如 backgroundColor -> background-color。
+func cssPropertyName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CSSString 将值转义为 CSS 双引号字符串。
+func CSSString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", "", "\r", "")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// FontFormat 按对象扩展名返回 @font-face src 的 format() 提示，不支持的扩展名返回空串。
+func FontFormat(objectKey string) string {
+	switch strings.ToLower(path.Ext(objectKey)) {
+	case ".woff2":
+		return "woff2"
+	case ".ttf":
+		return "truetype"
+	default:
+		return ""
+	}
+}
+
+// FontFaceRule 生成一条 @font-face 规则；src 为字体 URL（预签名链接或 data URI）。
+func FontFaceRule(family, src, format string) string {
+	return fmt.Sprintf("@font-face { font-family: %s; src: url(%s) format(%s); font-display: block; }\n",
+		CSSString(family), CSSString(src), CSSString(format))
+}
@@ -0,0 +1,112 @@
+package resumehtml
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"testing"
+
+	resumepkg "phResume/internal/resume"
+)
+
+func TestResumeTemplateFuncs(t *testing.T) {
+	funcs := resumeTemplateFuncs()
+	for _, name := range []string{"add", "safeHTML", "safeCSS", "safeURL"} {
+		if funcs[name] == nil {
+			t.Fatalf("FuncMap missing %q", name)
+		}
+	}
+	if got := funcs["add"].(func(int, int) int)(0, 1); got != 1 {
+		t.Fatalf("add(0, 1) = %d, want 1", got)
+	}
+	if got := funcs["safeURL"].(func(string) template.URL)("data:image/png;base64,AA=="); got != "data:image/png;base64,AA==" {
+		t.Fatalf("safeURL changed value: %q", got)
+	}
+}
+
+func TestBuildResumeHTML_DefaultContentGridAndImages(t *testing.T) {
+	var content map[string]any
+	if err := json.Unmarshal(resumepkg.DefaultContent(), &content); err != nil {
+		t.Fatalf("decode default content: %v", err)
+	}
+	const dataURI = "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR4nGNgYGD4DwABBAEAwS2OUAAAAABJRU5ErkJggg=="
+	content["items"] = append(content["items"].([]any), map[string]any{
+		"id":      "avatar",
+		"type":    "image",
+		"content": dataURI,
+		"style":   map[string]any{"objectFit": "contain"},
+		"layout":  map[string]any{"x": 20, "y": 12, "w": 4, "h": 8},
+	})
+	printData, err := json.Marshal(content)
+	if err != nil {
+		t.Fatalf("encode print data: %v", err)
+	}
+
+	html, err := Build(printData, "")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	out := string(html)
+
+	for _, want := range []string{
+		// 0 起始的 layout 坐标换算为 1 起始的 grid 行列号，宽高按 span 输出。
+		"grid-column: 1 / span 16; grid-row: 3 / span 6;",
+		"grid-column: 1 / span 16; grid-row: 9 / span 4;",
+		"grid-column: 17 / span 8; grid-row: 3 / span 10;",
+		"grid-column: 21 / span 4; grid-row: 13 / span 8;",
+		"grid-template-columns: repeat(24, 1fr); grid-auto-rows: 10px;",
+		"你的名字",
+		"电话: 123-456-7890",
+		`src="` + dataURI + `"`,
+		`style="object-fit: contain;"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("html missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ZgotmplZ") {
+		t.Fatalf("html/template rejected a value:\n%s", out)
+	}
+}
+
+func TestBuildResumeHTML_SkipsUnsupportedItems(t *testing.T) {
+	printData := []byte(`{
+  "layout_settings": {"columns": 24, "row_height_px": 10, "accent_color": "red;}", "font_family": "Inter, \"Noto Sans SC\"", "page_size": "letter"},
+  "items": [
+    {"id": "t1", "type": "text", "content": "<p>Hello</p>", "style": {"fontSize": 14, "lineHeight": 1.5, "backgroundColor": "#fff", "backgroundOpacity": 0.5}, "layout": {"x": 0, "y": 0, "w": 24, "h": 4}},
+    {"id": "img", "type": "image", "content": "javascript:alert(1)", "style": {}, "layout": {"x": 0, "y": 4, "w": 4, "h": 4}},
+    {"id": "x", "type": "chart", "content": "ignored", "style": {}, "layout": {"x": 0, "y": 8, "w": 4, "h": 4}}
+  ]
+}`)
+
+	html, err := Build(printData, "")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	out := string(html)
+
+	for _, want := range []string{
+		"Content-Security-Policy",
+		"size: letter;",
+		`font-family: "Inter", "Noto Sans SC", sans-serif;`,
+		"color: #3388ff;",
+		"<p>Hello</p>",
+		`style="background-color: #fff; font-size: 14px; line-height: 1.5;"`,
+		`id="pdf-render-ready"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("html missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"javascript:", "ignored", "background-opacity", "red;}"} {
+		if strings.Contains(out, unwanted) {
+			t.Fatalf("html unexpectedly contains %q", unwanted)
+		}
+	}
+}
+
+func TestBuildResumeHTML_InvalidPrintData(t *testing.T) {
+	if _, err := Build([]byte(`not json`), ""); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
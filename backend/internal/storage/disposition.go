@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AttachmentDisposition 生成下载用的 Content-Disposition 值：filename 为 ASCII 兜底（非 ASCII 字符替换为 _），
+// filename* 按 RFC 5987 以 UTF-8 百分号编码保留原名，现代浏览器优先使用后者。
+// 既可直接写入响应头，也可作为预签名链接的 response-content-disposition 参数。
+func AttachmentDisposition(filename string) string {
+	var fallback strings.Builder
+	for _, r := range filename {
+		switch {
+		case r > unicode.MaxASCII || unicode.IsControl(r):
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			// 引号内不允许未转义的 " 与 \，直接丢弃。
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	return `attachment; filename="` + fallback.String() + `"; filename*=UTF-8''` + encodeRFC5987(filename)
+}
+
+// encodeRFC5987 对 attr-char 以外的字节做百分号编码。
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"mime"
+	"testing"
+)
+
+func TestAttachmentDisposition(t *testing.T) {
+	cases := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"ascii", "Resume-1.pdf", `attachment; filename="Resume-1.pdf"; filename*=UTF-8''Resume-1.pdf`},
+		{"spaces and quotes", `my "cv" (1).pdf`, `attachment; filename="my cv (1).pdf"; filename*=UTF-8''my%20%22cv%22%20%281%29.pdf`},
+		{"non-ascii", "后端简历.pdf", `attachment; filename="____.pdf"; filename*=UTF-8''%E5%90%8E%E7%AB%AF%E7%AE%80%E5%8E%86.pdf`},
+	}
+	for _, tc := range cases {
+		got := AttachmentDisposition(tc.filename)
+		if got != tc.want {
+			t.Errorf("%s: got %q want %q", tc.name, got, tc.want)
+			continue
+		}
+		// 标准库解析时优先采用 filename*，应还原出原始文件名。
+		_, params, err := mime.ParseMediaType(got)
+		if err != nil {
+			t.Errorf("%s: parse: %v", tc.name, err)
+			continue
+		}
+		if params["filename"] != tc.filename {
+			t.Errorf("%s: parsed filename %q want %q", tc.name, params["filename"], tc.filename)
+		}
+	}
+}
@@ -16,10 +16,15 @@ import (
 )
 
 // Client 封装 MinIO 客户端，提供简化的上传接口。
+// 每次调用都在调用方 ctx 上派生单次超时：上传用 uploadTimeout，其余用 requestTimeout；
+// stat/get/delete/list 等幂等操作在瞬时错误上最多重试 retries 次。
 type Client struct {
 	internalClient *minio.Client
-	publicClient   *minio.Client
+	presignClient  *minio.Client // 签发预签名链接；默认基于 public endpoint，探测失败且开启回退时为 internalClient
 	bucketName     string
+	requestTimeout time.Duration
+	uploadTimeout  time.Duration
+	retries        int
 }
 
 // ObjectMeta 描述 Bucket 中对象的关键信息。
@@ -89,15 +94,37 @@ func NewClient(cfg config.MinIOConfig) (*Client, error) {
 		}
 	}
 
+	presignClient := publicClient
+	if err := checkPublicEndpoint(context.Background(), publicClient, cfg.Bucket); err != nil {
+		if cfg.PresignFallback {
+			presignClient = internalClient
+			slog.Default().Warn("minio public endpoint check failed, presigning with internal endpoint",
+				slog.String("public_endpoint", cfg.PublicEndpoint),
+				slog.String("internal_endpoint", cfg.Endpoint),
+				slog.Any("error", err),
+			)
+		} else {
+			slog.Default().Warn("minio public endpoint check failed, presigned urls may be unusable (ignore if the endpoint is only reachable from clients)",
+				slog.String("public_endpoint", cfg.PublicEndpoint),
+				slog.Any("error", err),
+			)
+		}
+	}
+
 	return &Client{
 		internalClient: internalClient,
-		publicClient:   publicClient,
+		presignClient:  presignClient,
 		bucketName:     cfg.Bucket,
+		requestTimeout: cfg.RequestTimeout,
+		uploadTimeout:  cfg.UploadTimeout,
+		retries:        cfg.RequestRetries,
 	}, nil
 }
 
-// UploadFile 将对象上传到私有 Bucket，并返回上传结果。
+// UploadFile 将对象上传到私有 Bucket，并返回上传结果。reader 只能消费一次，因此上传不重试。
 func (c *Client) UploadFile(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (*minio.UploadInfo, error) {
+	ctx, cancel := withTimeout(ctx, c.uploadTimeout)
+	defer cancel()
 	opts := minio.PutObjectOptions{ContentType: contentType}
 	info, err := c.internalClient.PutObject(ctx, c.bucketName, objectName, reader, size, opts)
 	if err != nil {
@@ -107,7 +134,15 @@ func (c *Client) UploadFile(ctx context.Context, objectName string, reader io.Re
 }
 
 // GetObject 直接读取私有 Bucket 中的对象。
+// minio 的 Object 在首次读取时才发起请求，无法给读取过程单独计时；因此先在超时与重试内 stat 一次，
+// 让连接故障与对象不存在（IsNoSuchKey）在这里就返回，正文读取沿用调用方 ctx。
 func (c *Client) GetObject(ctx context.Context, objectKey string) (*minio.Object, error) {
+	if err := c.retry(ctx, func(ctx context.Context) error {
+		_, err := c.internalClient.StatObject(ctx, c.bucketName, objectKey, minio.StatObjectOptions{})
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("get object %q: %w", objectKey, err)
+	}
 	obj, err := c.internalClient.GetObject(ctx, c.bucketName, objectKey, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("get object %q: %w", objectKey, err)
@@ -115,9 +150,25 @@ func (c *Client) GetObject(ctx context.Context, objectKey string) (*minio.Object
 	return obj, nil
 }
 
+// ObjectExists 判断对象是否存在；对象不存在返回 (false, nil)，其他错误原样返回。
+func (c *Client) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	if err := c.retry(ctx, func(ctx context.Context) error {
+		_, err := c.internalClient.StatObject(ctx, c.bucketName, objectKey, minio.StatObjectOptions{})
+		return err
+	}); err != nil {
+		if IsNoSuchKey(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("stat object %q: %w", objectKey, err)
+	}
+	return true, nil
+}
+
 // GeneratePresignedURL 生成对象的限时下载链接。
 func (c *Client) GeneratePresignedURL(ctx context.Context, objectKey string, duration time.Duration) (string, error) {
-	presignedURL, err := c.publicClient.PresignedGetObject(ctx, c.bucketName, objectKey, duration, nil)
+	ctx, cancel := withTimeout(ctx, c.requestTimeout)
+	defer cancel()
+	presignedURL, err := c.presignClient.PresignedGetObject(ctx, c.bucketName, objectKey, duration, nil)
 	if err != nil {
 		return "", fmt.Errorf("generate presigned url for %q: %w", objectKey, err)
 	}
@@ -126,6 +177,8 @@ func (c *Client) GeneratePresignedURL(ctx context.Context, objectKey string, dur
 
 // GeneratePresignedURLWithParams 生成带自定义响应参数的限时下载链接。
 func (c *Client) GeneratePresignedURLWithParams(ctx context.Context, objectKey string, duration time.Duration, params map[string]string) (string, error) {
+	ctx, cancel := withTimeout(ctx, c.requestTimeout)
+	defer cancel()
 	var v url.Values
 	if params != nil {
 		v = url.Values{}
@@ -133,7 +186,7 @@ func (c *Client) GeneratePresignedURLWithParams(ctx context.Context, objectKey s
 			v.Set(k, val)
 		}
 	}
-	presignedURL, err := c.publicClient.PresignedGetObject(ctx, c.bucketName, objectKey, duration, v)
+	presignedURL, err := c.presignClient.PresignedGetObject(ctx, c.bucketName, objectKey, duration, v)
 	if err != nil {
 		return "", fmt.Errorf("generate presigned url with params for %q: %w", objectKey, err)
 	}
@@ -145,26 +198,40 @@ func (c *Client) ListObjects(ctx context.Context, prefix string, limit int) ([]O
 	if limit <= 0 {
 		limit = 50
 	}
+	var result []ObjectMeta
+	if err := c.retry(ctx, func(ctx context.Context) error {
+		result = make([]ObjectMeta, 0, limit)
+		return c.listObjects(ctx, prefix, func(object minio.ObjectInfo) bool {
+			result = append(result, ObjectMeta{
+				Key:          object.Key,
+				Size:         object.Size,
+				LastModified: object.LastModified,
+			})
+			return len(result) < limit
+		})
+	}); err != nil {
+		return nil, fmt.Errorf("list objects under %q: %w", prefix, err)
+	}
+	return result, nil
+}
+
+// listObjects 递归遍历 prefix 下的对象，fn 返回 false 时提前结束；提前结束时取消 ctx 让 minio 停止后台分页。
+func (c *Client) listObjects(ctx context.Context, prefix string, fn func(minio.ObjectInfo) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	objCh := c.internalClient.ListObjects(ctx, c.bucketName, minio.ListObjectsOptions{
 		Prefix:    prefix,
 		Recursive: true,
 	})
-	result := make([]ObjectMeta, 0, limit)
 	for object := range objCh {
 		if object.Err != nil {
-			return nil, fmt.Errorf("list objects under %q: %w", prefix, object.Err)
+			return object.Err
 		}
-		meta := ObjectMeta{
-			Key:          object.Key,
-			Size:         object.Size,
-			LastModified: object.LastModified,
-		}
-		result = append(result, meta)
-		if len(result) >= limit {
-			break
+		if !fn(object) {
+			return nil
 		}
 	}
-	return result, nil
+	return nil
 }
 
 // DeleteObject 删除指定对象。
@@ -174,7 +241,9 @@ func (c *Client) DeleteObject(ctx context.Context, objectKey string) error {
 	if objectKey == "" {
 		return nil
 	}
-	if err := c.internalClient.RemoveObject(ctx, c.bucketName, objectKey, minio.RemoveObjectOptions{}); err != nil {
+	if err := c.retry(ctx, func(ctx context.Context) error {
+		return c.internalClient.RemoveObject(ctx, c.bucketName, objectKey, minio.RemoveObjectOptions{})
+	}); err != nil {
 		if IsNoSuchKey(err) {
 			return nil
 		}
@@ -183,6 +252,44 @@ func (c *Client) DeleteObject(ctx context.Context, objectKey string) error {
 	return nil
 }
 
+// DeleteObjects 通过 S3 批量删除接口删除多个对象，返回删除失败的 key 及其错误。
+// 与 DeleteObject 一致，对象不存在视为成功；ctx 取消或超时时尚未确认的 key 记为失败，由调用方决定是否重试。
+func (c *Client) DeleteObjects(ctx context.Context, objectKeys []string) map[string]error {
+	ctx, cancel := withTimeout(ctx, c.requestTimeout)
+	defer cancel()
+	failed := make(map[string]error)
+	keys := make([]string, 0, len(objectKeys))
+	for _, key := range objectKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return failed
+	}
+
+	objectsCh := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objectsCh <- minio.ObjectInfo{Key: key}
+	}
+	close(objectsCh)
+
+	for removeErr := range c.internalClient.RemoveObjects(ctx, c.bucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		if removeErr.Err == nil || IsNoSuchKey(removeErr.Err) {
+			continue
+		}
+		failed[removeErr.ObjectName] = fmt.Errorf("remove object %q: %w", removeErr.ObjectName, removeErr.Err)
+	}
+	if err := ctx.Err(); err != nil {
+		for _, key := range keys {
+			if _, ok := failed[key]; !ok {
+				failed[key] = err
+			}
+		}
+	}
+	return failed
+}
+
 // DeletePrefix 删除指定前缀下的所有对象。
 // 若某些对象已不存在会被忽略；其余错误会聚合返回。
 func (c *Client) DeletePrefix(ctx context.Context, prefix string) error {
@@ -191,19 +298,17 @@ func (c *Client) DeletePrefix(ctx context.Context, prefix string) error {
 		return nil
 	}
 
-	objCh := c.internalClient.ListObjects(ctx, c.bucketName, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: true,
-	})
-
-	keys := make([]string, 0, 32)
-	for object := range objCh {
-		if object.Err != nil {
-			return fmt.Errorf("list objects under %q: %w", prefix, object.Err)
-		}
-		if strings.TrimSpace(object.Key) != "" {
-			keys = append(keys, object.Key)
-		}
+	var keys []string
+	if err := c.retry(ctx, func(ctx context.Context) error {
+		keys = make([]string, 0, 32)
+		return c.listObjects(ctx, prefix, func(object minio.ObjectInfo) bool {
+			if strings.TrimSpace(object.Key) != "" {
+				keys = append(keys, object.Key)
+			}
+			return true
+		})
+	}); err != nil {
+		return fmt.Errorf("list objects under %q: %w", prefix, err)
 	}
 	if len(keys) == 0 {
 		return nil
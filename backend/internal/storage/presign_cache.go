@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// URLPresigner 为生成预签名 GET URL 的最小能力，*Client 即满足。
+type URLPresigner interface {
+	GeneratePresignedURL(ctx context.Context, objectKey string, expiry time.Duration) (string, error)
+}
+
+// PresignCacheStore 为预签名缓存所需的最小 Redis 能力。
+type PresignCacheStore interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+}
+
+// 缓存的 URL 至少保留这么长的剩余有效期，避免客户端拿到即将过期的链接。
+const (
+	presignCacheMinMargin = 30 * time.Second
+	presignCacheKeyPrefix = "presign:"
+)
+
+// PresignCache 以 Redis 缓存预签名 URL：同一对象、同一有效期在缓存 TTL 内复用同一 URL，
+// 减少签名开销并让浏览器可以按 URL 缓存图片。Redis 不可用时直接回落到实时签名。
+type PresignCache struct {
+	signer URLPresigner
+	store  PresignCacheStore
+}
+
+// NewPresignCache 创建预签名缓存；store 为 nil 时不做缓存。
+func NewPresignCache(signer URLPresigner, store PresignCacheStore) *PresignCache {
+	return &PresignCache{signer: signer, store: store}
+}
+
+// GeneratePresignedURL 优先返回缓存中的 URL，未命中时签名并写入缓存。
+// 缓存 TTL 比 expiry 短 max(expiry/5, 30s)，保证返回的 URL 仍有足够的剩余有效期。
+func (p *PresignCache) GeneratePresignedURL(ctx context.Context, objectKey string, expiry time.Duration) (string, error) {
+	ttl := presignCacheTTL(expiry)
+	if p.store == nil || ttl <= 0 {
+		return p.signer.GeneratePresignedURL(ctx, objectKey, expiry)
+	}
+
+	key := presignCacheKey(objectKey, expiry)
+	// 未命中（redis.Nil）或 Redis 异常都回落到实时签名，不影响主流程。
+	if cached, err := p.store.Get(ctx, key).Result(); err == nil && cached != "" {
+		return cached, nil
+	}
+
+	signed, err := p.signer.GeneratePresignedURL(ctx, objectKey, expiry)
+	if err != nil {
+		return "", err
+	}
+	_ = p.store.Set(ctx, key, signed, ttl).Err()
+	return signed, nil
+}
+
+func presignCacheTTL(expiry time.Duration) time.Duration {
+	margin := expiry / 5
+	if margin < presignCacheMinMargin {
+		margin = presignCacheMinMargin
+	}
+	return expiry - margin
+}
+
+// presignCacheKey 将有效期纳入缓存键，不同 TTL 的调用方（列表/单个查看）互不复用。
+func presignCacheKey(objectKey string, expiry time.Duration) string {
+	return presignCacheKeyPrefix + strconv.FormatInt(int64(expiry/time.Second), 10) + ":" + objectKey
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type countingPresigner struct {
+	calls int
+	err   error
+}
+
+func (p *countingPresigner) GeneratePresignedURL(_ context.Context, objectKey string, expiry time.Duration) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.calls++
+	return "https://minio.local/" + objectKey + "?sig=" + expiry.String() + "-" + string(rune('0'+p.calls)), nil
+}
+
+type memoryCacheStore struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+	down   bool
+}
+
+func (s *memoryCacheStore) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx)
+	switch v, ok := s.values[key]; {
+	case s.down:
+		cmd.SetErr(errors.New("redis down"))
+	case !ok:
+		cmd.SetErr(redis.Nil)
+	default:
+		cmd.SetVal(v)
+	}
+	return cmd
+}
+
+func (s *memoryCacheStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	cmd := redis.NewStatusCmd(ctx)
+	if s.down {
+		cmd.SetErr(errors.New("redis down"))
+		return cmd
+	}
+	s.values[key] = value.(string)
+	s.ttls[key] = expiration
+	return cmd
+}
+
+func TestPresignCache_ReusesURLWithinTTL(t *testing.T) {
+	ctx := context.Background()
+	signer := &countingPresigner{}
+	store := &memoryCacheStore{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	cache := NewPresignCache(signer, store)
+
+	first, err := cache.GeneratePresignedURL(ctx, "user-assets/1/a.png", 10*time.Minute)
+	if err != nil {
+		t.Fatalf("first presign: %v", err)
+	}
+	second, err := cache.GeneratePresignedURL(ctx, "user-assets/1/a.png", 10*time.Minute)
+	if err != nil {
+		t.Fatalf("second presign: %v", err)
+	}
+	if first != second || signer.calls != 1 {
+		t.Fatalf("expected cached url reuse, calls=%d first=%q second=%q", signer.calls, first, second)
+	}
+	if ttl := store.ttls[presignCacheKey("user-assets/1/a.png", 10*time.Minute)]; ttl != 8*time.Minute {
+		t.Fatalf("expected cache ttl 8m got %s", ttl)
+	}
+
+	// 不同有效期不复用，避免把短 TTL 的 URL 当作长 TTL 返回。
+	if _, err := cache.GeneratePresignedURL(ctx, "user-assets/1/a.png", 15*time.Minute); err != nil {
+		t.Fatalf("presign with other ttl: %v", err)
+	}
+	if signer.calls != 2 {
+		t.Fatalf("expected a fresh signature for a different ttl, calls=%d", signer.calls)
+	}
+}
+
+func TestPresignCache_FallsBackWhenRedisUnavailable(t *testing.T) {
+	ctx := context.Background()
+	signer := &countingPresigner{}
+	store := &memoryCacheStore{values: map[string]string{}, ttls: map[string]time.Duration{}, down: true}
+	cache := NewPresignCache(signer, store)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GeneratePresignedURL(ctx, "user-assets/1/a.png", time.Minute); err != nil {
+			t.Fatalf("presign: %v", err)
+		}
+	}
+	if signer.calls != 2 {
+		t.Fatalf("expected every call to sign when redis is down, calls=%d", signer.calls)
+	}
+
+	// 有效期过短（不足以保留余量）时不缓存。
+	store.down = false
+	if _, err := NewPresignCache(signer, store).GeneratePresignedURL(ctx, "user-assets/1/b.png", 20*time.Second); err != nil {
+		t.Fatalf("presign short ttl: %v", err)
+	}
+	if len(store.values) != 0 {
+		t.Fatalf("expected short-lived urls not to be cached, got %v", store.values)
+	}
+
+	signer.err = errors.New("sign failed")
+	if _, err := cache.GeneratePresignedURL(ctx, "user-assets/1/c.png", time.Minute); err == nil {
+		t.Fatalf("expected signer error to propagate")
+	}
+}
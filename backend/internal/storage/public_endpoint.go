@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// publicEndpointCheckTimeout 为启动时探测 public endpoint 的超时，避免拖慢启动。
+const publicEndpointCheckTimeout = 3 * time.Second
+
+// errPublicBucketMissing 表示 public endpoint 可达，但看不到目标 bucket（通常指向了另一个存储实例）。
+var errPublicBucketMissing = errors.New("bucket not found at public endpoint")
+
+// checkPublicEndpoint 用 public client 对 bucket 发起一次 HEAD：网络不可达或 bucket 不存在时返回错误。
+// 服务端返回的其他 S3 错误（如签名/权限不符）说明请求已到达存储服务，视为可达。
+func checkPublicEndpoint(ctx context.Context, client *minio.Client, bucket string) error {
+	ctx, cancel := context.WithTimeout(ctx, publicEndpointCheckTimeout)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		if IsNoSuchBucket(err) {
+			return errPublicBucketMissing
+		}
+		var resp minio.ErrorResponse
+		if errors.As(err, &resp) && resp.StatusCode != 0 {
+			return nil
+		}
+		return fmt.Errorf("public endpoint unreachable: %w", err)
+	}
+	if !exists {
+		return errPublicBucketMissing
+	}
+	return nil
+}
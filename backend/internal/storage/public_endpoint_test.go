@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+func newTestMinioClient(t *testing.T, endpoint string) *minio.Client {
+	t.Helper()
+	client, err := minio.New(strings.TrimPrefix(endpoint, "http://"), &minio.Options{
+		Creds:        credentials.NewStaticV4("key", "secret", ""),
+		Region:       "us-east-1",
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		t.Fatalf("minio.New: %v", err)
+	}
+	return client
+}
+
+func TestCheckPublicEndpoint(t *testing.T) {
+	ctx := context.Background()
+
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	client := newTestMinioClient(t, srv.URL)
+
+	if err := checkPublicEndpoint(ctx, client, "resumes"); err != nil {
+		t.Fatalf("expected reachable endpoint, got %v", err)
+	}
+
+	// 签名或权限不符说明请求已到达存储服务，不影响判断。
+	status = http.StatusForbidden
+	if err := checkPublicEndpoint(ctx, client, "resumes"); err != nil {
+		t.Fatalf("expected 403 to count as reachable, got %v", err)
+	}
+
+	status = http.StatusNotFound
+	if err := checkPublicEndpoint(ctx, client, "resumes"); !errors.Is(err, errPublicBucketMissing) {
+		t.Fatalf("expected missing bucket error, got %v", err)
+	}
+
+	srv.Close()
+	shortCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := checkPublicEndpoint(shortCtx, client, "resumes"); err == nil || errors.Is(err, errPublicBucketMissing) {
+		t.Fatalf("expected unreachable error, got %v", err)
+	}
+}
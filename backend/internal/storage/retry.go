@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// retryBackoff 为第一次重试前的等待时间，之后按尝试次数线性增加。
+const retryBackoff = 100 * time.Millisecond
+
+// withTimeout 为单次存储调用派生带超时的 ctx；timeout <= 0 时沿用调用方 ctx。
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// retry 执行幂等操作：每次尝试单独计时（requestTimeout），遇到瞬时错误时最多再试 retries 次；
+// 调用方 ctx 结束或错误不可重试（对象不存在、权限不足等）时立即返回。
+func (c *Client) retry(ctx context.Context, op func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := withTimeout(ctx, c.requestTimeout)
+		err = op(attemptCtx)
+		cancel()
+		if err == nil || attempt >= c.retries || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * retryBackoff):
+		}
+	}
+}
+
+// isRetryable 判断错误是否为瞬时故障：网络错误、单次超时、5xx 与限流响应。
+func isRetryable(err error) bool {
+	var resp minio.ErrorResponse
+	if errors.As(err, &resp) {
+		switch resp.Code {
+		case "SlowDown", "RequestTimeout", "InternalError", "ServiceUnavailable":
+			return true
+		}
+		return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", minio.ErrorResponse{StatusCode: http.StatusBadGateway}, true},
+		{"slow down", minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable}, true},
+		{"throttled", minio.ErrorResponse{StatusCode: http.StatusTooManyRequests}, true},
+		{"attempt timeout", context.DeadlineExceeded, true},
+		{"no such key", minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}, false},
+		{"access denied", minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden}, false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tc := range cases {
+		if got := isRetryable(tc.err); got != tc.want {
+			t.Errorf("%s: isRetryable=%v want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{requestTimeout: 50 * time.Millisecond, retries: 2}
+
+	// 瞬时错误重试到成功为止。
+	calls := 0
+	err := c.retry(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return minio.ErrorResponse{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success on third attempt, calls=%d err=%v", calls, err)
+	}
+
+	// 对象不存在不重试。
+	calls = 0
+	err = c.retry(ctx, func(context.Context) error {
+		calls++
+		return minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}
+	})
+	if !IsNoSuchKey(err) || calls != 1 {
+		t.Fatalf("expected single attempt for NoSuchKey, calls=%d err=%v", calls, err)
+	}
+
+	// 每次尝试单独计时，超时后按次数上限放弃。
+	calls = 0
+	err = c.retry(ctx, func(ctx context.Context) error {
+		calls++
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 3 {
+		t.Fatalf("expected 3 timed out attempts, calls=%d err=%v", calls, err)
+	}
+
+	// 调用方 ctx 已取消时不再重试。
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	_ = c.retry(canceled, func(context.Context) error {
+		calls++
+		return minio.ErrorResponse{StatusCode: http.StatusInternalServerError}
+	})
+	if calls != 1 {
+		t.Fatalf("expected no retry after caller cancel, calls=%d", calls)
+	}
+}
@@ -0,0 +1,14 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+)
+
+// PDFCancelTTL 为取消标记的保留时间，需覆盖任务在队列中等待与重试的最长窗口。
+const PDFCancelTTL = 24 * time.Hour
+
+// PDFCancelKey 返回简历 PDF 任务取消标记的 Redis key：API 写入，Worker 在启动浏览器前读取。
+func PDFCancelKey(resumeID uint) string {
+	return fmt.Sprintf("pdf:cancelled:%d", resumeID)
+}
@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PDFInflightTTL 为"生成中"标记的兜底过期时间：Worker 异常未清理时，超时后允许重新入队。
+const PDFInflightTTL = 10 * time.Minute
+
+// PDFInflightKey 返回简历"PDF 生成中"标记的 Redis key：API 入队时写入，Worker 任务结束时清理。
+func PDFInflightKey(resumeID uint) string {
+	return fmt.Sprintf("pdf:inflight:%d", resumeID)
+}
+
+// PDFBundleInflightTTL 为"打包中"标记的兜底过期时间：打包逐份渲染，耗时明显长于单份 PDF。
+const PDFBundleInflightTTL = 30 * time.Minute
+
+// PDFBundleInflightKey 返回用户"批量打包中"标记的 Redis key：同一用户同时只允许一个打包任务。
+func PDFBundleInflightKey(userID uint) string {
+	return fmt.Sprintf("pdf:bundle:inflight:%d", userID)
+}
+
+// ResumePreviewInflightTTL 为简历"缩略图生成中"标记的有效期：标记不由 Worker 清理，到期即允许再次入队，
+// 期间对同一内容的重复请求合并到已入队的任务。
+const ResumePreviewInflightTTL = time.Minute
+
+// ResumePreviewInflightKey 返回简历"缩略图生成中"标记的 Redis key。
+func ResumePreviewInflightKey(resumeID uint) string {
+	return fmt.Sprintf("preview:resume:inflight:%d", resumeID)
+}
+
+// PDFInflight 记录某份简历正在进行的 PDF 生成任务，用于合并重复的下载请求。
+// TaskID 在入队前生成并随标记一起写入，任务可能在入队调用返回前就已结束。
+type PDFInflight struct {
+	TaskID        string `json:"task_id"`
+	CorrelationID string `json:"correlation_id"`
+	ContentHash   string `json:"content_hash"`
+}
+
+// releasePDFInflightScript 仅当标记仍属于指定任务时删除，避免误删后续请求写入的新标记。
+var releasePDFInflightScript = redis.NewScript(`
+local v = redis.call('GET', KEYS[1])
+if not v then
+  return 0
+end
+local ok, data = pcall(cjson.decode, v)
+if ok and data.task_id == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// ReleaseInflight 以 compare-and-delete 释放 key 上属于 taskID 的在途标记；API 入队失败与 Worker 任务结束共用。
+func ReleaseInflight(ctx context.Context, client redis.Scripter, key, taskID string) error {
+	if taskID == "" {
+		return nil
+	}
+	return releasePDFInflightScript.Run(ctx, client, []string{key}, taskID).Err()
+}
@@ -2,6 +2,7 @@ package tasks
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/hibiken/asynq"
 )
@@ -9,20 +10,30 @@ import (
 // 任务类型常量，确保队列生产者与消费者一致。
 const (
 	TypePDFGenerate     = "pdf:generate"
+	TypePDFBundle       = "pdf:bundle"
 	TypeTemplatePreview = "template:generate_preview"
+	TypeResumePreview   = "resume:generate_preview"
+	TypeAccountCleanup  = "account:cleanup"
+	TypeMaintenance     = "maintenance:refresh"
 )
 
 // PDFGeneratePayload 描述生成 PDF 所需的最小信息。
 type PDFGeneratePayload struct {
 	ResumeID      uint   `json:"resume_id"`
+	UserID        uint   `json:"user_id,omitempty"` // 入队时的简历所有者，Worker 与内部打印接口据此交叉校验
 	CorrelationID string `json:"correlation_id"`
+	TraceParent   string `json:"traceparent,omitempty"`  // 入队请求的 W3C traceparent，Worker 调用内部接口时继续传播
+	ContentHash   string `json:"content_hash,omitempty"` // 入队时的内容哈希，Worker 据此跳过已过期的任务；为空不校验
 }
 
 // NewPDFGenerateTask 构造一个新的简历 PDF 生成任务。
-func NewPDFGenerateTask(id uint, correlationID string) (*asynq.Task, error) {
+func NewPDFGenerateTask(id, ownerID uint, correlationID, traceParent, contentHash string) (*asynq.Task, error) {
 	payload, err := json.Marshal(PDFGeneratePayload{
 		ResumeID:      id,
+		UserID:        ownerID,
 		CorrelationID: correlationID,
+		TraceParent:   traceParent,
+		ContentHash:   contentHash,
 	})
 	if err != nil {
 		return nil, err
@@ -30,20 +41,111 @@ func NewPDFGenerateTask(id uint, correlationID string) (*asynq.Task, error) {
 	return asynq.NewTask(TypePDFGenerate, payload), nil
 }
 
+// PDFBundlePayload 描述打包用户多份简历 PDF 的任务：Worker 逐份复用或重新渲染 PDF 后打成一个 zip。
+type PDFBundlePayload struct {
+	UserID        uint   `json:"user_id"`
+	ResumeIDs     []uint `json:"resume_ids"`
+	CorrelationID string `json:"correlation_id"`
+	TraceParent   string `json:"traceparent,omitempty"`
+}
+
+// PDFBundlePrefix 返回用户打包 zip 的对象前缀；位于 generated-resumes/{user_id}/ 下，注销账号时一并清理。
+func PDFBundlePrefix(userID uint) string {
+	return fmt.Sprintf("generated-resumes/%d/bundles/", userID)
+}
+
+// NewPDFBundleTask 构造批量 PDF 打包任务。
+func NewPDFBundleTask(payload PDFBundlePayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypePDFBundle, data), nil
+}
+
 // TemplatePreviewPayload 描述模板缩略图生成任务。
 type TemplatePreviewPayload struct {
 	TemplateID    uint   `json:"template_id"`
+	UserID        uint   `json:"user_id,omitempty"`
 	CorrelationID string `json:"correlation_id"`
+	TraceParent   string `json:"traceparent,omitempty"`
 }
 
 // NewTemplatePreviewTask 构造模板预览生成任务。
-func NewTemplatePreviewTask(templateID uint, correlationID string) (*asynq.Task, error) {
+func NewTemplatePreviewTask(templateID, ownerID uint, correlationID, traceParent string) (*asynq.Task, error) {
 	payload, err := json.Marshal(TemplatePreviewPayload{
 		TemplateID:    templateID,
+		UserID:        ownerID,
 		CorrelationID: correlationID,
+		TraceParent:   traceParent,
 	})
 	if err != nil {
 		return nil, err
 	}
 	return asynq.NewTask(TypeTemplatePreview, payload), nil
 }
+
+// ResumePreviewPayload 描述独立的简历缩略图生成任务（不导出 PDF）。
+type ResumePreviewPayload struct {
+	ResumeID      uint   `json:"resume_id"`
+	UserID        uint   `json:"user_id,omitempty"`
+	CorrelationID string `json:"correlation_id"`
+	TraceParent   string `json:"traceparent,omitempty"`
+}
+
+// NewResumePreviewTask 构造简历预览生成任务。
+func NewResumePreviewTask(resumeID, ownerID uint, correlationID, traceParent string) (*asynq.Task, error) {
+	payload, err := json.Marshal(ResumePreviewPayload{
+		ResumeID:      resumeID,
+		UserID:        ownerID,
+		CorrelationID: correlationID,
+		TraceParent:   traceParent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeResumePreview, payload), nil
+}
+
+// AccountCleanupPayload 描述注销账号后需要清理的对象存储范围。
+// 缩略图按简历/模板 ID 存放，因此需在删除数据库记录前记下这些 ID。
+type AccountCleanupPayload struct {
+	UserID        uint   `json:"user_id"`
+	ResumeIDs     []uint `json:"resume_ids,omitempty"`
+	TemplateIDs   []uint `json:"template_ids,omitempty"`
+	AssetKeyDir   string `json:"asset_key_dir,omitempty"` // 不透明资产目录（users.asset_key_dir），未分配时为空
+	CorrelationID string `json:"correlation_id"`
+}
+
+// ObjectPrefixes 返回该账号在对象存储中的全部前缀，需与 API/Worker 的对象命名保持一致。
+func (p AccountCleanupPayload) ObjectPrefixes() []string {
+	prefixes := make([]string, 0, 3+len(p.ResumeIDs)+len(p.TemplateIDs))
+	prefixes = append(prefixes,
+		fmt.Sprintf("user-assets/%d/", p.UserID),
+		fmt.Sprintf("generated-resumes/%d/", p.UserID),
+	)
+	if p.AssetKeyDir != "" {
+		prefixes = append(prefixes, "user-assets/"+p.AssetKeyDir+"/")
+	}
+	for _, id := range p.ResumeIDs {
+		prefixes = append(prefixes, fmt.Sprintf("thumbnails/resume/%d/", id))
+	}
+	for _, id := range p.TemplateIDs {
+		prefixes = append(prefixes, fmt.Sprintf("thumbnails/template/%d/", id))
+	}
+	return prefixes
+}
+
+// NewAccountCleanupTask 构造账号注销后的对象存储兜底清理任务。
+func NewAccountCleanupTask(payload AccountCleanupPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeAccountCleanup, data), nil
+}
+
+// NewMaintenanceTask 构造周期性维护任务（续签即将过期的缩略图链接、清理失效状态），无 payload。
+func NewMaintenanceTask() *asynq.Task {
+	return asynq.NewTask(TypeMaintenance, nil)
+}
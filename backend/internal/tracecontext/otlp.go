@@ -0,0 +1,165 @@
+package tracecontext
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	otlpQueueSize     = 2048
+	otlpBatchSize     = 256
+	otlpFlushInterval = 5 * time.Second
+	otlpTracesPath    = "/v1/traces"
+	otlpScopeName     = "phResume"
+)
+
+// OTLPExporter 将本包的 SpanData 转为 OpenTelemetry SDK 的只读 span，经 SDK BatchSpanProcessor
+// 交给 otlptracehttp 以 OTLP/HTTP 上报，兼容 OpenTelemetry Collector、Jaeger、Tempo 等接收端。
+// 批量、有界队列（满时丢弃）与重试均由 SDK 负责，上报故障不会拖慢任务与请求。
+type OTLPExporter struct {
+	processor sdktrace.SpanProcessor
+	resource  *resource.Resource
+	scope     instrumentation.Scope
+}
+
+// NewOTLPExporter 创建并启动导出器。endpoint 为 Collector 基地址（如 http://otel-collector:4318），
+// 未带 /v1/traces 时自动补全，http 地址按明文上报；serviceName 写入 resource 的 service.name。
+func NewOTLPExporter(endpoint, serviceName string) (*OTLPExporter, error) {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if !strings.HasSuffix(endpoint, otlpTracesPath) {
+		endpoint += otlpTracesPath
+	}
+	client, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+	processor := sdktrace.NewBatchSpanProcessor(client,
+		sdktrace.WithMaxQueueSize(otlpQueueSize),
+		sdktrace.WithMaxExportBatchSize(otlpBatchSize),
+		sdktrace.WithBatchTimeout(otlpFlushInterval),
+	)
+	return &OTLPExporter{
+		processor: processor,
+		resource:  resource.NewSchemaless(attribute.String("service.name", serviceName)),
+		scope:     instrumentation.Scope{Name: otlpScopeName},
+	}, nil
+}
+
+// Setup 按 endpoint 安装全局导出器：endpoint 为空或导出器创建失败时保持 noop，返回的 shutdown 亦为空操作。
+// SDK 内部的上报错误经 otel 全局错误处理器写入 logger。
+func Setup(endpoint, serviceName string, logger *slog.Logger) (shutdown func(context.Context) error) {
+	noop := func(context.Context) error { return nil }
+	if strings.TrimSpace(endpoint) == "" {
+		return noop
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("export spans failed", slog.Any("error", err))
+	}))
+	exporter, err := NewOTLPExporter(endpoint, serviceName)
+	if err != nil {
+		logger.Warn("tracing disabled", slog.Any("error", err))
+		return noop
+	}
+	SetExporter(exporter)
+	return func(ctx context.Context) error {
+		SetExporter(nil)
+		return exporter.Shutdown(ctx)
+	}
+}
+
+// ExportSpan 实现 Exporter；span id 非法时丢弃该 span。
+func (e *OTLPExporter) ExportSpan(span SpanData) {
+	stub, ok := e.stub(span)
+	if !ok {
+		return
+	}
+	e.processor.OnEnd(stub.Snapshot())
+}
+
+// Shutdown 上报队列中剩余的 span 并停止后台协程；ctx 到期时放弃等待。
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return e.processor.Shutdown(ctx)
+}
+
+func (e *OTLPExporter) stub(span SpanData) (tracetest.SpanStub, bool) {
+	traceID, err := trace.TraceIDFromHex(span.TraceID)
+	if err != nil {
+		return tracetest.SpanStub{}, false
+	}
+	spanID, err := trace.SpanIDFromHex(span.SpanID)
+	if err != nil {
+		return tracetest.SpanStub{}, false
+	}
+	stub := tracetest.SpanStub{
+		Name: span.Name,
+		SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+		SpanKind:             otelSpanKind(span.Kind),
+		StartTime:            span.Start,
+		EndTime:              span.End,
+		Attributes:           otelAttributes(span.Attributes),
+		Resource:             e.resource,
+		InstrumentationScope: e.scope,
+	}
+	if parentID, err := trace.SpanIDFromHex(span.ParentSpanID); err == nil {
+		stub.Parent = trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     parentID,
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		})
+	}
+	if span.Error != "" {
+		stub.Status = sdktrace.Status{Code: codes.Error, Description: span.Error}
+	}
+	return stub, true
+}
+
+func otelSpanKind(kind SpanKind) trace.SpanKind {
+	switch kind {
+	case SpanKindServer:
+		return trace.SpanKindServer
+	case SpanKindClient:
+		return trace.SpanKindClient
+	case SpanKindConsumer:
+		return trace.SpanKindConsumer
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+func otelAttributes(attrs map[string]string) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, attribute.String(key, attrs[key]))
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package tracecontext
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPExporter_FlushesOnShutdown(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		path string
+		body coltracepb.ExportTraceServiceRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path = r.URL.Path
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read otlp body: %v", err)
+		}
+		if err := proto.Unmarshal(raw, &body); err != nil {
+			t.Errorf("decode otlp body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	exporter, err := NewOTLPExporter(srv.URL+"/", "phresume-worker")
+	if err != nil {
+		t.Fatalf("new exporter: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	exporter.ExportSpan(SpanData{
+		Name:         "worker.render_page",
+		Kind:         SpanKindInternal,
+		TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:       "00f067aa0ba902b7",
+		ParentSpanID: "b7ad6b7169203331",
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   map[string]string{"correlation_id": "corr-1"},
+		Error:        "render failed",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := exporter.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/v1/traces" {
+		t.Fatalf("expected POST to /v1/traces, got %q", path)
+	}
+	if len(body.ResourceSpans) != 1 || len(body.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected otlp body: %+v", &body)
+	}
+	if attrs := body.ResourceSpans[0].Resource.Attributes; len(attrs) != 1 || attrs[0].Value.GetStringValue() != "phresume-worker" {
+		t.Fatalf("expected service.name resource attribute, got %+v", attrs)
+	}
+	spans := body.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if hex.EncodeToString(span.TraceId) != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		hex.EncodeToString(span.ParentSpanId) != "b7ad6b7169203331" ||
+		span.StartTimeUnixNano != 1700000000000000000 ||
+		span.Kind != tracepb.Span_SPAN_KIND_INTERNAL ||
+		span.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Fatalf("unexpected encoded span: %+v", span)
+	}
+}
+
+func TestSetup_NoopWithoutEndpoint(t *testing.T) {
+	shutdown := Setup("", "phresume-api", nil)
+	if currentExporter() != nil {
+		t.Fatalf("expected no exporter without endpoint")
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("noop shutdown: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package tracecontext
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind 对应 OTLP 的 span kind。
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+	SpanKindConsumer SpanKind = 5
+)
+
+// SpanData 为结束后的 span 快照，交给 Exporter 导出。
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+	Error        string
+}
+
+// Exporter 接收已结束的 span；实现需自行处理批量与失败，不得阻塞调用方。
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+type exporterHolder struct{ exporter Exporter }
+
+var globalExporter atomic.Pointer[exporterHolder]
+
+// SetExporter 设置进程级 span 导出器；传 nil 时恢复为 noop（span 仍生成 id 以便传播 traceparent）。
+func SetExporter(e Exporter) {
+	if e == nil {
+		globalExporter.Store(nil)
+		return
+	}
+	globalExporter.Store(&exporterHolder{exporter: e})
+}
+
+func currentExporter() Exporter {
+	if holder := globalExporter.Load(); holder != nil {
+		return holder.exporter
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+// ContextWith 将 traceparent 写入 ctx，后续 StartSpan 以其为父 span。
+func ContextWith(ctx context.Context, tp TraceParent) context.Context {
+	return context.WithValue(ctx, contextKey{}, tp)
+}
+
+// ContextWithRemote 在 value 为合法 traceparent 时将其作为远端父 span 写入 ctx，否则原样返回。
+func ContextWithRemote(ctx context.Context, value string) context.Context {
+	if tp, ok := Parse(value); ok {
+		return ContextWith(ctx, tp)
+	}
+	return ctx
+}
+
+// FromContext 取出 ctx 中当前 span 的 traceparent。
+func FromContext(ctx context.Context) (TraceParent, bool) {
+	tp, ok := ctx.Value(contextKey{}).(TraceParent)
+	return tp, ok
+}
+
+// Span 表示一次进行中的操作；End 之前可附加属性与错误，End 可重复调用，仅首次生效。
+type Span struct {
+	name     string
+	kind     SpanKind
+	self     TraceParent
+	parentID string
+	start    time.Time
+
+	mu    sync.Mutex
+	attrs map[string]string
+	err   string
+	ended bool
+}
+
+// StartSpan 以 ctx 中的 span 为父开启子 span（没有父 span 时新建 trace），并返回携带该 span 的 ctx。
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{name: name, kind: kind, start: time.Now()}
+	if parent, ok := FromContext(ctx); ok {
+		span.self = parent.Child()
+		span.parentID = parent.ParentID
+	} else {
+		span.self = New()
+	}
+	return ContextWith(ctx, span.self), span
+}
+
+// TraceParent 返回本 span 的 traceparent，用于向下游传播。
+func (s *Span) TraceParent() TraceParent {
+	return s.self
+}
+
+// SetName 修改 span 名称（如请求结束后才能确定路由模板）。
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttribute 附加字符串属性。
+func (s *Span) SetAttribute(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attrs == nil {
+		s.attrs = make(map[string]string)
+	}
+	s.attrs[key] = value
+}
+
+// RecordError 将 span 标记为失败；err 为 nil 时忽略。
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+// End 结束 span 并交给当前导出器；未配置导出器时为 noop。
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:         s.name,
+		Kind:         s.kind,
+		TraceID:      s.self.TraceID,
+		SpanID:       s.self.ParentID,
+		ParentSpanID: s.parentID,
+		Start:        s.start,
+		End:          time.Now(),
+		Attributes:   s.attrs,
+		Error:        s.err,
+	}
+	s.mu.Unlock()
+
+	if exporter := currentExporter(); exporter != nil {
+		exporter.ExportSpan(data)
+	}
+}
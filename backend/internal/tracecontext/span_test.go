@@ -0,0 +1,61 @@
+package tracecontext
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (r *recordingExporter) ExportSpan(span SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func TestStartSpan_LinksChildToRemoteParent(t *testing.T) {
+	rec := &recordingExporter{}
+	SetExporter(rec)
+	defer SetExporter(nil)
+
+	ctx := ContextWithRemote(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, task := StartSpan(ctx, "pdf:generate", SpanKindConsumer)
+	_, fetch := StartSpan(ctx, "worker.fetch_print_data", SpanKindClient)
+	fetch.RecordError(errors.New("boom"))
+	fetch.End()
+	task.End()
+	task.End()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("expected 2 exported spans (End is idempotent), got %d", len(rec.spans))
+	}
+	fetchData, taskData := rec.spans[0], rec.spans[1]
+	if taskData.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || taskData.ParentSpanID != "00f067aa0ba902b7" {
+		t.Fatalf("expected task span under remote parent, got %+v", taskData)
+	}
+	if fetchData.TraceID != taskData.TraceID || fetchData.ParentSpanID != taskData.SpanID {
+		t.Fatalf("expected fetch span to be a child of the task span, got %+v", fetchData)
+	}
+	if fetchData.Error != "boom" || taskData.Error != "" {
+		t.Fatalf("expected error recorded only on fetch span")
+	}
+}
+
+func TestStartSpan_WithoutParentStartsNewTraceAndNoopExporter(t *testing.T) {
+	SetExporter(nil)
+	ctx, span := StartSpan(context.Background(), "root", SpanKindInternal)
+	span.End()
+
+	tp, ok := FromContext(ctx)
+	if !ok || tp != span.TraceParent() {
+		t.Fatalf("expected ctx to carry the span traceparent")
+	}
+	if _, ok := Parse(tp.String()); !ok {
+		t.Fatalf("expected valid traceparent, got %q", tp.String())
+	}
+}
@@ -0,0 +1,101 @@
+// Package tracecontext 实现 W3C Trace Context 的 traceparent 头解析与生成，
+// 用于在 API、任务队列与 Worker 之间传播 trace id，与基于 OpenTelemetry 的链路系统互通，而无需引入完整 SDK。
+package tracecontext
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// Header 为 traceparent 的 HTTP 头名。
+const Header = "traceparent"
+
+const (
+	traceIDLen  = 32
+	parentIDLen = 16
+	// flagSampled 为本地新建 trace 时使用的 trace-flags，交由下游采样器决定是否丢弃。
+	flagSampled = "01"
+)
+
+// TraceParent 为解析后的 traceparent（version 00）：同一 trace 内的 TraceID 不变，每一跳生成新的 ParentID。
+type TraceParent struct {
+	TraceID  string
+	ParentID string
+	Flags    string
+}
+
+// Parse 按 W3C 规范解析 traceparent；格式非法、全零 id 或 version 为 ff 时返回 false。
+// 高于 00 的版本只取前四段，以兼容未来扩展字段。
+func Parse(value string) (TraceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceParent{}, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return TraceParent{}, false
+	}
+	if !isLowerHex(traceID, traceIDLen) || isAllZero(traceID) {
+		return TraceParent{}, false
+	}
+	if !isLowerHex(parentID, parentIDLen) || isAllZero(parentID) {
+		return TraceParent{}, false
+	}
+	if !isLowerHex(flags, 2) {
+		return TraceParent{}, false
+	}
+	return TraceParent{TraceID: traceID, ParentID: parentID, Flags: flags}, true
+}
+
+// New 生成新的根 traceparent。
+func New() TraceParent {
+	return TraceParent{TraceID: randomHex(traceIDLen / 2), ParentID: randomHex(parentIDLen / 2), Flags: flagSampled}
+}
+
+// Continue 以 value 为上游继续 trace：合法时沿用其 trace id 并生成新的 parent id，否则新建根 trace。
+func Continue(value string) TraceParent {
+	if parent, ok := Parse(value); ok {
+		return parent.Child()
+	}
+	return New()
+}
+
+// Child 返回同一 trace 下的下一跳（新 parent id，保留 trace-flags）。
+func (t TraceParent) Child() TraceParent {
+	return TraceParent{TraceID: t.TraceID, ParentID: randomHex(parentIDLen / 2), Flags: t.Flags}
+}
+
+// String 按 version 00 格式输出 traceparent 头的值。
+func (t TraceParent) String() string {
+	return "00-" + t.TraceID + "-" + t.ParentID + "-" + t.Flags
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	for {
+		// crypto/rand 读取失败时极罕见，重试即可；全零 id 在规范中无效，需重新生成。
+		if _, err := rand.Read(buf); err == nil {
+			if s := hex.EncodeToString(buf); !isAllZero(s) {
+				return s
+			}
+		}
+	}
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
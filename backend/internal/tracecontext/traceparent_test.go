@@ -0,0 +1,45 @@
+package tracecontext
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		ok    bool
+	}{
+		{name: "spec example", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true},
+		{name: "future version with extra field", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ok: true},
+		{name: "version 00 with extra field", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ok: false},
+		{name: "invalid version ff", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: false},
+		{name: "all zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ok: false},
+		{name: "all zero parent id", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", ok: false},
+		{name: "uppercase hex", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ok: false},
+		{name: "short trace id", value: "00-4bf92f35-00f067aa0ba902b7-01", ok: false},
+		{name: "empty", value: "", ok: false},
+	}
+	for _, tc := range cases {
+		if _, ok := Parse(tc.value); ok != tc.ok {
+			t.Fatalf("%s: expected ok=%v", tc.name, tc.ok)
+		}
+	}
+}
+
+func TestContinue_KeepsTraceIDAndRotatesParent(t *testing.T) {
+	upstream := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	next := Continue(upstream)
+	if next.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || next.Flags != "00" {
+		t.Fatalf("expected trace id and flags to be preserved, got %+v", next)
+	}
+	if next.ParentID == "00f067aa0ba902b7" {
+		t.Fatalf("expected a new parent id")
+	}
+	if _, ok := Parse(next.String()); !ok {
+		t.Fatalf("continued traceparent %q must be valid", next.String())
+	}
+
+	fresh := Continue("garbage")
+	if _, ok := Parse(fresh.String()); !ok || fresh.TraceID == next.TraceID {
+		t.Fatalf("expected a new valid root trace, got %q", fresh.String())
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+
+	"phResume/internal/storage"
+	"phResume/internal/tasks"
+)
+
+type prefixDeleter interface {
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// AccountCleanupHandler 负责账号注销后的对象存储兜底清理。
+// API 注销时已尽力删除一次，本任务重复删除同一批前缀（幂等），失败返回 error 交给 asynq 重试。
+type AccountCleanupHandler struct {
+	storage prefixDeleter
+	logger  *slog.Logger
+}
+
+func NewAccountCleanupHandler(storageClient *storage.Client, logger *slog.Logger) *AccountCleanupHandler {
+	return &AccountCleanupHandler{
+		storage: storageClient,
+		logger:  logger,
+	}
+}
+
+func (h *AccountCleanupHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload tasks.AccountCleanupPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		h.logger.Error("unmarshal account cleanup payload failed", slog.Any("error", err))
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+
+	log := h.logger.With(
+		slog.Uint64("user_id", uint64(payload.UserID)),
+		slog.String("correlation_id", payload.CorrelationID),
+	)
+	if payload.UserID == 0 {
+		log.Warn("account cleanup payload missing user id, skipping task")
+		return nil
+	}
+
+	var errs []error
+	for _, prefix := range payload.ObjectPrefixes() {
+		if err := h.storage.DeletePrefix(ctx, prefix); err != nil {
+			log.Warn("account cleanup delete prefix failed", slog.String("prefix", prefix), slog.Any("error", err))
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	log.Info("account object cleanup completed")
+	return nil
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"phResume/internal/tasks"
+)
+
+type recordingPrefixDeleter struct {
+	prefixes []string
+	failOn   string
+}
+
+func (d *recordingPrefixDeleter) DeletePrefix(_ context.Context, prefix string) error {
+	d.prefixes = append(d.prefixes, prefix)
+	if prefix == d.failOn {
+		return errors.New("minio unavailable")
+	}
+	return nil
+}
+
+func TestAccountCleanupHandler_DeletesAllPrefixes(t *testing.T) {
+	deleter := &recordingPrefixDeleter{failOn: "thumbnails/resume/7/"}
+	h := &AccountCleanupHandler{storage: deleter, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	task, err := tasks.NewAccountCleanupTask(tasks.AccountCleanupPayload{
+		UserID:      3,
+		ResumeIDs:   []uint{7},
+		TemplateIDs: []uint{11},
+	})
+	if err != nil {
+		t.Fatalf("new task: %v", err)
+	}
+
+	// 单个前缀失败不影响其余前缀的清理，但整体返回 error 以触发重试。
+	if err := h.ProcessTask(context.Background(), task); err == nil {
+		t.Fatal("expected error when a prefix fails")
+	}
+
+	want := []string{"user-assets/3/", "generated-resumes/3/", "thumbnails/resume/7/", "thumbnails/template/11/"}
+	if len(deleter.prefixes) != len(want) {
+		t.Fatalf("expected prefixes %v got %v", want, deleter.prefixes)
+	}
+	for i, prefix := range want {
+		if deleter.prefixes[i] != prefix {
+			t.Fatalf("expected prefixes %v got %v", want, deleter.prefixes)
+		}
+	}
+}
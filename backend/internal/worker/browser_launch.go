@@ -0,0 +1,167 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+)
+
+// browserLaunchPolicy 控制 Chromium 的启动方式与启动/连接失败时的进程内重试：
+// 偶发的 GPU、/dev/shm 等启动抖动在任务内消化，避免白白消耗一次 asynq 重试。
+// Bin 为空时按 launcher.LookPath 查找；Flags 追加在内置参数之后，同名时覆盖内置值。
+type browserLaunchPolicy struct {
+	Attempts int
+	Backoff  time.Duration
+	Bin      string
+	Flags    []string
+}
+
+func newBrowserLaunchPolicy(attempts int, backoff time.Duration, bin string, extraFlags []string) browserLaunchPolicy {
+	if attempts <= 0 {
+		attempts = 1
+	}
+	if backoff < 0 {
+		backoff = 0
+	}
+	return browserLaunchPolicy{
+		Attempts: attempts,
+		Backoff:  backoff,
+		Bin:      strings.TrimSpace(bin),
+		Flags:    extraFlags,
+	}
+}
+
+// CheckBrowserBin 校验配置的 Chromium 路径存在且可执行；path 为空时不做检查（运行时自动查找）。
+// 仅 Worker 启动时调用，避免把渲染环境的问题推迟到第一个任务才暴露。
+func CheckBrowserBin(path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat browser binary: %w", err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("browser binary %s is not an executable file", path)
+	}
+	return nil
+}
+
+// isPersistentLaunchError 判断启动失败是否为重试也无法恢复的环境问题（如找不到可执行文件、无执行权限）。
+func isPersistentLaunchError(err error) bool {
+	return errors.Is(err, exec.ErrNotFound) ||
+		errors.Is(err, fs.ErrNotExist) ||
+		errors.Is(err, fs.ErrPermission)
+}
+
+func newChromiumLauncher(policy browserLaunchPolicy) *launcher.Launcher {
+	launch := launcher.New().
+		// 关闭 Leakless：生产环境使用 tmpfs(/tmp) 时通常是 noexec，
+		// Leakless 需要在 /tmp 解压并 exec 自身，会触发 permission denied。
+		Leakless(false).
+		Headless(true).
+		NoSandbox(true).
+		// 云端常见：GPU/Vulkan 初始化失败会导致 GPU 进程反复崩溃，进而让 CDP 调用卡死/超时。
+		Set("disable-gpu").
+		Set("disable-vulkan").
+		Set("use-gl", "swiftshader").
+		// 容器内常见问题：/dev/shm 太小会导致 Chromium 卡死/崩溃
+		Set("disable-dev-shm-usage").
+		Set("no-zygote").
+		// 强制把用户数据与缓存写到 /tmp（配合只读根文件系统与 tmpfs）
+		Set("user-data-dir", "/tmp/chromium").
+		Set("disk-cache-dir", "/tmp/chromium-cache")
+
+	// 额外参数形如 --proxy-server=http://proxy:3128 或 --disable-features=A,B（前导 -- 可省略）。
+	for _, raw := range policy.Flags {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(strings.TrimSpace(raw), "-"), "=")
+		if name == "" {
+			continue
+		}
+		if hasValue {
+			launch = launch.Set(flags.Flag(name), value)
+		} else {
+			launch = launch.Set(flags.Flag(name))
+		}
+	}
+
+	if policy.Bin != "" {
+		launch = launch.Bin(policy.Bin)
+	} else if path, ok := launcher.LookPath(); ok {
+		launch = launch.Bin(path)
+	}
+	return launch
+}
+
+// launchBrowser 按策略启动并连接 Chromium；单次失败会强制回收该次启动的进程后再重试。
+func launchBrowser(logger *slog.Logger, policy browserLaunchPolicy) (*launcher.Launcher, *rod.Browser, error) {
+	policy = newBrowserLaunchPolicy(policy.Attempts, policy.Backoff, policy.Bin, policy.Flags)
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		launch, browser, err := launchBrowserOnce(policy)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Worker: Browser launched after retry", slog.Int("attempt", attempt))
+			}
+			return launch, browser, nil
+		}
+		lastErr = err
+
+		persistent := isPersistentLaunchError(err)
+		logger.Warn("Worker: Browser launch attempt failed",
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", policy.Attempts),
+			slog.Bool("persistent", persistent),
+			slog.Any("error", err),
+		)
+		if persistent {
+			return nil, nil, err
+		}
+		if attempt < policy.Attempts && policy.Backoff > 0 {
+			time.Sleep(time.Duration(attempt) * policy.Backoff)
+		}
+	}
+	return nil, nil, fmt.Errorf("browser launch failed after %d attempts: %w", policy.Attempts, lastErr)
+}
+
+func launchBrowserOnce(policy browserLaunchPolicy) (*launcher.Launcher, *rod.Browser, error) {
+	launch := newChromiumLauncher(policy)
+
+	browserURL, err := launch.Launch()
+	if err != nil {
+		killLauncher(launch)
+		return nil, nil, fmt.Errorf("launch chromium: %w", err)
+	}
+
+	browser := rod.New().ControlURL(browserURL).Timeout(30 * time.Second)
+	if err := browser.Connect(); err != nil {
+		killLauncher(launch)
+		return nil, nil, fmt.Errorf("connect browser: %w", err)
+	}
+	return launch, browser.CancelTimeout(), nil
+}
+
+// killLauncher 强制结束一次失败启动遗留的 Chromium 进程并清理其用户数据目录。
+func killLauncher(launch *launcher.Launcher) {
+	launch.Kill()
+	done := make(chan struct{})
+	go func() {
+		launch.Cleanup()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+	}
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher/flags"
+)
+
+func TestNewBrowserLaunchPolicy_Normalizes(t *testing.T) {
+	policy := newBrowserLaunchPolicy(0, -time.Second, "", nil)
+	if policy.Attempts != 1 || policy.Backoff != 0 {
+		t.Fatalf("policy = %+v, want 1 attempt without backoff", policy)
+	}
+
+	policy = newBrowserLaunchPolicy(3, 500*time.Millisecond, " /opt/chromium/chrome ", nil)
+	if policy.Attempts != 3 || policy.Backoff != 500*time.Millisecond || policy.Bin != "/opt/chromium/chrome" {
+		t.Fatalf("policy = %+v, want configured values kept", policy)
+	}
+}
+
+func TestNewChromiumLauncher_AppliesBinAndExtraFlags(t *testing.T) {
+	policy := newBrowserLaunchPolicy(1, 0, "/opt/chromium/chrome", []string{
+		"--proxy-server=http://proxy:3128",
+		"disable-features=Translate,MediaRouter",
+		"--use-gl=egl",
+		"--mute-audio",
+	})
+	launch := newChromiumLauncher(policy)
+
+	if got := launch.Get(flags.Bin); got != "/opt/chromium/chrome" {
+		t.Fatalf("bin = %q, want configured path", got)
+	}
+	if got := launch.Get("proxy-server"); got != "http://proxy:3128" {
+		t.Fatalf("proxy-server = %q", got)
+	}
+	if got := launch.Get("disable-features"); got != "Translate,MediaRouter" {
+		t.Fatalf("disable-features = %q, want comma-separated value kept", got)
+	}
+	if got := launch.Get("use-gl"); got != "egl" {
+		t.Fatalf("use-gl = %q, want extra flag to override built-in value", got)
+	}
+	if !launch.Has("mute-audio") {
+		t.Fatal("expected valueless flag to be set")
+	}
+}
+
+func TestCheckBrowserBin(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "chrome")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write exe: %v", err)
+	}
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write plain: %v", err)
+	}
+
+	if err := CheckBrowserBin(""); err != nil {
+		t.Fatalf("empty path: %v", err)
+	}
+	if err := CheckBrowserBin(exe); err != nil {
+		t.Fatalf("executable: %v", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing"), plain, dir} {
+		if err := CheckBrowserBin(path); err == nil {
+			t.Fatalf("CheckBrowserBin(%q) = nil, want error", path)
+		}
+	}
+}
+
+func TestIsPersistentLaunchError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "binary missing", err: fmt.Errorf("launch chromium: %w", exec.ErrNotFound), want: true},
+		{name: "path missing", err: fmt.Errorf("launch chromium: %w", fs.ErrNotExist), want: true},
+		{name: "permission denied", err: fmt.Errorf("launch chromium: %w", fs.ErrPermission), want: true},
+		{name: "transient", err: errors.New("connect browser: context deadline exceeded"), want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isPersistentLaunchError(tc.err); got != tc.want {
+				t.Fatalf("isPersistentLaunchError() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/tasks"
+)
+
+// errPDFTaskCancelled 表示简历已被删除/取消，任务不再渲染且不重试。
+var errPDFTaskCancelled = errors.New("pdf task cancelled")
+
+type cancelFlagReader interface {
+	Exists(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// isPDFTaskCancelled 检查 API 写入的取消标记（见 tasks.PDFCancelKey）。
+func isPDFTaskCancelled(ctx context.Context, client cancelFlagReader, resumeID uint) (bool, error) {
+	if client == nil {
+		return false, nil
+	}
+	n, err := client.Exists(ctx, tasks.PDFCancelKey(resumeID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// cancelledTaskError 包装 asynq.SkipRetry，使任务直接归档而不是进入重试。
+func cancelledTaskError() error {
+	return errors.Join(errPDFTaskCancelled, asynq.SkipRetry)
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/tasks"
+)
+
+type fakeCancelFlags map[string]bool
+
+func (f fakeCancelFlags) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	var n int64
+	for _, k := range keys {
+		if f[k] {
+			n++
+		}
+	}
+	cmd.SetVal(n)
+	return cmd
+}
+
+func TestIsPDFTaskCancelled(t *testing.T) {
+	ctx := context.Background()
+	flags := fakeCancelFlags{tasks.PDFCancelKey(7): true}
+
+	cancelled, err := isPDFTaskCancelled(ctx, flags, 7)
+	if err != nil || !cancelled {
+		t.Fatalf("expected resume 7 cancelled, got %v err=%v", cancelled, err)
+	}
+	cancelled, err = isPDFTaskCancelled(ctx, flags, 8)
+	if err != nil || cancelled {
+		t.Fatalf("expected resume 8 not cancelled, got %v err=%v", cancelled, err)
+	}
+}
+
+func TestCancelledTaskError_SkipsRetry(t *testing.T) {
+	err := cancelledTaskError()
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Fatal("cancelled error must wrap asynq.SkipRetry")
+	}
+	if !errors.Is(err, errPDFTaskCancelled) {
+		t.Fatal("cancelled error must be identifiable as errPDFTaskCancelled")
+	}
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"phResume/internal/auth"
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+)
+
+// HTMLToPDFPath 为内部 HTML 转 PDF 接口的路径，由独立监听端口（WORKER_HTML_TO_PDF_ADDR）提供。
+const HTMLToPDFPath = "/v1/internal/html-to-pdf"
+
+const (
+	// maxHTMLToPDFMarginMM 为页边距上限（毫米），超过后 A4/Letter 都已无可用版心。
+	maxHTMLToPDFMarginMM = 50
+	// htmlToPDFRenderTimeout 为单次渲染（含等待渲染名额）的上限，写超时在此基础上留出余量。
+	htmlToPDFRenderTimeout = 2 * time.Minute
+)
+
+// htmlToPDFRequest 为内部 HTML 转 PDF 接口的请求体。
+type htmlToPDFRequest struct {
+	HTML     string  `json:"html"`
+	PageSize string  `json:"page_size"` // A4 / Letter，空值按 A4
+	MarginMM float64 `json:"margin_mm"` // 四边页边距（毫米），默认 0
+}
+
+// HTMLToPDFHandler 将调用方提供的 HTML 直接渲染为 PDF，用于验证渲染栈或渲染服务端拼装的 HTML，不依赖前端打印页。
+// 仅供内部调用（X-Internal-Secret）；渲染时拦截除 data: 以外的全部网络请求，HTML 需自行内联图片与字体。
+type HTMLToPDFHandler struct {
+	logger         *slog.Logger
+	internalSecret string
+	maxBytes       int64
+	renderSlots    *RenderSemaphore
+	render         func(ctx context.Context, html []byte, layout printLayout) ([]byte, error)
+}
+
+// NewHTMLToPDFHandler 创建内部 HTML 转 PDF 接口；opts.HTMLToPDFMaxBytes 为请求体上限。
+func NewHTMLToPDFHandler(logger *slog.Logger, opts RenderOptions) *HTMLToPDFHandler {
+	launch := opts.browserLaunch()
+	return &HTMLToPDFHandler{
+		logger:         logger,
+		internalSecret: strings.TrimSpace(opts.InternalSecret),
+		maxBytes:       int64(opts.HTMLToPDFMaxBytes),
+		renderSlots:    opts.RenderSemaphore,
+		render: func(ctx context.Context, html []byte, layout printLayout) ([]byte, error) {
+			page, cleanup, err := renderHTMLPage(ctx, logger, html, launch, true)
+			if err != nil {
+				return nil, err
+			}
+			defer cleanup()
+			return exportPDF(page, layout)
+		},
+	}
+}
+
+// NewHTMLToPDFServer 返回只挂载 HTMLToPDFPath 的 HTTP 服务，与指标端口分开监听并设置读写/空闲超时。
+func NewHTMLToPDFServer(addr string, handler *HTMLToPDFHandler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(HTMLToPDFPath, handler)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      htmlToPDFRenderTimeout + 30*time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+}
+
+// ServeHTTP 实现 http.Handler：校验密钥与请求体后占用一个渲染名额，成功时直接返回 PDF 字节。
+// 渲染绑定请求 context，调用方断开或超过 htmlToPDFRenderTimeout 时中止并释放浏览器。
+func (h *HTMLToPDFHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeHTMLToPDFError(w, http.StatusMethodNotAllowed, errcode.MethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.internalSecret == "" {
+		writeHTMLToPDFError(w, http.StatusInternalServerError, errcode.SystemError, "internal api secret is not configured")
+		return
+	}
+	if !auth.SecretEqual(strings.TrimSpace(r.Header.Get("X-Internal-Secret")), h.internalSecret) {
+		writeHTMLToPDFError(w, http.StatusUnauthorized, errcode.Unauthorized, "unauthorized")
+		return
+	}
+
+	var req htmlToPDFRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeHTMLToPDFError(w, http.StatusRequestEntityTooLarge, errcode.PayloadTooLarge, fmt.Sprintf("request body exceeds %d bytes", h.maxBytes))
+			return
+		}
+		writeHTMLToPDFError(w, http.StatusBadRequest, errcode.InvalidRequest, "invalid request body")
+		return
+	}
+	layout, err := req.layout()
+	if err != nil {
+		writeHTMLToPDFError(w, http.StatusBadRequest, errcode.InvalidRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), htmlToPDFRenderTimeout)
+	defer cancel()
+
+	release, err := h.renderSlots.acquire(ctx)
+	if err != nil {
+		writeHTMLToPDFError(w, http.StatusServiceUnavailable, errcode.SystemError, "render slot unavailable")
+		return
+	}
+	defer release()
+
+	started := time.Now()
+	data, err := h.render(ctx, []byte(req.HTML), layout)
+	if err != nil {
+		h.logger.Error("html to pdf render failed", slog.Any("error", err))
+		writeHTMLToPDFError(w, http.StatusInternalServerError, errcode.SystemError, "render pdf failed")
+		return
+	}
+	h.logger.Info("html to pdf rendered",
+		slog.Int("html_bytes", len(req.HTML)),
+		slog.Int("pdf_bytes", len(data)),
+		slog.Duration("duration", time.Since(started)),
+	)
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// layout 校验渲染选项并转换为 exportPDF 使用的版式参数。
+func (req htmlToPDFRequest) layout() (printLayout, error) {
+	if strings.TrimSpace(req.HTML) == "" {
+		return printLayout{}, errors.New("html is required")
+	}
+	switch strings.ToLower(strings.TrimSpace(req.PageSize)) {
+	case "", "a4", "letter":
+	default:
+		return printLayout{}, fmt.Errorf("page_size must be one of: %s,%s", resumepkg.PageSizeA4, resumepkg.PageSizeLetter)
+	}
+	if req.MarginMM < 0 || req.MarginMM > maxHTMLToPDFMarginMM {
+		return printLayout{}, fmt.Errorf("margin_mm must be between 0 and %d", maxHTMLToPDFMarginMM)
+	}
+	return printLayout{
+		PageSize:     resumepkg.NormalizePageSize(req.PageSize),
+		MarginInches: req.MarginMM / 25.4,
+	}, nil
+}
+
+func writeHTMLToPDFError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]errcode.Detail{"error": {Code: code, Message: message}})
+}
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+)
+
+func TestHTMLToPDFHandler(t *testing.T) {
+	var (
+		gotHTML   string
+		gotLayout printLayout
+		gotCtx    context.Context
+		renderErr error
+	)
+	h := &HTMLToPDFHandler{
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		internalSecret: "s3cret",
+		maxBytes:       256,
+		render: func(ctx context.Context, html []byte, layout printLayout) ([]byte, error) {
+			gotHTML, gotLayout, gotCtx = string(html), layout, ctx
+			if renderErr != nil {
+				return nil, renderErr
+			}
+			return []byte("%PDF-1.7"), nil
+		},
+	}
+
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	defer cancelReq()
+	send := func(method, secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, HTMLToPDFPath, strings.NewReader(body)).WithContext(reqCtx)
+		if secret != "" {
+			req.Header.Set("X-Internal-Secret", secret)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	errorCode := func(w *httptest.ResponseRecorder) int {
+		var body struct {
+			Error errcode.Detail `json:"error"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return body.Error.Code
+	}
+
+	w := send(http.MethodPost, "s3cret", `{"html":"<p>hi</p>","page_size":"letter","margin_mm":25.4}`)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" || w.Body.String() != "%PDF-1.7" {
+		t.Fatalf("expected pdf bytes, got %d %q headers=%v", w.Code, w.Body.String(), w.Header())
+	}
+	if gotHTML != "<p>hi</p>" || gotLayout.PageSize != resumepkg.PageSizeLetter || gotLayout.MarginInches != 1 {
+		t.Fatalf("unexpected render input html=%q layout=%+v", gotHTML, gotLayout)
+	}
+	// 渲染 context 派生自请求 context：带渲染超时，调用方断开时随之取消。
+	if _, ok := gotCtx.Deadline(); !ok {
+		t.Fatalf("expected render context to carry a deadline")
+	}
+	cancelReq()
+	if gotCtx.Err() == nil {
+		t.Fatalf("expected render context to be cancelled with the request")
+	}
+	reqCtx = context.Background()
+
+	cases := []struct {
+		name   string
+		method string
+		secret string
+		body   string
+		status int
+		code   int
+	}{
+		{"get", http.MethodGet, "s3cret", "", http.StatusMethodNotAllowed, errcode.MethodNotAllowed},
+		{"missing secret", http.MethodPost, "", `{"html":"x"}`, http.StatusUnauthorized, errcode.Unauthorized},
+		{"wrong secret", http.MethodPost, "nope", `{"html":"x"}`, http.StatusUnauthorized, errcode.Unauthorized},
+		{"too large", http.MethodPost, "s3cret", `{"html":"` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge, errcode.PayloadTooLarge},
+		{"bad json", http.MethodPost, "s3cret", `{"html":`, http.StatusBadRequest, errcode.InvalidRequest},
+		{"empty html", http.MethodPost, "s3cret", `{"html":"  "}`, http.StatusBadRequest, errcode.InvalidRequest},
+		{"bad page size", http.MethodPost, "s3cret", `{"html":"x","page_size":"A3"}`, http.StatusBadRequest, errcode.InvalidRequest},
+		{"bad margin", http.MethodPost, "s3cret", `{"html":"x","margin_mm":80}`, http.StatusBadRequest, errcode.InvalidRequest},
+	}
+	for _, tc := range cases {
+		gotHTML = ""
+		w := send(tc.method, tc.secret, tc.body)
+		if w.Code != tc.status || errorCode(w) != tc.code {
+			t.Fatalf("%s: expected %d/%d, got %d %s", tc.name, tc.status, tc.code, w.Code, w.Body.String())
+		}
+		if gotHTML != "" {
+			t.Fatalf("%s: rejected request must not render", tc.name)
+		}
+	}
+
+	renderErr = errors.New("chromium crashed")
+	if w := send(http.MethodPost, "s3cret", `{"html":"x"}`); w.Code != http.StatusInternalServerError || errorCode(w) != errcode.SystemError {
+		t.Fatalf("expected 500 on render failure, got %d %s", w.Code, w.Body.String())
+	}
+
+	h.internalSecret = ""
+	if w := send(http.MethodPost, "", `{"html":"x"}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 without configured secret, got %d", w.Code)
+	}
+}
+
+func TestNewHTMLToPDFServer(t *testing.T) {
+	srv := NewHTMLToPDFServer(":0", &HTMLToPDFHandler{logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if srv.ReadHeaderTimeout <= 0 || srv.ReadTimeout <= 0 || srv.IdleTimeout <= 0 || srv.WriteTimeout <= htmlToPDFRenderTimeout {
+		t.Fatalf("expected read/write/idle timeouts, got %+v", srv)
+	}
+
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected only %s to be served, got %d for /metrics", HTMLToPDFPath, w.Code)
+	}
+}
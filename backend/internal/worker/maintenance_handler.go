@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	"phResume/internal/storage"
+)
+
+const maintenanceBatchSize = 100
+
+type previewObjectStore interface {
+	ObjectExists(ctx context.Context, objectKey string) (bool, error)
+}
+
+// MaintenanceHandler 处理周期性维护任务：
+//   - 缩略图对象已不存在的简历/模板清空预览字段并扣回存储用量；
+//   - 清空旧版本持久化在行上的缩略图预签名 URL（现由 API 读取时按 object key 签发）；
+//   - 清除已过期的数据库账号锁定时间；
+//   - 删除超过保留期的简历生命周期事件。
+//
+// 单行失败只记录日志，留待下一轮重试；数据库查询失败时返回 error。
+type MaintenanceHandler struct {
+	db      *gorm.DB
+	storage previewObjectStore
+	logger  *slog.Logger
+	// eventRetention 为简历生命周期事件的保留时长，0 表示不清理。
+	eventRetention time.Duration
+}
+
+func NewMaintenanceHandler(db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, eventRetention time.Duration) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		db:             db,
+		storage:        storageClient,
+		logger:         logger,
+		eventRetention: eventRetention,
+	}
+}
+
+// previewRow 为校验缩略图所需的最小列集合，简历与模板共用。
+type previewRow struct {
+	ID               uint
+	UserID           uint
+	PreviewObjectKey string
+	PreviewSize      int64
+}
+
+type previewCheckStats struct {
+	legacyURLsCleared int64
+	orphansCleared    int
+	failed            int
+}
+
+func (h *MaintenanceHandler) ProcessTask(ctx context.Context, _ *asynq.Task) error {
+	resumes, err := h.checkPreviews(ctx, &database.Resume{})
+	if err != nil {
+		return fmt.Errorf("check resume previews: %w", err)
+	}
+	templates, err := h.checkPreviews(ctx, &database.Template{})
+	if err != nil {
+		return fmt.Errorf("check template previews: %w", err)
+	}
+
+	unlock := h.db.WithContext(ctx).Model(&database.User{}).
+		Where("locked_until IS NOT NULL AND locked_until <= ?", time.Now()).
+		UpdateColumn("locked_until", nil)
+	if unlock.Error != nil {
+		return fmt.Errorf("clear expired login locks: %w", unlock.Error)
+	}
+
+	// 增量维护的用量可能因异常偏离实际值（或新字段尚未回填），每轮维护按记录重新汇总一次。
+	if err := database.RecalculateStorageUsage(h.db.WithContext(ctx)); err != nil {
+		return fmt.Errorf("recalculate storage usage: %w", err)
+	}
+
+	var eventsPruned int64
+	if h.eventRetention > 0 {
+		pruned, err := database.PruneResumeEvents(h.db.WithContext(ctx), time.Now().Add(-h.eventRetention))
+		if err != nil {
+			return fmt.Errorf("prune resume events: %w", err)
+		}
+		eventsPruned = pruned
+	}
+
+	h.logger.Info("maintenance task completed",
+		slog.Int64("legacy_preview_urls_cleared", resumes.legacyURLsCleared+templates.legacyURLsCleared),
+		slog.Int("resume_previews_cleared", resumes.orphansCleared),
+		slog.Int("template_previews_cleared", templates.orphansCleared),
+		slog.Int("preview_failures", resumes.failed+templates.failed),
+		slog.Int64("expired_locks_cleared", unlock.RowsAffected),
+		slog.Int64("resume_events_pruned", eventsPruned),
+	)
+	return nil
+}
+
+// checkPreviews 清空 model 对应表中已有 object key 的行上残留的预签名 URL，
+// 再按 id 分批确认缩略图对象仍然存在。
+func (h *MaintenanceHandler) checkPreviews(ctx context.Context, model any) (previewCheckStats, error) {
+	var stats previewCheckStats
+	legacy := h.db.WithContext(ctx).Model(model).
+		Where("preview_object_key <> '' AND preview_image_url <> ''").
+		UpdateColumn("preview_image_url", "")
+	if legacy.Error != nil {
+		return stats, legacy.Error
+	}
+	stats.legacyURLsCleared = legacy.RowsAffected
+
+	var lastID uint
+	for {
+		var rows []previewRow
+		if err := h.db.WithContext(ctx).Model(model).
+			Select("id", "user_id", "preview_object_key", "preview_size").
+			Where("id > ? AND preview_object_key <> ''", lastID).
+			Order("id").
+			Limit(maintenanceBatchSize).
+			Find(&rows).Error; err != nil {
+			return stats, err
+		}
+
+		for _, row := range rows {
+			lastID = row.ID
+			cleared, err := h.clearIfOrphaned(ctx, model, row)
+			switch {
+			case err != nil:
+				stats.failed++
+				h.logger.Warn("check preview object failed", slog.Uint64("id", uint64(row.ID)), slog.String("object_key", row.PreviewObjectKey), slog.Any("error", err))
+			case cleared:
+				stats.orphansCleared++
+			}
+		}
+		if len(rows) < maintenanceBatchSize {
+			return stats, nil
+		}
+	}
+}
+
+// clearIfOrphaned 在缩略图对象已不存在时清空预览字段并扣回存储用量，返回是否清理。
+// 更新条件带上扫描时的 object key，避免覆盖扫描后由渲染任务写入的新缩略图。
+func (h *MaintenanceHandler) clearIfOrphaned(ctx context.Context, model any, row previewRow) (bool, error) {
+	exists, err := h.storage.ObjectExists(ctx, strings.TrimSpace(row.PreviewObjectKey))
+	if err != nil || exists {
+		return false, err
+	}
+
+	cleared := false
+	err = h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(model).
+			Where("id = ? AND preview_object_key = ?", row.ID, row.PreviewObjectKey).
+			UpdateColumns(map[string]any{
+				"preview_image_url":  "",
+				"preview_object_key": "",
+				"preview_size":       0,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cleared = true
+		return database.AdjustStorageBytes(tx, row.UserID, -row.PreviewSize)
+	})
+	return cleared, err
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	"phResume/internal/tasks"
+)
+
+type fakePreviewStore struct {
+	existing map[string]bool
+}
+
+func (s *fakePreviewStore) ObjectExists(_ context.Context, objectKey string) (bool, error) {
+	return s.existing[objectKey], nil
+}
+
+func newMaintenanceTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", url.PathEscape(t.Name()))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&database.User{}, &database.Resume{}, &database.ResumeEvent{}, &database.Template{}, &database.Asset{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestMaintenanceHandler_ClearsOrphanedPreviewsAndLegacyURLs(t *testing.T) {
+	db := newMaintenanceTestDB(t)
+	expiredLock := time.Now().Add(-time.Minute)
+	activeLock := time.Now().Add(time.Hour)
+	user := database.User{Username: "alice", StorageBytes: 300, LockedUntil: &expiredLock}
+	locked := database.User{Username: "bob", LockedUntil: &activeLock}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if err := db.Create(&locked).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	// 维护清理不是用户修改，不应改变 updated_at（列表按其排序）。
+	seededAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	resumes := []database.Resume{
+		{Title: "legacy", UserID: user.ID, PreviewImageURL: "https://stale-presign", PreviewObjectKey: "thumbnails/resume/1/a.jpg", PreviewSize: 100},
+		{Title: "orphan", UserID: user.ID, PreviewImageURL: "https://stale-presign", PreviewObjectKey: "thumbnails/resume/2/gone.jpg", PreviewSize: 100},
+		{Title: "external", UserID: user.ID, PreviewImageURL: "https://example.com/custom.png"},
+	}
+	if err := db.Create(&resumes).Error; err != nil {
+		t.Fatalf("seed resumes: %v", err)
+	}
+	if err := db.Model(&database.Resume{}).Where("1 = 1").UpdateColumn("updated_at", seededAt).Error; err != nil {
+		t.Fatalf("backdate resumes: %v", err)
+	}
+	template := database.Template{Title: "tpl", UserID: user.ID, PreviewObjectKey: "thumbnails/template/1/gone.jpg", PreviewSize: 50}
+	if err := db.Create(&template).Error; err != nil {
+		t.Fatalf("seed template: %v", err)
+	}
+
+	h := &MaintenanceHandler{
+		db:      db,
+		storage: &fakePreviewStore{existing: map[string]bool{"thumbnails/resume/1/a.jpg": true}},
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := h.ProcessTask(context.Background(), tasks.NewMaintenanceTask()); err != nil {
+		t.Fatalf("process task: %v", err)
+	}
+
+	var legacy database.Resume
+	db.First(&legacy, resumes[0].ID)
+	if legacy.PreviewImageURL != "" || legacy.PreviewObjectKey != "thumbnails/resume/1/a.jpg" {
+		t.Fatalf("expected stored presigned url cleared and key kept, got %+v", legacy)
+	}
+	var orphan database.Resume
+	db.First(&orphan, resumes[1].ID)
+	if orphan.PreviewObjectKey != "" || orphan.PreviewSize != 0 {
+		t.Fatalf("expected orphaned resume preview cleared, got %+v", orphan)
+	}
+	if !legacy.UpdatedAt.Equal(seededAt) || !orphan.UpdatedAt.Equal(seededAt) {
+		t.Fatalf("maintenance must not bump updated_at, got legacy=%v orphan=%v", legacy.UpdatedAt, orphan.UpdatedAt)
+	}
+	var external database.Resume
+	db.First(&external, resumes[2].ID)
+	if external.PreviewImageURL != "https://example.com/custom.png" {
+		t.Fatalf("preview url without object key must be left untouched")
+	}
+	var tpl database.Template
+	db.First(&tpl, template.ID)
+	if tpl.PreviewObjectKey != "" {
+		t.Fatalf("expected orphaned template preview cleared")
+	}
+
+	var reloaded database.User
+	db.First(&reloaded, user.ID)
+	// 种子用量 300 与记录不符：清理孤儿缩略图后按剩余记录重新汇总为 100。
+	if reloaded.StorageBytes != 100 {
+		t.Fatalf("expected storage usage recalculated from remaining previews, got storage_bytes=%d", reloaded.StorageBytes)
+	}
+	if reloaded.LockedUntil != nil {
+		t.Fatalf("expected expired login lock to be cleared")
+	}
+	var stillLocked database.User
+	db.First(&stillLocked, locked.ID)
+	if stillLocked.LockedUntil == nil {
+		t.Fatalf("active login lock must be kept")
+	}
+}
+
+func TestMaintenanceHandler_PrunesExpiredResumeEvents(t *testing.T) {
+	db := newMaintenanceTestDB(t)
+	old := database.ResumeEvent{ResumeID: 1, UserID: 1, Type: database.ResumeEventCreated, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	recent := database.ResumeEvent{ResumeID: 1, UserID: 1, Type: database.ResumeEventUpdated, CreatedAt: time.Now().Add(-time.Hour)}
+	if err := db.Create(&[]database.ResumeEvent{old, recent}).Error; err != nil {
+		t.Fatalf("seed events: %v", err)
+	}
+
+	h := &MaintenanceHandler{
+		db:             db,
+		storage:        &fakePreviewStore{},
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		eventRetention: 24 * time.Hour,
+	}
+	if err := h.ProcessTask(context.Background(), tasks.NewMaintenanceTask()); err != nil {
+		t.Fatalf("process task: %v", err)
+	}
+
+	var types []string
+	db.Model(&database.ResumeEvent{}).Pluck("type", &types)
+	if len(types) != 1 || types[0] != database.ResumeEventUpdated {
+		t.Fatalf("expected only the recent event to remain, got %v", types)
+	}
+
+	h.eventRetention = 0
+	if err := h.ProcessTask(context.Background(), tasks.NewMaintenanceTask()); err != nil {
+		t.Fatalf("process task: %v", err)
+	}
+	var count int64
+	db.Model(&database.ResumeEvent{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("retention 0 must keep events, got %d", count)
+	}
+}
@@ -1,12 +1,61 @@
 package worker
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/notify"
+)
+
 // 统一的 WebSocket 消息协议（通过 Redis Pub/Sub 转发给前端）。
 // 注意：这里的字段名与前端解析保持一致。
 type PDFGenerationNotifyMessage struct {
+	// ID 为通知重放日志分配的事件 id，客户端重连时回传以补发错过的通知；日志写入失败时为空。
+	ID            string   `json:"id,omitempty"`
 	Status        string   `json:"status"`
 	ResumeID      uint     `json:"resume_id"`
 	CorrelationID string   `json:"correlation_id"`
 	ErrorCode     int      `json:"error_code"`
 	ErrorMessage  string   `json:"error_message"`
 	MissingKeys   []string `json:"missing_keys,omitempty"`
+	// 以下字段仅批量打包通知（Type 为 NotifyTypePDFBundle）使用，单份 PDF 通知省略。
+	Type            string `json:"type,omitempty"`
+	DownloadURL     string `json:"download_url,omitempty"`
+	ExpiresIn       int    `json:"expires_in,omitempty"`
+	FailedResumeIDs []uint `json:"failed_resume_ids,omitempty"`
+}
+
+// NotifyTypePDFBundle 标识批量 PDF 打包完成/失败的通知。
+const NotifyTypePDFBundle = "pdf_bundle"
+
+// notifyPublisher 为推送用户通知所需的 Redis 命令，redis.UniversalClient 满足该接口。
+type notifyPublisher interface {
+	notify.LogWriter
+	Publish(ctx context.Context, channel string, message any) *redis.IntCmd
+}
+
+// publishUserNotify 先将通知写入用户的重放日志再发布到 notify.Channel；
+// 日志写入失败时仍发布（不带 id，断线客户端无法补发）。
+func publishUserNotify(ctx context.Context, client notifyPublisher, logger *slog.Logger, userID uint, msg PDFGenerationNotifyMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal notification payload: %w", err)
+	}
+	if id, err := notify.Append(ctx, client, userID, data); err != nil {
+		logger.Warn("append notification log failed", slog.Uint64("user_id", uint64(userID)), slog.Any("error", err))
+	} else {
+		msg.ID = id
+		if data, err = json.Marshal(msg); err != nil {
+			return fmt.Errorf("marshal notification payload: %w", err)
+		}
+	}
+	channel := notify.Channel(userID)
+	if err := client.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("publish redis notification to %q: %w", channel, err)
+	}
+	return nil
 }
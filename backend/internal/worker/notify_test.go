@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/notify"
+)
+
+type fakeNotifyPublisher struct {
+	streamKey string
+	appendErr error
+	channel   string
+	published []byte
+}
+
+func (f *fakeNotifyPublisher) XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	f.streamKey = a.Stream
+	cmd := redis.NewStringCmd(ctx)
+	if f.appendErr != nil {
+		cmd.SetErr(f.appendErr)
+		return cmd
+	}
+	cmd.SetVal("1700000000000-0")
+	return cmd
+}
+
+func (f *fakeNotifyPublisher) Expire(ctx context.Context, _ string, _ time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(ctx)
+	cmd.SetVal(true)
+	return cmd
+}
+
+func (f *fakeNotifyPublisher) Publish(ctx context.Context, channel string, message any) *redis.IntCmd {
+	f.channel = channel
+	f.published, _ = message.([]byte)
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(1)
+	return cmd
+}
+
+// 发布端与 API 订阅端（forwardUserNotifications）都经由 notify.Channel / notify.LogKey 生成名称。
+func TestPublishUserNotify_UsesSharedChannelNames(t *testing.T) {
+	notify.SetNamespace("tenant-a")
+	t.Cleanup(func() { notify.SetNamespace("") })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	fake := &fakeNotifyPublisher{}
+	if err := publishUserNotify(context.Background(), fake, logger, 7, PDFGenerationNotifyMessage{Status: "completed", ResumeID: 3}); err != nil {
+		t.Fatalf("publishUserNotify: %v", err)
+	}
+	if fake.channel != notify.Channel(7) || fake.channel != "tenant-a:user_notify:7" {
+		t.Fatalf("published to %q, subscriber listens on %q", fake.channel, notify.Channel(7))
+	}
+	if fake.streamKey != notify.LogKey(7) {
+		t.Fatalf("appended to %q, replay reads %q", fake.streamKey, notify.LogKey(7))
+	}
+	var msg PDFGenerationNotifyMessage
+	if err := json.Unmarshal(fake.published, &msg); err != nil {
+		t.Fatalf("decode published payload: %v", err)
+	}
+	if msg.ID != "1700000000000-0" || msg.ResumeID != 3 {
+		t.Fatalf("unexpected published message %+v", msg)
+	}
+
+	fake = &fakeNotifyPublisher{appendErr: errors.New("stream unavailable")}
+	if err := publishUserNotify(context.Background(), fake, logger, 7, PDFGenerationNotifyMessage{Status: "error"}); err != nil {
+		t.Fatalf("log failure must not block publish: %v", err)
+	}
+	var fallback PDFGenerationNotifyMessage
+	if err := json.Unmarshal(fake.published, &fallback); err != nil || fallback.ID != "" {
+		t.Fatalf("expected payload without id, got %s (err=%v)", fake.published, err)
+	}
+}
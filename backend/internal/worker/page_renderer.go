@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -13,7 +14,10 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 )
 
-func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript string) (_ *rod.Page, cleanup func(), err error) {
+// pageSizePlaceholder 为 cleanupCSS 中 @page size 的占位符，按打印数据的纸张尺寸替换。
+const pageSizePlaceholder = "__PAGE_SIZE__"
+
+func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript string, fontFaceCSS string, layout printLayout, launchPolicy browserLaunchPolicy) (_ *rod.Page, cleanup func(), err error) {
 	var (
 		launch  *launcher.Launcher
 		browser *rod.Browser
@@ -22,26 +26,7 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 	)
 
 	cleanup = func() {
-		if page != nil {
-			_ = page.Close()
-		}
-		if browser != nil {
-			_ = browser.Timeout(5 * time.Second).Close()
-		}
-		if launch != nil {
-			if force {
-				launch.Kill()
-			}
-			done := make(chan struct{})
-			go func() {
-				launch.Cleanup()
-				close(done)
-			}()
-			select {
-			case <-done:
-			case <-time.After(5 * time.Second):
-			}
-		}
+		closeRenderSession(launch, browser, page, force)
 	}
 	defer func() {
 		if recovered := recover(); recovered != nil {
@@ -54,44 +39,17 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 		}
 	}()
 
-	launch = launcher.New().
-		// 关闭 Leakless：生产环境使用 tmpfs(/tmp) 时通常是 noexec，
-		// Leakless 需要在 /tmp 解压并 exec 自身，会触发 permission denied。
-		Leakless(false).
-		Headless(true).
-		NoSandbox(true).
-		// 云端常见：GPU/Vulkan 初始化失败会导致 GPU 进程反复崩溃，进而让 CDP 调用卡死/超时。
-		Set("disable-gpu").
-		Set("disable-vulkan").
-		Set("use-gl", "swiftshader").
-		// 容器内常见问题：/dev/shm 太小会导致 Chromium 卡死/崩溃
-		Set("disable-dev-shm-usage").
-		Set("no-zygote").
-		// 强制把用户数据与缓存写到 /tmp（配合只读根文件系统与 tmpfs）
-		Set("user-data-dir", "/tmp/chromium").
-		Set("disk-cache-dir", "/tmp/chromium-cache")
-
-	if path, ok := launcher.LookPath(); ok {
-		launch = launch.Bin(path)
-	}
-
-	browserURL, err := launch.Launch()
+	launch, browser, err = launchBrowser(logger, launchPolicy)
 	if err != nil {
-		return nil, cleanup, fmt.Errorf("launch chromium: %w", err)
-	}
-
-	browser = rod.New().ControlURL(browserURL)
-	browser = browser.Timeout(30 * time.Second)
-	if err := browser.Connect(); err != nil {
-		return nil, cleanup, fmt.Errorf("connect browser: %w", err)
+		return nil, cleanup, err
 	}
-	browser = browser.CancelTimeout()
 
 	page, err = browser.Timeout(45 * time.Second).Page(proto.TargetCreateTarget{})
 	if err != nil {
 		return nil, cleanup, fmt.Errorf("create page: %w", err)
 	}
 	page = page.CancelTimeout()
+	consoleErrors := watchConsoleErrors(page)
 
 	if strings.TrimSpace(preReadyScript) != "" {
 		// 用 EvalOnNewDocument 把数据“导航前注入”，彻底消除前端 5s 轮询窗口与 worker 注入时机的竞态。
@@ -107,7 +65,7 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 	}
 
 	if err := page.Timeout(90 * time.Second).WaitLoad(); err != nil {
-		return nil, cleanup, fmt.Errorf("wait page load: %w", err)
+		return nil, cleanup, consoleErrors.wrap(fmt.Errorf("wait page load: %w", err))
 	}
 
 	// 兜底：若浏览器在极端情况下未触发新文档脚本（或被某些导航路径绕开），确保打印数据仍被注入。
@@ -122,24 +80,20 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 		}
 	}
 
+	// 自定义字体在等待渲染信号前注入，使 document.fonts.ready 覆盖其加载过程。
+	if strings.TrimSpace(fontFaceCSS) != "" {
+		logger.Info("Worker: Injecting custom @font-face rules...")
+		if err := page.AddStyleTag("", fontFaceCSS); err != nil {
+			logger.Warn("Worker: inject custom fonts failed, continue", slog.Any("error", err))
+		}
+	}
+
 	logger.Info("Worker: Waiting for frontend render signal (#pdf-render-ready)...")
 	if _, err := page.Timeout(30 * time.Second).Element("#pdf-render-ready"); err != nil {
-		return nil, cleanup, fmt.Errorf("wait for #pdf-render-ready: %w", err)
+		return nil, cleanup, consoleErrors.wrap(fmt.Errorf("wait for #pdf-render-ready: %w", err))
 	}
 
-	// 额外等待 WebFont/系统字体就绪，避免回退字体度量导致排版差异
-	logger.Info("Worker: Waiting for document.fonts.ready...")
-	if _, evalErr := page.Timeout(5 * time.Second).Eval(`() => {
-	  if (document && document.fonts && document.fonts.ready) {
-	    return Promise.race([
-	      document.fonts.ready.then(() => true),
-	      new Promise((resolve) => setTimeout(() => resolve(true), 3000))
-	    ]);
-	  }
-	  return true;
-	}`); evalErr != nil {
-		logger.Warn("Worker: document.fonts.ready wait failed, continue", slog.Any("error", evalErr))
-	}
+	waitFontsReady(logger, page)
 	logger.Info("Worker: Render signal received.")
 
 	if err := (proto.EmulationSetEmulatedMedia{Media: "print"}).Call(page); err != nil {
@@ -149,11 +103,14 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 	logger.Info("Worker: Marking A4 canvas as #pdf-root...")
 	if _, err := page.Timeout(10 * time.Second).Eval(`() => {
   const normalize = s => (s || '').replace(/\s+/g, '').toLowerCase();
-  let target = null;
+  // 多页容器高度随内容增长，不满足 A4 宽高比，优先按前端显式标记识别。
+  let target = document.querySelector('[data-pdf-pages="true"]');
   const all = Array.from(document.querySelectorAll('body *'));
-  for (const el of all) {
-    const cs = getComputedStyle(el);
-    if (normalize(cs.aspectRatio) === '210/297') { target = el; break; }
+  if (!target) {
+    for (const el of all) {
+      const cs = getComputedStyle(el);
+      if (normalize(cs.aspectRatio) === '210/297') { target = el; break; }
+    }
   }
   if (!target) {
     for (const el of all) {
@@ -230,7 +187,7 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
       print-color-adjust: exact !important;
     }
     @page {
-      size: A4;
+      size: __PAGE_SIZE__;
       margin: 0;
     }
     body {
@@ -296,6 +253,10 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
     }
   }
 `
+	cleanupCSS = strings.Replace(cleanupCSS, pageSizePlaceholder, layout.paper().CSS, 1)
+	if layout.MultiPage {
+		cleanupCSS += multiPageCSS
+	}
 	if err := page.AddStyleTag("", cleanupCSS); err != nil {
 		return nil, cleanup, fmt.Errorf("inject cleanup css: %w", err)
 	}
@@ -331,21 +292,159 @@ func renderFrontendPage(logger *slog.Logger, targetURL string, preReadyScript st
 		return nil, cleanup, fmt.Errorf("cleanup dev overlays: %w", err)
 	}
 
+	if err := page.WaitIdle(30 * time.Second); err != nil {
+		return nil, cleanup, consoleErrors.wrap(fmt.Errorf("wait idle: %w", err))
+	}
+	return page, cleanup, nil
+}
+
+// renderHTMLPage 将 HTML 直接载入空白页，不依赖前端打印页；用于服务端模板兜底渲染与内部 HTML 转 PDF 接口，
+// 返回的页面可直接交给 exportPDF 与截图。
+// 页面操作绑定 ctx，调用方取消即中止渲染；blockNetwork 时拦截除 data: 以外的全部请求，
+// 避免不可信 HTML 借 Worker 的 Chromium 访问内网或外网（SSRF）。
+func renderHTMLPage(ctx context.Context, logger *slog.Logger, html []byte, launchPolicy browserLaunchPolicy, blockNetwork bool) (_ *rod.Page, cleanup func(), err error) {
+	var (
+		launch  *launcher.Launcher
+		browser *rod.Browser
+		page    *rod.Page
+		router  *rod.HijackRouter
+		force   bool
+	)
+
+	cleanup = func() {
+		if router != nil {
+			_ = router.Stop()
+		}
+		closeRenderSession(launch, browser, page, force)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			force = true
+			err = fmt.Errorf("render html page panic: %v\n%s", recovered, debug.Stack())
+		}
+		if err != nil {
+			force = true
+			cleanup()
+		}
+	}()
+
+	launch, browser, err = launchBrowser(logger, launchPolicy)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	page, err = browser.Context(ctx).Timeout(45 * time.Second).Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("create page: %w", err)
+	}
+	page = page.CancelTimeout()
+
+	if blockNetwork {
+		router = page.HijackRequests()
+		if err := router.Add("*", "", denyNonDataRequest); err != nil {
+			return nil, cleanup, fmt.Errorf("hijack requests: %w", err)
+		}
+		go router.Run()
+	}
+
+	logger.Info("Worker: Loading HTML document...")
+	if err := page.Timeout(30 * time.Second).SetDocumentContent(string(html)); err != nil {
+		return nil, cleanup, fmt.Errorf("set document content: %w", err)
+	}
+	if err := page.Timeout(60 * time.Second).WaitLoad(); err != nil {
+		return nil, cleanup, fmt.Errorf("wait page load: %w", err)
+	}
+	waitFontsReady(logger, page)
+
+	if err := (proto.EmulationSetEmulatedMedia{Media: "print"}).Call(page); err != nil {
+		return nil, cleanup, fmt.Errorf("set emulated media to print: %w", err)
+	}
 	if err := page.WaitIdle(30 * time.Second); err != nil {
 		return nil, cleanup, fmt.Errorf("wait idle: %w", err)
 	}
 	return page, cleanup, nil
 }
 
-func exportPDF(page *rod.Page) ([]byte, error) {
+// denyNonDataRequest 只放行内联的 data: 资源，其余导航、子资源与 XHR 一律以 BlockedByClient 失败。
+func denyNonDataRequest(h *rod.Hijack) {
+	if h.Request.URL().Scheme == "data" {
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+		return
+	}
+	h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+}
+
+// waitFontsReady 额外等待 WebFont/系统字体就绪（最多 3s），避免回退字体度量导致排版差异；失败只记录日志。
+func waitFontsReady(logger *slog.Logger, page *rod.Page) {
+	logger.Info("Worker: Waiting for document.fonts.ready...")
+	if _, evalErr := page.Timeout(5 * time.Second).Eval(`() => {
+	  if (document && document.fonts && document.fonts.ready) {
+	    return Promise.race([
+	      document.fonts.ready.then(() => true),
+	      new Promise((resolve) => setTimeout(() => resolve(true), 3000))
+	    ]);
+	  }
+	  return true;
+	}`); evalErr != nil {
+		logger.Warn("Worker: document.fonts.ready wait failed, continue", slog.Any("error", evalErr))
+	}
+}
+
+// closeRenderSession 关闭页面与浏览器并清理用户数据目录；force 时先强制结束 Chromium 进程。
+func closeRenderSession(launch *launcher.Launcher, browser *rod.Browser, page *rod.Page, force bool) {
+	if page != nil {
+		_ = page.Close()
+	}
+	if browser != nil {
+		_ = browser.Timeout(5 * time.Second).Close()
+	}
+	if launch != nil {
+		if force {
+			launch.Kill()
+		}
+		done := make(chan struct{})
+		go func() {
+			launch.Cleanup()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// multiPageCSS 在多页模式下追加于 cleanupCSS 之后：取消单页画布的 fixed 定位与 body 溢出裁剪，
+// 让 #pdf-root 回到文档流中按 @page 自然分页（exportPDF 本身支持多页输出）。
+const multiPageCSS = `
+  @media print {
+    body {
+      overflow: visible !important;
+    }
+    #pdf-root {
+      position: static !important;
+      top: auto !important;
+      left: auto !important;
+      transform: none !important;
+      height: auto !important;
+      overflow: visible !important;
+    }
+    #pdf-root .print-mask {
+      display: none !important;
+    }
+  }
+`
+
+func exportPDF(page *rod.Page, layout printLayout) ([]byte, error) {
+	paper := layout.paper()
 	params := &proto.PagePrintToPDF{
 		PrintBackground:   true,
-		PaperWidth:        float64Ptr(8.27),
-		PaperHeight:       float64Ptr(11.69),
-		MarginTop:         float64Ptr(0),
-		MarginBottom:      float64Ptr(0),
-		MarginLeft:        float64Ptr(0),
-		MarginRight:       float64Ptr(0),
+		PaperWidth:        float64Ptr(paper.Width),
+		PaperHeight:       float64Ptr(paper.Height),
+		MarginTop:         float64Ptr(layout.MarginInches),
+		MarginBottom:      float64Ptr(layout.MarginInches),
+		MarginLeft:        float64Ptr(layout.MarginInches),
+		MarginRight:       float64Ptr(layout.MarginInches),
 		PreferCSSPageSize: true,
 	}
 	reader, err := page.PDF(params)
@@ -363,17 +462,17 @@ func exportPDF(page *rod.Page) ([]byte, error) {
 	return data, nil
 }
 
-func capturePreparedScreenshot(page *rod.Page, quality int) ([]byte, error) {
+func capturePreparedScreenshot(page *rod.Page, format previewImageFormat) ([]byte, error) {
 	element, err := page.Timeout(5 * time.Second).Element("#a4-container")
 	if err == nil {
-		if data, shotErr := element.Screenshot(proto.PageCaptureScreenshotFormatJpeg, quality); shotErr == nil {
+		if data, shotErr := element.Screenshot(format.screenshotFormat(), format.Quality); shotErr == nil {
 			return data, nil
 		}
 	}
 
 	req := &proto.PageCaptureScreenshot{
-		Format:  proto.PageCaptureScreenshotFormatJpeg,
-		Quality: intPtr(quality),
+		Format:  format.screenshotFormat(),
+		Quality: intPtr(format.Quality),
 	}
 	data, err := page.Screenshot(true, req)
 	if err != nil {
@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"phResume/internal/database"
+	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+	"phResume/internal/storage"
+	"phResume/internal/tasks"
+)
+
+// PDFBundleHandler 消费批量 PDF 打包任务：逐份复用内容未变的 PDF、重新渲染过期的 PDF，再打成一个 zip 上传，
+// 通过用户通知下发预签名下载链接。渲染复用 PDFTaskHandler 的流程与并发名额。
+type PDFBundleHandler struct {
+	pdf     *PDFTaskHandler
+	linkTTL time.Duration
+}
+
+// NewPDFBundleHandler 创建打包任务处理器；linkTTL 为 zip 预签名链接的有效期。
+func NewPDFBundleHandler(pdf *PDFTaskHandler, linkTTL time.Duration) *PDFBundleHandler {
+	return &PDFBundleHandler{pdf: pdf, linkTTL: linkTTL}
+}
+
+// bundleEntry 为 zip 中的一份 PDF：Name 为包内文件名，ObjectKey 为已生成 PDF 的对象 key。
+type bundleEntry struct {
+	Name      string
+	ObjectKey string
+}
+
+// ProcessTask 实现 asynq.Handler。整个任务占用一个用户渲染名额，逐份渲染，每次渲染再占用全局渲染名额，
+// 因此打包不会比单份下载占用更多浏览器。单份失败只记入 failed_resume_ids，全部失败时任务失败并重试。
+func (h *PDFBundleHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr error) {
+	log := h.pdf.logger
+
+	var payload tasks.PDFBundlePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		log.Error("unmarshal task payload failed", slog.Any("error", err))
+		return err
+	}
+
+	ctx, span := startTaskSpan(ctx, tasks.TypePDFBundle, payload.TraceParent, payload.CorrelationID)
+	defer func() { endSpan(span, retErr) }()
+	log = log.With(
+		slog.String("correlation_id", payload.CorrelationID),
+		slog.String("trace_id", span.TraceParent().TraceID),
+		slog.Uint64("user_id", uint64(payload.UserID)),
+	)
+	log.Info("Starting pdf bundle task...", slog.Int("resume_count", len(payload.ResumeIDs)))
+
+	defer func() {
+		if !pdfTaskFinished(retErr, isFinalAsynqAttempt(ctx)) {
+			return
+		}
+		taskID, _ := asynq.GetTaskID(ctx)
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tasks.ReleaseInflight(releaseCtx, h.pdf.redisClient, tasks.PDFBundleInflightKey(payload.UserID), taskID); err != nil {
+			log.Warn("release pdf bundle inflight marker failed", slog.Any("error", err))
+		}
+	}()
+
+	defer func() {
+		if retErr == nil || errors.Is(retErr, ErrUserRenderLimit) || !isFinalAsynqAttempt(ctx) {
+			return
+		}
+		notify := PDFGenerationNotifyMessage{
+			Type:          NotifyTypePDFBundle,
+			Status:        "error",
+			CorrelationID: payload.CorrelationID,
+			ErrorCode:     errcode.SystemError,
+			ErrorMessage:  strings.TrimSpace(retErr.Error()),
+		}
+		if err := h.pdf.publishPDFGenerationNotify(ctx, payload.UserID, notify); err != nil {
+			log.Error("publish pdf bundle error notification failed", slog.Any("error", err))
+		}
+	}()
+
+	var resumes []database.Resume
+	if len(payload.ResumeIDs) > 0 {
+		if err := h.pdf.db.WithContext(ctx).
+			Where("user_id = ? AND id IN ?", payload.UserID, payload.ResumeIDs).
+			Order("id").
+			Find(&resumes).Error; err != nil {
+			log.Error("query resumes failed", slog.Any("error", err))
+			return err
+		}
+	}
+	if len(resumes) == 0 {
+		log.Warn("no resumes to bundle, skipping task")
+		return nil
+	}
+
+	releaseSlot, err := h.pdf.renderLimiter.acquire(ctx, payload.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserRenderLimit) {
+			log.Info("user concurrent render limit reached, deferring task")
+		} else {
+			log.Error("acquire user render slot failed", slog.Any("error", err))
+		}
+		return err
+	}
+	defer releaseSlot()
+
+	entries := make([]bundleEntry, 0, len(resumes))
+	usedNames := make(map[string]int, len(resumes))
+	var failed []uint
+	for i := range resumes {
+		resume := &resumes[i]
+		objectKey, err := h.ensurePDF(ctx, log, resume, payload.CorrelationID)
+		if err != nil {
+			log.Warn("bundle resume pdf failed", slog.Uint64("resume_id", uint64(resume.ID)), slog.Any("error", err))
+			failed = append(failed, resume.ID)
+			continue
+		}
+		entries = append(entries, bundleEntry{Name: bundleFileName(resume.Title, resume.ID, usedNames), ObjectKey: objectKey})
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("all %d resume pdfs failed", len(failed))
+	}
+
+	var buf bytes.Buffer
+	if err := writePDFBundle(&buf, entries, func(objectKey string) (io.ReadCloser, error) {
+		return h.pdf.storage.GetObject(ctx, objectKey)
+	}); err != nil {
+		log.Error("build pdf bundle failed", slog.Any("error", err))
+		return err
+	}
+
+	// 每个用户只保留最新一个 zip：上传前清掉旧包，避免打包结果无限堆积。
+	prefix := tasks.PDFBundlePrefix(payload.UserID)
+	if err := h.pdf.storage.DeletePrefix(ctx, prefix); err != nil {
+		log.Warn("delete previous pdf bundles failed", slog.Any("error", err))
+	}
+	objectName := prefix + uuid.NewString() + ".zip"
+	if _, err := h.pdf.storage.UploadFile(ctx, objectName, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "application/zip"); err != nil {
+		log.Error("upload pdf bundle failed", slog.Any("error", err))
+		return err
+	}
+	// 对象名是 UUID，借 response-content-disposition 让浏览器按可读的文件名保存。
+	url, err := h.pdf.storage.GeneratePresignedURLWithParams(ctx, objectName, h.linkTTL, map[string]string{
+		"response-content-disposition": storage.AttachmentDisposition(bundleArchiveName(time.Now())),
+	})
+	if err != nil {
+		log.Error("presign pdf bundle failed", slog.Any("error", err))
+		return err
+	}
+
+	notify := PDFGenerationNotifyMessage{
+		Type:            NotifyTypePDFBundle,
+		Status:          "completed",
+		CorrelationID:   payload.CorrelationID,
+		ErrorCode:       errcode.OK,
+		DownloadURL:     url,
+		ExpiresIn:       int(h.linkTTL.Seconds()),
+		FailedResumeIDs: failed,
+	}
+	if err := h.pdf.publishPDFGenerationNotify(ctx, payload.UserID, notify); err != nil {
+		log.Error("publish pdf bundle notification failed", slog.Any("error", err))
+		return err
+	}
+	log.Info("PDF bundle task completed successfully.", slog.Int("bundled", len(entries)), slog.Int("failed", len(failed)))
+	return nil
+}
+
+// ensurePDF 返回简历当前内容对应的 PDF 对象 key：已有缓存直接复用，否则在全局渲染名额内渲染并保存。
+func (h *PDFBundleHandler) ensurePDF(ctx context.Context, log *slog.Logger, resume *database.Resume, correlationID string) (string, error) {
+	contentHash := resumepkg.ContentHash(resume.Content)
+	if decidePDFCache("", contentHash, resume.PdfContentHash, resume.PdfUrl) == pdfCacheHit {
+		return resume.PdfUrl, nil
+	}
+
+	releaseRender, err := h.pdf.renderSlots.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer releaseRender()
+
+	log = log.With(slog.Uint64("resume_id", uint64(resume.ID)))
+	pdfBytes, _, cleanup, _, _, err := h.pdf.generatePDF(ctx, log, resume.ID, resume.UserID, correlationID)
+	if err != nil {
+		h.pdf.recordRenderEvent(ctx, log, *resume, database.ResumeEventRenderFailed, correlationID, strings.TrimSpace(err.Error()))
+		return "", err
+	}
+	defer cleanup()
+
+	objectKey, err := h.pdf.storePDF(ctx, log, resume, contentHash, pdfBytes)
+	if err != nil {
+		return "", err
+	}
+	h.pdf.recordRenderEvent(ctx, log, *resume, database.ResumeEventRenderCompleted, correlationID, "bundle")
+	return objectKey, nil
+}
+
+// bundleArchiveName 为下载时的 zip 文件名，如 resumes-20260115.zip。
+func bundleArchiveName(now time.Time) string {
+	return "resumes-" + now.Format("20060102") + ".zip"
+}
+
+// writePDFBundle 将 entries 依次写入 zip；open 负责读取对象内容。
+func writePDFBundle(w io.Writer, entries []bundleEntry, open func(objectKey string) (io.ReadCloser, error)) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		if err := copyBundleEntry(zw, entry, open); err != nil {
+			_ = zw.Close()
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func copyBundleEntry(zw *zip.Writer, entry bundleEntry, open func(objectKey string) (io.ReadCloser, error)) error {
+	src, err := open(entry.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("open %q: %w", entry.ObjectKey, err)
+	}
+	defer src.Close()
+
+	// PDF 本身已压缩，Store 省去无效的二次压缩。
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: entry.Name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("copy %q: %w", entry.ObjectKey, err)
+	}
+	return nil
+}
+
+// bundleFileName 由简历标题生成包内文件名：去掉路径分隔符与控制字符，标题为空时用 Resume-<id>；
+// 重名时追加序号，如 "后端 (2).pdf"。used 记录已分配的名字。
+func bundleFileName(title string, resumeID uint, used map[string]int) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(title))
+	name = strings.TrimSpace(strings.TrimSuffix(name, ".pdf"))
+	if strings.Trim(name, ".") == "" {
+		name = fmt.Sprintf("Resume-%d", resumeID)
+	}
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+
+	used[name]++
+	if n := used[name]; n > 1 {
+		return fmt.Sprintf("%s (%d).pdf", name, n)
+	}
+	return name + ".pdf"
+}
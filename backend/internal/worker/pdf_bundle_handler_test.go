@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBundleFileName(t *testing.T) {
+	used := map[string]int{}
+	cases := []struct {
+		title string
+		id    uint
+		want  string
+	}{
+		{"后端工程师", 1, "后端工程师.pdf"},
+		{"后端工程师", 2, "后端工程师 (2).pdf"},
+		{"  ../a/b:c  ", 3, "..abc.pdf"},
+		{"", 4, "Resume-4.pdf"},
+		{"...", 5, "Resume-5.pdf"},
+		{"cv.pdf", 6, "cv.pdf"},
+	}
+	for _, tc := range cases {
+		if got := bundleFileName(tc.title, tc.id, used); got != tc.want {
+			t.Fatalf("bundleFileName(%q) = %q want %q", tc.title, got, tc.want)
+		}
+	}
+}
+
+func TestWritePDFBundle(t *testing.T) {
+	objects := map[string]string{"a.pdf": "%PDF-a", "b.pdf": "%PDF-b"}
+	open := func(key string) (io.ReadCloser, error) {
+		data, ok := objects[key]
+		if !ok {
+			return nil, errors.New("missing")
+		}
+		return io.NopCloser(strings.NewReader(data)), nil
+	}
+
+	var buf bytes.Buffer
+	entries := []bundleEntry{{Name: "A.pdf", ObjectKey: "a.pdf"}, {Name: "B.pdf", ObjectKey: "b.pdf"}}
+	if err := writePDFBundle(&buf, entries, open); err != nil {
+		t.Fatalf("writePDFBundle: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	if len(zr.File) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(zr.File))
+	}
+	for i, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name != entries[i].Name || string(data) != objects[entries[i].ObjectKey] {
+			t.Fatalf("unexpected entry %s = %q", f.Name, data)
+		}
+	}
+
+	if err := writePDFBundle(io.Discard, []bundleEntry{{Name: "X.pdf", ObjectKey: "missing"}}, open); err == nil {
+		t.Fatal("expected error for missing object")
+	}
+}
@@ -0,0 +1,21 @@
+package worker
+
+// pdfCacheDecision 表示 Worker 在渲染前对 PDF 缓存的判定结果。
+type pdfCacheDecision int
+
+const (
+	pdfCacheRender     pdfCacheDecision = iota // 需要渲染
+	pdfCacheHit                                // 已有与当前内容一致的 PDF，直接复用
+	pdfCacheSuperseded                         // 入队后内容已被修改，本任务作废
+)
+
+// decidePDFCache 根据入队时哈希、当前内容哈希与已缓存 PDF 的哈希决定是否渲染。
+func decidePDFCache(requestedHash, currentHash, cachedHash, pdfURL string) pdfCacheDecision {
+	if requestedHash != "" && requestedHash != currentHash {
+		return pdfCacheSuperseded
+	}
+	if pdfURL != "" && cachedHash != "" && cachedHash == currentHash {
+		return pdfCacheHit
+	}
+	return pdfCacheRender
+}
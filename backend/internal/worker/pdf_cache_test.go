@@ -0,0 +1,24 @@
+package worker
+
+import "testing"
+
+func TestDecidePDFCache(t *testing.T) {
+	cases := []struct {
+		name                               string
+		requested, current, cached, pdfURL string
+		want                               pdfCacheDecision
+	}{
+		{name: "miss without pdf", requested: "h1", current: "h1", want: pdfCacheRender},
+		{name: "miss with stale pdf", requested: "h2", current: "h2", cached: "h1", pdfURL: "generated-resumes/1/1/a.pdf", want: pdfCacheRender},
+		{name: "hit", requested: "h1", current: "h1", cached: "h1", pdfURL: "generated-resumes/1/1/a.pdf", want: pdfCacheHit},
+		{name: "superseded during queue", requested: "h1", current: "h2", cached: "h1", pdfURL: "generated-resumes/1/1/a.pdf", want: pdfCacheSuperseded},
+		{name: "legacy payload without hash renders", requested: "", current: "h1", want: pdfCacheRender},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := decidePDFCache(tc.requested, tc.current, tc.cached, tc.pdfURL); got != tc.want {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
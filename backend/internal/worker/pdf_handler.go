@@ -18,39 +18,44 @@ import (
 
 	"phResume/internal/database"
 	"phResume/internal/errcode"
+	resumepkg "phResume/internal/resume"
+	"phResume/internal/resumehtml"
 	"phResume/internal/storage"
 	"phResume/internal/tasks"
+	"phResume/internal/tracecontext"
 )
 
 // PDFTaskHandler 负责消费 PDF 生成任务。
 type PDFTaskHandler struct {
 	db                 *gorm.DB
 	storage            *storage.Client
-	redisClient        *redis.Client
+	redisClient        redis.UniversalClient
 	logger             *slog.Logger
 	internalSecret     string
 	internalAPIBaseURL string
 	frontendBaseURL    string
+	renderLimiter      *userRenderLimiter
+	browserLaunch      browserLaunchPolicy
+	renderMode         string
+	previewFormat      previewImageFormat
+	renderSlots        *RenderSemaphore
 }
 
 // NewPDFTaskHandler 创建任务处理器。
-func NewPDFTaskHandler(
-	db *gorm.DB,
-	storage *storage.Client,
-	redisClient *redis.Client,
-	logger *slog.Logger,
-	internalSecret string,
-	internalAPIBaseURL string,
-	frontendBaseURL string,
-) *PDFTaskHandler {
+func NewPDFTaskHandler(db *gorm.DB, storage *storage.Client, redisClient redis.UniversalClient, logger *slog.Logger, opts RenderOptions) *PDFTaskHandler {
 	return &PDFTaskHandler{
 		db:                 db,
 		storage:            storage,
 		redisClient:        redisClient,
 		logger:             logger,
-		internalSecret:     internalSecret,
-		internalAPIBaseURL: strings.TrimRight(strings.TrimSpace(internalAPIBaseURL), "/"),
-		frontendBaseURL:    strings.TrimRight(strings.TrimSpace(frontendBaseURL), "/"),
+		internalSecret:     opts.InternalSecret,
+		internalAPIBaseURL: opts.internalAPIBase(),
+		frontendBaseURL:    opts.frontendBase(),
+		renderLimiter:      newUserRenderLimiter(redisClient, opts.MaxConcurrentRendersPerUser),
+		browserLaunch:      opts.browserLaunch(),
+		renderMode:         normalizeRenderMode(opts.RenderMode),
+		previewFormat:      opts.previewImageFormat(),
+		renderSlots:        opts.RenderSemaphore,
 	}
 }
 
@@ -64,12 +69,28 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 		return err
 	}
 
+	// 沿用入队请求的 trace（缺失时新建），任务 span 随 ctx 传给内部接口调用与渲染步骤。
+	ctx, span := startTaskSpan(ctx, tasks.TypePDFGenerate, payload.TraceParent, payload.CorrelationID)
+	defer func() { endSpan(span, retErr) }()
 	log = log.With(
 		slog.String("correlation_id", payload.CorrelationID),
+		slog.String("trace_id", span.TraceParent().TraceID),
 		slog.Int("resume_id", int(payload.ResumeID)),
 	)
 	log.Info("Starting WYSIWYG PDF generation task...")
 
+	defer func() {
+		if !pdfTaskFinished(retErr, isFinalAsynqAttempt(ctx)) {
+			return
+		}
+		taskID, _ := asynq.GetTaskID(ctx)
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := releasePDFInflight(releaseCtx, h.redisClient, payload.ResumeID, taskID); err != nil {
+			log.Warn("release pdf inflight marker failed", slog.Any("error", err))
+		}
+	}()
+
 	var resume database.Resume
 	if err := h.db.WithContext(ctx).First(&resume, payload.ResumeID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -82,11 +103,16 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 
 	log = log.With(slog.Uint64("user_id", uint64(resume.UserID)))
 
+	if _, err := resolvePrintOwner(payload.UserID, resume.UserID); err != nil {
+		log.Error("pdf task owner mismatch, skipping task", slog.Uint64("payload_user_id", uint64(payload.UserID)))
+		return err
+	}
+
 	defer func() {
 		if retErr == nil {
 			return
 		}
-		if !isFinalAsynqAttempt(ctx) {
+		if errors.Is(retErr, ErrUserRenderLimit) || errors.Is(retErr, errPDFTaskCancelled) || !isFinalAsynqAttempt(ctx) {
 			return
 		}
 
@@ -100,28 +126,76 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 		if err := h.publishPDFGenerationNotify(ctx, resume.UserID, notify); err != nil {
 			log.Error("publish pdf error notification failed", slog.Any("error", err))
 		}
+		h.recordRenderEvent(ctx, log, resume, database.ResumeEventRenderFailed, payload.CorrelationID, notify.ErrorMessage)
 	}()
 
-	pdfBytes, page, cleanup, missingKeys, resourceMissing, err := h.generatePDFFromFrontend(ctx, resume.ID, payload.CorrelationID)
+	// 取消检查失败时放行：宁可多渲染一次，也不因 Redis 抖动丢任务。
+	cancelled, err := isPDFTaskCancelled(ctx, h.redisClient, resume.ID)
 	if err != nil {
-		log.Error("generate pdf via frontend failed", slog.Any("error", err))
+		log.Warn("check pdf cancel flag failed", slog.Any("error", err))
+	}
+	if cancelled {
+		log.Info("pdf task cancelled, skipping render")
+		return cancelledTaskError()
+	}
+
+	contentHash := resumepkg.ContentHash(resume.Content)
+	switch decidePDFCache(payload.ContentHash, contentHash, resume.PdfContentHash, resume.PdfUrl) {
+	case pdfCacheSuperseded:
+		log.Info("resume content changed since enqueue, skipping superseded task")
+		notify := PDFGenerationNotifyMessage{
+			Status:        "error",
+			ResumeID:      resume.ID,
+			CorrelationID: payload.CorrelationID,
+			ErrorCode:     errcode.ContentSuperseded,
+		}
+		if err := h.publishPDFGenerationNotify(ctx, resume.UserID, notify); err != nil {
+			log.Error("publish superseded notification failed", slog.Any("error", err))
+		}
+		h.recordRenderEvent(ctx, log, resume, database.ResumeEventRenderFailed, payload.CorrelationID, "content changed since enqueue")
+		return nil
+	case pdfCacheHit:
+		log.Info("pdf for current content already exists, skipping render")
+		notify := PDFGenerationNotifyMessage{
+			Status:        "completed",
+			ResumeID:      resume.ID,
+			CorrelationID: payload.CorrelationID,
+			ErrorCode:     errcode.OK,
+		}
+		if err := h.publishPDFGenerationNotify(ctx, resume.UserID, notify); err != nil {
+			log.Error("publish redis notification failed", slog.Any("error", err))
+			return err
+		}
+		h.recordRenderEvent(ctx, log, resume, database.ResumeEventRenderCompleted, payload.CorrelationID, "cache hit")
+		return nil
+	}
+
+	releaseSlot, err := h.renderLimiter.acquire(ctx, resume.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserRenderLimit) {
+			log.Info("user concurrent render limit reached, deferring task")
+		} else {
+			log.Error("acquire user render slot failed", slog.Any("error", err))
+		}
 		return err
 	}
-	defer cleanup()
+	defer releaseSlot()
 
-	objectName := fmt.Sprintf("generated-resumes/%d/%s.pdf", resume.UserID, uuid.NewString())
-	pdfReader := bytes.NewReader(pdfBytes)
-	if _, err := h.storage.UploadFile(ctx, objectName, pdfReader, int64(len(pdfBytes)), "application/pdf"); err != nil {
-		log.Error("upload pdf to minio failed", slog.Any("error", err))
+	releaseRender, err := h.renderSlots.acquire(ctx)
+	if err != nil {
+		log.Error("acquire render slot failed", slog.Any("error", err))
 		return err
 	}
+	defer releaseRender()
 
-	update := map[string]any{
-		"pdf_url": objectName,
-		"status":  "completed",
+	pdfBytes, page, cleanup, missingKeys, resourceMissing, err := h.generatePDF(ctx, log, resume.ID, resume.UserID, payload.CorrelationID)
+	if err != nil {
+		log.Error("generate pdf failed", slog.Any("error", err))
+		return err
 	}
-	if err := h.db.WithContext(ctx).Model(&resume).Updates(update).Error; err != nil {
-		log.Error("update resume failed", slog.Any("error", err))
+	defer cleanup()
+
+	if _, err := h.storePDF(ctx, log, &resume, contentHash, pdfBytes); err != nil {
 		return err
 	}
 
@@ -134,7 +208,6 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 	}
 	if resourceMissing {
 		notify.ErrorCode = errcode.ResourceMissing
-		notify.ErrorMessage = "部分图片资源缺失/无效，已自动跳过并继续生成"
 		notify.MissingKeys = missingKeys
 		log.Warn("pdf generated with missing assets",
 			slog.Int("missing_count", len(missingKeys)),
@@ -145,6 +218,7 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 		log.Error("publish redis notification failed", slog.Any("error", err))
 		return err
 	}
+	h.recordRenderEvent(ctx, log, resume, database.ResumeEventRenderCompleted, payload.CorrelationID, "")
 
 	if err := h.generatePreviewImage(ctx, &resume, page); err != nil {
 		log.Warn("generate resume preview failed", slog.Any("error", err))
@@ -154,16 +228,46 @@ func (h *PDFTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr
 	return nil
 }
 
-func (h *PDFTaskHandler) publishPDFGenerationNotify(ctx context.Context, userID uint, notify PDFGenerationNotifyMessage) error {
-	data, err := json.Marshal(notify)
-	if err != nil {
-		return fmt.Errorf("marshal notification payload: %w", err)
+// storePDF 上传渲染好的 PDF，并在同一事务内更新简历的 pdf_url / 内容哈希与用户存储用量，返回对象 key。
+func (h *PDFTaskHandler) storePDF(ctx context.Context, log *slog.Logger, resume *database.Resume, contentHash string, pdfBytes []byte) (string, error) {
+	// 按简历分目录存放，删除简历时可按前缀清理全部历史 PDF。
+	objectName := fmt.Sprintf("generated-resumes/%d/%d/%s.pdf", resume.UserID, resume.ID, uuid.NewString())
+	pdfReader := bytes.NewReader(pdfBytes)
+	if _, err := h.storage.UploadFile(ctx, objectName, pdfReader, int64(len(pdfBytes)), "application/pdf"); err != nil {
+		log.Error("upload pdf to minio failed", slog.Any("error", err))
+		return "", err
 	}
-	channel := fmt.Sprintf("user_notify:%d", userID)
-	if err := h.redisClient.Publish(ctx, channel, data).Err(); err != nil {
-		return fmt.Errorf("publish redis notification to %q: %w", channel, err)
+
+	// 记录渲染开始时的内容哈希：渲染期间若内容被修改，下次下载会因哈希不一致而重新生成。
+	update := map[string]any{
+		"pdf_url":          objectName,
+		"pdf_content_hash": contentHash,
+		"pdf_size":         int64(len(pdfBytes)),
+		"status":           "completed",
+	}
+	// 存储用量只计当前 PDF：按新旧大小的差值调整。
+	sizeDelta := int64(len(pdfBytes)) - resume.PdfSize
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(resume).Updates(update).Error; err != nil {
+			return err
+		}
+		return database.AdjustStorageBytes(tx, resume.UserID, sizeDelta)
+	}); err != nil {
+		log.Error("update resume failed", slog.Any("error", err))
+		return "", err
+	}
+	return objectName, nil
+}
+
+func (h *PDFTaskHandler) publishPDFGenerationNotify(ctx context.Context, userID uint, msg PDFGenerationNotifyMessage) error {
+	return publishUserNotify(ctx, h.redisClient, h.logger, userID, msg)
+}
+
+// recordRenderEvent 写入 PDF 渲染结果的生命周期事件；仅用于时间线展示与审计，失败只记录日志。
+func (h *PDFTaskHandler) recordRenderEvent(ctx context.Context, log *slog.Logger, resume database.Resume, eventType, correlationID, detail string) {
+	if err := database.RecordResumeEvent(h.db.WithContext(ctx), resume.ID, resume.UserID, eventType, correlationID, detail); err != nil {
+		log.Warn("record resume event failed", slog.String("event_type", eventType), slog.Any("error", err))
 	}
-	return nil
 }
 
 func isFinalAsynqAttempt(ctx context.Context) bool {
@@ -212,7 +316,8 @@ func extractResourceMissingWarning(printData []byte) (missingKeys []string, hasW
 	return result, hasWarning
 }
 
-func (h *PDFTaskHandler) generatePDFFromFrontend(ctx context.Context, resumeID uint, correlationID string) (_ []byte, page *rod.Page, cleanup func(), missingKeys []string, resourceMissing bool, err error) {
+// generatePDF 按渲染模式经前端打印页或服务端模板渲染并导出 PDF。
+func (h *PDFTaskHandler) generatePDF(ctx context.Context, log *slog.Logger, resumeID, ownerID uint, correlationID string) (_ []byte, page *rod.Page, cleanup func(), missingKeys []string, resourceMissing bool, err error) {
 	cleanup = func() {}
 	defer func() {
 		if err != nil {
@@ -220,21 +325,43 @@ func (h *PDFTaskHandler) generatePDFFromFrontend(ctx context.Context, resumeID u
 		}
 	}()
 
-	printData, err := fetchInternalPrintData(ctx, h.internalAPIBaseURL, resumePrintPath, resumeID, h.internalSecret, correlationID)
+	printData, err := fetchInternalPrintData(ctx, h.internalAPIBaseURL, resumePrintPath, resumeID, ownerID, h.internalSecret, correlationID)
 	if err != nil {
 		return nil, nil, cleanup, nil, false, err
 	}
 	missingKeys, resourceMissing = extractResourceMissingWarning(printData)
-
-	targetURL := fmt.Sprintf("%s/print/%d", h.frontendBaseURL, resumeID)
-
-	injectionScript := buildPrintDataBootstrapScript(printData)
-	page, cleanup, err = renderFrontendPage(h.logger, targetURL, injectionScript)
+	layout := extractPrintLayout(printData)
+
+	fontFaceCSS := buildFontFaceCSS(ctx, h.storage, layout.CustomFonts, h.logger)
+	_, renderSpan := tracecontext.StartSpan(ctx, "worker.render_page", tracecontext.SpanKindInternal)
+	source := renderModeFrontend
+	if h.renderMode == renderModeServer {
+		source = renderModeServer
+	} else {
+		targetURL := fmt.Sprintf("%s/print/%d", h.frontendBaseURL, resumeID)
+		injectionScript := buildPrintDataBootstrapScript(printData)
+		page, cleanup, err = renderFrontendPage(h.logger, targetURL, injectionScript, fontFaceCSS, layout, h.browserLaunch)
+		if shouldFallbackToServerRender(h.renderMode, err) {
+			log.Warn("frontend render failed, falling back to server template", slog.Any("error", err))
+			source = renderModeServer
+		}
+	}
+	if source == renderModeServer {
+		var html []byte
+		html, err = resumehtml.Build(printData, fontFaceCSS)
+		if err == nil {
+			page, cleanup, err = renderHTMLPage(ctx, h.logger, html, h.browserLaunch, false)
+		}
+	}
+	renderSpan.SetAttribute("render.source", source)
+	endSpan(renderSpan, err)
 	if err != nil {
 		return nil, nil, cleanup, missingKeys, resourceMissing, err
 	}
 
-	data, err := exportPDF(page)
+	_, exportSpan := tracecontext.StartSpan(ctx, "worker.export_pdf", tracecontext.SpanKindInternal)
+	data, err := exportPDF(page, layout)
+	endSpan(exportSpan, err)
 	if err != nil {
 		return nil, nil, cleanup, missingKeys, resourceMissing, err
 	}
@@ -243,33 +370,5 @@ func (h *PDFTaskHandler) generatePDFFromFrontend(ctx context.Context, resumeID u
 }
 
 func (h *PDFTaskHandler) generatePreviewImage(ctx context.Context, resume *database.Resume, page *rod.Page) error {
-	const (
-		previewQuality = 80
-		presignTTL     = 7 * 24 * time.Hour
-	)
-
-	previewBytes, err := capturePreparedScreenshot(page, previewQuality)
-	if err != nil {
-		return fmt.Errorf("capture preview screenshot: %w", err)
-	}
-
-	objectName := fmt.Sprintf("thumbnails/resume/%d/preview.jpg", resume.ID)
-	reader := bytes.NewReader(previewBytes)
-	if _, err := h.storage.UploadFile(ctx, objectName, reader, int64(len(previewBytes)), "image/jpeg"); err != nil {
-		return fmt.Errorf("upload preview image: %w", err)
-	}
-
-	presignedURL, err := h.storage.GeneratePresignedURL(ctx, objectName, presignTTL)
-	if err != nil {
-		return fmt.Errorf("generate preview presigned url: %w", err)
-	}
-
-	if err := h.db.WithContext(ctx).Model(resume).Updates(map[string]any{
-		"preview_image_url":  presignedURL,
-		"preview_object_key": objectName,
-	}).Error; err != nil {
-		return fmt.Errorf("update resume preview url: %w", err)
-	}
-
-	return nil
+	return saveResumePreview(ctx, h.db, h.storage, h.logger, h.previewFormat, resume, page)
 }
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+
+	"phResume/internal/tasks"
+)
+
+// pdfTaskFinished 判断本次执行后任务是否不再在途（成功、跳过重试或已是最后一次尝试）。
+// 并发名额不足的任务会被重新调度，仍视为在途。
+func pdfTaskFinished(err error, finalAttempt bool) bool {
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, ErrUserRenderLimit) {
+		return false
+	}
+	return errors.Is(err, asynq.SkipRetry) || finalAttempt
+}
+
+func releasePDFInflight(ctx context.Context, client redis.Scripter, resumeID uint, taskID string) error {
+	return tasks.ReleaseInflight(ctx, client, tasks.PDFInflightKey(resumeID), taskID)
+}
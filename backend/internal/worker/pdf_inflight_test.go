@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestPDFTaskFinished(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		final bool
+		want  bool
+	}{
+		{name: "success", err: nil, want: true},
+		{name: "skip retry", err: fmt.Errorf("cancelled: %w", asynq.SkipRetry), want: true},
+		{name: "retryable failure", err: errors.New("boom"), want: false},
+		{name: "final failure", err: errors.New("boom"), final: true, want: true},
+		{name: "render limit", err: ErrUserRenderLimit, final: true, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pdfTaskFinished(tc.err, tc.final); got != tc.want {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
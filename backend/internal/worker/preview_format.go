@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	previewFormatJPEG = "jpeg"
+	previewFormatWebP = "webp"
+
+	defaultPreviewQuality = 80
+)
+
+// previewImageFormat 描述缩略图的编码格式与压缩质量，决定截图参数、content-type 与对象扩展名。
+// 文本密集的缩略图在 JPEG 下容易出现振铃伪影，可改用 WebP（质量取 100 时接近无损）。
+type previewImageFormat struct {
+	Format  string
+	Quality int
+}
+
+// newPreviewImageFormat 规范化配置值：未知格式回退 JPEG，质量越界回退默认值 80。
+func newPreviewImageFormat(format string, quality int) previewImageFormat {
+	f := previewImageFormat{Format: strings.ToLower(strings.TrimSpace(format)), Quality: quality}
+	if f.Format != previewFormatWebP {
+		f.Format = previewFormatJPEG
+	}
+	if f.Quality < 1 || f.Quality > 100 {
+		f.Quality = defaultPreviewQuality
+	}
+	return f
+}
+
+func (f previewImageFormat) screenshotFormat() proto.PageCaptureScreenshotFormat {
+	if f.Format == previewFormatWebP {
+		return proto.PageCaptureScreenshotFormatWebp
+	}
+	return proto.PageCaptureScreenshotFormatJpeg
+}
+
+func (f previewImageFormat) contentType() string {
+	if f.Format == previewFormatWebP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+// objectName 返回缩略图对象名 preview.<ext>，prefix 需以 "/" 结尾。
+func (f previewImageFormat) objectName(prefix string) string {
+	if f.Format == previewFormatWebP {
+		return prefix + "preview.webp"
+	}
+	return prefix + "preview.jpg"
+}
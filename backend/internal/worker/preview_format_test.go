@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestNewPreviewImageFormat_DefaultsToJPEG80(t *testing.T) {
+	f := newPreviewImageFormat("", 0)
+	if f.Format != previewFormatJPEG || f.Quality != defaultPreviewQuality {
+		t.Fatalf("unexpected default format: %+v", f)
+	}
+	if f.screenshotFormat() != proto.PageCaptureScreenshotFormatJpeg || f.contentType() != "image/jpeg" {
+		t.Fatalf("unexpected jpeg encoding: %+v", f)
+	}
+	if got := f.objectName("thumbnails/resume/1/"); got != "thumbnails/resume/1/preview.jpg" {
+		t.Fatalf("unexpected object name %q", got)
+	}
+}
+
+func TestNewPreviewImageFormat_WebP(t *testing.T) {
+	f := newPreviewImageFormat(" WebP ", 100)
+	if f.Format != previewFormatWebP || f.Quality != 100 {
+		t.Fatalf("unexpected webp format: %+v", f)
+	}
+	if f.screenshotFormat() != proto.PageCaptureScreenshotFormatWebp || f.contentType() != "image/webp" {
+		t.Fatalf("unexpected webp encoding: %+v", f)
+	}
+	if got := f.objectName("thumbnails/template/2/"); got != "thumbnails/template/2/preview.webp" {
+		t.Fatalf("unexpected object name %q", got)
+	}
+}
+
+func TestNewPreviewImageFormat_ClampsInvalidQuality(t *testing.T) {
+	if f := newPreviewImageFormat("jpeg", 101); f.Quality != defaultPreviewQuality {
+		t.Fatalf("expected quality fallback, got %d", f.Quality)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"phResume/internal/tracecontext"
 )
 
 const (
@@ -16,8 +18,9 @@ const (
 )
 
 // fetchInternalPrintData 从后端内部打印接口拉取 JSON 数据。
-// 只允许 Worker 通过 Header 携带 INTERNAL_API_SECRET 访问。
-func fetchInternalPrintData(ctx context.Context, internalAPIBaseURL string, resourcePath string, id uint, secret string, correlationID string) ([]byte, error) {
+// 只允许 Worker 通过 Header 携带 INTERNAL_API_SECRET 访问；ownerID 通过 X-Print-Owner-ID 声明期望的所有者，
+// 后端会据此拒绝所有权不匹配的请求。请求在 ctx 所在 trace 下以 client span 记录，并通过 traceparent 头传播给 API。
+func fetchInternalPrintData(ctx context.Context, internalAPIBaseURL string, resourcePath string, id uint, ownerID uint, secret string, correlationID string) (_ []byte, err error) {
 	secret = strings.TrimSpace(secret)
 	if secret == "" {
 		return nil, fmt.Errorf("internal api secret missing")
@@ -28,15 +31,23 @@ func fetchInternalPrintData(ctx context.Context, internalAPIBaseURL string, reso
 		return nil, fmt.Errorf("internal api base url missing")
 	}
 
+	ctx, span := tracecontext.StartSpan(ctx, "worker.fetch_print_data", tracecontext.SpanKindClient)
+	defer func() { endSpan(span, err) }()
+	span.SetAttribute("print.resource", strings.TrimPrefix(resourcePath, "/"))
+
 	targetURL := fmt.Sprintf("%s/v1/%s/%d", internalAPIBaseURL, strings.TrimPrefix(resourcePath, "/"), id)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("build internal request: %w", err)
 	}
 	req.Header.Set("X-Internal-Secret", secret)
+	req.Header.Set("X-Print-Owner-ID", strconv.FormatUint(uint64(ownerID), 10))
 	if strings.TrimSpace(correlationID) != "" {
 		req.Header.Set("X-Correlation-ID", correlationID)
 	}
+	if tp, ok := tracecontext.FromContext(ctx); ok {
+		req.Header.Set(tracecontext.Header, tp.String())
+	}
 
 	client := &http.Client{Timeout: 15 * time.Second}
 	resp, err := client.Do(req)
@@ -44,6 +55,7 @@ func fetchInternalPrintData(ctx context.Context, internalAPIBaseURL string, reso
 		return nil, fmt.Errorf("request internal print data: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttribute("http.response.status_code", strconv.Itoa(resp.StatusCode))
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 8*1024))
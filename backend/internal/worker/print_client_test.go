@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"phResume/internal/tracecontext"
+)
+
+func TestFetchInternalPrintData_PropagatesTraceHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	upstream := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := tracecontext.ContextWithRemote(context.Background(), upstream)
+	if _, err := fetchInternalPrintData(ctx, srv.URL, resumePrintPath, 1, 2, "secret", "corr-1"); err != nil {
+		t.Fatalf("fetch print data: %v", err)
+	}
+	sent, ok := tracecontext.Parse(got.Get(tracecontext.Header))
+	if !ok || sent.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sent.ParentID == "00f067aa0ba902b7" {
+		t.Fatalf("expected traceparent of a child span in the upstream trace, got %q", got.Get(tracecontext.Header))
+	}
+	if got.Get("X-Correlation-ID") != "corr-1" {
+		t.Fatalf("expected correlation id to be forwarded, got %q", got.Get("X-Correlation-ID"))
+	}
+
+	if _, err := fetchInternalPrintData(context.Background(), srv.URL, resumePrintPath, 1, 2, "secret", ""); err != nil {
+		t.Fatalf("fetch print data: %v", err)
+	}
+	if _, ok := tracecontext.Parse(got.Get(tracecontext.Header)); !ok {
+		t.Fatalf("expected a new root traceparent without upstream trace, got %q", got.Get(tracecontext.Header))
+	}
+}
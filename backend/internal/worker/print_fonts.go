@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	resumepkg "phResume/internal/resume"
+	"phResume/internal/resumehtml"
+)
+
+// customFontURLTTL 为注入打印页的字体预签名链接有效期，只需覆盖一次渲染。
+const customFontURLTTL = 15 * time.Minute
+
+type fontURLPresigner interface {
+	GeneratePresignedURL(ctx context.Context, objectKey string, duration time.Duration) (string, error)
+}
+
+// buildFontFaceCSS 为打印数据中的自定义字体签发预签名 URL 并生成 @font-face 规则。
+// 单个字体签名失败只记录日志并跳过（页面回退到默认字体），不影响整体渲染。
+func buildFontFaceCSS(ctx context.Context, presigner fontURLPresigner, fonts []resumepkg.CustomFont, logger *slog.Logger) string {
+	if presigner == nil || len(fonts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, font := range fonts {
+		family, ok := resumepkg.NormalizeFontFamily(font.Family)
+		format := resumehtml.FontFormat(font.ObjectKey)
+		if !ok || format == "" {
+			logger.Warn("skip invalid custom font", slog.String("family", font.Family), slog.String("object_key", font.ObjectKey))
+			continue
+		}
+		url, err := presigner.GeneratePresignedURL(ctx, font.ObjectKey, customFontURLTTL)
+		if err != nil {
+			logger.Warn("presign custom font failed", slog.String("object_key", font.ObjectKey), slog.Any("error", err))
+			continue
+		}
+		b.WriteString(resumehtml.FontFaceRule(family, url, format))
+	}
+	return b.String()
+}
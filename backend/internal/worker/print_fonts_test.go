@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	resumepkg "phResume/internal/resume"
+)
+
+type fakeFontPresigner struct {
+	failKey string
+}
+
+func (p fakeFontPresigner) GeneratePresignedURL(_ context.Context, objectKey string, _ time.Duration) (string, error) {
+	if objectKey == p.failKey {
+		return "", errors.New("presign failed")
+	}
+	return "https://minio.example/" + objectKey + "?sig=1", nil
+}
+
+func TestBuildFontFaceCSS(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	css := buildFontFaceCSS(context.Background(), fakeFontPresigner{failKey: "user-assets/1/broken.ttf"}, []resumepkg.CustomFont{
+		{Family: "Brand Sans", ObjectKey: "user-assets/1/a.woff2"},
+		{Family: "Serif", ObjectKey: "user-assets/1/b.ttf"},
+		{Family: "Broken", ObjectKey: "user-assets/1/broken.ttf"},
+		{Family: `Evil"}`, ObjectKey: "user-assets/1/c.ttf"},
+		{Family: "Otf", ObjectKey: "user-assets/1/d.otf"},
+	}, logger)
+
+	for _, want := range []string{
+		`font-family: "Brand Sans"; src: url("https://minio.example/user-assets/1/a.woff2?sig=1") format("woff2")`,
+		`font-family: "Serif"; src: url("https://minio.example/user-assets/1/b.ttf?sig=1") format("truetype")`,
+	} {
+		if !strings.Contains(css, want) {
+			t.Fatalf("expected css to contain %q, got:\n%s", want, css)
+		}
+	}
+	if strings.Count(css, "@font-face") != 2 {
+		t.Fatalf("expected only valid fonts to be emitted, got:\n%s", css)
+	}
+}
+
+func TestExtractPrintLayout_CustomFonts(t *testing.T) {
+	layout := extractPrintLayout([]byte(`{"layout_settings":{"custom_fonts":[{"family":"Brand","object_key":"user-assets/1/a.woff2"}]}}`))
+	if len(layout.CustomFonts) != 1 || layout.CustomFonts[0].Family != "Brand" {
+		t.Fatalf("unexpected custom fonts %+v", layout.CustomFonts)
+	}
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"encoding/json"
+
+	resumepkg "phResume/internal/resume"
+)
+
+// printLayout 为 Worker 渲染/导出 PDF 时需要感知的版式参数，来源于打印数据的 layout_settings。
+type printLayout struct {
+	PageSize    string
+	MultiPage   bool
+	CustomFonts []resumepkg.CustomFont
+	// MarginInches 为导出时四边页边距（英寸），打印页自带版心，仅内部 HTML 转 PDF 接口设置。
+	MarginInches float64
+}
+
+// paperSize 以英寸表示纸张宽高（与 Page.printToPDF 参数单位一致）。
+type paperSize struct {
+	Width  float64
+	Height float64
+	// CSS 为 @page size 使用的关键字。
+	CSS string
+}
+
+var paperSizes = map[string]paperSize{
+	resumepkg.PageSizeA4:     {Width: 8.27, Height: 11.69, CSS: "A4"},
+	resumepkg.PageSizeLetter: {Width: 8.5, Height: 11, CSS: "letter"},
+}
+
+func extractPrintLayout(printData []byte) printLayout {
+	var meta struct {
+		LayoutSettings struct {
+			PageSize    string                 `json:"page_size"`
+			MultiPage   bool                   `json:"multi_page"`
+			CustomFonts []resumepkg.CustomFont `json:"custom_fonts"`
+		} `json:"layout_settings"`
+	}
+	_ = json.Unmarshal(printData, &meta)
+	return printLayout{
+		PageSize:    resumepkg.NormalizePageSize(meta.LayoutSettings.PageSize),
+		MultiPage:   meta.LayoutSettings.MultiPage,
+		CustomFonts: meta.LayoutSettings.CustomFonts,
+	}
+}
+
+func (l printLayout) paper() paperSize {
+	if size, ok := paperSizes[resumepkg.NormalizePageSize(l.PageSize)]; ok {
+		return size
+	}
+	return paperSizes[resumepkg.PageSizeA4]
+}
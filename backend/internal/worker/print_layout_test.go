@@ -0,0 +1,36 @@
+package worker
+
+import "testing"
+
+func TestExtractPrintLayout_PageSize(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      string
+		wantWidth float64
+		wantCSS   string
+	}{
+		{name: "unset", data: `{"layout_settings":{"columns":24}}`, wantWidth: 8.27, wantCSS: "A4"},
+		{name: "letter", data: `{"layout_settings":{"page_size":"Letter"}}`, wantWidth: 8.5, wantCSS: "letter"},
+		{name: "case insensitive", data: `{"layout_settings":{"page_size":" letter "}}`, wantWidth: 8.5, wantCSS: "letter"},
+		{name: "unknown", data: `{"layout_settings":{"page_size":"A3"}}`, wantWidth: 8.27, wantCSS: "A4"},
+		{name: "invalid json", data: `not-json`, wantWidth: 8.27, wantCSS: "A4"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			paper := extractPrintLayout([]byte(tc.data)).paper()
+			if paper.Width != tc.wantWidth || paper.CSS != tc.wantCSS {
+				t.Fatalf("paper = %+v, want width %v css %q", paper, tc.wantWidth, tc.wantCSS)
+			}
+		})
+	}
+}
+
+func TestExtractPrintLayout_MultiPage(t *testing.T) {
+	if extractPrintLayout([]byte(`{"layout_settings":{}}`)).MultiPage {
+		t.Fatalf("multi page should default to false")
+	}
+	if !extractPrintLayout([]byte(`{"layout_settings":{"multi_page":true}}`)).MultiPage {
+		t.Fatalf("multi page flag not read from layout_settings")
+	}
+}
@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// errPrintOwnerMismatch 表示任务 payload 声明的所有者与数据库记录不一致，任务不再渲染且不重试。
+var errPrintOwnerMismatch = errors.New("print task owner mismatch")
+
+// resolvePrintOwner 交叉校验 payload 携带的所有者与记录的实际所有者，返回调用内部打印接口时声明的所有者。
+// payloadOwner 为 0 表示升级前入队的旧任务，按记录所有者处理。
+func resolvePrintOwner(payloadOwner, recordOwner uint) (uint, error) {
+	if payloadOwner != 0 && payloadOwner != recordOwner {
+		return 0, errors.Join(fmt.Errorf("%w: payload owner %d, record owner %d", errPrintOwnerMismatch, payloadOwner, recordOwner), asynq.SkipRetry)
+	}
+	return recordOwner, nil
+}
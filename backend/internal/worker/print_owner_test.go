@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestResolvePrintOwner(t *testing.T) {
+	if owner, err := resolvePrintOwner(3, 3); err != nil || owner != 3 {
+		t.Fatalf("expected matching owner to pass, got %d %v", owner, err)
+	}
+	if owner, err := resolvePrintOwner(0, 5); err != nil || owner != 5 {
+		t.Fatalf("expected legacy payload to fall back to record owner, got %d %v", owner, err)
+	}
+	_, err := resolvePrintOwner(3, 4)
+	if !errors.Is(err, errPrintOwnerMismatch) || !errors.Is(err, asynq.SkipRetry) {
+		t.Fatalf("expected mismatch to skip retry, got %v", err)
+	}
+}
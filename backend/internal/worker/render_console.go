@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// 控制台错误缓冲上限：仅用于拼进失败错误信息，避免前端刷屏导致超长错误串。
+const (
+	maxConsoleErrorEntries    = 20
+	maxConsoleErrorEntryBytes = 512
+	maxConsoleErrorTotalBytes = 4096
+)
+
+// consoleErrorLog 收集打印页运行期的 console.error 与未捕获异常。
+type consoleErrorLog struct {
+	mu      sync.Mutex
+	entries []string
+	size    int
+	dropped int
+}
+
+// watchConsoleErrors 在导航前挂载监听，事件循环随页面关闭结束。
+func watchConsoleErrors(page *rod.Page) *consoleErrorLog {
+	log := &consoleErrorLog{}
+	wait := page.EachEvent(
+		func(e *proto.RuntimeConsoleAPICalled) {
+			if e.Type == proto.RuntimeConsoleAPICalledTypeError || e.Type == proto.RuntimeConsoleAPICalledTypeAssert {
+				log.add(formatConsoleAPICall(e))
+			}
+		},
+		func(e *proto.RuntimeExceptionThrown) {
+			log.add(formatExceptionThrown(e))
+		},
+	)
+	go wait()
+	return log
+}
+
+func (l *consoleErrorLog) add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return
+	}
+	if len(entry) > maxConsoleErrorEntryBytes {
+		entry = entry[:maxConsoleErrorEntryBytes] + "..."
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= maxConsoleErrorEntries || l.size+len(entry) > maxConsoleErrorTotalBytes {
+		l.dropped++
+		return
+	}
+	l.entries = append(l.entries, entry)
+	l.size += len(entry)
+}
+
+func (l *consoleErrorLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return ""
+	}
+	out := strings.Join(l.entries, " | ")
+	if l.dropped > 0 {
+		out += fmt.Sprintf(" | (%d more omitted)", l.dropped)
+	}
+	return out
+}
+
+// wrap 在已有错误后附加收集到的浏览器错误；未收集到时原样返回。
+func (l *consoleErrorLog) wrap(err error) error {
+	if err == nil || l == nil {
+		return err
+	}
+	captured := l.String()
+	if captured == "" {
+		return err
+	}
+	return fmt.Errorf("%w; browser console errors: %s", err, captured)
+}
+
+func formatConsoleAPICall(e *proto.RuntimeConsoleAPICalled) string {
+	parts := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		if arg == nil {
+			continue
+		}
+		switch {
+		case arg.Description != "":
+			parts = append(parts, arg.Description)
+		case arg.Value.Nil():
+			parts = append(parts, string(arg.Type))
+		default:
+			parts = append(parts, arg.Value.String())
+		}
+	}
+	return "console." + string(e.Type) + ": " + strings.Join(parts, " ")
+}
+
+func formatExceptionThrown(e *proto.RuntimeExceptionThrown) string {
+	details := e.ExceptionDetails
+	if details == nil {
+		return "uncaught exception"
+	}
+	message := details.Text
+	if details.Exception != nil && details.Exception.Description != "" {
+		message = details.Exception.Description
+	}
+	if details.URL != "" {
+		return fmt.Sprintf("exception: %s (%s:%d:%d)", message, details.URL, details.LineNumber+1, details.ColumnNumber+1)
+	}
+	return "exception: " + message
+}
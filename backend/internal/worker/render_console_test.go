@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestConsoleErrorLog_WrapAppendsCapturedErrors(t *testing.T) {
+	log := &consoleErrorLog{}
+	base := errors.New("wait for #pdf-render-ready: context deadline exceeded")
+
+	if err := log.wrap(base); err != base {
+		t.Fatalf("empty log should return the original error, got %v", err)
+	}
+
+	log.add(formatConsoleAPICall(&proto.RuntimeConsoleAPICalled{
+		Type: proto.RuntimeConsoleAPICalledTypeError,
+		Args: []*proto.RuntimeRemoteObject{{Type: proto.RuntimeRemoteObjectTypeObject, Description: "failed to load font"}},
+	}))
+	log.add(formatExceptionThrown(&proto.RuntimeExceptionThrown{
+		ExceptionDetails: &proto.RuntimeExceptionDetails{
+			Text:      "Uncaught",
+			URL:       "http://frontend/print/1",
+			Exception: &proto.RuntimeRemoteObject{Description: "TypeError: x is undefined"},
+		},
+	}))
+
+	err := log.wrap(base)
+	if !errors.Is(err, base) {
+		t.Fatalf("wrapped error should keep the original cause")
+	}
+	for _, want := range []string{"console.error: failed to load font", "TypeError: x is undefined", "http://frontend/print/1:1:1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
+
+func TestConsoleErrorLog_IsBounded(t *testing.T) {
+	log := &consoleErrorLog{}
+	for i := 0; i < maxConsoleErrorEntries*3; i++ {
+		log.add(strings.Repeat("x", maxConsoleErrorEntryBytes*2))
+	}
+
+	out := log.String()
+	if len(out) > maxConsoleErrorTotalBytes+200 {
+		t.Fatalf("captured log too large: %d bytes", len(out))
+	}
+	if !strings.Contains(out, "more omitted") {
+		t.Fatalf("expected omitted marker, got %q", out[len(out)-40:])
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUserRenderLimit 表示该用户的并发渲染数已达上限。
+// 任务会被稍后重试，且不计入失败次数（见 cmd/worker 中的 IsFailure）。
+var ErrUserRenderLimit = errors.New("user concurrent render limit reached")
+
+// userRenderSlotTTL 为计数键的兜底过期时间：Worker 崩溃未能释放时，计数最终会自动归零。
+const userRenderSlotTTL = 10 * time.Minute
+
+type renderSlotCounter interface {
+	Incr(ctx context.Context, key string) *redis.IntCmd
+	Decr(ctx context.Context, key string) *redis.IntCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+}
+
+// userRenderLimiter 基于 Redis 计数限制单个用户同时进行的渲染数量。
+type userRenderLimiter struct {
+	client renderSlotCounter
+	max    int
+}
+
+func newUserRenderLimiter(client renderSlotCounter, max int) *userRenderLimiter {
+	return &userRenderLimiter{client: client, max: max}
+}
+
+func userRenderSlotKey(userID uint) string {
+	return fmt.Sprintf("render:concurrent:%d", userID)
+}
+
+// acquire 占用一个渲染名额；超过上限时返回 ErrUserRenderLimit。
+// 返回的 release 使用独立的 context，确保任务超时/取消后依然能归还名额。
+func (l *userRenderLimiter) acquire(ctx context.Context, userID uint) (release func(), err error) {
+	release = func() {}
+	if l == nil || l.client == nil || l.max <= 0 {
+		return release, nil
+	}
+
+	key := userRenderSlotKey(userID)
+	count, err := l.client.Incr(ctx, key).Result()
+	if err != nil {
+		return release, fmt.Errorf("incr render slot: %w", err)
+	}
+	_ = l.client.Expire(ctx, key, userRenderSlotTTL).Err()
+
+	release = func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = l.client.Decr(releaseCtx, key).Err()
+	}
+
+	if count > int64(l.max) {
+		release()
+		return func() {}, ErrUserRenderLimit
+	}
+	return release, nil
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeSlotCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newFakeSlotCounter() *fakeSlotCounter {
+	return &fakeSlotCounter{counts: map[string]int64{}}
+}
+
+func (f *fakeSlotCounter) Incr(ctx context.Context, key string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[key]++
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(f.counts[key])
+	return cmd
+}
+
+func (f *fakeSlotCounter) Decr(ctx context.Context, key string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counts[key]--
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(f.counts[key])
+	return cmd
+}
+
+func (f *fakeSlotCounter) Expire(ctx context.Context, _ string, _ time.Duration) *redis.BoolCmd {
+	cmd := redis.NewBoolCmd(ctx)
+	cmd.SetVal(true)
+	return cmd
+}
+
+func TestUserRenderLimiter_BoundsConcurrentRenders(t *testing.T) {
+	ctx := context.Background()
+	counter := newFakeSlotCounter()
+	limiter := newUserRenderLimiter(counter, 2)
+
+	releaseA, err := limiter.acquire(ctx, 7)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	if _, err := limiter.acquire(ctx, 7); err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+	if _, err := limiter.acquire(ctx, 7); !errors.Is(err, ErrUserRenderLimit) {
+		t.Fatalf("expected ErrUserRenderLimit, got %v", err)
+	}
+	if got := counter.counts[userRenderSlotKey(7)]; got != 2 {
+		t.Fatalf("rejected acquire must not hold a slot, count=%d", got)
+	}
+
+	if _, err := limiter.acquire(ctx, 8); err != nil {
+		t.Fatalf("other user must not be limited: %v", err)
+	}
+
+	releaseA()
+	if _, err := limiter.acquire(ctx, 7); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+}
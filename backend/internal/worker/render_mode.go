@@ -0,0 +1,27 @@
+package worker
+
+import "strings"
+
+// 渲染模式（WORKER_RENDER_MODE）：frontend 只使用前端打印页；auto 在前端渲染失败时改用服务端模板；
+// server 始终使用服务端模板，不依赖前端服务。
+const (
+	renderModeFrontend = "frontend"
+	renderModeAuto     = "auto"
+	renderModeServer   = "server"
+)
+
+// normalizeRenderMode 规范化配置值，未知取值回退 frontend。
+func normalizeRenderMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case renderModeAuto, renderModeServer:
+		return mode
+	default:
+		return renderModeFrontend
+	}
+}
+
+// shouldFallbackToServerRender 判断前端渲染失败后是否改用服务端模板：仅 auto 模式生效，
+// 找不到 Chromium 等持久性启动错误换用模板也无法恢复，直接返回原错误。
+func shouldFallbackToServerRender(mode string, renderErr error) bool {
+	return renderErr != nil && mode == renderModeAuto && !isPersistentLaunchError(renderErr)
+}
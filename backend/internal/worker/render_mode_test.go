@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestNormalizeRenderMode(t *testing.T) {
+	cases := map[string]string{
+		"":          renderModeFrontend,
+		"frontend":  renderModeFrontend,
+		" AUTO ":    renderModeAuto,
+		"server":    renderModeServer,
+		"something": renderModeFrontend,
+	}
+	for input, want := range cases {
+		if got := normalizeRenderMode(input); got != want {
+			t.Fatalf("normalizeRenderMode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestShouldFallbackToServerRender(t *testing.T) {
+	frontendDown := errors.New("navigate to frontend: connection refused")
+	noChromium := fmt.Errorf("launch chromium: %w", exec.ErrNotFound)
+
+	cases := []struct {
+		name string
+		mode string
+		err  error
+		want bool
+	}{
+		{name: "auto after frontend failure", mode: renderModeAuto, err: frontendDown, want: true},
+		{name: "auto without error", mode: renderModeAuto, err: nil, want: false},
+		{name: "auto with missing chromium", mode: renderModeAuto, err: noChromium, want: false},
+		{name: "frontend mode never falls back", mode: renderModeFrontend, err: frontendDown, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shouldFallbackToServerRender(tc.mode, tc.err); got != tc.want {
+				t.Fatalf("shouldFallbackToServerRender() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"strings"
+	"time"
+)
+
+// RenderOptions 汇总渲染类 handler（PDF、简历/模板缩略图、内部 HTML 转 PDF）的配置项，由 cmd/worker 从 config.Config 填充；
+// 新增配置在此加字段，不再扩充构造函数参数。各 handler 只读取与自身相关的字段，其余字段忽略。
+type RenderOptions struct {
+	// InternalSecret 为调用 API 内部接口、校验内部 HTML 转 PDF 请求时使用的 X-Internal-Secret。
+	InternalSecret string
+	// InternalAPIBaseURL / FrontendBaseURL 为获取打印数据与打开打印页的地址。
+	InternalAPIBaseURL string
+	FrontendBaseURL    string
+
+	// BrowserLaunchAttempts / BrowserLaunchBackoff 为浏览器启动失败时的进程内重试；BrowserBin / BrowserFlags 为可执行文件与额外启动参数。
+	BrowserLaunchAttempts int
+	BrowserLaunchBackoff  time.Duration
+	BrowserBin            string
+	BrowserFlags          []string
+	// RenderSemaphore 为全局渲染名额池，所有渲染类 handler 共享。
+	RenderSemaphore *RenderSemaphore
+
+	// PreviewFormat / PreviewQuality 为缩略图的图片格式与质量。
+	PreviewFormat  string
+	PreviewQuality int
+
+	// MaxConcurrentRendersPerUser 与 RenderMode 仅 PDFTaskHandler 使用。
+	MaxConcurrentRendersPerUser int
+	RenderMode                  string
+	// HTMLToPDFMaxBytes 仅 HTMLToPDFHandler 使用，为请求体上限。
+	HTMLToPDFMaxBytes int
+}
+
+func (o RenderOptions) browserLaunch() browserLaunchPolicy {
+	return newBrowserLaunchPolicy(o.BrowserLaunchAttempts, o.BrowserLaunchBackoff, o.BrowserBin, o.BrowserFlags)
+}
+
+func (o RenderOptions) previewImageFormat() previewImageFormat {
+	return newPreviewImageFormat(o.PreviewFormat, o.PreviewQuality)
+}
+
+func (o RenderOptions) internalAPIBase() string {
+	return strings.TrimRight(strings.TrimSpace(o.InternalAPIBaseURL), "/")
+}
+
+func (o RenderOptions) frontendBase() string {
+	return strings.TrimRight(strings.TrimSpace(o.FrontendBaseURL), "/")
+}
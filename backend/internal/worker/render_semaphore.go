@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"phResume/internal/metrics"
+)
+
+// RenderSemaphore 限制进程内同时运行的浏览器渲染数量，与 asynq 的任务并发数相互独立。
+// 每个 Chromium 实例内存占用较大，PDF 与预览任务在启动浏览器前都需占用一个名额，避免突发流量下 OOM。
+type RenderSemaphore struct {
+	slots chan struct{}
+}
+
+// NewRenderSemaphore 构造渲染信号量；size <= 0 表示不限制（返回 nil）。
+func NewRenderSemaphore(size int) *RenderSemaphore {
+	if size <= 0 {
+		return nil
+	}
+	metrics.SetRenderSlotsCapacity(size)
+	return &RenderSemaphore{slots: make(chan struct{}, size)}
+}
+
+// acquire 阻塞等待一个渲染名额，ctx 结束时返回错误；返回的 release 可重复调用。
+func (s *RenderSemaphore) acquire(ctx context.Context) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return func() {}, fmt.Errorf("wait for render slot: %w", ctx.Err())
+	}
+	metrics.RenderSlotAcquired()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-s.slots
+			metrics.RenderSlotReleased()
+		})
+	}, nil
+}
+
+// inUse 返回当前占用的名额数量。
+func (s *RenderSemaphore) inUse() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.slots)
+}
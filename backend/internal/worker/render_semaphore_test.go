@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRenderSemaphore_BlocksWhenFull(t *testing.T) {
+	sem := NewRenderSemaphore(1)
+
+	release, err := sem.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	if sem.inUse() != 1 {
+		t.Fatalf("expected 1 slot in use, got %d", sem.inUse())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := sem.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded while full, got %v", err)
+	}
+
+	release()
+	release() // 重复释放不应多归还名额
+	if sem.inUse() != 0 {
+		t.Fatalf("expected 0 slots in use, got %d", sem.inUse())
+	}
+
+	release2, err := sem.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release2()
+}
+
+func TestRenderSemaphore_NilIsUnlimited(t *testing.T) {
+	sem := NewRenderSemaphore(0)
+	if sem != nil {
+		t.Fatal("expected nil semaphore for non-positive size")
+	}
+	release, err := sem.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("nil semaphore acquire: %v", err)
+	}
+	release()
+}
@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+
+	"phResume/internal/database"
+	"phResume/internal/storage"
+	"phResume/internal/tasks"
+	"phResume/internal/tracecontext"
+)
+
+// ResumePreviewHandler 负责独立的简历缩略图生成任务：只渲染打印页并截图，不导出/上传 PDF。
+type ResumePreviewHandler struct {
+	db                 *gorm.DB
+	storage            *storage.Client
+	logger             *slog.Logger
+	internalSecret     string
+	internalAPIBaseURL string
+	frontendBaseURL    string
+	browserLaunch      browserLaunchPolicy
+	previewFormat      previewImageFormat
+	renderSlots        *RenderSemaphore
+}
+
+func NewResumePreviewHandler(db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, opts RenderOptions) *ResumePreviewHandler {
+	return &ResumePreviewHandler{
+		db:                 db,
+		storage:            storageClient,
+		logger:             logger,
+		internalSecret:     opts.InternalSecret,
+		internalAPIBaseURL: opts.internalAPIBase(),
+		frontendBaseURL:    opts.frontendBase(),
+		browserLaunch:      opts.browserLaunch(),
+		previewFormat:      opts.previewImageFormat(),
+		renderSlots:        opts.RenderSemaphore,
+	}
+}
+
+func (h *ResumePreviewHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr error) {
+	log := h.logger
+
+	var payload tasks.ResumePreviewPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		log.Error("unmarshal resume preview payload failed", slog.Any("error", err))
+		return err
+	}
+
+	ctx, span := startTaskSpan(ctx, tasks.TypeResumePreview, payload.TraceParent, payload.CorrelationID)
+	defer func() { endSpan(span, retErr) }()
+	log = log.With(
+		slog.Int("resume_id", int(payload.ResumeID)),
+		slog.String("correlation_id", payload.CorrelationID),
+		slog.String("trace_id", span.TraceParent().TraceID),
+	)
+	log.Info("Starting resume preview generation task...")
+
+	var resume database.Resume
+	if err := h.db.WithContext(ctx).First(&resume, payload.ResumeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn("resume not found, skipping task")
+			return nil
+		}
+		log.Error("query resume failed", slog.Any("error", err))
+		return err
+	}
+
+	ownerID, err := resolvePrintOwner(payload.UserID, resume.UserID)
+	if err != nil {
+		log.Error("resume preview task owner mismatch, skipping task", slog.Uint64("payload_user_id", uint64(payload.UserID)))
+		return err
+	}
+
+	printData, err := fetchInternalPrintData(ctx, h.internalAPIBaseURL, resumePrintPath, resume.ID, ownerID, h.internalSecret, payload.CorrelationID)
+	if err != nil {
+		log.Error("fetch internal print data failed", slog.Any("error", err))
+		return err
+	}
+
+	releaseRender, err := h.renderSlots.acquire(ctx)
+	if err != nil {
+		log.Error("acquire render slot failed", slog.Any("error", err))
+		return err
+	}
+	defer releaseRender()
+
+	targetURL := fmt.Sprintf("%s/print/%d", h.frontendBaseURL, resume.ID)
+
+	injectionScript := buildPrintDataBootstrapScript(printData)
+	layout := extractPrintLayout(printData)
+	fontFaceCSS := buildFontFaceCSS(ctx, h.storage, layout.CustomFonts, log)
+	_, renderSpan := tracecontext.StartSpan(ctx, "worker.render_page", tracecontext.SpanKindInternal)
+	page, cleanup, err := renderFrontendPage(h.logger, targetURL, injectionScript, fontFaceCSS, layout, h.browserLaunch)
+	endSpan(renderSpan, err)
+	if err != nil {
+		log.Error("render resume page failed", slog.Any("error", err))
+		return err
+	}
+	defer cleanup()
+
+	if err := saveResumePreview(ctx, h.db, h.storage, log, h.previewFormat, &resume, page); err != nil {
+		log.Error("generate resume preview failed", slog.Any("error", err))
+		return err
+	}
+
+	log.Info("Resume preview generation completed.")
+	return nil
+}
+
+// saveResumePreview 截取已渲染打印页的缩略图，上传并写回简历预览字段；PDF 任务与独立预览任务共用。
+func saveResumePreview(ctx context.Context, db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, format previewImageFormat, resume *database.Resume, page *rod.Page) error {
+	previewBytes, err := capturePreparedScreenshot(page, format)
+	if err != nil {
+		return fmt.Errorf("capture preview screenshot: %w", err)
+	}
+
+	objectName := format.objectName(fmt.Sprintf("thumbnails/resume/%d/", resume.ID))
+	reader := bytes.NewReader(previewBytes)
+	if _, err := storageClient.UploadFile(ctx, objectName, reader, int64(len(previewBytes)), format.contentType()); err != nil {
+		return fmt.Errorf("upload preview image: %w", err)
+	}
+
+	previousKey := strings.TrimSpace(resume.PreviewObjectKey)
+
+	sizeDelta := int64(len(previewBytes)) - resume.PreviewSize
+	if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(resume).Updates(map[string]any{
+			// 预览链接由 API 读取时按 object key 签发，不再持久化预签名 URL（会过期）。
+			"preview_image_url":  "",
+			"preview_object_key": objectName,
+			"preview_size":       int64(len(previewBytes)),
+		}).Error; err != nil {
+			return err
+		}
+		return database.AdjustStorageBytes(tx, resume.UserID, sizeDelta)
+	}); err != nil {
+		return fmt.Errorf("update resume preview url: %w", err)
+	}
+	// 切换格式后旧扩展名的缩略图不再被引用，尽力删除。
+	if previousKey != "" && previousKey != objectName {
+		if err := storageClient.DeleteObject(ctx, previousKey); err != nil {
+			logger.Warn("delete stale resume preview failed", slog.String("object_key", previousKey), slog.Any("error", err))
+		}
+	}
+
+	return nil
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
-	"time"
 
 	"github.com/hibiken/asynq"
 	"gorm.io/gorm"
@@ -16,6 +15,7 @@ import (
 	"phResume/internal/database"
 	"phResume/internal/storage"
 	"phResume/internal/tasks"
+	"phResume/internal/tracecontext"
 )
 
 // TemplatePreviewHandler 负责模板缩略图生成任务。
@@ -26,27 +26,26 @@ type TemplatePreviewHandler struct {
 	internalSecret     string
 	internalAPIBaseURL string
 	frontendBaseURL    string
+	browserLaunch      browserLaunchPolicy
+	previewFormat      previewImageFormat
+	renderSlots        *RenderSemaphore
 }
 
-func NewTemplatePreviewHandler(
-	db *gorm.DB,
-	storageClient *storage.Client,
-	logger *slog.Logger,
-	internalSecret string,
-	internalAPIBaseURL string,
-	frontendBaseURL string,
-) *TemplatePreviewHandler {
+func NewTemplatePreviewHandler(db *gorm.DB, storageClient *storage.Client, logger *slog.Logger, opts RenderOptions) *TemplatePreviewHandler {
 	return &TemplatePreviewHandler{
 		db:                 db,
 		storage:            storageClient,
 		logger:             logger,
-		internalSecret:     internalSecret,
-		internalAPIBaseURL: strings.TrimRight(strings.TrimSpace(internalAPIBaseURL), "/"),
-		frontendBaseURL:    strings.TrimRight(strings.TrimSpace(frontendBaseURL), "/"),
+		internalSecret:     opts.InternalSecret,
+		internalAPIBaseURL: opts.internalAPIBase(),
+		frontendBaseURL:    opts.frontendBase(),
+		browserLaunch:      opts.browserLaunch(),
+		previewFormat:      opts.previewImageFormat(),
+		renderSlots:        opts.RenderSemaphore,
 	}
 }
 
-func (h *TemplatePreviewHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+func (h *TemplatePreviewHandler) ProcessTask(ctx context.Context, t *asynq.Task) (retErr error) {
 	log := h.logger
 
 	var payload tasks.TemplatePreviewPayload
@@ -55,9 +54,12 @@ func (h *TemplatePreviewHandler) ProcessTask(ctx context.Context, t *asynq.Task)
 		return err
 	}
 
+	ctx, span := startTaskSpan(ctx, tasks.TypeTemplatePreview, payload.TraceParent, payload.CorrelationID)
+	defer func() { endSpan(span, retErr) }()
 	log = log.With(
 		slog.Int("template_id", int(payload.TemplateID)),
 		slog.String("correlation_id", payload.CorrelationID),
+		slog.String("trace_id", span.TraceParent().TraceID),
 	)
 	log.Info("Starting template preview generation task...")
 
@@ -71,51 +73,73 @@ func (h *TemplatePreviewHandler) ProcessTask(ctx context.Context, t *asynq.Task)
 		return err
 	}
 
-	printData, err := fetchInternalPrintData(ctx, h.internalAPIBaseURL, templatePrintPath, template.ID, h.internalSecret, payload.CorrelationID)
+	ownerID, err := resolvePrintOwner(payload.UserID, template.UserID)
+	if err != nil {
+		log.Error("template preview task owner mismatch, skipping task", slog.Uint64("payload_user_id", uint64(payload.UserID)))
+		return err
+	}
+
+	printData, err := fetchInternalPrintData(ctx, h.internalAPIBaseURL, templatePrintPath, template.ID, ownerID, h.internalSecret, payload.CorrelationID)
 	if err != nil {
 		log.Error("fetch internal print data failed", slog.Any("error", err))
 		return err
 	}
 
+	releaseRender, err := h.renderSlots.acquire(ctx)
+	if err != nil {
+		log.Error("acquire render slot failed", slog.Any("error", err))
+		return err
+	}
+	defer releaseRender()
+
 	targetURL := fmt.Sprintf("%s/print-template/%d", h.frontendBaseURL, template.ID)
 
 	injectionScript := buildPrintDataBootstrapScript(printData)
-	page, cleanup, err := renderFrontendPage(h.logger, targetURL, injectionScript)
+	layout := extractPrintLayout(printData)
+	fontFaceCSS := buildFontFaceCSS(ctx, h.storage, layout.CustomFonts, log)
+	_, renderSpan := tracecontext.StartSpan(ctx, "worker.render_page", tracecontext.SpanKindInternal)
+	page, cleanup, err := renderFrontendPage(h.logger, targetURL, injectionScript, fontFaceCSS, layout, h.browserLaunch)
+	endSpan(renderSpan, err)
 	if err != nil {
 		log.Error("render template page failed", slog.Any("error", err))
 		return err
 	}
 	defer cleanup()
 
-	const previewQuality = 80
-	previewBytes, err := capturePreparedScreenshot(page, previewQuality)
+	previewBytes, err := capturePreparedScreenshot(page, h.previewFormat)
 	if err != nil {
 		log.Error("capture template screenshot failed", slog.Any("error", err))
 		return err
 	}
 
-	objectName := fmt.Sprintf("thumbnails/template/%d/preview.jpg", template.ID)
-	if _, err := h.storage.UploadFile(ctx, objectName, bytes.NewReader(previewBytes), int64(len(previewBytes)), "image/jpeg"); err != nil {
+	objectName := h.previewFormat.objectName(fmt.Sprintf("thumbnails/template/%d/", template.ID))
+	if _, err := h.storage.UploadFile(ctx, objectName, bytes.NewReader(previewBytes), int64(len(previewBytes)), h.previewFormat.contentType()); err != nil {
 		log.Error("upload template preview failed", slog.Any("error", err))
 		return err
 	}
 
-	const presignTTL = 7 * 24 * time.Hour
-	url, err := h.storage.GeneratePresignedURL(ctx, objectName, presignTTL)
-	if err != nil {
-		log.Error("generate template preview url failed", slog.Any("error", err))
-		return err
-	}
-
-	if err := h.db.WithContext(ctx).
-		Model(&template).
-		Updates(map[string]any{
-			"preview_image_url":  url,
+	previousKey := strings.TrimSpace(template.PreviewObjectKey)
+	sizeDelta := int64(len(previewBytes)) - template.PreviewSize
+	if err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&template).Updates(map[string]any{
+			// 预览链接由 API 读取时按 object key 签发，不再持久化预签名 URL（会过期）。
+			"preview_image_url":  "",
 			"preview_object_key": objectName,
+			"preview_size":       int64(len(previewBytes)),
 		}).Error; err != nil {
+			return err
+		}
+		return database.AdjustStorageBytes(tx, template.UserID, sizeDelta)
+	}); err != nil {
 		log.Error("update template preview url failed", slog.Any("error", err))
 		return err
 	}
+	// 切换格式后旧扩展名的缩略图不再被引用，尽力删除。
+	if previousKey != "" && previousKey != objectName {
+		if err := h.storage.DeleteObject(ctx, previousKey); err != nil {
+			log.Warn("delete stale template preview failed", slog.String("object_key", previousKey), slog.Any("error", err))
+		}
+	}
 
 	log.Info("Template preview generation completed.")
 	return nil
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+
+	"phResume/internal/tracecontext"
+)
+
+// startTaskSpan 以 payload 中入队请求的 traceparent 为远端父 span 开启任务的 consumer span（缺失时新建 trace）。
+func startTaskSpan(ctx context.Context, taskType, traceParent, correlationID string) (context.Context, *tracecontext.Span) {
+	ctx = tracecontext.ContextWithRemote(ctx, traceParent)
+	ctx, span := tracecontext.StartSpan(ctx, taskType, tracecontext.SpanKindConsumer)
+	span.SetAttribute("messaging.operation", "process")
+	if correlationID != "" {
+		span.SetAttribute("correlation_id", correlationID)
+	}
+	return ctx, span
+}
+
+// endSpan 记录 err（可为 nil）并结束 span。
+func endSpan(span *tracecontext.Span, err error) {
+	span.RecordError(err)
+	span.End()
+}
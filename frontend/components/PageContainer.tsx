@@ -6,6 +6,8 @@ interface PageContainerProps extends PropsWithChildren {
   width?: number;
   height?: number;
   style?: CSSProperties;
+  // 多页模式：高度随内容增长（至少一页），不再裁剪溢出内容，由打印分页接管。
+  multiPage?: boolean;
 }
 
 export function PageContainer({
@@ -13,6 +15,7 @@ export function PageContainer({
   width = 900,
   height = 1272,
   style,
+  multiPage = false,
 }: PageContainerProps) {
   const baseStyle: CSSProperties = {
     width: `${width}px`,
@@ -24,7 +27,17 @@ export function PageContainer({
     justifyContent: "stretch",
     alignItems: "stretch",
     overflow: "hidden",
+    ...(multiPage
+      ? { height: "auto", minHeight: `${height}px`, overflow: "visible" }
+      : {}),
   };
 
-  return <div style={{ ...baseStyle, ...style }}>{children}</div>;
+  return (
+    <div
+      style={{ ...baseStyle, ...style }}
+      data-pdf-pages={multiPage ? "true" : undefined}
+    >
+      {children}
+    </div>
+  );
 }
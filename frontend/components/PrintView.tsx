@@ -158,6 +158,7 @@ export function PrintView() {
       <PageContainer
         width={CANVAS_WIDTH}
         height={CANVAS_HEIGHT}
+        multiPage={Boolean(layoutSettings.multi_page)}
         style={{
           fontFamily: layoutSettings.font_family,
           fontSize: `${layoutSettings.font_size_pt}pt`,
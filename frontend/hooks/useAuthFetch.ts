@@ -2,6 +2,7 @@
 
 import { useCallback } from "react";
 import { useAuth } from "@/context/AuthContext";
+import type { ApiErrorDetail } from "@/types/api";
 
 type FetchInput = Parameters<typeof fetch>[0];
 type FetchInit = Parameters<typeof fetch>[1];
@@ -55,6 +56,20 @@ export function useAuthFetch() {
   return authFetch;
 }
 
+// readApiError 读取失败响应体中的 {"error":{code,message,fields?}}；响应体不是该结构时返回 null。
+export async function readApiError(response: Response): Promise<ApiErrorDetail | null> {
+  try {
+    const body = (await response.json()) as { error?: Partial<ApiErrorDetail> };
+    const detail = body?.error;
+    if (!detail || typeof detail.code !== "number" || typeof detail.message !== "string") {
+      return null;
+    }
+    return { code: detail.code, message: detail.message, fields: detail.fields };
+  } catch {
+    return null;
+  }
+}
+
 export function friendlyMessageForStatus(status: number, kind?: "upload" | "pdf" | "login" | "default") {
   const k = kind ?? "default";
   if (k === "upload") {
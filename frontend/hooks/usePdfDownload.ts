@@ -239,6 +239,13 @@ export function usePdfDownload({
 
       try {
         const data = await response.json();
+        // 内容未变化且已有 PDF：后端不会入队也不会推送通知，直接获取下载链接。
+        if (data?.cached === true) {
+          setTaskStatus("completed");
+          setReadyResumeId(savedResumeId);
+          void requestDownloadLink(savedResumeId, true);
+          return;
+        }
         const correlationId =
           typeof data?.correlation_id === "string" ? data.correlation_id : null;
         if (correlationId) {
@@ -262,6 +269,7 @@ export function usePdfDownload({
     authFetch,
     isAuthenticated,
     isRateLimitModalOpen,
+    requestDownloadLink,
     resetDownloadLinkState,
     resetPdfGenerationState,
     savedResumeId,
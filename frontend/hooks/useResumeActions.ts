@@ -3,7 +3,7 @@
 import { useCallback, useState, type ChangeEvent, type Dispatch, type MutableRefObject, type SetStateAction } from "react";
 import { v4 as uuidv4 } from "uuid";
 import { useRefState } from "@/hooks/useRefState";
-import { friendlyMessageForStatus } from "@/hooks/useAuthFetch";
+import { friendlyMessageForStatus, readApiError } from "@/hooks/useAuthFetch";
 import { API_ROUTES } from "@/lib/api-routes";
 import { DEFAULT_LAYOUT_SETTINGS, normalizeResumeContent } from "@/utils/resume";
 import {
@@ -326,9 +326,11 @@ export function useResumeActions({
             });
           }
           if (response.status === 403) {
+            // 资产数量与存储配额超限都返回 403，提示以服务端本地化后的 message 为准。
+            const apiError = await readApiError(response);
             showAlert({
               title: "上传上限",
-              message: `您已达到最大上传数量限制（${4}张），请一段时间后再尝试上传`,
+              message: apiError?.message ?? "您已达到上传上限，请删除部分图片后再尝试上传",
             });
             setError(null);
             throw new Error("asset limit reached");
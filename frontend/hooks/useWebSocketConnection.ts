@@ -23,6 +23,8 @@ export function useWebSocketConnection({
   const reconnectAttemptsRef = useRef(0);
   const shouldReconnectRef = useRef(true);
   const connectRef = useRef<() => void>(() => {});
+  // 最近收到的通知 id，重连鉴权时回传给服务端以补发断线期间的通知。
+  const lastEventIdRef = useRef<string | null>(null);
   const onMessageRef = useRef<typeof onMessage>(onMessage);
   const onErrorRef = useRef<typeof onError>(onError);
 
@@ -111,7 +113,13 @@ export function useWebSocketConnection({
         return;
       }
       reconnectAttemptsRef.current = 0;
-      ws.send(JSON.stringify({ type: "auth", token: accessToken }));
+      ws.send(
+        JSON.stringify({
+          type: "auth",
+          token: accessToken,
+          ...(lastEventIdRef.current ? { last_event_id: lastEventIdRef.current } : {}),
+        }),
+      );
       if (heartbeatTimerRef.current) {
         window.clearInterval(heartbeatTimerRef.current);
         heartbeatTimerRef.current = null;
@@ -130,6 +138,12 @@ export function useWebSocketConnection({
       if (typeof event.data !== "string") {
         return;
       }
+      try {
+        const parsed = JSON.parse(event.data) as { id?: unknown };
+        if (typeof parsed.id === "string" && parsed.id) {
+          lastEventIdRef.current = parsed.id;
+        }
+      } catch {}
       onMessageRef.current?.(event.data);
     };
 
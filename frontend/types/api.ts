@@ -1,9 +1,20 @@
 import type { ResumeData } from "@/types/resume";
 
+// ApiErrorDetail 与后端 errcode.Detail 一致：code 见 GET /v1/meta/error-codes，fields 仅在请求体校验失败时返回。
+export interface ApiErrorDetail {
+  code: number;
+  message: string;
+  fields?: Record<string, string>;
+}
+
+export interface ApiErrorResponse {
+  error: ApiErrorDetail;
+}
+
 export interface ApiResponse<T = unknown> {
   message?: string;
   data?: T;
-  error?: string;
+  error?: ApiErrorDetail;
 }
 
 export interface Paginated<T> {
@@ -28,6 +39,7 @@ export interface ResumeSummary {
   id: number;
   title: string;
   preview_image_url?: string;
+  tags?: string[];
   created_at: string;
 }
 
@@ -38,6 +50,11 @@ export interface Resume extends ResumeSummary {
 
 export type ResumeListResponse = ResumeSummary[];
 
+export interface ResumeTagCount {
+  tag: string;
+  count: number;
+}
+
 export interface ResumeDownloadAccepted {
   message: string;
   task_id: string;
@@ -56,6 +73,16 @@ export interface AssetItem {
 
 export interface AssetListResponse {
   items: AssetItem[];
+  nextCursor?: string | null;
+}
+
+export interface AssetUsage {
+  assetCount: number;
+  maxAssets: number;
+  storageUsed: number;
+  storageQuota: number;
+  todayUploads: number;
+  maxUploadsPerDay: number;
 }
 
 export interface AssetUploadResponse {
@@ -66,11 +93,15 @@ export interface AssetViewResponse {
   url: string;
 }
 
+export type TemplateStatus = "draft" | "pending" | "approved" | "rejected";
+
 export interface TemplateSummary {
   id: number;
   title: string;
   preview_image_url?: string;
   is_owner?: boolean;
+  is_public?: boolean;
+  status?: TemplateStatus;
 }
 
 export interface Template {
@@ -82,6 +113,13 @@ export interface Template {
 
 export type TemplateListResponse = TemplateSummary[];
 
+export interface TemplateVisibilityResponse {
+  id: number;
+  is_public: boolean;
+  status: TemplateStatus;
+  pending_review: boolean;
+}
+
 export interface TemplatePreviewAccepted {
   message: string;
   task_id: string;
@@ -6,9 +6,17 @@ export type LayoutSettings = {
   row_height_px: number;
   margin_px: number;
   enable_watermark?: boolean;
+  page_size?: "A4" | "Letter";
+  multi_page?: boolean;
+  custom_fonts?: CustomFont[];
   [key: string]: unknown;
 };
 
+export type CustomFont = {
+  family: string;
+  object_key: string;
+};
+
 export type ResumeLayout = {
   x: number;
   y: number;